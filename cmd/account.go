@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+)
+
+const defaultAccountRetentionInterval = time.Hour
+
+// getAccountRetention reads ACCOUNT_RETENTION, how long the data of a
+// customer whose wallets are all closed is kept before it is anonymized.
+func getAccountRetention() time.Duration {
+	retention, err := time.ParseDuration(os.Getenv("ACCOUNT_RETENTION"))
+	if err != nil {
+		return usecase.DefaultAccountRetention
+	}
+
+	return retention
+}
+
+// getAccountRetentionInterval reads ACCOUNT_RETENTION_INTERVAL, how often the
+// accounts past their retention are anonymized.
+func getAccountRetentionInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("ACCOUNT_RETENTION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return defaultAccountRetentionInterval
+	}
+
+	return interval
+}
+
+// runAccountRetention anonymizes the accounts past their retention, and
+// purges the revocations of the tokens and the idempotency keys that have
+// expired, every interval until ctx is done.
+func runAccountRetention(ctx context.Context, accountUsecase *usecase.AccountUsecase, tokenUsecase *usecase.TokenUsecase, idempotency *middlewares.Idempotency, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		anonymized, err := accountUsecase.AnonymizeClosedAccounts(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("account retention failed", "error", err)
+		}
+		if anonymized > 0 {
+			log.Info("accounts anonymized", "count", anonymized)
+		}
+
+		purged, err := tokenUsecase.PurgeExpiredRevocations(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("token revocation purge failed", "error", err)
+		}
+		if purged > 0 {
+			log.Info("expired token revocations purged", "count", purged)
+		}
+
+		purged, err = idempotency.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("idempotency key purge failed", "error", err)
+		}
+		if purged > 0 {
+			log.Info("expired idempotency keys purged", "count", purged)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runAdminToken prints an admin API token for the staff member named by args,
+// signed with the same keys as the customer tokens:
+//
+//	api admin-token <staff id>
+func runAdminToken(args []string) {
+	// The staff id is kept with the revocation of the token, in 255 bytes.
+	if len(args) != 1 || args[0] == "" || len(args[0]) > 255 {
+		fmt.Fprintln(os.Stderr, "usage: admin-token <staff id>")
+		os.Exit(2)
+	}
+
+	tokenString, err := getTokenService().IssueAdmin(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin-token:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(tokenString)
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
 
+	migrations "github.com/herwando/mini-wallet/module/wallet/db"
 	_ "github.com/lib/pq"
 )
 
@@ -33,9 +35,21 @@ func getDBConnection() *sql.DB {
 	psqlconn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", options.Host, options.Port, options.User, options.Password, options.Database)
 	db, err := sql.Open("postgres", psqlconn)
 	if err != nil {
-		fmt.Println("mini-wallet database error:", err)
+		slog.Error("mini-wallet database error", "error", err)
 		panic(err)
 	}
 
 	return db
 }
+
+// getMigrationVersion returns the migration version the database must be at
+// for /readyz to report ready.
+func getMigrationVersion() uint64 {
+	version, err := migrations.LatestMigrationVersion()
+	if err != nil {
+		slog.Error("mini-wallet migration error", "error", err)
+		panic(err)
+	}
+
+	return version
+}
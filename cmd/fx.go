@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/fx"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+)
+
+// getRateProvider reads the exchange rates from FX_RATES_FILE. Without it only
+// the default currency is known, so every conversion is refused.
+func getRateProvider() fx.RateProvider {
+	path := os.Getenv("FX_RATES_FILE")
+	if path == "" {
+		slog.Warn("FX_RATES_FILE not set, conversions are disabled")
+		return fx.NewStaticRateProvider(model.DefaultCurrency, nil)
+	}
+
+	provider, err := fx.NewFileRateProvider(path)
+	if err != nil {
+		slog.Error("mini-wallet fx rates error", "error", err)
+		panic(err)
+	}
+
+	return provider
+}
+
+// getQuoteTTL reads FX_QUOTE_TTL, how long a conversion quote locks its rate.
+func getQuoteTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("FX_QUOTE_TTL"))
+	if err != nil {
+		return usecase.DefaultQuoteTTL
+	}
+
+	return ttl
+}
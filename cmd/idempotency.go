@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+// getIdempotencyKeyTTL reads IDEMPOTENCY_KEY_TTL, how long the response given
+// to a request is replayed on its retries.
+func getIdempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil {
+		return middlewares.DefaultIdempotencyKeyTTL
+	}
+
+	return ttl
+}
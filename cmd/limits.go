@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+)
+
+// getLimitDefaults reads the default transaction limits from LIMITS_FILE.
+// Without it a wallet only has the limits overridden for it in wallet_limits.
+func getLimitDefaults() limits.Defaults {
+	path := os.Getenv("LIMITS_FILE")
+	if path == "" {
+		return nil
+	}
+
+	defaults, err := limits.NewFileDefaults(path)
+	if err != nil {
+		slog.Error("mini-wallet limits error", "error", err)
+		panic(err)
+	}
+
+	return defaults
+}
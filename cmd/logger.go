@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+)
+
+type LoggerOption struct {
+	Level  string
+	Format string
+}
+
+func getLoggerDefaultOption() LoggerOption {
+	defaultOpt := LoggerOption{
+		Level:  os.Getenv("LOG_LEVEL"),
+		Format: os.Getenv("LOG_FORMAT"),
+	}
+	if defaultOpt.Format == "" {
+		defaultOpt.Format = logger.FormatJSON
+	}
+
+	return defaultOpt
+}
+
+// getLogger builds the process logger from LOG_LEVEL (debug, info, warn,
+// error) and LOG_FORMAT (json, text) and makes it the slog default.
+func getLogger() *slog.Logger {
+	options := getLoggerDefaultOption()
+	l := logger.New(os.Stdout, logger.ParseLevel(options.Level), options.Format)
+	slog.SetDefault(l)
+
+	return l
+}
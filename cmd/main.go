@@ -1,53 +1,145 @@
 package main
 
 import (
-	"fmt"
-	"net/http"
+	"context"
 	"os"
 	"strings"
 
 	httpHandler "github.com/herwando/mini-wallet/module/wallet/entity/interface/handler"
 	"github.com/herwando/mini-wallet/module/wallet/handler"
 	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/herwando/mini-wallet/module/wallet/ratelimit"
 	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/herwando/mini-wallet/module/wallet/token"
 	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 	"github.com/subosito/gotenv"
 )
 
 func main() {
 	loadEnv()
+	if len(os.Args) > 1 && os.Args[1] == "admin-token" {
+		runAdminToken(os.Args[2:])
+		return
+	}
+
+	log := getLogger()
 	db := getDBConnection()
+	metricsRegistry := metrics.NewRegistry(db, getDatabaseDefaultOption().Database)
+	tokenService := getTokenService()
+	tokenRepo := repository.NewTokenRepository(db)
+	revocationCache := token.NewRevocationCache(tokenRepo, getRevocationCacheTTL())
 
 	walletRepo := repository.NewWalletRepository(db)
 	walletUsecase := usecase.NewWalletUsecase(walletRepo)
 	walletHandler := handler.NewWalletHandler(walletUsecase)
 	accountRepo := repository.NewAccountRepository(db)
-	accountUsecase := usecase.NewAccountUsecase(accountRepo)
+	accountUsecase := usecase.NewAccountUsecase(accountRepo, tokenService, getAccountRetention())
 	accountHandler := handler.NewAccountHandler(accountUsecase)
+	limitChecker := usecase.NewLimitChecker(repository.NewLimitRepository(db), getLimitDefaults())
 	depositRepo := repository.NewDepositRepository(db)
-	depositUsecase := usecase.NewDepositUsecase(depositRepo, walletRepo)
+	depositUsecase := usecase.NewDepositUsecase(depositRepo, walletRepo, limitChecker)
 	depositHandler := handler.NewDepositHandler(depositUsecase)
 	withdrawalRepo := repository.NewWithdrawalRepository(db)
-	withdrawalUsecase := usecase.NewWithdrawalUsecase(withdrawalRepo, walletRepo)
+	withdrawalUsecase := usecase.NewWithdrawalUsecase(withdrawalRepo, walletRepo, limitChecker, getHoldTTL())
 	withdrawalHandler := handler.NewWithdrawalHandler(withdrawalUsecase)
+	transferRepo := repository.NewTransferRepository(db)
+	transferUsecase := usecase.NewTransferUsecase(transferRepo, walletRepo, limitChecker)
+	transferHandler := handler.NewTransferHandler(transferUsecase)
+	conversionUsecase := usecase.NewConversionUsecase(repository.NewConversionRepository(db), walletRepo, limitChecker, getRateProvider(), getQuoteTTL())
+	conversionHandler := handler.NewConversionHandler(conversionUsecase)
+	refundUsecase := usecase.NewRefundUsecase(repository.NewRefundRepository(db), depositRepo, withdrawalRepo, walletRepo, limitChecker)
+	refundHandler := handler.NewRefundHandler(refundUsecase)
+	webhookTimeout := getWebhookTimeout()
+	webhookUsecase := usecase.NewWebhookUsecase(repository.NewWebhookRepository(db), webhook.NewSender(webhookTimeout), webhookTimeout, getWebhookMaxAttempts())
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+	transactionUsecase := usecase.NewTransactionUsecase(depositRepo, withdrawalRepo, transferRepo, walletRepo)
+	transactionHandler := handler.NewTransactionHandler(transactionUsecase)
+	healthUsecase := usecase.NewHealthUsecase(repository.NewHealthRepository(db), getMigrationVersion())
+	healthHandler := handler.NewHealthHandler(healthUsecase)
+	auditRepo := repository.NewAuditRepository(db)
+	adminUsecase := usecase.NewAdminUsecase(walletRepo, repository.NewAdjustmentRepository(db), auditRepo, transactionUsecase)
+	adminHandler := handler.NewAdminHandler(adminUsecase)
+	auditHandler := handler.NewAuditHandler(usecase.NewAuditUsecase(auditRepo))
+	tokenUsecase := usecase.NewTokenUsecase(revocationCache, tokenService)
+	tokenHandler := handler.NewTokenHandler(tokenUsecase)
+
+	serverOption, err := getServerDefaultOption()
+	if err != nil {
+		log.Error("mini-wallet server error", "error", err)
+		panic(err)
+	}
 
-	port := os.Getenv("APP_PORT")
 	handler := &httpHandler.Handler{
-		WalletHandler:     walletHandler,
-		AccountHandler:    accountHandler,
-		DepositHandler:    depositHandler,
-		WithdrawalHandler: withdrawalHandler,
+		WalletHandler:      walletHandler,
+		AccountHandler:     accountHandler,
+		DepositHandler:     depositHandler,
+		WithdrawalHandler:  withdrawalHandler,
+		TransactionHandler: transactionHandler,
+		TransferHandler:    transferHandler,
+		ConversionHandler:  conversionHandler,
+		RefundHandler:      refundHandler,
+		WebhookHandler:     webhookHandler,
+		AdminHandler:       adminHandler,
+		AuditHandler:       auditHandler,
+		TokenHandler:       tokenHandler,
+		HealthHandler:      healthHandler,
 	}
-	authHandler := &middlewares.Module{}
+	loggingHandler := middlewares.NewLogging(log)
+	metricsHandler := middlewares.NewMetrics()
+	bodyLimitHandler := middlewares.NewBodyLimit(serverOption.MaxBodyBytes)
+	auditSourceHandler := middlewares.NewAuditSource(getTrustForwardedFor())
+	authHandler := middlewares.NewModule(tokenService, revocationCache)
+	adminAuthHandler := middlewares.NewAdminModule(tokenService, revocationCache)
+	idempotencyHandler := middlewares.NewIdempotency(repository.NewIdempotencyRepository(db), getIdempotencyKeyTTL())
+	rateLimitStore := ratelimit.NewMemoryStore()
+	ipRateLimitHandler := middlewares.NewIPRateLimit(rateLimitStore, getIPRate(), getTrustForwardedFor())
+	customerRateLimitHandler := middlewares.NewCustomerRateLimit(rateLimitStore, getCustomerRate())
 
 	router := newRoutes(moduleHandler{
-		httpHandler:    handler,
-		authMiddleware: authHandler,
+		httpHandler:                 handler,
+		loggingMiddleware:           loggingHandler,
+		metricsMiddleware:           metricsHandler,
+		metricsHandler:              metrics.Handler(metricsRegistry),
+		bodyLimitMiddleware:         bodyLimitHandler,
+		auditSourceMiddleware:       auditSourceHandler,
+		ipRateLimitMiddleware:       ipRateLimitHandler,
+		authMiddleware:              authHandler,
+		adminAuthMiddleware:         adminAuthHandler,
+		customerRateLimitMiddleware: customerRateLimitHandler,
+		idempotencyMiddleware:       idempotencyHandler,
 	})
 
-	fmt.Println("mini-wallet is now running and ready to listen at port", port)
-	err := http.ListenAndServe(":"+port, router)
-	fmt.Println("mini-wallet error:", err)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	holdExpiryDone := make(chan struct{})
+	go func() {
+		defer close(holdExpiryDone)
+		runHoldExpiry(workerCtx, withdrawalUsecase, getHoldExpiryInterval(), log)
+	}()
+	webhookDispatchDone := make(chan struct{})
+	go func() {
+		defer close(webhookDispatchDone)
+		runWebhookDispatch(workerCtx, webhookUsecase, getWebhookDispatchInterval(), log)
+	}()
+	accountRetentionDone := make(chan struct{})
+	go func() {
+		defer close(accountRetentionDone)
+		runAccountRetention(workerCtx, accountUsecase, tokenUsecase, idempotencyHandler, getAccountRetentionInterval(), log)
+	}()
+
+	err = runServer(newServer(serverOption, router), serverOption.ShutdownTimeout, log)
+	stopWorkers()
+	<-holdExpiryDone
+	<-webhookDispatchDone
+	<-accountRetentionDone
+	_ = db.Close()
+	if err != nil {
+		log.Error("mini-wallet stopped", "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("mini-wallet stopped")
 }
 
 func loadEnv() {
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/herwando/mini-wallet/module/wallet/ratelimit"
+)
+
+const (
+	defaultIPRatePerMinute       = 60
+	defaultIPRateBurst           = 10
+	defaultCustomerRatePerMinute = 300
+	defaultCustomerRateBurst     = 30
+)
+
+// getIPRate reads RATE_LIMIT_IP_PER_MINUTE and RATE_LIMIT_IP_BURST, the rate
+// of the routes called without a token. A rate of 0 turns the limit off.
+func getIPRate() ratelimit.Rate {
+	return ratelimit.PerMinute(
+		getEnvInt("RATE_LIMIT_IP_PER_MINUTE", defaultIPRatePerMinute),
+		getEnvInt("RATE_LIMIT_IP_BURST", defaultIPRateBurst),
+	)
+}
+
+// getCustomerRate reads RATE_LIMIT_CUSTOMER_PER_MINUTE and
+// RATE_LIMIT_CUSTOMER_BURST, the rate of every customer on the authenticated
+// routes. A rate of 0 turns the limit off.
+func getCustomerRate() ratelimit.Rate {
+	return ratelimit.PerMinute(
+		getEnvInt("RATE_LIMIT_CUSTOMER_PER_MINUTE", defaultCustomerRatePerMinute),
+		getEnvInt("RATE_LIMIT_CUSTOMER_BURST", defaultCustomerRateBurst),
+	)
+}
+
+// getTrustForwardedFor reads RATE_LIMIT_TRUST_FORWARDED_FOR, whether the
+// client IP is taken from X-Forwarded-For. Only set it behind a proxy.
+func getTrustForwardedFor() bool {
+	trust, err := strconv.ParseBool(os.Getenv("RATE_LIMIT_TRUST_FORWARDED_FOR"))
+	if err != nil {
+		return false
+	}
+
+	return trust
+}
+
+func getEnvInt(env string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(env))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+
+	return value
+}
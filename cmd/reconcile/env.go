@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	_ "github.com/lib/pq"
+	"github.com/subosito/gotenv"
+)
+
+// loadEnv reads the .env file of the API server, so both programs run with
+// the same configuration.
+func loadEnv() {
+	environment, ok := os.LookupEnv("ENVIRONMENT")
+	if !ok {
+		environment = "DEVELOPMENT"
+	}
+	_ = os.Setenv("ENVIRONMENT", strings.ToUpper(strings.TrimSpace(environment)))
+	_ = gotenv.Load()
+}
+
+// getLogger builds the logger from LOG_LEVEL and LOG_FORMAT, writing to
+// stderr since the report may go to stdout.
+func getLogger() *slog.Logger {
+	format := os.Getenv("LOG_FORMAT")
+	if format == "" {
+		format = logger.FormatJSON
+	}
+	l := logger.New(os.Stderr, logger.ParseLevel(os.Getenv("LOG_LEVEL")), format)
+	slog.SetDefault(l)
+
+	return l
+}
+
+func getDBConnection() *sql.DB {
+	psqlconn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		os.Getenv("PSQL_HOST"), os.Getenv("PSQL_PORT"), os.Getenv("PSQL_USER"), os.Getenv("PSQL_PASSWORD"), os.Getenv("PSQL_DATABASE"))
+	db, err := sql.Open("postgres", psqlconn)
+	if err != nil {
+		slog.Error("mini-wallet database error", "error", err)
+		panic(err)
+	}
+
+	return db
+}
@@ -0,0 +1,134 @@
+// Command reconcile recomputes the balance of every wallet from its
+// transactions and reports the wallets whose balance drifted from it. It is
+// its own program, run daily from cron or a scheduled job, so the API server
+// carries no batch jobs:
+//
+//	reconcile [-format csv|json] [-output file] [-record]
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+)
+
+const (
+	reconcileFormatCSV  = "csv"
+	reconcileFormatJSON = "json"
+)
+
+// main writes the wallets that drifted as a CSV or JSON report. It exits 1
+// when any wallet drifted and 2 when the reconciliation could not run, so a
+// scheduler can alert on either.
+func main() {
+	loadEnv()
+	os.Exit(reconcile(os.Args[1:]))
+}
+
+// reconcile does the work of main and returns its exit code, so the report
+// file and the database are closed before the process exits.
+func reconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	format := flags.String("format", reconcileFormatCSV, "report format, csv or json")
+	output := flags.String("output", "", "file to write the report to instead of stdout")
+	record := flags.Bool("record", false, "also keep the discrepancies in the balance_discrepancies table")
+	_ = flags.Parse(args)
+	if (*format != reconcileFormatCSV && *format != reconcileFormatJSON) || flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	log := getLogger()
+	ctx := logger.WithContext(context.Background(), log)
+
+	db := getDBConnection()
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("reconciliation database close failed", "error", err)
+		}
+	}()
+	reconciliationUsecase := usecase.NewReconciliationUsecase(repository.NewReconciliationRepository(db))
+
+	report, err := reconciliationUsecase.Reconcile(ctx, *record)
+	if err != nil {
+		log.Error("reconciliation failed", "error", err)
+		return 2
+	}
+
+	w := io.Writer(os.Stdout)
+	var file *os.File
+	if *output != "" {
+		file, err = os.Create(*output)
+		if err != nil {
+			log.Error("reconciliation report failed", "error", err)
+			return 2
+		}
+		w = file
+	}
+
+	if *format == reconcileFormatJSON {
+		err = writeReconciliationJSON(w, report)
+	} else {
+		err = writeReconciliationCSV(w, report)
+	}
+	if file != nil {
+		// A write may only fail when the file is closed.
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Error("reconciliation report failed", "error", err)
+		return 2
+	}
+
+	log.Info("reconciliation done", "wallets_checked", report.WalletsChecked, "discrepancies", len(report.Discrepancies))
+	if len(report.Discrepancies) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func writeReconciliationJSON(w io.Writer, report *model.ReconciliationReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeReconciliationCSV writes a header and one row per discrepancy, so a
+// report without drift is the header alone.
+func writeReconciliationCSV(w io.Writer, report *model.ReconciliationReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"wallet_id", "customer_xid", "currency", "balance", "expected_balance", "drift", "reconciled_at"})
+	if err != nil {
+		return err
+	}
+
+	for _, discrepancy := range report.Discrepancies {
+		err = writer.Write([]string{
+			discrepancy.WalletID,
+			discrepancy.CustomerXid,
+			discrepancy.Currency,
+			discrepancy.Balance.String(),
+			discrepancy.ExpectedBalance.String(),
+			discrepancy.Drift.String(),
+			discrepancy.ReconciledAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
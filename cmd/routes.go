@@ -1,6 +1,8 @@
 package main
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi"
 
 	"github.com/herwando/mini-wallet/module/wallet/entity/interface/handler"
@@ -8,8 +10,17 @@ import (
 )
 
 type moduleHandler struct {
-	httpHandler    *handler.Handler
-	authMiddleware *middlewares.Module
+	httpHandler                 *handler.Handler
+	loggingMiddleware           *middlewares.Logging
+	metricsMiddleware           *middlewares.Metrics
+	metricsHandler              http.Handler
+	bodyLimitMiddleware         *middlewares.BodyLimit
+	auditSourceMiddleware       *middlewares.AuditSource
+	ipRateLimitMiddleware       *middlewares.RateLimit
+	authMiddleware              *middlewares.Module
+	adminAuthMiddleware         *middlewares.Module
+	customerRateLimitMiddleware *middlewares.RateLimit
+	idempotencyMiddleware       *middlewares.Idempotency
 }
 
 func newRoutes(mHandler moduleHandler) *chi.Mux {
@@ -18,21 +29,73 @@ func newRoutes(mHandler moduleHandler) *chi.Mux {
 	)
 
 	router := chi.NewRouter()
+	router.Use(
+		mHandler.loggingMiddleware.Handler,
+		mHandler.metricsMiddleware.Handler,
+		mHandler.bodyLimitMiddleware.Handler,
+		mHandler.auditSourceMiddleware.Handler,
+	)
+
+	router.Handle("/metrics", mHandler.metricsHandler)
+	router.Get("/healthz", httpHandler.HealthHandler.Liveness)
+	router.Get("/readyz", httpHandler.HealthHandler.Readiness)
+
 	router.Route("/api/v1", func(v1 chi.Router) {
-		v1.Post("/init", httpHandler.AccountHandler.Init)
+		v1.With(mHandler.ipRateLimitMiddleware.Handler).Post("/init", httpHandler.AccountHandler.Init)
+		v1.With(mHandler.authMiddleware.Handler, mHandler.customerRateLimitMiddleware.Handler).Post("/token/refresh", httpHandler.TokenHandler.Refresh)
+		v1.With(mHandler.authMiddleware.Handler, mHandler.customerRateLimitMiddleware.Handler).Post("/logout", httpHandler.TokenHandler.Logout)
 
 		v1.Route("/wallet", func(wallet chi.Router) {
 			wallet.Use(
 				mHandler.authMiddleware.Handler,
+				mHandler.customerRateLimitMiddleware.Handler,
 			)
 
-			wallet.Post("/", httpHandler.WalletHandler.Enabled)
-			wallet.Patch("/", httpHandler.WalletHandler.Disable)
 			wallet.Get("/", httpHandler.WalletHandler.GetWallet)
-			wallet.Post("/deposits", httpHandler.DepositHandler.Create)
-			wallet.Post("/withdrawals", httpHandler.WithdrawalHandler.Create)
+			wallet.Get("/transactions", httpHandler.TransactionHandler.GetTransactions)
+			wallet.Get("/webhooks", httpHandler.WebhookHandler.List)
+
+			wallet.Group(func(mutation chi.Router) {
+				mutation.Use(
+					mHandler.idempotencyMiddleware.Handler,
+				)
+
+				mutation.Post("/", httpHandler.WalletHandler.Enabled)
+				mutation.Patch("/", httpHandler.WalletHandler.Disable)
+				mutation.Delete("/", httpHandler.WalletHandler.Close)
+				mutation.Post("/deposits", httpHandler.DepositHandler.Create)
+				mutation.Post("/withdrawals", httpHandler.WithdrawalHandler.Create)
+				mutation.Post("/withdrawals/{id}/capture", httpHandler.WithdrawalHandler.Capture)
+				mutation.Post("/withdrawals/{id}/void", httpHandler.WithdrawalHandler.Void)
+				mutation.Post("/transfers", httpHandler.TransferHandler.Create)
+				mutation.Post("/conversions/quotes", httpHandler.ConversionHandler.CreateQuote)
+				mutation.Post("/conversions", httpHandler.ConversionHandler.Create)
+				mutation.Post("/webhooks", httpHandler.WebhookHandler.Create)
+				mutation.Delete("/webhooks/{id}", httpHandler.WebhookHandler.Delete)
+			})
 		})
 	})
 
+	router.Route("/api/admin/v1", func(admin chi.Router) {
+		admin.Use(
+			mHandler.adminAuthMiddleware.Handler,
+		)
+
+		admin.Post("/logout", httpHandler.TokenHandler.Logout)
+		admin.Get("/wallets", httpHandler.AdminHandler.SearchWallets)
+		admin.Post("/wallets/{id}/enable", httpHandler.AdminHandler.EnableWallet)
+		admin.Post("/wallets/{id}/disable", httpHandler.AdminHandler.DisableWallet)
+		admin.Post("/wallets/{id}/freeze", httpHandler.AdminHandler.FreezeWallet)
+		admin.Get("/wallets/{id}/status-history", httpHandler.AdminHandler.GetWalletStatusHistory)
+		admin.Post("/wallets/{id}/adjustments", httpHandler.AdminHandler.AdjustBalance)
+		admin.Get("/customers/{customer_xid}/transactions", httpHandler.AdminHandler.GetTransactions)
+		// Refunds and reversals credit and debit a customer's wallet outside
+		// of anything the customer does, so only staff make them.
+		admin.With(mHandler.idempotencyMiddleware.Handler).Post("/customers/{customer_xid}/withdrawals/{id}/refund", httpHandler.RefundHandler.Refund)
+		admin.With(mHandler.idempotencyMiddleware.Handler).Post("/customers/{customer_xid}/deposits/{id}/reverse", httpHandler.RefundHandler.Reverse)
+		admin.Get("/audit-events", httpHandler.AuditHandler.GetEvents)
+		admin.Get("/audit-events/verify", httpHandler.AuditHandler.Verify)
+	})
+
 	return router
 }
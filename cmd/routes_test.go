@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/interface/handler"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	walletHandler "github.com/herwando/mini-wallet/module/wallet/handler"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+	"github.com/herwando/mini-wallet/module/wallet/ratelimit"
+	"github.com/herwando/mini-wallet/module/wallet/token"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+type notRevoked struct{}
+
+func (notRevoked) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return false, nil
+}
+
+type freshIdempotencyStore struct{}
+
+func (freshIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	return nil, nil
+}
+
+func (freshIdempotencyStore) SaveIdempotencyResponse(ctx context.Context, record *model.IdempotencyRecord) error {
+	return nil
+}
+
+func (freshIdempotencyStore) DeleteIdempotencyKey(ctx context.Context, customerXid, route, key string) error {
+	return nil
+}
+
+func (freshIdempotencyStore) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newTestRoutes builds the routes with every handler but refunds and tokens
+// left without a usecase, for tests that only reach those.
+func newTestRoutes(tokens *token.Service, revocations middlewares.RevocationChecker, refunds *walletHandler.RefundHandler, tokenHandler *walletHandler.TokenHandler) http.Handler {
+	store := ratelimit.NewMemoryStore()
+	return newRoutes(moduleHandler{
+		httpHandler: &handler.Handler{
+			WalletHandler:      walletHandler.NewWalletHandler(nil),
+			AccountHandler:     walletHandler.NewAccountHandler(nil),
+			DepositHandler:     walletHandler.NewDepositHandler(nil),
+			WithdrawalHandler:  walletHandler.NewWithdrawalHandler(nil),
+			TransactionHandler: walletHandler.NewTransactionHandler(nil),
+			TransferHandler:    walletHandler.NewTransferHandler(nil),
+			ConversionHandler:  walletHandler.NewConversionHandler(nil),
+			RefundHandler:      refunds,
+			WebhookHandler:     walletHandler.NewWebhookHandler(nil),
+			AdminHandler:       walletHandler.NewAdminHandler(nil),
+			AuditHandler:       walletHandler.NewAuditHandler(nil),
+			TokenHandler:       tokenHandler,
+			HealthHandler:      walletHandler.NewHealthHandler(nil),
+		},
+		loggingMiddleware:           middlewares.NewLogging(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		metricsMiddleware:           middlewares.NewMetrics(),
+		metricsHandler:              http.NotFoundHandler(),
+		bodyLimitMiddleware:         middlewares.NewBodyLimit(1 << 20),
+		auditSourceMiddleware:       middlewares.NewAuditSource(false),
+		ipRateLimitMiddleware:       middlewares.NewIPRateLimit(store, ratelimit.Rate{}, false),
+		authMiddleware:              middlewares.NewModule(tokens, revocations),
+		adminAuthMiddleware:         middlewares.NewAdminModule(tokens, revocations),
+		customerRateLimitMiddleware: middlewares.NewCustomerRateLimit(store, ratelimit.Rate{}),
+		idempotencyMiddleware:       middlewares.NewIdempotency(freshIdempotencyStore{}, time.Hour),
+	})
+}
+
+func TestRoutes_RefundsRequireAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRefundUC := mockUC.NewMockRefundUsecase(ctrl)
+	defer ctrl.Finish()
+
+	key, _ := token.NewHMACKey(token.DefaultKeyID, []byte("secret"))
+	tokens, _ := token.NewService(token.Config{Keys: []token.Key{key}, ActiveKeyID: token.DefaultKeyID})
+	customerToken, _ := tokens.Issue("ea0212d3-abd6-406f-8c67-868e814a2436")
+	adminToken, _ := tokens.IssueAdmin("ops-alice")
+
+	router := newTestRoutes(tokens, notRevoked{}, walletHandler.NewRefundHandler(mockRefundUC), walletHandler.NewTokenHandler(nil))
+
+	customerPath := "/api/admin/v1/customers/ea0212d3-abd6-406f-8c67-868e814a2436"
+	testCases := map[string]struct {
+		path       string
+		token      string
+		wantStatus int
+		patch      func()
+	}{
+		"failed refund with customer token": {
+			path:       customerPath + "/withdrawals/5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11/refund",
+			token:      customerToken,
+			wantStatus: http.StatusForbidden,
+			patch:      func() {},
+		},
+		"failed reversal with customer token": {
+			path:       customerPath + "/deposits/2c9a8f1e-6b4d-4a3e-9c1f-0d7e5b6a4f22/reverse",
+			token:      customerToken,
+			wantStatus: http.StatusForbidden,
+			patch:      func() {},
+		},
+		"failed refund on the customer api": {
+			path:       "/api/v1/wallet/withdrawals/5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11/refund",
+			token:      customerToken,
+			wantStatus: http.StatusNotFound,
+			patch:      func() {},
+		},
+		"success refund with admin token": {
+			path:       customerPath + "/withdrawals/5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11/refund",
+			token:      adminToken,
+			wantStatus: http.StatusAccepted,
+			patch: func() {
+				mockRefundUC.EXPECT().RefundWithdrawal(gomock.Any(), "ea0212d3-abd6-406f-8c67-868e814a2436", "5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11", gomock.Any()).Return(&model.Deposit{}, nil)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.patch()
+			form := url.Values{"reference_id": {"9b7e2d4c-1a3f-4e5b-8c6d-7f0a1b2c3d44"}}
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", "Token "+tc.token)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+type memoryRevocationStore struct {
+	revoked map[string]string
+}
+
+func (s *memoryRevocationStore) RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error) {
+	if _, ok := s.revoked[jti]; ok {
+		return false, nil
+	}
+	s.revoked[jti] = customerXid
+	return true, nil
+}
+
+func (s *memoryRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+func (s *memoryRevocationStore) PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRoutes_AdminLogout(t *testing.T) {
+	key, _ := token.NewHMACKey(token.DefaultKeyID, []byte("secret"))
+	tokens, _ := token.NewService(token.Config{Keys: []token.Key{key}, ActiveKeyID: token.DefaultKeyID})
+	adminToken, _ := tokens.IssueAdmin("ops-alice")
+	store := &memoryRevocationStore{revoked: map[string]string{}}
+	revocations := token.NewRevocationCache(store, time.Minute)
+
+	router := newTestRoutes(tokens, revocations, walletHandler.NewRefundHandler(nil), walletHandler.NewTokenHandler(usecase.NewTokenUsecase(revocations, tokens)))
+
+	steps := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{http.MethodPost, "/api/admin/v1/logout", http.StatusOK},
+		{http.MethodGet, "/api/admin/v1/audit-events", http.StatusUnauthorized},
+		{http.MethodPost, "/api/admin/v1/logout", http.StatusUnauthorized},
+	}
+
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.path, nil)
+		req.Header.Set("Authorization", "Token "+adminToken)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, "%s %s", step.method, step.path)
+	}
+	assert.Equal(t, map[string]string{claimsID(t, tokens, adminToken): "ops-alice"}, store.revoked)
+}
+
+func claimsID(t *testing.T, tokens *token.Service, tokenStr string) string {
+	claims, err := tokens.Parse(tokenStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims.ID
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/reader"
+)
+
+const (
+	defaultReadTimeout       = 10 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+type ServerOption struct {
+	Port              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	MaxBodyBytes      int64
+}
+
+func getServerDefaultOption() (ServerOption, error) {
+	defaultOpt := ServerOption{
+		Port:         os.Getenv("APP_PORT"),
+		MaxBodyBytes: reader.DefaultMaxBodyBytes,
+	}
+
+	if value := os.Getenv("HTTP_MAX_BODY_BYTES"); value != "" {
+		maxBodyBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxBodyBytes <= 0 {
+			return ServerOption{}, fmt.Errorf("HTTP_MAX_BODY_BYTES: invalid value %q", value)
+		}
+		defaultOpt.MaxBodyBytes = maxBodyBytes
+	}
+
+	for _, opt := range []struct {
+		env          string
+		value        *time.Duration
+		defaultValue time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", &defaultOpt.ReadTimeout, defaultReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", &defaultOpt.ReadHeaderTimeout, defaultReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", &defaultOpt.WriteTimeout, defaultWriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", &defaultOpt.IdleTimeout, defaultIdleTimeout},
+		{"HTTP_SHUTDOWN_TIMEOUT", &defaultOpt.ShutdownTimeout, defaultShutdownTimeout},
+	} {
+		*opt.value = opt.defaultValue
+		if value := os.Getenv(opt.env); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return ServerOption{}, fmt.Errorf("%s: %w", opt.env, err)
+			}
+			*opt.value = duration
+		}
+	}
+
+	return defaultOpt, nil
+}
+
+func newServer(options ServerOption, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + options.Port,
+		Handler:           handler,
+		ReadTimeout:       options.ReadTimeout,
+		ReadHeaderTimeout: options.ReadHeaderTimeout,
+		WriteTimeout:      options.WriteTimeout,
+		IdleTimeout:       options.IdleTimeout,
+	}
+}
+
+// runServer serves until SIGINT or SIGTERM, then stops accepting connections
+// and waits up to shutdownTimeout for the requests in flight, such as a
+// deposit halfway through its database transaction, to finish.
+func runServer(server *http.Server, shutdownTimeout time.Duration, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("mini-wallet is now running and ready to listen", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Info("mini-wallet is shutting down", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	err = <-serveErr
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/token"
+)
+
+type TokenOption struct {
+	Algorithm      string
+	Secret         string
+	KeyID          string
+	PrivateKeyFile string
+	PublicKeyFiles string
+	TTL            string
+	Issuer         string
+	Audience       string
+}
+
+func getTokenDefaultOption() TokenOption {
+	defaultOpt := TokenOption{
+		Algorithm:      os.Getenv("JWT_ALGORITHM"),
+		Secret:         os.Getenv("JWT_SECRET"),
+		KeyID:          os.Getenv("JWT_KEY_ID"),
+		PrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		PublicKeyFiles: os.Getenv("JWT_PUBLIC_KEY_FILES"),
+		TTL:            os.Getenv("JWT_TTL"),
+		Issuer:         os.Getenv("JWT_ISSUER"),
+		Audience:       os.Getenv("JWT_AUDIENCE"),
+	}
+	if defaultOpt.Algorithm == "" {
+		defaultOpt.Algorithm = token.AlgorithmHS256
+	}
+	if defaultOpt.KeyID == "" {
+		defaultOpt.KeyID = token.DefaultKeyID
+	}
+
+	return defaultOpt
+}
+
+func getTokenService() *token.Service {
+	service, err := newTokenService(getTokenDefaultOption())
+	if err != nil {
+		slog.Error("mini-wallet token error", "error", err)
+		panic(err)
+	}
+
+	return service
+}
+
+// newTokenService builds the active key from JWT_SECRET (HS256) or
+// JWT_PRIVATE_KEY_FILE (RS256, EdDSA). Keys retired by a rotation are listed in
+// JWT_PUBLIC_KEY_FILES as comma separated kid=path pairs and only verify.
+func newTokenService(options TokenOption) (*token.Service, error) {
+	config := token.Config{
+		ActiveKeyID: options.KeyID,
+		Issuer:      options.Issuer,
+		Audience:    options.Audience,
+	}
+
+	if options.TTL != "" {
+		ttl, err := time.ParseDuration(options.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_TTL: %w", err)
+		}
+		config.TTL = ttl
+	}
+
+	if options.Algorithm == token.AlgorithmHS256 {
+		key, err := token.NewHMACKey(options.KeyID, []byte(options.Secret))
+		if err != nil {
+			return nil, err
+		}
+		config.Keys = append(config.Keys, key)
+	} else {
+		key, err := readTokenKey(options.KeyID, options.Algorithm, options.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Keys = append(config.Keys, key)
+
+		for _, pair := range strings.Split(options.PublicKeyFiles, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+
+			kid, path, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("JWT_PUBLIC_KEY_FILES: %q is not kid=path", pair)
+			}
+
+			key, err := readTokenKey(kid, options.Algorithm, path)
+			if err != nil {
+				return nil, err
+			}
+			config.Keys = append(config.Keys, key)
+		}
+	}
+
+	return token.NewService(config)
+}
+
+func readTokenKey(kid, algorithm, path string) (token.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return token.Key{}, err
+	}
+
+	return token.NewKeyFromPEM(kid, algorithm, data)
+}
+
+// getRevocationCacheTTL reads JWT_REVOCATION_CACHE_TTL, how long a token found
+// not revoked is trusted before the revoked_tokens table is asked again.
+func getRevocationCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("JWT_REVOCATION_CACHE_TTL"))
+	if err != nil {
+		return token.DefaultRevocationCacheTTL
+	}
+
+	return ttl
+}
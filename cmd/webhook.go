@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+const defaultWebhookDispatchInterval = 5 * time.Second
+
+// getWebhookTimeout reads WEBHOOK_TIMEOUT, how long an endpoint has to answer
+// a delivery.
+func getWebhookTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return webhook.DefaultTimeout
+	}
+
+	return timeout
+}
+
+// getWebhookMaxAttempts reads WEBHOOK_MAX_ATTEMPTS, how many times a delivery
+// is tried before it is dead.
+func getWebhookMaxAttempts() int {
+	return getEnvInt("WEBHOOK_MAX_ATTEMPTS", webhook.DefaultMaxAttempts)
+}
+
+// getWebhookDispatchInterval reads WEBHOOK_DISPATCH_INTERVAL, how often the
+// deliveries that are due are sent.
+func getWebhookDispatchInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("WEBHOOK_DISPATCH_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return defaultWebhookDispatchInterval
+	}
+
+	return interval
+}
+
+// runWebhookDispatch sends the webhook deliveries that are due every interval
+// until ctx is done.
+func runWebhookDispatch(ctx context.Context, webhookUsecase *usecase.WebhookUsecase, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		delivered, err := webhookUsecase.DispatchWebhooks(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("webhook dispatch failed", "error", err)
+		}
+		if delivered > 0 {
+			log.Info("webhooks delivered", "count", delivered)
+		}
+	}
+}
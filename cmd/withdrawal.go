@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+)
+
+const defaultHoldExpiryInterval = time.Minute
+
+// getHoldTTL reads WITHDRAWAL_HOLD_TTL, how long a pending withdrawal holds
+// its amount before it expires.
+func getHoldTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("WITHDRAWAL_HOLD_TTL"))
+	if err != nil {
+		return usecase.DefaultHoldTTL
+	}
+
+	return ttl
+}
+
+// getHoldExpiryInterval reads WITHDRAWAL_HOLD_EXPIRY_INTERVAL, how often the
+// holds that lapsed are released.
+func getHoldExpiryInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("WITHDRAWAL_HOLD_EXPIRY_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return defaultHoldExpiryInterval
+	}
+
+	return interval
+}
+
+// runHoldExpiry releases the holds of lapsed pending withdrawals every
+// interval until ctx is done.
+func runHoldExpiry(ctx context.Context, withdrawalUsecase *usecase.WithdrawalUsecase, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := withdrawalUsecase.ExpireWithdrawals(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("withdrawal hold expiry failed", "error", err)
+		}
+		if expired > 0 {
+			log.Info("withdrawal holds expired", "count", expired)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package commonerr
+
+import (
+	"net/http"
+)
+
+// A DomainError is an error a usecase returns for a request it refuses. Code
+// is a stable machine readable identifier, Message the description shown to
+// the client, and Status the HTTP status writer.WriteJSONAPIError answers with.
+// Meta, when set, carries details of this occurrence and is answered along
+// with the code.
+type DomainError struct {
+	Status  int
+	Code    string
+	Message string
+	Meta    map[string]string
+}
+
+// Error to implement error interface
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// NewUnauthorized returns a domain error answered with 401.
+func NewUnauthorized(code, message string) *DomainError {
+	return &DomainError{Status: http.StatusUnauthorized, Code: code, Message: message}
+}
+
+// NewNotFound returns a domain error answered with 404.
+func NewNotFound(code, message string) *DomainError {
+	return &DomainError{Status: http.StatusNotFound, Code: code, Message: message}
+}
+
+// NewConflict returns a domain error answered with 409.
+func NewConflict(code, message string) *DomainError {
+	return &DomainError{Status: http.StatusConflict, Code: code, Message: message}
+}
+
+// NewUnprocessable returns a domain error answered with 422.
+func NewUnprocessable(code, message string) *DomainError {
+	return &DomainError{Status: http.StatusUnprocessableEntity, Code: code, Message: message}
+}
+
+// Is reports whether target is a domain error with the same code, so a copy
+// made by WithMeta still matches the catalogue entry it came from.
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Code == e.Code
+}
+
+// WithMeta returns a copy of the error carrying meta.
+func (e *DomainError) WithMeta(meta map[string]string) *DomainError {
+	err := *e
+	err.Meta = meta
+	return &err
+}
+
+// ToErrorMessage converts the domain error to the error_list response body.
+func (e *DomainError) ToErrorMessage() *ErrorMessage {
+	msg := SetNewError(e.Status, e.Code, e.Message)
+	msg.ErrorList[0].Meta = e.Meta
+	return msg
+}
+
+// Catalogue of the domain errors returned by the wallet usecases. Compare
+// against them with errors.Is.
+var (
+	ErrAccountAnonymized       = NewUnprocessable("account_anonymized", "Account has been anonymized")
+	ErrTokenRevoked            = NewUnauthorized("token_revoked", "Token revoked")
+	ErrWalletNotFound          = NewNotFound("wallet_not_found", "Wallet not enable")
+	ErrWalletDisabled          = NewNotFound("wallet_disabled", "Wallet disabled")
+	ErrWalletAlreadyEnabled    = NewConflict("wallet_already_enabled", "Already enabled")
+	ErrWalletAlreadyDisabled   = NewConflict("wallet_already_disabled", "Wallet already disabled")
+	ErrWalletFrozen            = NewUnprocessable("wallet_frozen", "Wallet frozen")
+	ErrWalletClosed            = NewUnprocessable("wallet_closed", "Wallet closed")
+	ErrWalletTransition        = NewConflict("wallet_transition", "Wallet can not move to that status")
+	ErrWalletNotEmpty          = NewUnprocessable("wallet_not_empty", "Wallet balance must be paid out to close it")
+	ErrWalletHoldsPending      = NewUnprocessable("wallet_holds_pending", "Wallet has pending withdrawals")
+	ErrRecipientWalletNotFound = NewNotFound("recipient_wallet_not_found", "Recipient wallet not found")
+	ErrRecipientWalletDisabled = NewUnprocessable("recipient_wallet_disabled", "Recipient wallet disabled")
+	ErrTransferToOwnWallet     = NewUnprocessable("transfer_to_own_wallet", "Can not transfer to own wallet")
+	ErrBalanceNotEnough        = NewUnprocessable("balance_not_enough", "Wallet balance not enough")
+	ErrReferenceIdUsed         = NewConflict("reference_id_used", "Reference id already used")
+	ErrCurrencyNotSupported    = NewUnprocessable("currency_not_supported", "Currency not supported")
+	ErrAmountPrecision         = NewUnprocessable("amount_precision", "Amount has more decimals than the currency allows")
+	ErrConversionSameCurrency  = NewUnprocessable("conversion_same_currency", "Can not convert to the same currency")
+	ErrConversionAmountTooLow  = NewUnprocessable("conversion_amount_too_low", "Converted amount rounds down to zero")
+	ErrRateUnavailable         = NewUnprocessable("rate_unavailable", "Exchange rate unavailable")
+	ErrQuoteNotFound           = NewNotFound("quote_not_found", "Quote not found")
+	ErrQuoteExpired            = NewUnprocessable("quote_expired", "Quote expired")
+	ErrQuoteUsed               = NewConflict("quote_used", "Quote already used")
+	ErrWithdrawalNotFound      = NewNotFound("withdrawal_not_found", "Withdrawal not found")
+	ErrWithdrawalTransition    = NewConflict("withdrawal_transition", "Withdrawal can not move to that status")
+	ErrWithdrawalExpired       = NewUnprocessable("withdrawal_expired", "Withdrawal hold expired")
+	ErrWithdrawalNotRefundable = NewUnprocessable("withdrawal_not_refundable", "Withdrawal can not be refunded")
+	ErrRefundExceedsAmount     = NewUnprocessable("refund_exceeds_amount", "Refund exceeds the amount left to refund")
+	ErrDepositNotFound         = NewNotFound("deposit_not_found", "Deposit not found")
+	ErrDepositNotReversible    = NewUnprocessable("deposit_not_reversible", "Deposit can not be reversed")
+	ErrDepositAlreadyReversed  = NewConflict("deposit_already_reversed", "Deposit already reversed")
+	ErrLimitExceeded           = NewUnprocessable("limit_exceeded", "Transaction limit exceeded")
+	ErrWebhookURLInvalid       = NewUnprocessable("webhook_url_invalid", "Webhook URL must be https and not a private address")
+	ErrWebhookNotFound         = NewNotFound("webhook_not_found", "Webhook endpoint not found")
+)
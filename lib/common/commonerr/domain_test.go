@@ -0,0 +1,81 @@
+package commonerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDomainError_ToErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *DomainError
+		want *ErrorMessage
+	}{
+		{
+			name: "not found",
+			err:  ErrWalletNotFound,
+			want: &ErrorMessage{
+				Code:      http.StatusNotFound,
+				ErrorList: []*ErrorFormat{{ErrorName: "wallet_not_found", ErrorDescription: "Wallet not enable"}},
+			},
+		},
+		{
+			name: "conflict",
+			err:  ErrReferenceIdUsed,
+			want: &ErrorMessage{
+				Code:      http.StatusConflict,
+				ErrorList: []*ErrorFormat{{ErrorName: "reference_id_used", ErrorDescription: "Reference id already used"}},
+			},
+		},
+		{
+			name: "unprocessable",
+			err:  ErrBalanceNotEnough,
+			want: &ErrorMessage{
+				Code:      http.StatusUnprocessableEntity,
+				ErrorList: []*ErrorFormat{{ErrorName: "balance_not_enough", ErrorDescription: "Wallet balance not enough"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.ToErrorMessage(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DomainError.ToErrorMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainError_Is(t *testing.T) {
+	err := fmt.Errorf("create withdrawal: %w", ErrBalanceNotEnough)
+	if !errors.Is(err, ErrBalanceNotEnough) {
+		t.Errorf("errors.Is() = false, want true")
+	}
+	if errors.Is(err, ErrReferenceIdUsed) {
+		t.Errorf("errors.Is() = true, want false")
+	}
+}
+
+func TestDomainError_WithMeta(t *testing.T) {
+	err := ErrLimitExceeded.WithMeta(map[string]string{"limit": "daily_withdrawal", "remaining": "150000"})
+	if !errors.Is(fmt.Errorf("create withdrawal: %w", err), ErrLimitExceeded) {
+		t.Errorf("errors.Is() = false, want true")
+	}
+	if ErrLimitExceeded.Meta != nil {
+		t.Errorf("WithMeta() changed the catalogue error, meta = %v", ErrLimitExceeded.Meta)
+	}
+
+	want := &ErrorMessage{
+		Code: http.StatusUnprocessableEntity,
+		ErrorList: []*ErrorFormat{{
+			ErrorName:        "limit_exceeded",
+			ErrorDescription: "Transaction limit exceeded",
+			Meta:             map[string]string{"limit": "daily_withdrawal", "remaining": "150000"},
+		}},
+	}
+	if got := err.ToErrorMessage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DomainError.ToErrorMessage() = %v, want %v", got, want)
+	}
+}
@@ -19,8 +19,9 @@ type ErrorMessage struct {
 // An ErrorFormat represents an error message format and code that we used.
 //
 type ErrorFormat struct {
-	ErrorName        string `json:"error_name"`
-	ErrorDescription string `json:"error_description"`
+	ErrorName        string            `json:"error_name"`
+	ErrorDescription string            `json:"error_description"`
+	Meta             map[string]string `json:"meta,omitempty"`
 }
 
 // OrderErrorFormat Error Format for order
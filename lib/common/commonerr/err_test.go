@@ -687,46 +687,6 @@ func TestErrorMessage_Errorln(t *testing.T) {
 	}
 }
 
-func TestErrorMessage_Debugln(t *testing.T) {
-	type fields struct {
-		ErrorList []*ErrorFormat
-		Code      int
-	}
-	type args struct {
-		err error
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   *ErrorMessage
-	}{
-		{
-			name: "",
-			fields: fields{
-				Code: 1,
-			},
-			args: args{
-				err: errors.New("hai"),
-			},
-			want: &ErrorMessage{
-				Code: 1,
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			errorMessage := &ErrorMessage{
-				ErrorList: tt.fields.ErrorList,
-				Code:      tt.fields.Code,
-			}
-			if got := errorMessage.Debugln(tt.args.err); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("ErrorMessage.Debugln() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestErrorMessage_GetErrorDesc(t *testing.T) {
 	type fields struct {
 		ErrorList []*ErrorFormat
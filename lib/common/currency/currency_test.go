@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   Currency
+		wantOk bool
+	}{
+		{name: "two minor units", code: "IDR", want: Currency{Code: "IDR", MinorUnits: 2}, wantOk: true},
+		{name: "no minor units", code: "JPY", want: Currency{Code: "JPY", MinorUnits: 0}, wantOk: true},
+		{name: "three minor units", code: "KWD", want: Currency{Code: "KWD", MinorUnits: 3}, wantOk: true},
+		{name: "lower case", code: "usd"},
+		{name: "unknown", code: "ABC"},
+		{name: "empty", code: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.code)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, IsValid(tt.code))
+		})
+	}
+}
+
+func TestCurrency_ValidAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		amount string
+		want   bool
+	}{
+		{name: "whole amount", code: "JPY", amount: "1000", want: true},
+		{name: "fraction without minor units", code: "JPY", amount: "1000.5", want: false},
+		{name: "trailing zeros", code: "JPY", amount: "1000.00", want: true},
+		{name: "within minor units", code: "USD", amount: "10.25", want: true},
+		{name: "beyond minor units", code: "USD", amount: "10.255", want: false},
+		{name: "three minor units", code: "KWD", amount: "10.255", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := Lookup(tt.code)
+			assert.Equal(t, tt.want, c.ValidAmount(decimal.RequireFromString(tt.amount)))
+		})
+	}
+}
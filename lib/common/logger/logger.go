@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type loggerCtx struct{}
+
+var LoggerCtxKey = loggerCtx{}
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns a logger writing to w at level. format is FormatJSON or
+// FormatText, anything else falls back to JSON.
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if strings.ToLower(format) == FormatText {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// ParseLevel reads debug, info, warn or error, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo
+	}
+
+	return l
+}
+
+// WithContext returns a copy of ctx carrying l, so everything handling the
+// request logs with the attributes the middlewares attached to it.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, LoggerCtxKey, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(LoggerCtxKey).(*slog.Logger); ok {
+			return l
+		}
+	}
+
+	return slog.Default()
+}
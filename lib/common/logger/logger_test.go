@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			check: func(t *testing.T, out string) {
+				var line map[string]interface{}
+				assert.Nil(t, json.Unmarshal([]byte(out), &line))
+				assert.Equal(t, "hello", line["msg"])
+				assert.Equal(t, "bar", line["foo"])
+			},
+		},
+		{
+			name:   "text",
+			format: FormatText,
+			check: func(t *testing.T, out string) {
+				assert.True(t, strings.Contains(out, "msg=hello foo=bar"))
+			},
+		},
+		{
+			name:   "unknown falls back to json",
+			format: "xml",
+			check: func(t *testing.T, out string) {
+				assert.True(t, json.Valid([]byte(out)))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf, slog.LevelInfo, tt.format)
+			l.Debug("hidden")
+			l.Info("hello", "foo", "bar")
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, ParseLevel("debug"))
+	assert.Equal(t, slog.LevelWarn, ParseLevel(" WARN "))
+	assert.Equal(t, slog.LevelError, ParseLevel("error"))
+	assert.Equal(t, slog.LevelInfo, ParseLevel(""))
+	assert.Equal(t, slog.LevelInfo, ParseLevel("verbose"))
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, slog.LevelInfo, FormatJSON)
+
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+	assert.Equal(t, l, FromContext(WithContext(context.Background(), l)))
+}
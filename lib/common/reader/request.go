@@ -0,0 +1,194 @@
+package reader
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/currency"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultMaxBodyBytes is the body size limit used when none is configured.
+	DefaultMaxBodyBytes int64 = 1 << 20
+
+	maxMemory = 32 << 20
+)
+
+var validate = newValidator()
+
+// Decode reads the body of r into v, a pointer to a struct, according to the
+// Content-Type of the request: application/json is decoded with its json
+// tags, and application/x-www-form-urlencoded and multipart/form-data fill
+// the fields whose json tag matches a form key. The decoded payload is then
+// checked against its validate tags. Every error returned is a
+// *commonerr.ErrorMessage ready for writer.WriteJSONAPIError.
+func Decode(r *http.Request, v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		err := decodeJSON(r, v)
+		if err != nil {
+			return err
+		}
+	case "multipart/form-data", "application/x-www-form-urlencoded", "":
+		err := r.ParseMultipartForm(maxMemory)
+		if err != nil && err != http.ErrNotMultipart {
+			return BodyError(err)
+		}
+		err = decodeForm(r.Form, v)
+		if err != nil {
+			return err
+		}
+	default:
+		return commonerr.SetNewError(http.StatusUnsupportedMediaType, "Request invalid", "Content-Type "+mediaType+" not supported")
+	}
+
+	err := validate.Struct(v)
+	if err != nil {
+		errorMessage := &commonerr.ErrorMessage{Code: http.StatusBadRequest}
+		return errorMessage.SetErrorValidator(err)
+	}
+
+	return nil
+}
+
+// BodyError converts an error met while reading a request body, answering
+// 413 when the body went over the limit set by http.MaxBytesReader.
+func BodyError(err error) *commonerr.ErrorMessage {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return commonerr.SetNewError(http.StatusRequestEntityTooLarge, "Request invalid", "Body larger than "+strconv.FormatInt(maxBytesErr.Limit, 10)+" bytes")
+	}
+
+	return commonerr.SetDefaultErrBodyRequest()
+}
+
+func decodeJSON(r *http.Request, v interface{}) error {
+	if r.Body == nil {
+		return commonerr.SetDefaultErrBodyRequest()
+	}
+
+	// An empty body is an empty payload, left to the validate tags to refuse.
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return invalidParam(typeErr.Field)
+		}
+		return BodyError(err)
+	}
+
+	return nil
+}
+
+// decodeForm sets the fields of v from values, converting each value to the
+// type of its field.
+func decodeForm(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return commonerr.SetDefaultErrBodyRequest()
+	}
+	rv = rv.Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		name := fieldName(field)
+		if name == "" || field.PkgPath != "" {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok || len(value) == 0 {
+			continue
+		}
+
+		err := setField(rv.Field(i), value[0])
+		if err != nil {
+			return invalidParam(name)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
+	}
+
+	return nil
+}
+
+func invalidParam(name string) *commonerr.ErrorMessage {
+	return commonerr.SetNewBadRequest("Request invalid", "Params "+name+" not valid")
+}
+
+// fieldName returns the json name of the field, or "" when it is skipped.
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// newValidator reports fields by their json name, validates a
+// decimal.Decimal as its float value so tags such as gt=0 apply to amounts,
+// and adds the iso4217 tag for currency codes.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	_ = v.RegisterValidation("iso4217", func(fl validator.FieldLevel) bool {
+		return currency.IsValid(fl.Field().String())
+	})
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if d, ok := field.Interface().(decimal.Decimal); ok {
+			return d.InexactFloat64()
+		}
+		return nil
+	}, decimal.Decimal{})
+
+	return v
+}
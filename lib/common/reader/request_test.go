@@ -0,0 +1,153 @@
+package reader
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type payload struct {
+	ReferenceID string          `json:"reference_id" validate:"required"`
+	Amount      decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	IsDisabled  bool            `json:"is_disabled"`
+	Currency    string          `json:"currency" validate:"omitempty,iso4217"`
+	Skipped     string          `json:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	multipartRequest := func(fields map[string]string) *http.Request {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for k, v := range fields {
+			_ = mw.WriteField(k, v)
+		}
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+	request := func(contentType, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+	form := url.Values{"reference_id": {"ref-1"}, "amount": {"1000.5"}, "is_disabled": {"true"}, "Skipped": {"x"}}
+
+	tests := []struct {
+		name       string
+		r          *http.Request
+		want       payload
+		wantStatus int
+		wantErrors []*commonerr.ErrorFormat
+	}{
+		{
+			name: "Success json",
+			r:    request("application/json; charset=utf-8", `{"reference_id":"ref-1","amount":1000.5,"is_disabled":true}`),
+			want: payload{ReferenceID: "ref-1", Amount: decimal.RequireFromString("1000.5"), IsDisabled: true},
+		},
+		{
+			name: "Success json string amount",
+			r:    request("application/json", `{"reference_id":"ref-1","amount":"1000.5"}`),
+			want: payload{ReferenceID: "ref-1", Amount: decimal.RequireFromString("1000.5")},
+		},
+		{
+			name: "Success urlencoded",
+			r:    request("application/x-www-form-urlencoded", form.Encode()),
+			want: payload{ReferenceID: "ref-1", Amount: decimal.RequireFromString("1000.5"), IsDisabled: true},
+		},
+		{
+			name: "Success multipart",
+			r:    multipartRequest(map[string]string{"reference_id": "ref-1", "amount": "1000.5"}),
+			want: payload{ReferenceID: "ref-1", Amount: decimal.RequireFromString("1000.5")},
+		},
+		{
+			name:       "Failed empty json",
+			r:          request("application/json", ""),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{
+				{ErrorName: "reference_id", ErrorDescription: "required"},
+				{ErrorName: "amount", ErrorDescription: "required"},
+			},
+		},
+		{
+			name:       "Failed unsupported content type",
+			r:          request("text/plain", "amount=1000"),
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "Failed malformed json",
+			r:          request("application/json", `{"amount":`),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{&commonerr.DefaultInputBody},
+		},
+		{
+			name:       "Failed json field type",
+			r:          request("application/json", `{"reference_id":1}`),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{{ErrorName: "Request invalid", ErrorDescription: "Params reference_id not valid"}},
+		},
+		{
+			name:       "Failed form field type",
+			r:          request("application/x-www-form-urlencoded", "reference_id=ref-1&amount=abc"),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{{ErrorName: "Request invalid", ErrorDescription: "Params amount not valid"}},
+		},
+		{
+			name:       "Failed validation",
+			r:          request("application/json", `{"amount":-5}`),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{
+				{ErrorName: "reference_id", ErrorDescription: "required"},
+				{ErrorName: "amount", ErrorDescription: "gt"},
+			},
+		},
+		{
+			name: "Success currency",
+			r:    request("application/json", `{"reference_id":"ref-1","amount":1,"currency":"USD"}`),
+			want: payload{ReferenceID: "ref-1", Amount: decimal.NewFromInt(1), Currency: "USD"},
+		},
+		{
+			name:       "Failed currency",
+			r:          request("application/json", `{"reference_id":"ref-1","amount":1,"currency":"usd"}`),
+			wantStatus: http.StatusBadRequest,
+			wantErrors: []*commonerr.ErrorFormat{{ErrorName: "currency", ErrorDescription: "iso4217"}},
+		},
+		{
+			name: "Failed body too large",
+			r: func() *http.Request {
+				req := request("application/json", `{"reference_id":"`+strings.Repeat("a", 64)+`"}`)
+				req.Body = http.MaxBytesReader(nil, req.Body, 16)
+				return req
+			}(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			err := Decode(tt.r, &got)
+			if tt.wantStatus == 0 {
+				assert.Nil(t, err)
+				assert.True(t, tt.want.Amount.Equal(got.Amount))
+				got.Amount = tt.want.Amount
+				assert.Equal(t, tt.want, got)
+				return
+			}
+
+			errorMessage, ok := err.(*commonerr.ErrorMessage)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantStatus, errorMessage.Code)
+			if tt.wantErrors != nil {
+				assert.Equal(t, tt.wantErrors, errorMessage.ErrorList)
+			}
+		})
+	}
+}
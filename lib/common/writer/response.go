@@ -3,10 +3,10 @@ package writer
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
 	"github.com/pkg/errors"
 )
 
@@ -33,14 +33,24 @@ func WriteStrOK(ctx context.Context, w http.ResponseWriter) {
 	set(ctx, w, ok, http.StatusOK)
 }
 
-// WriteJSONAPIError is a helper
+// WriteJSONAPIError is a helper. An ErrorMessage or DomainError is written with
+// its own status; any other error, such as a database failure, is logged and
+// answered with a bare 500 so its details never reach the client.
 func WriteJSONAPIError(ctx context.Context, w http.ResponseWriter, err error) {
+	var domainErr *commonerr.DomainError
+	if errors.As(err, &domainErr) {
+		write(ctx, w, domainErr.ToErrorMessage(), domainErr.Status)
+		return
+	}
+
 	switch errCause := errors.Cause(err).(type) {
 	case *commonerr.ErrorMessage:
 		write(ctx, w, errCause, errCause.Code)
 	default:
+		logger.FromContext(ctx).Error("internal error", "error", err)
 		write(ctx, w, commonerr.ErrorMessage{
 			ErrorList: commonerr.SetNewInternalError().GetListError(),
+			Code:      http.StatusInternalServerError,
 		}, http.StatusInternalServerError)
 	}
 }
@@ -54,10 +64,10 @@ func write(ctx context.Context, w http.ResponseWriter, data interface{}, status
 }
 
 func set(ctx context.Context, w http.ResponseWriter, datab []byte, status int) {
-	w.WriteHeader(status)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	_, err := w.Write(datab)
 	if err != nil {
-		fmt.Println("[HTTP]", err)
+		logger.FromContext(ctx).Error("write response", "error", err)
 	}
 }
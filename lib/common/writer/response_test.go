@@ -3,6 +3,7 @@ package writer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,8 +11,6 @@ import (
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
 )
 
-var errFoo = errors.New("errFoo")
-
 func TestWriteOK(t *testing.T) {
 	w := httptest.NewRecorder()
 	type args struct {
@@ -67,34 +66,73 @@ func TestWriteStrOK(t *testing.T) {
 }
 
 func TestWriteJSONAPIError(t *testing.T) {
-	w := httptest.NewRecorder()
 	type args struct {
 		ctx context.Context
-		w   http.ResponseWriter
 		err error
 	}
 	tests := []struct {
-		name string
-		args args
+		name       string
+		args       args
+		wantStatus int
+		wantBody   string
 	}{
 		{
+			name: "unknown error is hidden",
 			args: args{
 				ctx: context.WithValue(context.Background(), ErrorCtxKey, nil),
-				w:   w,
-				err: errFoo,
+				err: errors.New(`pq: relation "wallets" does not exist`),
 			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error_list":[{"error_name":"internal_server_error","error_description":"The server is unable to complete your request"}],"code":500}`,
 		},
 		{
+			name: "error message",
 			args: args{
 				ctx: context.WithValue(context.Background(), ErrorCtxKey, nil),
-				w:   w,
 				err: commonerr.SetNewInternalError(),
 			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error_list":[{"error_name":"internal_server_error","error_description":"The server is unable to complete your request"}],"code":500}`,
+		},
+		{
+			name: "not found domain error",
+			args: args{
+				err: commonerr.ErrWalletNotFound,
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error_list":[{"error_name":"wallet_not_found","error_description":"Wallet not enable"}],"code":404}`,
+		},
+		{
+			name: "conflict domain error",
+			args: args{
+				err: commonerr.ErrReferenceIdUsed,
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error_list":[{"error_name":"reference_id_used","error_description":"Reference id already used"}],"code":409}`,
+		},
+		{
+			name: "wrapped unprocessable domain error",
+			args: args{
+				err: fmt.Errorf("withdrawal: %w", commonerr.ErrBalanceNotEnough),
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"error_list":[{"error_name":"balance_not_enough","error_description":"Wallet balance not enough"}],"code":422}`,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			WriteJSONAPIError(tt.args.ctx, tt.args.w, tt.args.err)
+			w := httptest.NewRecorder()
+			WriteJSONAPIError(tt.args.ctx, w, tt.args.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("WriteJSONAPIError() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("WriteJSONAPIError() body = %v, want %v", w.Body.String(), tt.wantBody)
+			}
+			if w.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("WriteJSONAPIError() content type = %v", w.Header().Get("Content-Type"))
+			}
 		})
 	}
 }
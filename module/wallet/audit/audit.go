@@ -0,0 +1,293 @@
+// Package audit keeps the record of who changed what: every status change,
+// balance change and admin action, with the actor, the request it came
+// from and the wallet before and after. Events are written in the same
+// transaction as the change they describe and chained by hash per wallet,
+// each event hashing the one before it, so an event edited or removed
+// afterwards is found by walking the chains.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ActorCustomer = "customer"
+	ActorAdmin    = "admin"
+	// ActorSystem is the actor of the changes made by workers, such as
+	// expiring holds, rather than on request.
+	ActorSystem = "system"
+)
+
+const (
+	ActionWalletCreated             = "wallet.created"
+	ActionWalletEnabled             = "wallet.enabled"
+	ActionWalletDisabled            = "wallet.disabled"
+	ActionWalletFrozen              = "wallet.frozen"
+	ActionWalletClosed              = "wallet.closed"
+	ActionAccountAnonymized         = "account.anonymized"
+	ActionDepositCreated            = "deposit.created"
+	ActionDepositReversed           = "deposit.reversed"
+	ActionWithdrawalCreated         = "withdrawal.created"
+	ActionWithdrawalHeld            = "withdrawal.held"
+	ActionWithdrawalCaptured        = "withdrawal.captured"
+	ActionWithdrawalReleased        = "withdrawal.released"
+	ActionWithdrawalRefunded        = "withdrawal.refunded"
+	ActionTransferCreated           = "transfer.created"
+	ActionConversionCreated         = "conversion.created"
+	ActionBalanceAdjusted           = "balance.adjusted"
+	ActionWalletsSearched           = "admin.wallets.searched"
+	ActionTransactionsListed        = "admin.transactions.listed"
+	ActionWalletStatusHistoryListed = "admin.wallet_status_history.listed"
+	ActionAuditEventsListed         = "admin.audit_events.listed"
+	ActionAuditEventsVerified       = "admin.audit_events.verified"
+)
+
+const (
+	ResourceWallet     = "wallet"
+	ResourceAccount    = "account"
+	ResourceDeposit    = "deposit"
+	ResourceWithdrawal = "withdrawal"
+	ResourceAdjustment = "adjustment"
+	ResourceTransfer   = "transfer"
+	ResourceConversion = "conversion"
+)
+
+// chainLock is the class of the transaction advisory locks writers take to
+// append to a chain one at a time, keyed by the hash of the chain.
+const chainLock = 4417001
+
+// Tx is the part of *sql.Tx events are written through, so an event is always
+// committed together with the change it describes.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// WalletState is the part of a wallet an event snapshots before and after.
+type WalletState struct {
+	Status      int             `json:"status"`
+	Balance     decimal.Decimal `json:"balance"`
+	HeldBalance decimal.Decimal `json:"held_balance"`
+}
+
+func StateOf(wallet *model.Wallet) WalletState {
+	return WalletState{
+		Status:      wallet.Status,
+		Balance:     wallet.Balance,
+		HeldBalance: wallet.HeldBalance,
+	}
+}
+
+// NewWalletEvent describes action on the resource, changing wallet from
+// before to its current state.
+func NewWalletEvent(action, resourceType, resourceID string, wallet *model.Wallet, before WalletState) *model.AuditEvent {
+	return &model.AuditEvent{
+		Action:       action,
+		CustomerXid:  wallet.OwnedBy,
+		WalletID:     wallet.ID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       snapshot(before),
+		After:        snapshot(StateOf(wallet)),
+	}
+}
+
+func snapshot(state WalletState) json.RawMessage {
+	b, _ := json.Marshal(state)
+	return b
+}
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	sourceKey
+	reasonKey
+)
+
+type actor struct {
+	typ string
+	id  string
+}
+
+type source struct {
+	requestID string
+	ip        string
+}
+
+// WithActor tells who is acting in ctx, a customer or a staff member.
+func WithActor(ctx context.Context, actorType, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey, actor{typ: actorType, id: actorID})
+}
+
+// WithSource tells the request the changes in ctx are made on.
+func WithSource(ctx context.Context, requestID, ip string) context.Context {
+	return context.WithValue(ctx, sourceKey, source{requestID: requestID, ip: ip})
+}
+
+// ActorFromContext returns who is acting in ctx, the system when nobody is.
+func ActorFromContext(ctx context.Context) (actorType, actorID string) {
+	if a, ok := ctx.Value(actorKey).(actor); ok {
+		return a.typ, a.id
+	}
+	return ActorSystem, ""
+}
+
+// SourceFromContext returns the request id and client IP of ctx.
+func SourceFromContext(ctx context.Context) (requestID, ip string) {
+	s, _ := ctx.Value(sourceKey).(source)
+	return s.requestID, s.ip
+}
+
+// WithReason tells why the changes in ctx are made, as staff have to.
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey, reason)
+}
+
+// ChainOf returns the chain the event is appended to: that of its wallet, or
+// of its customer when it is about no wallet in particular. Events about
+// neither, such as staff verifying the log, share the chain "".
+func ChainOf(event *model.AuditEvent) string {
+	switch {
+	case event.WalletID != "":
+		return "wallet:" + event.WalletID
+	case event.CustomerXid != "":
+		return "customer:" + event.CustomerXid
+	}
+	return ""
+}
+
+// Record appends the event to its chain, filling in the request and reason
+// of ctx, and its actor unless the event has one. Only its chain is locked
+// until tx ends, so the events of a wallet are appended one at a time while
+// those of other wallets are not held up.
+func Record(ctx context.Context, tx Tx, event *model.AuditEvent) error {
+	fill(ctx, event)
+	event.Chain = ChainOf(event)
+
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, chainLock, event.Chain)
+	if err != nil {
+		return err
+	}
+
+	var last int64
+	var prevHash string
+	err = tx.QueryRowContext(ctx, `SELECT chain_seq, hash FROM audit_events WHERE chain = $1 ORDER BY chain_seq DESC LIMIT 1`, event.Chain).
+		Scan(&last, &prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	event.ChainSeq = last + 1
+	event.PrevHash = prevHash
+	event.Hash = Hash(event)
+	return tx.QueryRowContext(ctx, `INSERT INTO audit_events (chain, chain_seq, actor_type, actor_id, action, customer_xid, wallet_id, resource_type, resource_id, before, after, reason, request_id, ip, created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING seq`,
+		event.Chain, event.ChainSeq, event.ActorType, event.ActorID, event.Action, event.CustomerXid, event.WalletID, event.ResourceType, event.ResourceID,
+		nullJSON(event.Before), nullJSON(event.After), event.Reason, event.RequestID, event.IP, event.CreatedAt, event.PrevHash, event.Hash).
+		Scan(&event.Seq)
+}
+
+func fill(ctx context.Context, event *model.AuditEvent) {
+	if event.ActorType == "" {
+		event.ActorType, event.ActorID = ActorFromContext(ctx)
+	}
+
+	event.RequestID, event.IP = SourceFromContext(ctx)
+
+	if reason, ok := ctx.Value(reasonKey).(string); ok && event.Reason == "" {
+		event.Reason = reason
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	// The database keeps microseconds; the hash has to cover what is read back.
+	event.CreatedAt = event.CreatedAt.UTC().Truncate(time.Microsecond)
+}
+
+func nullJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// hashed is what an event hash covers, in a fixed order. Seq is the place of
+// the event in its chain; the events written before chains were kept per
+// wallet make up the chain "", which is left out, numbered as they were.
+type hashed struct {
+	Chain        string          `json:"chain,omitempty"`
+	Seq          string          `json:"seq"`
+	ActorType    string          `json:"actor_type"`
+	ActorID      string          `json:"actor_id"`
+	Action       string          `json:"action"`
+	CustomerXid  string          `json:"customer_xid"`
+	WalletID     string          `json:"wallet_id"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	Reason       string          `json:"reason"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// Hash returns the hex SHA-256 of the previous hash followed by the event.
+func Hash(event *model.AuditEvent) string {
+	b, _ := json.Marshal(hashed{
+		Chain:        event.Chain,
+		Seq:          strconv.FormatInt(event.ChainSeq, 10),
+		ActorType:    event.ActorType,
+		ActorID:      event.ActorID,
+		Action:       event.Action,
+		CustomerXid:  event.CustomerXid,
+		WalletID:     event.WalletID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Before:       rawOrNull(event.Before),
+		After:        rawOrNull(event.After),
+		Reason:       event.Reason,
+		RequestID:    event.RequestID,
+		IP:           event.IP,
+		CreatedAt:    event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(append([]byte(event.PrevHash), b...))
+	return hex.EncodeToString(sum[:])
+}
+
+func rawOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
+
+// Verify checks the events, ordered by chain and place in it, each follow on
+// from the one before it in their chain, head being the last event checked
+// before them or nil. It returns the first event that does not, or nil when
+// they all do.
+func Verify(head *model.AuditEvent, events []*model.AuditEvent) *model.AuditEvent {
+	for _, event := range events {
+		var prevSeq int64
+		var prevHash string
+		if head != nil && head.Chain == event.Chain {
+			prevSeq, prevHash = head.ChainSeq, head.Hash
+		}
+		if event.ChainSeq != prevSeq+1 || event.PrevHash != prevHash || event.Hash != Hash(event) {
+			return event
+		}
+		head = event
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	now         = time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	customerXid = "ea0212d3-abd6-406f-8c67-868e814a2436"
+	wallet      = &model.Wallet{
+		ID:       "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:  customerXid,
+		Status:   1,
+		Balance:  decimal.NewFromInt(11000),
+		Currency: "IDR",
+	}
+	depositID = "5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"
+)
+
+func newEvent() *model.AuditEvent {
+	before := audit.StateOf(wallet)
+	before.Balance = decimal.NewFromInt(10000)
+	event := audit.NewWalletEvent(audit.ActionDepositCreated, audit.ResourceDeposit, depositID, wallet, before)
+	event.CreatedAt = now
+	return event
+}
+
+func TestAudit_NewWalletEvent(t *testing.T) {
+	event := newEvent()
+
+	assert.Equal(t, customerXid, event.CustomerXid)
+	assert.Equal(t, wallet.ID, event.WalletID)
+	assert.JSONEq(t, `{"status":1,"balance":"10000","held_balance":"0"}`, string(event.Before))
+	assert.JSONEq(t, `{"status":1,"balance":"11000","held_balance":"0"}`, string(event.After))
+}
+
+func TestAudit_ChainOf(t *testing.T) {
+	assert.Equal(t, "wallet:"+wallet.ID, audit.ChainOf(newEvent()))
+	assert.Equal(t, "customer:"+customerXid, audit.ChainOf(&model.AuditEvent{CustomerXid: customerXid}))
+	assert.Equal(t, "", audit.ChainOf(&model.AuditEvent{}))
+}
+
+func TestAudit_Record(t *testing.T) {
+	testCases := map[string]struct {
+		ctx          context.Context
+		last         *sqlmock.Rows
+		wantActor    string
+		wantActorID  string
+		wantChainSeq int64
+		wantPrevHash string
+		wantErr      bool
+	}{
+		"success first event by system": {
+			ctx:          context.Background(),
+			last:         sqlmock.NewRows([]string{"chain_seq", "hash"}),
+			wantActor:    audit.ActorSystem,
+			wantChainSeq: 1,
+		},
+		"success chained by customer": {
+			ctx:          audit.WithSource(audit.WithActor(context.Background(), audit.ActorCustomer, customerXid), "req-1", "10.0.0.1"),
+			last:         sqlmock.NewRows([]string{"chain_seq", "hash"}).AddRow(41, "abc"),
+			wantActor:    audit.ActorCustomer,
+			wantActorID:  customerXid,
+			wantChainSeq: 42,
+			wantPrevHash: "abc",
+		},
+		"failed insert": {
+			ctx:     context.Background(),
+			last:    sqlmock.NewRows([]string{"chain_seq", "hash"}),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, sMock, _ := sqlmock.New()
+			sMock.ExpectBegin()
+			// Only the chain of the wallet is locked.
+			sMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).WithArgs(sqlmock.AnyArg(), "wallet:"+wallet.ID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			sMock.ExpectQuery(regexp.QuoteMeta("SELECT chain_seq, hash FROM audit_events WHERE chain = $1 ORDER BY chain_seq DESC LIMIT 1")).WithArgs("wallet:" + wallet.ID).
+				WillReturnRows(tc.last)
+			insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO audit_events"))
+			if tc.wantErr {
+				insert.WillReturnError(errors.New("database error"))
+			} else {
+				insert.WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(1042))
+			}
+
+			tx, _ := db.Begin()
+			event := newEvent()
+			err := audit.Record(tc.ctx, tx, event)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.wantActor, event.ActorType)
+				assert.Equal(t, tc.wantActorID, event.ActorID)
+				assert.Equal(t, int64(1042), event.Seq)
+				assert.Equal(t, "wallet:"+wallet.ID, event.Chain)
+				assert.Equal(t, tc.wantChainSeq, event.ChainSeq)
+				assert.Equal(t, tc.wantPrevHash, event.PrevHash)
+				assert.Equal(t, audit.Hash(event), event.Hash)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAudit_Verify(t *testing.T) {
+	// Two chains of three events, in the order they are read back: by chain,
+	// then place in it.
+	chains := func() []*model.AuditEvent {
+		var events []*model.AuditEvent
+		for _, chain := range []string{"customer:" + customerXid, "wallet:" + wallet.ID} {
+			prevHash := ""
+			for chainSeq := int64(1); chainSeq <= 3; chainSeq++ {
+				event := newEvent()
+				event.Seq = int64(len(events) + 1)
+				event.Chain, event.ChainSeq, event.PrevHash = chain, chainSeq, prevHash
+				event.Hash = audit.Hash(event)
+				prevHash = event.Hash
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+
+	testCases := map[string]struct {
+		tamper func(events []*model.AuditEvent) []*model.AuditEvent
+		want   int64
+	}{
+		"valid": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent { return events },
+			want:   0,
+		},
+		"edited": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent {
+				events[1].After = []byte(`{"status":1,"balance":"99000","held_balance":"0"}`)
+				return events
+			},
+			want: 2,
+		},
+		"edited and rehashed": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent {
+				events[1].Reason = "nothing to see"
+				events[1].Hash = audit.Hash(events[1])
+				return events
+			},
+			want: 3,
+		},
+		"deleted": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent {
+				return append(events[:1], events[2:]...)
+			},
+			want: 3,
+		},
+		"deleted first of a chain": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent {
+				return append(events[:3], events[4:]...)
+			},
+			want: 5,
+		},
+		"moved to another chain": {
+			tamper: func(events []*model.AuditEvent) []*model.AuditEvent {
+				events[3].Chain = events[0].Chain
+				return events
+			},
+			want: 4,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got int64
+			if broken := audit.Verify(nil, tc.tamper(chains())); broken != nil {
+				got = broken.Seq
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAudit_HashLegacyChain(t *testing.T) {
+	// An event of the single chain written before chains were kept per
+	// wallet hashes as it did then, with its place in the chain as seq.
+	event := newEvent()
+	event.ChainSeq = 7
+	assert.Equal(t, "347e2eee5face7ca35cedd787da7518a6ade092553d4afe63ac45e5e295901d7", audit.Hash(event))
+}
+
+func TestAudit_HashReadBack(t *testing.T) {
+	event := newEvent()
+	event.Chain, event.ChainSeq = audit.ChainOf(event), 1
+	hash := audit.Hash(event)
+
+	// Read back in another zone, as the driver may.
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("WIB", 7*3600))
+	assert.Equal(t, hash, audit.Hash(event))
+}
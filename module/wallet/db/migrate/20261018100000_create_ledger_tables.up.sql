@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS ledger_entries
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(32) NOT NULL,
+    reference_id uuid NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id uuid NOT NULL REFERENCES ledger_entries (id),
+    account_type VARCHAR(16) NOT NULL,
+    account_id VARCHAR(64) NOT NULL,
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount DECIMAL NOT NULL CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_postings_account_idx ON ledger_postings (account_type, account_id);
+
+-- Wallets funded before the ledger existed get an opening entry, so the ledger
+-- reconciles with the current balances from the start.
+WITH opening AS (
+    INSERT INTO ledger_entries (kind, reference_id)
+    SELECT 'opening', id FROM wallets WHERE balance > 0
+    RETURNING id, reference_id
+)
+INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount)
+SELECT opening.id, 'system', 'opening', 'debit', wallets.balance
+FROM opening JOIN wallets ON wallets.id = opening.reference_id
+UNION ALL
+SELECT opening.id, 'wallet', wallets.id::text, 'credit', wallets.balance
+FROM opening JOIN wallets ON wallets.id = opening.reference_id;
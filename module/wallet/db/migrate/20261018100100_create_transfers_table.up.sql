@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS transfers
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    transferred_by uuid NOT NULL,
+    transferred_to uuid NOT NULL,
+    status INTEGER DEFAULT 0,
+    amount DECIMAL DEFAULT 0.0::DECIMAL,
+    transferred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reference_id uuid NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS transfers_transferred_to_idx ON transfers (transferred_to);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens
+(
+    jti VARCHAR(64) PRIMARY KEY,
+    customer_xid uuid NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
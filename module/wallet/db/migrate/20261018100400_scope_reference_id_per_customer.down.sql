@@ -0,0 +1,9 @@
+-- Fails when two customers have since used the same reference id.
+ALTER TABLE transfers DROP CONSTRAINT IF EXISTS transfers_transferred_by_reference_id_key;
+ALTER TABLE transfers ADD CONSTRAINT transfers_reference_id_key UNIQUE (reference_id);
+
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS withdrawals_withdrawn_by_reference_id_key;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_reference_id_key UNIQUE (reference_id);
+
+ALTER TABLE deposits DROP CONSTRAINT IF EXISTS deposits_deposited_by_reference_id_key;
+ALTER TABLE deposits ADD CONSTRAINT deposits_reference_id_key UNIQUE (reference_id);
@@ -0,0 +1,8 @@
+ALTER TABLE deposits DROP CONSTRAINT IF EXISTS deposits_reference_id_key;
+ALTER TABLE deposits ADD CONSTRAINT deposits_deposited_by_reference_id_key UNIQUE (deposited_by, reference_id);
+
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS withdrawals_reference_id_key;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_withdrawn_by_reference_id_key UNIQUE (withdrawn_by, reference_id);
+
+ALTER TABLE transfers DROP CONSTRAINT IF EXISTS transfers_reference_id_key;
+ALTER TABLE transfers ADD CONSTRAINT transfers_transferred_by_reference_id_key UNIQUE (transferred_by, reference_id);
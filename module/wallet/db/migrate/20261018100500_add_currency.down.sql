@@ -0,0 +1,7 @@
+ALTER TABLE ledger_postings DROP COLUMN IF EXISTS currency;
+ALTER TABLE transfers DROP COLUMN IF EXISTS currency;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS currency;
+ALTER TABLE deposits DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_owned_by_currency_key;
+ALTER TABLE wallets DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,9 @@
+-- Balances and amounts stay DECIMAL; the currency tells how many minor units
+-- they may carry. Rows written before currencies existed are IDR.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE wallets ADD CONSTRAINT wallets_owned_by_currency_key UNIQUE (owned_by, currency);
+
+ALTER TABLE deposits ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE ledger_postings ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,5 @@
+ALTER TABLE deposits DROP COLUMN IF EXISTS conversion_id;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS conversion_id;
+
+DROP TABLE IF EXISTS conversions;
+DROP TABLE IF EXISTS conversion_quotes;
@@ -0,0 +1,36 @@
+-- A quote locks the rate of a conversion until expires_at; used_at is set by
+-- the conversion that spent it, so a quote converts at most once.
+CREATE TABLE IF NOT EXISTS conversion_quotes
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    quoted_for uuid NOT NULL,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    amount DECIMAL NOT NULL,
+    converted_amount DECIMAL NOT NULL,
+    rate DECIMAL NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS conversions
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    converted_by uuid NOT NULL,
+    quote_id uuid UNIQUE REFERENCES conversion_quotes (id),
+    status INTEGER DEFAULT 0,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    amount DECIMAL NOT NULL,
+    converted_amount DECIMAL NOT NULL,
+    rate DECIMAL NOT NULL,
+    converted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reference_id uuid NOT NULL,
+    CONSTRAINT conversions_converted_by_reference_id_key UNIQUE (converted_by, reference_id)
+);
+
+-- A conversion is booked as a withdrawal from the source balance and a deposit
+-- to the target balance, both pointing back at it.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS conversion_id uuid REFERENCES conversions (id);
+ALTER TABLE deposits ADD COLUMN IF NOT EXISTS conversion_id uuid REFERENCES conversions (id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS withdrawals_pending_expires_at_idx;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS settled_at;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS held_balance;
@@ -0,0 +1,11 @@
+-- held_balance is the part of balance reserved by pending withdrawals. Only
+-- balance - held_balance can be spent.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS held_balance DECIMAL NOT NULL DEFAULT 0 CHECK (held_balance >= 0);
+
+-- A pending withdrawal holds its amount until it is captured or voided, or
+-- until expires_at passes. settled_at is when it left the pending status.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP WITH TIME ZONE;
+
+-- Status 3 is pending; the expiry sweep only ever looks at those rows.
+CREATE INDEX IF NOT EXISTS withdrawals_pending_expires_at_idx ON withdrawals (expires_at) WHERE status = 3;
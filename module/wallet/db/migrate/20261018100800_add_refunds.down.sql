@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS withdrawals_reversal_of_key;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS reversal_of;
+
+DROP INDEX IF EXISTS deposits_refund_of_idx;
+ALTER TABLE deposits DROP COLUMN IF EXISTS refund_of;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS refunded_amount;
@@ -0,0 +1,11 @@
+-- A refund gives part or all of a withdrawal back as a deposit pointing at it
+-- with refund_of; refunded_amount keeps the refunds of a withdrawal from
+-- exceeding its amount.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL NOT NULL DEFAULT 0;
+ALTER TABLE deposits ADD COLUMN IF NOT EXISTS refund_of uuid REFERENCES withdrawals (id);
+CREATE INDEX IF NOT EXISTS deposits_refund_of_idx ON deposits (refund_of);
+
+-- A reversal takes a deposit back as a withdrawal pointing at it with
+-- reversal_of; a deposit is reversed at most once.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS reversal_of uuid REFERENCES deposits (id);
+CREATE UNIQUE INDEX IF NOT EXISTS withdrawals_reversal_of_key ON withdrawals (reversal_of);
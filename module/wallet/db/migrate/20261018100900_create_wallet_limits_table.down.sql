@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS withdrawals_withdrawn_by_withdrawn_at_idx;
+DROP INDEX IF EXISTS deposits_deposited_by_deposited_at_idx;
+DROP TABLE IF EXISTS wallet_limits;
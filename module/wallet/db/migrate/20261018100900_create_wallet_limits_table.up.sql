@@ -0,0 +1,18 @@
+-- Per-wallet overrides of the default limits. A NULL column inherits the
+-- default, any other value replaces it; 0 lifts the limit.
+CREATE TABLE IF NOT EXISTS wallet_limits
+(
+    wallet_id uuid PRIMARY KEY REFERENCES wallets (id),
+    max_deposit DECIMAL CHECK (max_deposit >= 0),
+    max_withdrawal DECIMAL CHECK (max_withdrawal >= 0),
+    daily_withdrawal DECIMAL CHECK (daily_withdrawal >= 0),
+    monthly_withdrawal DECIMAL CHECK (monthly_withdrawal >= 0),
+    max_balance DECIMAL CHECK (max_balance >= 0),
+    hourly_transactions INTEGER CHECK (hourly_transactions >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The velocity checks sum and count the recent rows of a customer in one
+-- currency.
+CREATE INDEX IF NOT EXISTS deposits_deposited_by_deposited_at_idx ON deposits (deposited_by, currency, deposited_at);
+CREATE INDEX IF NOT EXISTS withdrawals_withdrawn_by_withdrawn_at_idx ON withdrawals (withdrawn_by, currency, withdrawn_at);
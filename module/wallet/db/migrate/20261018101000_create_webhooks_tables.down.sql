@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS webhook_deliveries_pending_idx;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_events;
+DROP INDEX IF EXISTS webhook_endpoints_owned_by_idx;
+DROP TABLE IF EXISTS webhook_endpoints;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    owned_by uuid NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_owned_by_idx ON webhook_endpoints (owned_by);
+
+-- The outbox. An event is written in the same transaction as the change it
+-- describes, along with one delivery per endpoint of its customer.
+CREATE TABLE IF NOT EXISTS webhook_events
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(64) NOT NULL,
+    customer_xid uuid NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id uuid NOT NULL REFERENCES webhook_events (id),
+    endpoint_id uuid NOT NULL REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (event_id, endpoint_id)
+);
+
+-- The dispatcher only ever looks for the pending deliveries that are due.
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,14 @@
+-- Manual balance corrections made by operations staff. A positive amount is
+-- added to the wallet balance, a negative one taken off it.
+CREATE TABLE IF NOT EXISTS adjustments
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id uuid NOT NULL REFERENCES wallets (id),
+    adjusted_by VARCHAR(255) NOT NULL,
+    amount DECIMAL NOT NULL CHECK (amount <> 0),
+    currency CHAR(3) NOT NULL,
+    reason TEXT NOT NULL,
+    reference_id uuid NOT NULL,
+    adjusted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wallet_id, reference_id)
+);
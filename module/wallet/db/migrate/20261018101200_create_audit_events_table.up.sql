@@ -0,0 +1,34 @@
+-- An append-only, hash chained log of who changed what. seq is assigned by
+-- the writer under an advisory lock, so the chain has no gaps nor forks.
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    seq BIGINT PRIMARY KEY,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    customer_xid VARCHAR(255) NOT NULL DEFAULT '',
+    wallet_id VARCHAR(64) NOT NULL DEFAULT '',
+    resource_type VARCHAR(32) NOT NULL DEFAULT '',
+    resource_id VARCHAR(64) NOT NULL DEFAULT '',
+    -- JSON rather than JSONB keeps the snapshots byte for byte as hashed.
+    before JSON,
+    after JSON,
+    reason TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_customer_xid_idx ON audit_events (customer_xid, seq);
+CREATE INDEX IF NOT EXISTS audit_events_wallet_id_idx ON audit_events (wallet_id, seq);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wallet_status_history;
+ALTER TABLE wallets DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS disabled_at;
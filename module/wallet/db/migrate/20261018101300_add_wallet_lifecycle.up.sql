@@ -0,0 +1,20 @@
+-- A wallet is pending (0), enabled (1), disabled (2), frozen (3) or closed
+-- (4). disabled_at is when it was last disabled, closed_at when it was closed
+-- for good.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+
+-- Every transition of a wallet from a status to another, and who made it why.
+CREATE TABLE IF NOT EXISTS wallet_status_history
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id uuid NOT NULL REFERENCES wallets (id),
+    from_status INT NOT NULL,
+    to_status INT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallet_status_history_wallet_id_idx ON wallet_status_history (wallet_id, changed_at);
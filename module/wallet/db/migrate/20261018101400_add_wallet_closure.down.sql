@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS wallets_closed_at_idx;
+ALTER TABLE accounts DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS destination;
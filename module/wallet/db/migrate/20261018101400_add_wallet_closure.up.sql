@@ -0,0 +1,9 @@
+-- A closing wallet pays its balance out to a destination given by its owner.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS destination TEXT NOT NULL DEFAULT '';
+
+-- anonymized_at is when the data of a customer whose wallets are all closed
+-- was erased, once the retention period was over. Their transactions stay.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+-- The retention sweep looks for the closed wallets of customers.
+CREATE INDEX IF NOT EXISTS wallets_closed_at_idx ON wallets (owned_by, closed_at) WHERE status = 4;
@@ -0,0 +1,15 @@
+-- Wallets whose balance did not add up to their transactions when the
+-- reconciliation ran at reconciled_at. drift is balance - expected_balance.
+CREATE TABLE IF NOT EXISTS balance_discrepancies
+(
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id uuid NOT NULL REFERENCES wallets (id),
+    customer_xid uuid NOT NULL,
+    currency CHAR(3) NOT NULL,
+    balance DECIMAL NOT NULL,
+    expected_balance DECIMAL NOT NULL,
+    drift DECIMAL NOT NULL,
+    reconciled_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS balance_discrepancies_wallet_id_idx ON balance_discrepancies (wallet_id, reconciled_at);
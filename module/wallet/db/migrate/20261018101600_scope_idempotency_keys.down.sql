@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS expires_at;
+
+-- The same key used on two routes would collide once unscoped.
+DELETE FROM idempotency_keys WHERE route <> '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS route;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (customer_xid, idempotency_key);
@@ -0,0 +1,14 @@
+-- A key is scoped to the method and path of the request it was sent with, so
+-- the same key or reference_id used on two endpoints are two requests. Keys
+-- stored before have no route and are left to expire.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS route VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (customer_xid, route, idempotency_key);
+
+-- expires_at is when the response stops being replayed and the key may be
+-- used again. Expired keys are purged.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+UPDATE idempotency_keys SET expires_at = created_at + INTERVAL '24 hours' WHERE expires_at IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,4 @@
+-- claim_id is set each time a dispatcher claims the delivery, and the outcome
+-- of an attempt is only written under the claim it was sent with: a delivery
+-- whose lease ran out and was claimed again is left to the new claim.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS claim_id uuid;
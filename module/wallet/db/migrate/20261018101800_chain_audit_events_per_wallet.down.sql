@@ -0,0 +1,7 @@
+-- The chains can not be merged back into one; the events written since no
+-- longer verify once their chain is gone.
+ALTER TABLE audit_events ALTER COLUMN seq DROP DEFAULT;
+DROP SEQUENCE IF EXISTS audit_events_seq;
+DROP INDEX IF EXISTS audit_events_chain_idx;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS chain_seq;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS chain;
@@ -0,0 +1,18 @@
+-- Events are chained per wallet, or per customer for those about no wallet in
+-- particular, so that appending only locks its own chain rather than every
+-- audited transaction. chain_seq numbers the events of a chain without gaps;
+-- seq is now given by a sequence and only orders the log. The events written
+-- before make up the chain '' they were already chained in.
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS chain VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+
+ALTER TABLE audit_events DISABLE TRIGGER audit_events_append_only;
+UPDATE audit_events SET chain_seq = seq WHERE chain_seq IS NULL;
+ALTER TABLE audit_events ENABLE TRIGGER audit_events_append_only;
+
+ALTER TABLE audit_events ALTER COLUMN chain_seq SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS audit_events_chain_idx ON audit_events (chain, chain_seq);
+
+CREATE SEQUENCE IF NOT EXISTS audit_events_seq OWNED BY audit_events.seq;
+SELECT setval('audit_events_seq', COALESCE(MAX(seq), 0) + 1, false) FROM audit_events;
+ALTER TABLE audit_events ALTER COLUMN seq SET DEFAULT nextval('audit_events_seq');
@@ -0,0 +1,2 @@
+DELETE FROM revoked_tokens WHERE customer_xid !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE revoked_tokens ALTER COLUMN customer_xid TYPE uuid USING customer_xid::uuid;
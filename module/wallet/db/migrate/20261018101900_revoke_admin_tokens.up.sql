@@ -0,0 +1,3 @@
+-- Admin tokens are revoked too, and carry the staff id, which is not a uuid,
+-- where customer tokens carry the customer xid.
+ALTER TABLE revoked_tokens ALTER COLUMN customer_xid TYPE VARCHAR(255) USING customer_xid::text;
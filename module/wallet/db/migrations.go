@@ -0,0 +1,42 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrate/*.up.sql
+var migrations embed.FS
+
+// LatestMigrationVersion returns the version of the newest migration shipped
+// with this build, the one golang-migrate records in schema_migrations once
+// every migration is applied.
+func LatestMigrationVersion() (uint64, error) {
+	files, err := fs.Glob(migrations, "migrate/*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint64
+	for _, file := range files {
+		name := strings.TrimPrefix(file, "migrate/")
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("migration %s has no version prefix", name)
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
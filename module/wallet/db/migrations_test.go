@@ -0,0 +1,21 @@
+package db
+
+import (
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	files, err := fs.Glob(migrations, "migrate/*.up.sql")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	version, err := LatestMigrationVersion()
+	assert.Nil(t, err)
+	// Migration files sort by their timestamp prefix, newest last.
+	assert.True(t, strings.HasPrefix(files[len(files)-1], "migrate/"+strconv.FormatUint(version, 10)+"_"))
+}
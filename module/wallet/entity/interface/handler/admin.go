@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type AdminHandler interface {
+	SearchWallets(w http.ResponseWriter, r *http.Request)
+	EnableWallet(w http.ResponseWriter, r *http.Request)
+	DisableWallet(w http.ResponseWriter, r *http.Request)
+	FreezeWallet(w http.ResponseWriter, r *http.Request)
+	GetWalletStatusHistory(w http.ResponseWriter, r *http.Request)
+	AdjustBalance(w http.ResponseWriter, r *http.Request)
+	GetTransactions(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type AuditHandler interface {
+	GetEvents(w http.ResponseWriter, r *http.Request)
+	Verify(w http.ResponseWriter, r *http.Request)
+}
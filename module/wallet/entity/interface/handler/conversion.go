@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type ConversionHandler interface {
+	CreateQuote(w http.ResponseWriter, r *http.Request)
+	Create(w http.ResponseWriter, r *http.Request)
+}
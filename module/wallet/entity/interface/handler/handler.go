@@ -2,8 +2,17 @@ package handler
 
 // Handler manage http contract
 type Handler struct {
-	WalletHandler     WalletHandler
-	AccountHandler    AccountHandler
-	DepositHandler    DepositHandler
-	WithdrawalHandler WithdrawalHandler
+	WalletHandler      WalletHandler
+	AccountHandler     AccountHandler
+	DepositHandler     DepositHandler
+	WithdrawalHandler  WithdrawalHandler
+	TransactionHandler TransactionHandler
+	TransferHandler    TransferHandler
+	ConversionHandler  ConversionHandler
+	RefundHandler      RefundHandler
+	WebhookHandler     WebhookHandler
+	AdminHandler       AdminHandler
+	AuditHandler       AuditHandler
+	TokenHandler       TokenHandler
+	HealthHandler      HealthHandler
 }
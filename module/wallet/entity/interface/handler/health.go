@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type HealthHandler interface {
+	Liveness(w http.ResponseWriter, r *http.Request)
+	Readiness(w http.ResponseWriter, r *http.Request)
+}
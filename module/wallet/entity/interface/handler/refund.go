@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type RefundHandler interface {
+	Refund(w http.ResponseWriter, r *http.Request)
+	Reverse(w http.ResponseWriter, r *http.Request)
+}
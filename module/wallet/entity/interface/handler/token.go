@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type TokenHandler interface {
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+}
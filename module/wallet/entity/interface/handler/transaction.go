@@ -0,0 +1,9 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type TransactionHandler interface {
+	GetTransactions(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,9 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type TransferHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+}
@@ -8,4 +8,5 @@ type WalletHandler interface {
 	Enabled(w http.ResponseWriter, r *http.Request)
 	Disable(w http.ResponseWriter, r *http.Request)
 	GetWallet(w http.ResponseWriter, r *http.Request)
+	Close(w http.ResponseWriter, r *http.Request)
 }
@@ -0,0 +1,11 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type WebhookHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
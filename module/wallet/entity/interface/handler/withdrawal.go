@@ -6,4 +6,6 @@ import (
 
 type WithdrawalHandler interface {
 	Create(w http.ResponseWriter, r *http.Request)
+	Capture(w http.ResponseWriter, r *http.Request)
+	Void(w http.ResponseWriter, r *http.Request)
 }
@@ -1,15 +1,21 @@
 package model
 
 import (
+	"time"
+
 	jwt "github.com/golang-jwt/jwt/v4"
 )
 
 type Account struct {
-	CustomerXid string `json:"customer_xid"`
+	CustomerXid  string     `json:"customer_xid" validate:"required"`
+	AnonymizedAt *time.Time `json:"-" db:"anonymized_at"`
 }
 
 type Claims struct {
 	CustomerXid string `json:"customer_xid"`
+	// Role is empty for customers. Admin tokens carry the staff member in
+	// CustomerXid.
+	Role string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
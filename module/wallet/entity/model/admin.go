@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Adjustment is a manual correction of a wallet balance made by operations
+// staff. A positive Amount is added to the balance, a negative one taken off.
+type Adjustment struct {
+	ID          string          `json:"id" db:"id"`
+	WalletID    string          `json:"wallet_id" db:"wallet_id"`
+	AdjustedBy  string          `json:"adjusted_by" db:"adjusted_by"`
+	AdjustedAt  time.Time       `json:"adjusted_at" db:"adjusted_at"`
+	Amount      decimal.Decimal `json:"amount" db:"amount"`
+	Currency    string          `json:"currency" db:"currency"`
+	Reason      string          `json:"reason" db:"reason"`
+	ReferenceID string          `json:"reference_id" db:"reference_id"`
+	// Balance is the wallet balance after the adjustment.
+	Balance decimal.Decimal `json:"balance" db:"-"`
+}
+
+type PayloadAdminStatus struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type PayloadAdjustment struct {
+	ReferenceID string          `json:"reference_id" validate:"required,uuid"`
+	Amount      decimal.Decimal `json:"amount" validate:"required"`
+	Reason      string          `json:"reason" validate:"required"`
+}
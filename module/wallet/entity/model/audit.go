@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent records who changed what. The events of a wallet form a hash
+// chain: Hash covers the event and PrevHash, the Hash of the event before it
+// in Chain, so an event edited or deleted after the fact breaks the chain
+// from there on. Seq orders the whole log; ChainSeq numbers the events of
+// Chain without gaps.
+type AuditEvent struct {
+	Seq          int64           `json:"seq"`
+	Chain        string          `json:"chain"`
+	ChainSeq     int64           `json:"chain_seq"`
+	ActorType    string          `json:"actor_type"`
+	ActorID      string          `json:"actor_id"`
+	Action       string          `json:"action"`
+	CustomerXid  string          `json:"customer_xid"`
+	WalletID     string          `json:"wallet_id,omitempty"`
+	ResourceType string          `json:"resource_type,omitempty"`
+	ResourceID   string          `json:"resource_id,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+	RequestID    string          `json:"request_id,omitempty"`
+	IP           string          `json:"ip,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+}
+
+type AuditEventList struct {
+	Events     []*AuditEvent `json:"events"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type AuditEventFilter struct {
+	CustomerXid string
+	WalletID    string
+	ActorID     string
+	Action      string
+	From        *time.Time
+	To          *time.Time
+	// Before is the seq the page starts under, newest first.
+	Before int64
+	Limit  int
+}
+
+// AuditVerification is the outcome of walking the audit chains. Events counts
+// the events found intact, BrokenSeq is the seq of the first that does not
+// follow from the one before it in its chain, BrokenChain that chain, and
+// LastHash the hash of the last intact event.
+type AuditVerification struct {
+	Valid       bool   `json:"valid"`
+	Events      int64  `json:"events"`
+	BrokenSeq   int64  `json:"broken_seq,omitempty"`
+	BrokenChain string `json:"broken_chain,omitempty"`
+	LastHash    string `json:"last_hash,omitempty"`
+}
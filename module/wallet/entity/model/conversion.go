@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConversionQuote prices Amount of FromCurrency as ConvertedAmount of
+// ToCurrency, a rate the customer can convert at until ExpiresAt.
+type ConversionQuote struct {
+	ID              string          `json:"id" db:"id"`
+	QuotedFor       string          `json:"-" db:"quoted_for"`
+	FromCurrency    string          `json:"from_currency" db:"from_currency"`
+	ToCurrency      string          `json:"to_currency" db:"to_currency"`
+	Amount          decimal.Decimal `json:"amount" db:"amount"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount" db:"converted_amount"`
+	Rate            decimal.Decimal `json:"rate" db:"rate"`
+	CreatedAt       time.Time       `json:"-" db:"created_at"`
+	ExpiresAt       time.Time       `json:"expires_at" db:"expires_at"`
+	// Used is set once a conversion spent the quote.
+	Used bool `json:"-" db:"-"`
+}
+
+// Conversion moves money between two currency balances of a customer. It is
+// booked as the withdrawal WithdrawalID and the deposit DepositID.
+type Conversion struct {
+	ID              string          `json:"id" db:"id"`
+	ConvertedBy     string          `json:"converted_by" db:"converted_by"`
+	QuoteID         string          `json:"quote_id,omitempty" db:"quote_id"`
+	Status          int             `json:"-" db:"status"`
+	StatusMessage   string          `json:"status" db:"-"`
+	ConvertedAt     time.Time       `json:"-" db:"converted_at"`
+	FromCurrency    string          `json:"from_currency" db:"from_currency"`
+	ToCurrency      string          `json:"to_currency" db:"to_currency"`
+	Amount          decimal.Decimal `json:"amount" db:"amount"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount" db:"converted_amount"`
+	Rate            decimal.Decimal `json:"rate" db:"rate"`
+	ReferenceID     string          `json:"reference_id" db:"reference_id"`
+	WithdrawalID    string          `json:"withdrawal_id" db:"-"`
+	DepositID       string          `json:"deposit_id" db:"-"`
+	// Replayed is set when an earlier request with the same reference id
+	// already created this record.
+	Replayed bool `json:"-" db:"-"`
+}
+
+type PayloadConversionQuote struct {
+	FromCurrency string          `json:"from_currency" validate:"required,iso4217"`
+	ToCurrency   string          `json:"to_currency" validate:"required,iso4217"`
+	Amount       decimal.Decimal `json:"amount" validate:"required,gt=0"`
+}
+
+// PayloadConversion converts at the rate locked by QuoteID, or at the current
+// rate when it is empty and the currencies and amount are given instead.
+type PayloadConversion struct {
+	ReferenceID  string          `json:"reference_id" validate:"required,uuid"`
+	QuoteID      string          `json:"quote_id" validate:"omitempty,uuid"`
+	FromCurrency string          `json:"from_currency" validate:"required_without=QuoteID,omitempty,iso4217"`
+	ToCurrency   string          `json:"to_currency" validate:"required_without=QuoteID,omitempty,iso4217"`
+	Amount       decimal.Decimal `json:"amount" validate:"required_without=QuoteID,omitempty,gt=0"`
+}
@@ -14,9 +14,23 @@ type Deposit struct {
 	DepositedAt   time.Time       `json:"-" db:"deposited_at"`
 	Amount        decimal.Decimal `json:"amount" db:"amount"`
 	ReferenceID   string          `json:"reference_id" db:"reference_id"`
+	Currency      string          `json:"currency" db:"currency"`
+	// RefundOf is the withdrawal this deposit refunds, if any.
+	RefundOf string `json:"refund_of,omitempty" db:"refund_of"`
+	// ConversionID is the conversion this deposit is a leg of, if any.
+	ConversionID string `json:"-" db:"conversion_id"`
+	// Replayed is set when an earlier request with the same reference id
+	// already created this record.
+	Replayed bool `json:"-" db:"-"`
 }
 
 type PayloadDeposit struct {
-	ReferenceID string          `json:"reference_id"`
-	Amount      decimal.Decimal `json:"amount"`
+	ReferenceID string          `json:"reference_id" validate:"required,uuid"`
+	Amount      decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	Currency    string          `json:"currency" validate:"omitempty,iso4217"`
+}
+
+// PayloadReversal reverses a deposit in full.
+type PayloadReversal struct {
+	ReferenceID string `json:"reference_id" validate:"required,uuid"`
 }
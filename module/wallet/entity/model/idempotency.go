@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// IdempotencyRecord is the first response given to a request made with an
+// idempotency key. Route is the method and path the key was sent to.
+// StatusCode is zero while that request is still running, and the response is
+// replayed until ExpiresAt.
+type IdempotencyRecord struct {
+	CustomerXid  string
+	Route        string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
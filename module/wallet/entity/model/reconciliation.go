@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// WalletReconciliation is the balance of a wallet next to the balance its
+// deposits, withdrawals, transfers and adjustments add up to.
+type WalletReconciliation struct {
+	WalletID        string          `json:"wallet_id" db:"wallet_id"`
+	CustomerXid     string          `json:"customer_xid" db:"customer_xid"`
+	Currency        string          `json:"currency" db:"currency"`
+	Balance         decimal.Decimal `json:"balance" db:"balance"`
+	ExpectedBalance decimal.Decimal `json:"expected_balance" db:"expected_balance"`
+}
+
+// BalanceDiscrepancy is a wallet whose balance drifted from its transactions.
+// Drift is Balance - ExpectedBalance.
+type BalanceDiscrepancy struct {
+	WalletReconciliation
+	Drift        decimal.Decimal `json:"drift" db:"drift"`
+	ReconciledAt time.Time       `json:"reconciled_at" db:"reconciled_at"`
+}
+
+type ReconciliationReport struct {
+	ReconciledAt   time.Time             `json:"reconciled_at"`
+	WalletsChecked int                   `json:"wallets_checked"`
+	Discrepancies  []*BalanceDiscrepancy `json:"discrepancies"`
+}
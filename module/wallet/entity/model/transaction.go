@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TransactionTypeDeposit     = "deposit"
+	TransactionTypeWithdrawal  = "withdrawal"
+	TransactionTypeTransferIn  = "transfer_in"
+	TransactionTypeTransferOut = "transfer_out"
+)
+
+func IsValidTransactionType(transactionType string) bool {
+	switch transactionType {
+	case TransactionTypeDeposit, TransactionTypeWithdrawal, TransactionTypeTransferIn, TransactionTypeTransferOut:
+		return true
+	}
+	return false
+}
+
+type Transaction struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	Status       string          `json:"status"`
+	TransactedAt time.Time       `json:"transacted_at"`
+	Amount       decimal.Decimal `json:"amount"`
+	ReferenceID  string          `json:"reference_id"`
+	Currency     string          `json:"currency"`
+}
+
+type TransactionList struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+}
+
+type TransactionFilter struct {
+	Type     string
+	Currency string
+	From     *time.Time
+	To       *time.Time
+	Cursor   *TransactionCursor
+	Limit    int
+}
+
+var cursorIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// TransactionCursor points at the last transaction of a page, so the next page
+// starts strictly after it in (transacted_at, id) descending order.
+type TransactionCursor struct {
+	TransactedAt time.Time
+	ID           string
+}
+
+func (c TransactionCursor) Encode() string {
+	raw := c.TransactedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(cursor string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("Cursor not valid")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || !cursorIDPattern.MatchString(parts[1]) {
+		return nil, errors.New("Cursor not valid")
+	}
+
+	transactedAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errors.New("Cursor not valid")
+	}
+
+	return &TransactionCursor{
+		TransactedAt: transactedAt,
+		ID:           parts[1],
+	}, nil
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Transfer struct {
+	ID            string          `json:"id" db:"id"`
+	TransferredBy string          `json:"transferred_by" db:"transferred_by"`
+	TransferredTo string          `json:"transferred_to" db:"transferred_to"`
+	Status        int             `json:"-" db:"status"`
+	StatusMessage string          `json:"status" db:"-"`
+	TransferredAt time.Time       `json:"-" db:"transferred_at"`
+	Amount        decimal.Decimal `json:"amount" db:"amount"`
+	ReferenceID   string          `json:"reference_id" db:"reference_id"`
+	Currency      string          `json:"currency" db:"currency"`
+	// Replayed is set when an earlier request with the same reference id
+	// already created this record.
+	Replayed bool `json:"-" db:"-"`
+}
+
+type PayloadTransfer struct {
+	RecipientCustomerXid string          `json:"recipient_customer_xid" validate:"required"`
+	ReferenceID          string          `json:"reference_id" validate:"required,uuid"`
+	Amount               decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	Currency             string          `json:"currency" validate:"omitempty,iso4217"`
+}
@@ -3,18 +3,93 @@ package model
 import (
 	"time"
 
+	"github.com/herwando/mini-wallet/module/wallet/limits"
 	"github.com/shopspring/decimal"
 )
 
+// DefaultCurrency is the currency of a wallet or transaction when the request
+// names none, and of every row written before wallets had a currency.
+const DefaultCurrency = "IDR"
+
 type Wallet struct {
-	ID            string          `json:"id" db:"id"`
-	OwnedBy       string          `json:"owned_by" db:"owned_by"`
-	Status        int             `json:"-" db:"status"`
-	StatusMessage string          `json:"status" db:"-"`
-	EnabledAt     time.Time       `json:"-" db:"enabled_at"`
-	Balance       decimal.Decimal `json:"balance" db:"balance"`
+	ID            string    `json:"id" db:"id"`
+	OwnedBy       string    `json:"owned_by" db:"owned_by"`
+	Status        int       `json:"-" db:"status"`
+	StatusMessage string    `json:"status" db:"-"`
+	EnabledAt     time.Time `json:"enabled_at" db:"enabled_at"`
+	// DisabledAt and ClosedAt are when the wallet was last disabled and when
+	// it was closed, nil until then.
+	DisabledAt *time.Time      `json:"disabled_at" db:"disabled_at"`
+	ClosedAt   *time.Time      `json:"closed_at" db:"closed_at"`
+	Balance    decimal.Decimal `json:"balance" db:"balance"`
+	// HeldBalance is the part of Balance held by pending withdrawals.
+	HeldBalance decimal.Decimal `json:"held_balance" db:"held_balance"`
+	// AvailableBalance is set to Available when the wallet is viewed.
+	AvailableBalance decimal.Decimal `json:"available_balance" db:"-"`
+	Currency         string          `json:"currency" db:"currency"`
+	// Balances lists every wallet of the owner, one per currency, when the
+	// wallet is viewed.
+	Balances []*WalletBalance `json:"balances,omitempty" db:"-"`
+	// MaxBalance is the balance a credit must not take the wallet over, zero
+	// for none. It is set from the wallet's limits before it is credited.
+	MaxBalance decimal.Decimal `json:"-" db:"-"`
+	// Limits are the limits of the wallet, set before a transaction that
+	// counts against them is made from or to it, nil when none is.
+	Limits *limits.Limits `json:"-" db:"-"`
+	// WithdrawnStatuses are the statuses of the withdrawals and transfers
+	// counted against Limits.
+	WithdrawnStatuses []int `json:"-" db:"-"`
+}
+
+// Available is the balance that can be spent, the balance less what pending
+// withdrawals hold.
+func (w *Wallet) Available() decimal.Decimal {
+	return w.Balance.Sub(w.HeldBalance)
+}
+
+type WalletBalance struct {
+	Currency         string          `json:"currency"`
+	Balance          decimal.Decimal `json:"balance"`
+	HeldBalance      decimal.Decimal `json:"held_balance"`
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	Status           string          `json:"status"`
+}
+
+// WalletStatusChange is one transition of a wallet from a status to another,
+// kept in its status history.
+type WalletStatusChange struct {
+	ID         string    `json:"id" db:"id"`
+	WalletID   string    `json:"wallet_id" db:"wallet_id"`
+	FromStatus string    `json:"from_status" db:"-"`
+	ToStatus   string    `json:"to_status" db:"-"`
+	From       int       `json:"-" db:"from_status"`
+	To         int       `json:"-" db:"to_status"`
+	Reason     string    `json:"reason" db:"reason"`
+	ActorType  string    `json:"actor_type" db:"actor_type"`
+	ActorID    string    `json:"actor_id" db:"actor_id"`
+	ChangedAt  time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// WalletClosure is a closed wallet, along with the withdrawal that paid out
+// its balance, if it had any.
+type WalletClosure struct {
+	Wallet *Wallet     `json:"wallet"`
+	Payout *Withdrawal `json:"payout,omitempty"`
+}
+
+type PayloadEnable struct {
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
 }
 
 type PayloadDisable struct {
-	IsDisabled bool `json:"is_disabled"`
+	IsDisabled bool   `json:"is_disabled" validate:"required"`
+	Currency   string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+// PayloadClose closes the wallet in Currency. A wallet with a balance is paid
+// out in full to Destination, under ReferenceID, before it closes.
+type PayloadClose struct {
+	Currency    string `json:"currency" validate:"omitempty,iso4217"`
+	Destination string `json:"destination" validate:"max=255"`
+	ReferenceID string `json:"reference_id" validate:"required_with=Destination,omitempty,uuid"`
 }
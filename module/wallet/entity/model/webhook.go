@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEndpoint is a URL the events of its owner are delivered to, signed
+// with Secret. The secret is only shown when the endpoint is registered.
+type WebhookEndpoint struct {
+	ID        string    `json:"id" db:"id"`
+	OwnedBy   string    `json:"owned_by" db:"owned_by"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PayloadWebhookEndpoint registers URL, which must be https. A secret is
+// generated when none is given.
+type PayloadWebhookEndpoint struct {
+	URL    string `json:"url" validate:"required,url,startswith=https://"`
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+// WebhookDelivery is one event to be delivered to one endpoint, along with
+// what is needed to send it.
+type WebhookDelivery struct {
+	ID             string
+	EventID        string
+	EventType      string
+	Payload        json.RawMessage
+	EventCreatedAt time.Time
+	URL            string
+	Secret         string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	DeliveredAt    *time.Time
+	// ClaimID is the claim the delivery is being sent under.
+	ClaimID string
+}
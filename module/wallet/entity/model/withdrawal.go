@@ -14,9 +14,38 @@ type Withdrawal struct {
 	WithdrawnAt   time.Time       `json:"-" db:"withdrawn_at"`
 	Amount        decimal.Decimal `json:"amount" db:"amount"`
 	ReferenceID   string          `json:"reference_id" db:"reference_id"`
+	Currency      string          `json:"currency" db:"currency"`
+	// ExpiresAt is when the hold of a pending withdrawal lapses, nil for a
+	// withdrawal that was never pending.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	// SettledAt is when the withdrawal was captured, voided or expired.
+	SettledAt *time.Time `json:"settled_at,omitempty" db:"settled_at"`
+	// RefundedAmount is the part of Amount already refunded.
+	RefundedAmount decimal.Decimal `json:"refunded_amount" db:"refunded_amount"`
+	// ReversalOf is the deposit this withdrawal reverses, if any.
+	ReversalOf string `json:"reversal_of,omitempty" db:"reversal_of"`
+	// ConversionID is the conversion this withdrawal is a leg of, if any.
+	ConversionID string `json:"-" db:"conversion_id"`
+	// Destination is where the payout of a closing wallet was sent. It is
+	// only written, for the record, and shown in the closure response.
+	Destination string `json:"destination,omitempty" db:"destination"`
+	// Replayed is set when an earlier request with the same reference id
+	// already created this record.
+	Replayed bool `json:"-" db:"-"`
 }
 
 type PayloadWithdrawal struct {
-	ReferenceID string          `json:"reference_id"`
-	Amount      decimal.Decimal `json:"amount"`
+	ReferenceID string          `json:"reference_id" validate:"required,uuid"`
+	Amount      decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	Currency    string          `json:"currency" validate:"omitempty,iso4217"`
+	// Pending only holds the amount on the wallet, to be captured or voided
+	// later.
+	Pending bool `json:"pending"`
+}
+
+// PayloadRefund refunds Amount of a withdrawal, or all of what is left to
+// refund when Amount is empty.
+type PayloadRefund struct {
+	ReferenceID string          `json:"reference_id" validate:"required,uuid"`
+	Amount      decimal.Decimal `json:"amount" validate:"omitempty,gt=0"`
 }
@@ -0,0 +1,86 @@
+// Package fx provides the exchange rates a conversion between two currency
+// balances of a wallet is priced with.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrRateNotFound = errors.New("Exchange rate not found")
+
+// RateProvider returns how many units of to one unit of from buys.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// StaticRateProvider quotes every currency against a single base currency and
+// derives the cross rates from them, so only one rate per currency is needed.
+type StaticRateProvider struct {
+	base  string
+	rates map[string]decimal.Decimal
+}
+
+// NewStaticRateProvider returns a provider where one unit of base buys
+// rates[code] units of code.
+func NewStaticRateProvider(base string, rates map[string]decimal.Decimal) *StaticRateProvider {
+	provider := &StaticRateProvider{
+		base:  base,
+		rates: map[string]decimal.Decimal{base: decimal.NewFromInt(1)},
+	}
+	for code, rate := range rates {
+		provider.rates[code] = rate
+	}
+
+	return provider
+}
+
+type rateFile struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// NewFileRateProvider reads the rates of a StaticRateProvider from a JSON file
+// such as {"base": "USD", "rates": {"IDR": "15500", "EUR": "0.92"}}.
+func NewFileRateProvider(path string) (*StaticRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if file.Base == "" {
+		return nil, fmt.Errorf("%s: base currency missing", path)
+	}
+	for code, rate := range file.Rates {
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("%s: rate of %s must be positive", path, code)
+		}
+	}
+
+	return NewStaticRateProvider(file.Base, file.Rates), nil
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, from, to string) (decimal.Decimal, error) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return decimal.Zero, ErrRateNotFound
+	}
+
+	toRate, ok := p.rates[to]
+	if !ok {
+		return decimal.Zero, ErrRateNotFound
+	}
+
+	return toRate.Div(fromRate), nil
+}
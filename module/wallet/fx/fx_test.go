@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticRateProvider_Rate(t *testing.T) {
+	provider := NewStaticRateProvider("USD", map[string]decimal.Decimal{
+		"IDR": decimal.NewFromInt(15500),
+		"EUR": decimal.RequireFromString("0.8"),
+	})
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    string
+		wantErr error
+	}{
+		{name: "base to quote", from: "USD", to: "IDR", want: "15500"},
+		{name: "quote to base", from: "EUR", to: "USD", want: "1.25"},
+		{name: "cross rate", from: "EUR", to: "IDR", want: "19375"},
+		{name: "same currency", from: "IDR", to: "IDR", want: "1"},
+		{name: "unknown from", from: "JPY", to: "USD", wantErr: ErrRateNotFound},
+		{name: "unknown to", from: "USD", to: "JPY", wantErr: ErrRateNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := provider.Rate(context.Background(), tt.from, tt.to)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.True(t, decimal.RequireFromString(tt.want).Equal(rate), "rate = %s, want %s", rate, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFileRateProvider(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "Success", content: `{"base": "USD", "rates": {"IDR": "15500", "EUR": 0.92}}`},
+		{name: "Failed malformed", content: `{"base": "USD", "rates": `, wantErr: true},
+		{name: "Failed base missing", content: `{"rates": {"IDR": "15500"}}`, wantErr: true},
+		{name: "Failed rate not positive", content: `{"base": "USD", "rates": {"IDR": "0"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewFileRateProvider(write(t, tt.content))
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			rate, err := provider.Rate(context.Background(), "USD", "IDR")
+			assert.Nil(t, err)
+			assert.True(t, decimal.NewFromInt(15500).Equal(rate))
+		})
+	}
+
+	_, err := NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
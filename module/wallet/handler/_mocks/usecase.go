@@ -111,49 +111,64 @@ func (m *MockWalletUsecase) EXPECT() *MockWalletUsecaseMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockWalletUsecase) Close(ctx context.Context, customerXid string, payload model.PayloadClose) (*model.WalletClosure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx, customerXid, payload)
+	ret0, _ := ret[0].(*model.WalletClosure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockWalletUsecaseMockRecorder) Close(ctx, customerXid, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockWalletUsecase)(nil).Close), ctx, customerXid, payload)
+}
+
 // Disable mocks base method.
-func (m *MockWalletUsecase) Disable(ctx context.Context, customerXid string) (*model.Wallet, error) {
+func (m *MockWalletUsecase) Disable(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Disable", ctx, customerXid)
+	ret := m.ctrl.Call(m, "Disable", ctx, customerXid, currency)
 	ret0, _ := ret[0].(*model.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Disable indicates an expected call of Disable.
-func (mr *MockWalletUsecaseMockRecorder) Disable(ctx, customerXid interface{}) *gomock.Call {
+func (mr *MockWalletUsecaseMockRecorder) Disable(ctx, customerXid, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockWalletUsecase)(nil).Disable), ctx, customerXid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockWalletUsecase)(nil).Disable), ctx, customerXid, currency)
 }
 
 // Enabled mocks base method.
-func (m *MockWalletUsecase) Enabled(ctx context.Context, customerXid string) (*model.Wallet, error) {
+func (m *MockWalletUsecase) Enabled(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Enabled", ctx, customerXid)
+	ret := m.ctrl.Call(m, "Enabled", ctx, customerXid, currency)
 	ret0, _ := ret[0].(*model.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Enabled indicates an expected call of Enabled.
-func (mr *MockWalletUsecaseMockRecorder) Enabled(ctx, customerXid interface{}) *gomock.Call {
+func (mr *MockWalletUsecaseMockRecorder) Enabled(ctx, customerXid, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockWalletUsecase)(nil).Enabled), ctx, customerXid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockWalletUsecase)(nil).Enabled), ctx, customerXid, currency)
 }
 
 // GetWallet mocks base method.
-func (m *MockWalletUsecase) GetWallet(ctx context.Context, customerXid string) (*model.Wallet, error) {
+func (m *MockWalletUsecase) GetWallet(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWallet", ctx, customerXid)
+	ret := m.ctrl.Call(m, "GetWallet", ctx, customerXid, currency)
 	ret0, _ := ret[0].(*model.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWallet indicates an expected call of GetWallet.
-func (mr *MockWalletUsecaseMockRecorder) GetWallet(ctx, customerXid interface{}) *gomock.Call {
+func (mr *MockWalletUsecaseMockRecorder) GetWallet(ctx, customerXid, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletUsecase)(nil).GetWallet), ctx, customerXid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletUsecase)(nil).GetWallet), ctx, customerXid, currency)
 }
 
 // MockWithdrawalUsecase is a mock of WithdrawalUsecase interface.
@@ -179,6 +194,21 @@ func (m *MockWithdrawalUsecase) EXPECT() *MockWithdrawalUsecaseMockRecorder {
 	return m.recorder
 }
 
+// CaptureWithdrawal mocks base method.
+func (m *MockWithdrawalUsecase) CaptureWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureWithdrawal", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureWithdrawal indicates an expected call of CaptureWithdrawal.
+func (mr *MockWithdrawalUsecaseMockRecorder) CaptureWithdrawal(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureWithdrawal", reflect.TypeOf((*MockWithdrawalUsecase)(nil).CaptureWithdrawal), ctx, customerXid, id)
+}
+
 // CreateWithdrawal mocks base method.
 func (m *MockWithdrawalUsecase) CreateWithdrawal(ctx context.Context, customerXid string, payload model.PayloadWithdrawal) (*model.Withdrawal, error) {
 	m.ctrl.T.Helper()
@@ -193,3 +223,538 @@ func (mr *MockWithdrawalUsecaseMockRecorder) CreateWithdrawal(ctx, customerXid,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawal", reflect.TypeOf((*MockWithdrawalUsecase)(nil).CreateWithdrawal), ctx, customerXid, payload)
 }
+
+// VoidWithdrawal mocks base method.
+func (m *MockWithdrawalUsecase) VoidWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidWithdrawal", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidWithdrawal indicates an expected call of VoidWithdrawal.
+func (mr *MockWithdrawalUsecaseMockRecorder) VoidWithdrawal(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidWithdrawal", reflect.TypeOf((*MockWithdrawalUsecase)(nil).VoidWithdrawal), ctx, customerXid, id)
+}
+
+// MockTransactionUsecase is a mock of TransactionUsecase interface.
+type MockTransactionUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionUsecaseMockRecorder
+}
+
+// MockTransactionUsecaseMockRecorder is the mock recorder for MockTransactionUsecase.
+type MockTransactionUsecaseMockRecorder struct {
+	mock *MockTransactionUsecase
+}
+
+// NewMockTransactionUsecase creates a new mock instance.
+func NewMockTransactionUsecase(ctrl *gomock.Controller) *MockTransactionUsecase {
+	mock := &MockTransactionUsecase{ctrl: ctrl}
+	mock.recorder = &MockTransactionUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionUsecase) EXPECT() *MockTransactionUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetTransactions mocks base method.
+func (m *MockTransactionUsecase) GetTransactions(ctx context.Context, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, customerXid, filter)
+	ret0, _ := ret[0].(*model.TransactionList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockTransactionUsecaseMockRecorder) GetTransactions(ctx, customerXid, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockTransactionUsecase)(nil).GetTransactions), ctx, customerXid, filter)
+}
+
+// MockTransferUsecase is a mock of TransferUsecase interface.
+type MockTransferUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferUsecaseMockRecorder
+}
+
+// MockTransferUsecaseMockRecorder is the mock recorder for MockTransferUsecase.
+type MockTransferUsecaseMockRecorder struct {
+	mock *MockTransferUsecase
+}
+
+// NewMockTransferUsecase creates a new mock instance.
+func NewMockTransferUsecase(ctrl *gomock.Controller) *MockTransferUsecase {
+	mock := &MockTransferUsecase{ctrl: ctrl}
+	mock.recorder = &MockTransferUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferUsecase) EXPECT() *MockTransferUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateTransfer mocks base method.
+func (m *MockTransferUsecase) CreateTransfer(ctx context.Context, customerXid string, payload model.PayloadTransfer) (*model.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, customerXid, payload)
+	ret0, _ := ret[0].(*model.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockTransferUsecaseMockRecorder) CreateTransfer(ctx, customerXid, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockTransferUsecase)(nil).CreateTransfer), ctx, customerXid, payload)
+}
+
+// MockConversionUsecase is a mock of ConversionUsecase interface.
+type MockConversionUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversionUsecaseMockRecorder
+}
+
+// MockConversionUsecaseMockRecorder is the mock recorder for MockConversionUsecase.
+type MockConversionUsecaseMockRecorder struct {
+	mock *MockConversionUsecase
+}
+
+// NewMockConversionUsecase creates a new mock instance.
+func NewMockConversionUsecase(ctrl *gomock.Controller) *MockConversionUsecase {
+	mock := &MockConversionUsecase{ctrl: ctrl}
+	mock.recorder = &MockConversionUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversionUsecase) EXPECT() *MockConversionUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateConversion mocks base method.
+func (m *MockConversionUsecase) CreateConversion(ctx context.Context, customerXid string, payload model.PayloadConversion) (*model.Conversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConversion", ctx, customerXid, payload)
+	ret0, _ := ret[0].(*model.Conversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConversion indicates an expected call of CreateConversion.
+func (mr *MockConversionUsecaseMockRecorder) CreateConversion(ctx, customerXid, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConversion", reflect.TypeOf((*MockConversionUsecase)(nil).CreateConversion), ctx, customerXid, payload)
+}
+
+// CreateQuote mocks base method.
+func (m *MockConversionUsecase) CreateQuote(ctx context.Context, customerXid string, payload model.PayloadConversionQuote) (*model.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, customerXid, payload)
+	ret0, _ := ret[0].(*model.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockConversionUsecaseMockRecorder) CreateQuote(ctx, customerXid, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockConversionUsecase)(nil).CreateQuote), ctx, customerXid, payload)
+}
+
+// MockRefundUsecase is a mock of RefundUsecase interface.
+type MockRefundUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundUsecaseMockRecorder
+}
+
+// MockRefundUsecaseMockRecorder is the mock recorder for MockRefundUsecase.
+type MockRefundUsecaseMockRecorder struct {
+	mock *MockRefundUsecase
+}
+
+// NewMockRefundUsecase creates a new mock instance.
+func NewMockRefundUsecase(ctrl *gomock.Controller) *MockRefundUsecase {
+	mock := &MockRefundUsecase{ctrl: ctrl}
+	mock.recorder = &MockRefundUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundUsecase) EXPECT() *MockRefundUsecaseMockRecorder {
+	return m.recorder
+}
+
+// RefundWithdrawal mocks base method.
+func (m *MockRefundUsecase) RefundWithdrawal(ctx context.Context, customerXid, id string, payload model.PayloadRefund) (*model.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundWithdrawal", ctx, customerXid, id, payload)
+	ret0, _ := ret[0].(*model.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundWithdrawal indicates an expected call of RefundWithdrawal.
+func (mr *MockRefundUsecaseMockRecorder) RefundWithdrawal(ctx, customerXid, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundWithdrawal", reflect.TypeOf((*MockRefundUsecase)(nil).RefundWithdrawal), ctx, customerXid, id, payload)
+}
+
+// ReverseDeposit mocks base method.
+func (m *MockRefundUsecase) ReverseDeposit(ctx context.Context, customerXid, id string, payload model.PayloadReversal) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseDeposit", ctx, customerXid, id, payload)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseDeposit indicates an expected call of ReverseDeposit.
+func (mr *MockRefundUsecaseMockRecorder) ReverseDeposit(ctx, customerXid, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseDeposit", reflect.TypeOf((*MockRefundUsecase)(nil).ReverseDeposit), ctx, customerXid, id, payload)
+}
+
+// MockWebhookUsecase is a mock of WebhookUsecase interface.
+type MockWebhookUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookUsecaseMockRecorder
+}
+
+// MockWebhookUsecaseMockRecorder is the mock recorder for MockWebhookUsecase.
+type MockWebhookUsecaseMockRecorder struct {
+	mock *MockWebhookUsecase
+}
+
+// NewMockWebhookUsecase creates a new mock instance.
+func NewMockWebhookUsecase(ctrl *gomock.Controller) *MockWebhookUsecase {
+	mock := &MockWebhookUsecase{ctrl: ctrl}
+	mock.recorder = &MockWebhookUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookUsecase) EXPECT() *MockWebhookUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateEndpoint mocks base method.
+func (m *MockWebhookUsecase) CreateEndpoint(ctx context.Context, customerXid string, payload model.PayloadWebhookEndpoint) (*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEndpoint", ctx, customerXid, payload)
+	ret0, _ := ret[0].(*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEndpoint indicates an expected call of CreateEndpoint.
+func (mr *MockWebhookUsecaseMockRecorder) CreateEndpoint(ctx, customerXid, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEndpoint", reflect.TypeOf((*MockWebhookUsecase)(nil).CreateEndpoint), ctx, customerXid, payload)
+}
+
+// DeleteEndpoint mocks base method.
+func (m *MockWebhookUsecase) DeleteEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEndpoint", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEndpoint indicates an expected call of DeleteEndpoint.
+func (mr *MockWebhookUsecaseMockRecorder) DeleteEndpoint(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEndpoint", reflect.TypeOf((*MockWebhookUsecase)(nil).DeleteEndpoint), ctx, customerXid, id)
+}
+
+// GetEndpoints mocks base method.
+func (m *MockWebhookUsecase) GetEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEndpoints", ctx, customerXid)
+	ret0, _ := ret[0].([]*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEndpoints indicates an expected call of GetEndpoints.
+func (mr *MockWebhookUsecaseMockRecorder) GetEndpoints(ctx, customerXid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEndpoints", reflect.TypeOf((*MockWebhookUsecase)(nil).GetEndpoints), ctx, customerXid)
+}
+
+// MockAdminUsecase is a mock of AdminUsecase interface.
+type MockAdminUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUsecaseMockRecorder
+}
+
+// MockAdminUsecaseMockRecorder is the mock recorder for MockAdminUsecase.
+type MockAdminUsecaseMockRecorder struct {
+	mock *MockAdminUsecase
+}
+
+// NewMockAdminUsecase creates a new mock instance.
+func NewMockAdminUsecase(ctrl *gomock.Controller) *MockAdminUsecase {
+	mock := &MockAdminUsecase{ctrl: ctrl}
+	mock.recorder = &MockAdminUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUsecase) EXPECT() *MockAdminUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AdjustBalance mocks base method.
+func (m *MockAdminUsecase) AdjustBalance(ctx context.Context, admin, id string, payload model.PayloadAdjustment) (*model.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, admin, id, payload)
+	ret0, _ := ret[0].(*model.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockAdminUsecaseMockRecorder) AdjustBalance(ctx, admin, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockAdminUsecase)(nil).AdjustBalance), ctx, admin, id, payload)
+}
+
+// DisableWallet mocks base method.
+func (m *MockAdminUsecase) DisableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableWallet", ctx, admin, id, payload)
+	ret0, _ := ret[0].(*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableWallet indicates an expected call of DisableWallet.
+func (mr *MockAdminUsecaseMockRecorder) DisableWallet(ctx, admin, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableWallet", reflect.TypeOf((*MockAdminUsecase)(nil).DisableWallet), ctx, admin, id, payload)
+}
+
+// EnableWallet mocks base method.
+func (m *MockAdminUsecase) EnableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableWallet", ctx, admin, id, payload)
+	ret0, _ := ret[0].(*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableWallet indicates an expected call of EnableWallet.
+func (mr *MockAdminUsecaseMockRecorder) EnableWallet(ctx, admin, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableWallet", reflect.TypeOf((*MockAdminUsecase)(nil).EnableWallet), ctx, admin, id, payload)
+}
+
+// FreezeWallet mocks base method.
+func (m *MockAdminUsecase) FreezeWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeWallet", ctx, admin, id, payload)
+	ret0, _ := ret[0].(*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FreezeWallet indicates an expected call of FreezeWallet.
+func (mr *MockAdminUsecaseMockRecorder) FreezeWallet(ctx, admin, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeWallet", reflect.TypeOf((*MockAdminUsecase)(nil).FreezeWallet), ctx, admin, id, payload)
+}
+
+// GetTransactions mocks base method.
+func (m *MockAdminUsecase) GetTransactions(ctx context.Context, admin, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, admin, customerXid, filter)
+	ret0, _ := ret[0].(*model.TransactionList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockAdminUsecaseMockRecorder) GetTransactions(ctx, admin, customerXid, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockAdminUsecase)(nil).GetTransactions), ctx, admin, customerXid, filter)
+}
+
+// GetWalletStatusHistory mocks base method.
+func (m *MockAdminUsecase) GetWalletStatusHistory(ctx context.Context, admin, id string) ([]*model.WalletStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletStatusHistory", ctx, admin, id)
+	ret0, _ := ret[0].([]*model.WalletStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletStatusHistory indicates an expected call of GetWalletStatusHistory.
+func (mr *MockAdminUsecaseMockRecorder) GetWalletStatusHistory(ctx, admin, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletStatusHistory", reflect.TypeOf((*MockAdminUsecase)(nil).GetWalletStatusHistory), ctx, admin, id)
+}
+
+// SearchWallets mocks base method.
+func (m *MockAdminUsecase) SearchWallets(ctx context.Context, admin, customerXid string) ([]*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWallets", ctx, admin, customerXid)
+	ret0, _ := ret[0].([]*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWallets indicates an expected call of SearchWallets.
+func (mr *MockAdminUsecaseMockRecorder) SearchWallets(ctx, admin, customerXid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWallets", reflect.TypeOf((*MockAdminUsecase)(nil).SearchWallets), ctx, admin, customerXid)
+}
+
+// MockAuditUsecase is a mock of AuditUsecase interface.
+type MockAuditUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditUsecaseMockRecorder
+}
+
+// MockAuditUsecaseMockRecorder is the mock recorder for MockAuditUsecase.
+type MockAuditUsecaseMockRecorder struct {
+	mock *MockAuditUsecase
+}
+
+// NewMockAuditUsecase creates a new mock instance.
+func NewMockAuditUsecase(ctrl *gomock.Controller) *MockAuditUsecase {
+	mock := &MockAuditUsecase{ctrl: ctrl}
+	mock.recorder = &MockAuditUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditUsecase) EXPECT() *MockAuditUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetEvents mocks base method.
+func (m *MockAuditUsecase) GetEvents(ctx context.Context, admin string, filter model.AuditEventFilter) (*model.AuditEventList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", ctx, admin, filter)
+	ret0, _ := ret[0].(*model.AuditEventList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents.
+func (mr *MockAuditUsecaseMockRecorder) GetEvents(ctx, admin, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockAuditUsecase)(nil).GetEvents), ctx, admin, filter)
+}
+
+// Verify mocks base method.
+func (m *MockAuditUsecase) Verify(ctx context.Context, admin string) (*model.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, admin)
+	ret0, _ := ret[0].(*model.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockAuditUsecaseMockRecorder) Verify(ctx, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockAuditUsecase)(nil).Verify), ctx, admin)
+}
+
+// MockTokenUsecase is a mock of TokenUsecase interface.
+type MockTokenUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenUsecaseMockRecorder
+}
+
+// MockTokenUsecaseMockRecorder is the mock recorder for MockTokenUsecase.
+type MockTokenUsecaseMockRecorder struct {
+	mock *MockTokenUsecase
+}
+
+// NewMockTokenUsecase creates a new mock instance.
+func NewMockTokenUsecase(ctrl *gomock.Controller) *MockTokenUsecase {
+	mock := &MockTokenUsecase{ctrl: ctrl}
+	mock.recorder = &MockTokenUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenUsecase) EXPECT() *MockTokenUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Logout mocks base method.
+func (m *MockTokenUsecase) Logout(ctx context.Context, claims *model.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockTokenUsecaseMockRecorder) Logout(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockTokenUsecase)(nil).Logout), ctx, claims)
+}
+
+// Refresh mocks base method.
+func (m *MockTokenUsecase) Refresh(ctx context.Context, claims *model.Claims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockTokenUsecaseMockRecorder) Refresh(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockTokenUsecase)(nil).Refresh), ctx, claims)
+}
+
+// MockHealthUsecase is a mock of HealthUsecase interface.
+type MockHealthUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthUsecaseMockRecorder
+}
+
+// MockHealthUsecaseMockRecorder is the mock recorder for MockHealthUsecase.
+type MockHealthUsecaseMockRecorder struct {
+	mock *MockHealthUsecase
+}
+
+// NewMockHealthUsecase creates a new mock instance.
+func NewMockHealthUsecase(ctrl *gomock.Controller) *MockHealthUsecase {
+	mock := &MockHealthUsecase{ctrl: ctrl}
+	mock.recorder = &MockHealthUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthUsecase) EXPECT() *MockHealthUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Ready mocks base method.
+func (m *MockHealthUsecase) Ready(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockHealthUsecaseMockRecorder) Ready(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockHealthUsecase)(nil).Ready), ctx)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+// customerXidPattern matches the UUID customer ids are; the wallets of any
+// other id would fail to be looked up rather than be missing.
+var customerXidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AdminHandler serves the admin API. The authenticated subject is the staff
+// member acting, not a customer.
+type AdminHandler struct {
+	usecase AdminUsecase
+}
+
+func NewAdminHandler(usecase AdminUsecase) *AdminHandler {
+	return &AdminHandler{
+		usecase: usecase,
+	}
+}
+
+// SearchWallets lists the wallets of the customer_xid query param.
+func (h *AdminHandler) SearchWallets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerXid := r.URL.Query().Get("customer_xid")
+	if customerXid == "" {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params customer_xid empty"))
+		return
+	}
+
+	if !customerXidPattern.MatchString(customerXid) {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params customer_xid not valid"))
+		return
+	}
+
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	wallets, err := h.usecase.SearchWallets(ctx, admin, customerXid)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   wallets,
+		Status: "success",
+	})
+}
+
+func (h *AdminHandler) EnableWallet(w http.ResponseWriter, r *http.Request) {
+	h.setWalletStatus(w, r, h.usecase.EnableWallet)
+}
+
+func (h *AdminHandler) DisableWallet(w http.ResponseWriter, r *http.Request) {
+	h.setWalletStatus(w, r, h.usecase.DisableWallet)
+}
+
+func (h *AdminHandler) FreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.setWalletStatus(w, r, h.usecase.FreezeWallet)
+}
+
+// GetWalletStatusHistory lists the transitions of the wallet {id}.
+func (h *AdminHandler) GetWalletStatusHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	changes, err := h.usecase.GetWalletStatusHistory(ctx, admin, chi.URLParam(r, "id"))
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   changes,
+		Status: "success",
+	})
+}
+
+func (h *AdminHandler) setWalletStatus(w http.ResponseWriter, r *http.Request, set func(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error)) {
+	ctx := r.Context()
+	var payload model.PayloadAdminStatus
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	wallet, err := set(ctx, admin, chi.URLParam(r, "id"), payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   wallet,
+		Status: "success",
+	})
+}
+
+// AdjustBalance corrects the balance of the wallet {id}.
+func (h *AdminHandler) AdjustBalance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadAdjustment
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	adjustment, err := h.usecase.AdjustBalance(ctx, admin, chi.URLParam(r, "id"), payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, BasicResponse{
+		Data:   adjustment,
+		Status: "success",
+	})
+}
+
+// GetTransactions lists the transactions of the customer {customer_xid},
+// filtered as the customer's own history is.
+func (h *AdminHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerXid := chi.URLParam(r, "customer_xid")
+	if !customerXidPattern.MatchString(customerXid) {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params customer_xid not valid"))
+		return
+	}
+
+	filter, errMsg := readTransactionFilter(r)
+	if errMsg != nil {
+		writerWriteJSONAPIError(ctx, w, errMsg)
+		return
+	}
+
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	transactions, err := h.usecase.GetTransactions(ctx, admin, customerXid, filter)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   transactions,
+		Status: "success",
+	})
+}
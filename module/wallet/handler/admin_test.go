@@ -0,0 +1,423 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestHandlerAdmin_SearchWallets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAdminUC := mockUC.NewMockAdminUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	newRequest := func(ctx context.Context, query string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "/api/admin/v1/wallets?"+query, nil)
+		return req.WithContext(ctx)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, "customer_xid="+mockCustomerXid),
+			patch: func() {
+				mockAdminUC.EXPECT().SearchWallets(gomock.Any(), mockAdmin, mockCustomerXid).Return([]*model.Wallet{}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty customer_xid",
+			r:    newRequest(mockCtx, ""),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params customer_xid not uuid",
+			r:    newRequest(mockCtx, "customer_xid=not-a-customer"),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("AdminHandler.SearchWallets() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), "customer_xid="+mockCustomerXid),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, "customer_xid="+mockCustomerXid),
+			patch: func() {
+				mockAdminUC.EXPECT().SearchWallets(gomock.Any(), mockAdmin, mockCustomerXid).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAdminHandler(mockAdminUC)
+			h.SearchWallets(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerAdmin_SetWalletStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAdminUC := mockUC.NewMockAdminUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockID := "81401b03-60e0-4f20-afc6-419b3773e7b3"
+	mockError := errors.New("fake error")
+	mockPayload := model.PayloadAdminStatus{Reason: "Suspected account takeover"}
+	newRequest := func(ctx context.Context, param url.Values) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+	mockParam := url.Values{"reason": {mockPayload.Reason}}
+
+	tests := []struct {
+		name    string
+		disable bool
+		freeze  bool
+		r       *http.Request
+		patch   func()
+	}{
+		{
+			name:   "Success freeze",
+			freeze: true,
+			r:      newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().FreezeWallet(gomock.Any(), mockAdmin, mockID, mockPayload).Return(&model.Wallet{ID: mockID}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name:    "Success disable",
+			disable: true,
+			r:       newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().DisableWallet(gomock.Any(), mockAdmin, mockID, mockPayload).Return(&model.Wallet{ID: mockID}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name:    "Success enable",
+			disable: false,
+			r:       newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().EnableWallet(gomock.Any(), mockAdmin, mockID, mockPayload).Return(&model.Wallet{ID: mockID}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name:    "Failed params empty reason",
+			disable: true,
+			r:       newRequest(mockCtx, url.Values{}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name:    "Failed token",
+			disable: true,
+			r:       newRequest(context.TODO(), mockParam),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name:    "Failed usecase",
+			disable: true,
+			r:       newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().DisableWallet(gomock.Any(), mockAdmin, mockID, mockPayload).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAdminHandler(mockAdminUC)
+			if tt.freeze {
+				h.FreezeWallet(nil, tt.r)
+			} else if tt.disable {
+				h.DisableWallet(nil, tt.r)
+			} else {
+				h.EnableWallet(nil, tt.r)
+			}
+		})
+	}
+}
+
+func TestHandlerAdmin_GetWalletStatusHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAdminUC := mockUC.NewMockAdminUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockID := "81401b03-60e0-4f20-afc6-419b3773e7b3"
+	mockError := errors.New("fake error")
+	newRequest := func(ctx context.Context) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodGet, "", nil)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockAdminUC.EXPECT().GetWalletStatusHistory(gomock.Any(), mockAdmin, mockID).Return([]*model.WalletStatusChange{{WalletID: mockID}}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockAdminUC.EXPECT().GetWalletStatusHistory(gomock.Any(), mockAdmin, mockID).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAdminHandler(mockAdminUC)
+			h.GetWalletStatusHistory(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerAdmin_AdjustBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAdminUC := mockUC.NewMockAdminUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockID := "81401b03-60e0-4f20-afc6-419b3773e7b3"
+	mockError := errors.New("fake error")
+	mockPayload := model.PayloadAdjustment{
+		ReferenceID: "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+		Amount:      decimal.NewFromInt(-2500),
+		Reason:      "Duplicate bank credit",
+	}
+	newRequest := func(ctx context.Context, param url.Values) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+	mockParam := url.Values{"reference_id": {mockPayload.ReferenceID}, "amount": {"-2500"}, "reason": {mockPayload.Reason}}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().AdjustBalance(gomock.Any(), mockAdmin, mockID, gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, payload model.PayloadAdjustment) (*model.Adjustment, error) {
+					if !payload.Amount.Equal(mockPayload.Amount) || payload.Reason != mockPayload.Reason || payload.ReferenceID != mockPayload.ReferenceID {
+						t.Errorf("AdjustBalance() got %v, want %v", payload, mockPayload)
+					}
+					return &model.Adjustment{WalletID: mockID}, nil
+				})
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params reference not uuid",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {"ref-1"}, "amount": {"-2500"}, "reason": {mockPayload.Reason}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("AdminHandler.AdjustBalance() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed params zero amount",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {mockPayload.ReferenceID}, "amount": {"0"}, "reason": {mockPayload.Reason}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty reason",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {mockPayload.ReferenceID}, "amount": {"-2500"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), mockParam),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockAdminUC.EXPECT().AdjustBalance(gomock.Any(), mockAdmin, mockID, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAdminHandler(mockAdminUC)
+			h.AdjustBalance(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerAdmin_GetTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAdminUC := mockUC.NewMockAdminUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	newRequestFor := func(ctx context.Context, customerXid, query string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("customer_xid", customerXid)
+		req, _ := http.NewRequest(http.MethodGet, "/api/admin/v1/customers/"+customerXid+"/transactions?"+query, nil)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	newRequest := func(ctx context.Context, query string) *http.Request {
+		return newRequestFor(ctx, mockCustomerXid, query)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, "type=withdrawal&limit=20"),
+			patch: func() {
+				mockAdminUC.EXPECT().GetTransactions(gomock.Any(), mockAdmin, mockCustomerXid, model.TransactionFilter{Type: model.TransactionTypeWithdrawal, Limit: 20}).Return(&model.TransactionList{}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params customer_xid not uuid",
+			r:    newRequestFor(mockCtx, "not-a-customer", ""),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("AdminHandler.GetTransactions() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed params type",
+			r:    newRequest(mockCtx, "type=refund"),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), ""),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, ""),
+			patch: func() {
+				mockAdminUC.EXPECT().GetTransactions(gomock.Any(), mockAdmin, mockCustomerXid, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAdminHandler(mockAdminUC)
+			h.GetTransactions(nil, tt.r)
+		})
+	}
+}
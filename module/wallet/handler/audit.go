@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+// AuditHandler serves the audit log to auditors, on the admin API.
+type AuditHandler struct {
+	usecase AuditUsecase
+}
+
+func NewAuditHandler(usecase AuditUsecase) *AuditHandler {
+	return &AuditHandler{
+		usecase: usecase,
+	}
+}
+
+// GetEvents lists the audit events, newest first, filtered by the
+// customer_xid, wallet_id, actor_id, action, from and to query params.
+func (h *AuditHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, errMsg := readAuditEventFilter(r)
+	if errMsg != nil {
+		writerWriteJSONAPIError(ctx, w, errMsg)
+		return
+	}
+
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	events, err := h.usecase.GetEvents(ctx, admin, filter)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   events,
+		Status: "success",
+	})
+}
+
+// Verify walks the audit chain and tells whether it is intact.
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	admin, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	verification, err := h.usecase.Verify(ctx, admin)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   verification,
+		Status: "success",
+	})
+}
+
+func readAuditEventFilter(r *http.Request) (model.AuditEventFilter, *commonerr.ErrorMessage) {
+	query := r.URL.Query()
+	filter := model.AuditEventFilter{
+		CustomerXid: query.Get("customer_xid"),
+		WalletID:    query.Get("wallet_id"),
+		ActorID:     query.Get("actor_id"),
+		Action:      query.Get("action"),
+	}
+
+	if from := query.Get("from"); from != "" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params from not valid")
+		}
+		filter.From = &fromTime
+	}
+
+	if to := query.Get("to"); to != "" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params to not valid")
+		}
+		filter.To = &toTime
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt <= 0 {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params limit not valid")
+		}
+		filter.Limit = limitInt
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		seq, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || seq <= 0 {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params cursor not valid")
+		}
+		filter.Before = seq
+	}
+
+	return filter, nil
+}
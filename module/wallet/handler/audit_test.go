@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+)
+
+func TestHandlerAudit_GetEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuditUC := mockUC.NewMockAuditUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	mockFrom := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newRequest := func(ctx context.Context, query string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "/api/admin/v1/audit-events?"+query, nil)
+		return req.WithContext(ctx)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, "customer_xid="+mockCustomerXid+"&action=wallet.disabled&from=2026-10-01T00:00:00Z&limit=20&cursor=42"),
+			patch: func() {
+				mockAuditUC.EXPECT().GetEvents(gomock.Any(), mockAdmin, model.AuditEventFilter{
+					CustomerXid: mockCustomerXid,
+					Action:      "wallet.disabled",
+					From:        &mockFrom,
+					Before:      42,
+					Limit:       20,
+				}).Return(&model.AuditEventList{}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params cursor",
+			r:    newRequest(mockCtx, "cursor=abc"),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params to",
+			r:    newRequest(mockCtx, "to=yesterday"),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), ""),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, ""),
+			patch: func() {
+				mockAuditUC.EXPECT().GetEvents(gomock.Any(), mockAdmin, model.AuditEventFilter{}).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAuditHandler(mockAuditUC)
+			h.GetEvents(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerAudit_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuditUC := mockUC.NewMockAuditUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockAdmin := "ops-alice"
+	mockError := errors.New("fake error")
+	newRequest := func(ctx context.Context) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "/api/admin/v1/audit-events/verify", nil)
+		return req.WithContext(ctx)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockAdmin)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockAuditUC.EXPECT().Verify(gomock.Any(), mockAdmin).Return(&model.AuditVerification{Valid: true}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockAuditUC.EXPECT().Verify(gomock.Any(), mockAdmin).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewAuditHandler(mockAuditUC)
+			h.Verify(nil, tt.r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type ConversionHandler struct {
+	usecase ConversionUsecase
+}
+
+func NewConversionHandler(usecase ConversionUsecase) *ConversionHandler {
+	return &ConversionHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *ConversionHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadConversionQuote
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	quote, err := h.usecase.CreateQuote(ctx, customerXid, payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, BasicResponse{
+		Data:   quote,
+		Status: "success",
+	})
+}
+
+func (h *ConversionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadConversion
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	conversion, err := h.usecase.CreateConversion(ctx, customerXid, payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   conversion,
+		Status: "success",
+	}
+
+	if conversion.Replayed {
+		writerWriteData(ctx, w, response)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, response)
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestHandlerConversion_CreateQuote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockConversionUC := mockUC.NewMockConversionUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)
+	mockCtxEmpty := context.TODO()
+	mockQuote := &model.ConversionQuote{
+		ID:              "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(155000),
+		Rate:            decimal.NewFromInt(15500),
+		ExpiresAt:       time.Now().Add(30 * time.Second),
+	}
+	newRequest := func(ctx context.Context, body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(ctx)
+	}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, `{"from_currency":"USD","to_currency":"IDR","amount":"10"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateQuote(gomock.Any(), mockCustomerXid, model.PayloadConversionQuote{
+					FromCurrency: "USD",
+					ToCurrency:   "IDR",
+					Amount:       decimal.NewFromInt(10),
+				}).Return(mockQuote, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty to currency",
+			r:    newRequest(mockCtx, `{"from_currency":"USD","amount":"10"}`),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("ConversionHandler.CreateQuote() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(mockCtxEmpty, `{"from_currency":"USD","to_currency":"IDR","amount":"10"}`),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, `{"from_currency":"USD","to_currency":"IDR","amount":"10"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateQuote(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewConversionHandler(mockConversionUC)
+			h.CreateQuote(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerConversion_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockConversionUC := mockUC.NewMockConversionUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)
+	mockCtxEmpty := context.TODO()
+	mockConversion := &model.Conversion{
+		ID:              "9c1d2e3f-4a5b-4c6d-8e7f-0a1b2c3d4e5f",
+		ConvertedBy:     mockCustomerXid,
+		QuoteID:         "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+		Status:          1,
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(155000),
+		Rate:            decimal.NewFromInt(15500),
+		ReferenceID:     "50535246-dcb2-4929-8cc9-004ea06f5241",
+	}
+	mockConversionReplayed := *mockConversion
+	mockConversionReplayed.Replayed = true
+	newRequest := func(ctx context.Context, body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(ctx)
+	}
+	wantBadRequest := func(ctx context.Context, w http.ResponseWriter, err error) {
+		if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+			t.Errorf("ConversionHandler.Create() error = %v, want 400", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success with quote",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","quote_id":"3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateConversion(gomock.Any(), mockCustomerXid, model.PayloadConversion{
+					ReferenceID: "50535246-dcb2-4929-8cc9-004ea06f5241",
+					QuoteID:     "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+				}).Return(mockConversion, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Success without quote",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","from_currency":"USD","to_currency":"IDR","amount":"10"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateConversion(gomock.Any(), mockCustomerXid, gomock.Any()).Return(mockConversion, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Success replay",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","quote_id":"3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateConversion(gomock.Any(), mockCustomerXid, gomock.Any()).Return(&mockConversionReplayed, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params without quote nor amount",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","from_currency":"USD","to_currency":"IDR"}`),
+			patch: func() {
+				writerWriteJSONAPIError = wantBadRequest
+			},
+		},
+		{
+			name: "Failed params reference not uuid",
+			r:    newRequest(mockCtx, `{"reference_id":"ref-1","quote_id":"3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d"}`),
+			patch: func() {
+				writerWriteJSONAPIError = wantBadRequest
+			},
+		},
+		{
+			name: "Failed params invalid quote id",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","quote_id":"latest"}`),
+			patch: func() {
+				writerWriteJSONAPIError = wantBadRequest
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(mockCtxEmpty, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","quote_id":"3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d"}`),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, `{"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241","quote_id":"3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d"}`),
+			patch: func() {
+				mockConversionUC.EXPECT().CreateConversion(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewConversionHandler(mockConversionUC)
+			h.Create(nil, tt.r)
+		})
+	}
+}
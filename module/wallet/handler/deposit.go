@@ -6,7 +6,6 @@ import (
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
-	"github.com/shopspring/decimal"
 )
 
 type DepositHandler struct {
@@ -20,25 +19,11 @@ func NewDepositHandler(usecase DepositUsecase) *DepositHandler {
 }
 
 func (h *DepositHandler) Create(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(32 << 20)
 	ctx := r.Context()
 	var payload model.PayloadDeposit
-	payload.ReferenceID = r.Form.Get("reference_id")
-	amount, err := decimal.NewFromString(r.Form.Get("amount"))
+	err := readerDecode(r, &payload)
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params amount not valid"))
-		return
-	}
-
-	payload.Amount = amount
-
-	if payload.ReferenceID == "" {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params reference_id empty"))
-		return
-	}
-
-	if !payload.Amount.IsPositive() {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params amount not valid"))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
@@ -50,7 +35,7 @@ func (h *DepositHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	deposit, err := h.usecase.CreateDeposit(ctx, customerXid, payload)
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Deposit", err.Error()))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
@@ -59,5 +44,10 @@ func (h *DepositHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Status: "success",
 	}
 
+	if deposit.Replayed {
+		writerWriteData(ctx, w, response)
+		return
+	}
+
 	writerWriteDataAccepted(ctx, w, response)
 }
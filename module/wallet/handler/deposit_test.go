@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
 	"github.com/shopspring/decimal"
@@ -35,6 +36,8 @@ func TestHandlerDeposit_Create(t *testing.T) {
 		Amount:      decimal.NewFromInt(1000),
 		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
 	}
+	mockDepositReplayed := *mockDeposit
+	mockDepositReplayed.Replayed = true
 
 	type args struct {
 		w http.ResponseWriter
@@ -65,6 +68,63 @@ func TestHandlerDeposit_Create(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Success json",
+			args: args{
+				r: func() *http.Request {
+					body := `{"reference_id":"ea0212d3-abd6-406f-8c67-868e814a2436","amount":1000}`
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(body))
+					req.Header.Set("Content-Type", "application/json")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockDepositUC.EXPECT().CreateDeposit(gomock.Any(), mockCustomerXid, model.PayloadDeposit{
+					ReferenceID: "ea0212d3-abd6-406f-8c67-868e814a2436",
+					Amount:      decimal.NewFromInt(1000),
+				}).Return(mockDeposit, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed unsupported content type",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader("amount=1000"))
+					req.Header.Set("Content-Type", "text/plain")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Success replay",
+			args: args{
+				r: func() *http.Request {
+					var param = url.Values{}
+					param.Add("reference_id", "ea0212d3-abd6-406f-8c67-868e814a2436")
+					param.Add("amount", "1000")
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockDepositUC.EXPECT().CreateDeposit(gomock.Any(), mockCustomerXid, gomock.Any()).Return(&mockDepositReplayed, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
 		{
 			name: "Failed params empty reference",
 			args: args{
@@ -83,6 +143,28 @@ func TestHandlerDeposit_Create(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Failed params reference not uuid",
+			args: args{
+				r: func() *http.Request {
+					var param = url.Values{}
+					param.Add("reference_id", "ref-1")
+					param.Add("amount", "1000")
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("DepositHandler.Create() error = %v, want 400", err)
+					}
+				}
+			},
+		},
 		{
 			name: "Failed params zero amount",
 			args: args{
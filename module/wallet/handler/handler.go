@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"github.com/herwando/mini-wallet/lib/common/reader"
 	"github.com/herwando/mini-wallet/lib/common/writer"
 )
 
 var (
+	readerDecode            = reader.Decode
 	writerWriteStrOK        = writer.WriteStrOK
 	writerWriteData         = writer.WriteOK
 	writerWriteDataAccepted = writer.WriteAccepted
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+)
+
+type HealthHandler struct {
+	usecase HealthUsecase
+}
+
+func NewHealthHandler(usecase HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		usecase: usecase,
+	}
+}
+
+// Liveness answers as long as the process serves HTTP. It checks nothing else,
+// so a database outage does not get the process restarted.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writerWriteStrOK(r.Context(), w)
+}
+
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := h.usecase.Ready(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewError(http.StatusServiceUnavailable, "Not ready", err.Error()))
+		return
+	}
+
+	writerWriteStrOK(ctx, w)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/writer"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerHealth_Liveness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockHealthUC := mockUC.NewMockHealthUsecase(ctrl)
+	defer ctrl.Finish()
+	writerWriteStrOK = writer.WriteStrOK
+
+	rec := httptest.NewRecorder()
+	h := NewHealthHandler(mockHealthUC)
+	h.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHandlerHealth_Readiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockHealthUC := mockUC.NewMockHealthUsecase(ctrl)
+	defer ctrl.Finish()
+	writerWriteStrOK = writer.WriteStrOK
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+
+	tests := []struct {
+		name       string
+		wantStatus int
+		patch      func()
+	}{
+		{
+			name:       "Success",
+			wantStatus: http.StatusOK,
+			patch: func() {
+				mockHealthUC.EXPECT().Ready(gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:       "Failed usecase",
+			wantStatus: http.StatusServiceUnavailable,
+			patch: func() {
+				mockHealthUC.EXPECT().Ready(gomock.Any()).Return(errors.New("Database unreachable"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			rec := httptest.NewRecorder()
+			h := NewHealthHandler(mockHealthUC)
+			h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(context.Background()))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
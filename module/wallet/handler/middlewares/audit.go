@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+)
+
+// maxAuditIPLength is the width of the audit_events ip column.
+const maxAuditIPLength = 64
+
+// AuditSource tells the audit log the request id and client IP of the changes
+// made on a request. It has to run after the logging middleware, and takes the
+// client IP as the IP rate limit does.
+type AuditSource struct {
+	trustForwardedFor bool
+}
+
+func NewAuditSource(trustForwardedFor bool) *AuditSource {
+	return &AuditSource{
+		trustForwardedFor: trustForwardedFor,
+	}
+}
+
+func (m *AuditSource) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ip := clientIP(r, m.trustForwardedFor)
+		if len(ip) > maxAuditIPLength {
+			ip = ip[:maxAuditIPLength]
+		}
+
+		ctx = audit.WithSource(ctx, GetRequestIDFromContext(ctx), ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditSource_Handler(t *testing.T) {
+	tests := []struct {
+		name              string
+		trustForwardedFor bool
+		forwardedFor      string
+		wantIP            string
+	}{
+		{
+			name:   "Success remote address",
+			wantIP: "192.0.2.1",
+		},
+		{
+			name:         "Success forwarded for not trusted",
+			forwardedFor: "203.0.113.7",
+			wantIP:       "192.0.2.1",
+		},
+		{
+			name:              "Success forwarded for trusted",
+			trustForwardedFor: true,
+			forwardedFor:      "198.51.100.2, 203.0.113.7",
+			wantIP:            "203.0.113.7",
+		},
+		{
+			name:              "Success forwarded for too long",
+			trustForwardedFor: true,
+			forwardedFor:      strings.Repeat("f", 100),
+			wantIP:            strings.Repeat("f", maxAuditIPLength),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID, ip string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID, ip = audit.SourceFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/deposits", nil)
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), CONTEXT_REQUEST_ID, "9f86d081884c7d65"))
+			NewAuditSource(tt.trustForwardedFor).Handler(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, "9f86d081884c7d65", requestID)
+			assert.Equal(t, tt.wantIP, ip)
+		})
+	}
+}
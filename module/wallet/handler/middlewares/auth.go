@@ -6,19 +6,49 @@ import (
 	"net/http"
 	"strings"
 
-	jwt "github.com/golang-jwt/jwt/v4"
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/lib/common/writer"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/token"
 )
 
 var (
-	jwtKey                  = []byte("my_secret_key")
 	writerWriteJSONAPIError = writer.WriteJSONAPIError
 	CONTEXT_AUTH_DETAIL     = "AuthDetail"
+	CONTEXT_AUTH_CLAIMS     = "AuthClaims"
 )
 
+type TokenParser interface {
+	Parse(tokenStr string) (*model.Claims, error)
+}
+
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// Module authenticates the bearer of a token of its role: customers, whose
+// tokens have no role, or operations staff.
 type Module struct {
+	tokens      TokenParser
+	revocations RevocationChecker
+	role        string
+}
+
+func NewModule(tokens TokenParser, revocations RevocationChecker) *Module {
+	return &Module{
+		tokens:      tokens,
+		revocations: revocations,
+	}
+}
+
+// NewAdminModule authenticates operations staff, refusing customer tokens.
+func NewAdminModule(tokens TokenParser, revocations RevocationChecker) *Module {
+	return &Module{
+		tokens:      tokens,
+		revocations: revocations,
+		role:        token.RoleAdmin,
+	}
 }
 
 const authPrefix string = "Token "
@@ -31,6 +61,14 @@ func GetAuthDetailFromContext(ctx context.Context) (string, error) {
 	return v.(string), nil
 }
 
+func GetAuthClaimsFromContext(ctx context.Context) (*model.Claims, error) {
+	v := ctx.Value(CONTEXT_AUTH_CLAIMS)
+	if v == nil {
+		return nil, errors.New("unable to get token from context")
+	}
+	return v.(*model.Claims), nil
+}
+
 func (m *Module) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -40,33 +78,51 @@ func (m *Module) Handler(next http.Handler) http.Handler {
 			writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Header Authorization empty"))
 			return
 		}
-		claims := &model.Claims{}
-
-		tkn, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
 
+		claims, err := m.tokens.Parse(tokenStr)
 		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnauthorizedError("Unauthorized user", "You are not authorized to use this function"))
+			if err == token.ErrMalformedToken {
+				writerWriteJSONAPIError(ctx, w, commonerr.SetDefaultNewBadRequest())
 				return
 			}
-			writerWriteJSONAPIError(ctx, w, commonerr.SetDefaultNewBadRequest())
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnauthorizedError("Unauthorized user", "You are not authorized to use this function"))
 			return
 		}
 
-		if !tkn.Valid {
-			writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnauthorizedError("Unauthorized user", "You are not authorized to use this function"))
+		if claims.Role != m.role {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewError(http.StatusForbidden, "Forbidden", "You are not allowed to use this function"))
+			return
+		}
+
+		revoked, err := m.revocations.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewInternalError())
+			return
+		}
+
+		if revoked {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnauthorizedError("Unauthorized user", "Token revoked"))
 			return
 		}
 
 		ctx = context.WithValue(ctx, CONTEXT_AUTH_DETAIL, claims.CustomerXid)
+		ctx = context.WithValue(ctx, CONTEXT_AUTH_CLAIMS, claims)
+		ctx = audit.WithActor(ctx, m.actorType(), claims.CustomerXid)
+		ctx = withLogCustomer(ctx)
 
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
 }
 
+// actorType is who the bearer of a token of the role is to the audit log.
+func (m *Module) actorType() string {
+	if m.role == token.RoleAdmin {
+		return audit.ActorAdmin
+	}
+	return audit.ActorCustomer
+}
+
 func getBearerToken(r *http.Request, key string) string {
 	var token string
 	if authHeader := r.Header.Get(key); authHeader != "" {
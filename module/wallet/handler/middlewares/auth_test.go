@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/herwando/mini-wallet/lib/common/writer"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/token"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTokenParser struct {
+	claims *model.Claims
+	err    error
+}
+
+func (p *fakeTokenParser) Parse(tokenStr string) (*model.Claims, error) {
+	return p.claims, p.err
+}
+
+type fakeRevocationChecker struct {
+	revoked bool
+	err     error
+}
+
+func (c *fakeRevocationChecker) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return c.revoked, c.err
+}
+
+func TestModule_Handler(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	customerClaims := &model.Claims{CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436"}
+	customerClaims.ID = "4f1c2a9b8e7d6c5b4a3f2e1d0c9b8a7f"
+	adminClaims := &model.Claims{CustomerXid: "ops-alice", Role: token.RoleAdmin}
+	adminClaims.ID = "5a2d3b0c9f8e7d6c5b4a3f2e1d0c9b8a"
+
+	tests := []struct {
+		name        string
+		newModule   func(TokenParser, RevocationChecker) *Module
+		header      string
+		parser      *fakeTokenParser
+		revocations *fakeRevocationChecker
+		wantStatus  int
+		wantSubject string
+		wantActor   string
+	}{
+		{
+			name:        "Success customer",
+			newModule:   NewModule,
+			header:      "Token customer",
+			parser:      &fakeTokenParser{claims: customerClaims},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusOK,
+			wantSubject: customerClaims.CustomerXid,
+			wantActor:   audit.ActorCustomer,
+		},
+		{
+			name:        "Success admin",
+			newModule:   NewAdminModule,
+			header:      "Token admin",
+			parser:      &fakeTokenParser{claims: adminClaims},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusOK,
+			wantSubject: adminClaims.CustomerXid,
+			wantActor:   audit.ActorAdmin,
+		},
+		{
+			name:        "Failed empty header",
+			newModule:   NewModule,
+			parser:      &fakeTokenParser{claims: customerClaims},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Failed invalid token",
+			newModule:   NewModule,
+			header:      "Token invalid",
+			parser:      &fakeTokenParser{err: token.ErrInvalidToken},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed customer token on admin",
+			newModule:   NewAdminModule,
+			header:      "Token customer",
+			parser:      &fakeTokenParser{claims: customerClaims},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "Failed admin token on customer",
+			newModule:   NewModule,
+			header:      "Token admin",
+			parser:      &fakeTokenParser{claims: adminClaims},
+			revocations: &fakeRevocationChecker{},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "Failed revoked",
+			newModule:   NewAdminModule,
+			header:      "Token admin",
+			parser:      &fakeTokenParser{claims: adminClaims},
+			revocations: &fakeRevocationChecker{revoked: true},
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed revocation check",
+			newModule:   NewModule,
+			header:      "Token customer",
+			parser:      &fakeTokenParser{claims: customerClaims},
+			revocations: &fakeRevocationChecker{err: errors.New("database error")},
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject, actor string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject, _ = GetAuthDetailFromContext(r.Context())
+				actor, _ = audit.ActorFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/v1/wallets", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			tt.newModule(tt.parser, tt.revocations).Handler(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantSubject, subject)
+			assert.Equal(t, tt.wantActor, actor)
+		})
+	}
+}
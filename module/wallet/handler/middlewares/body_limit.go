@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+)
+
+// BodyLimit caps the request body at maxBytes. Reading past it fails with an
+// *http.MaxBytesError, which reader.BodyError answers with 413.
+type BodyLimit struct {
+	maxBytes int64
+}
+
+func NewBodyLimit(maxBytes int64) *BodyLimit {
+	return &BodyLimit{
+		maxBytes: maxBytes,
+	}
+}
+
+func (m *BodyLimit) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, m.maxBytes)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/herwando/mini-wallet/lib/common/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit_Handler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Success within limit",
+			body:       strings.Repeat("a", 16),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Failed over limit",
+			body:       strings.Repeat("a", 17),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(reader.BodyError(err).Code)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/deposits", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			NewBodyLimit(16).Handler(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/reader"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyKeyTTL is how long a response is replayed when no
+	// other ttl is configured.
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	// A request still marked in progress after this long is assumed to have
+	// died with its process, and its key may be taken over by a retry.
+	idempotencyLockTimeout = time.Minute
+)
+
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	SaveIdempotencyResponse(ctx context.Context, record *model.IdempotencyRecord) error
+	DeleteIdempotencyKey(ctx context.Context, customerXid, route, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Idempotency replays the first response given to a request on every retry of
+// it for ttl. A request is identified by the customer, its method and path,
+// and its Idempotency-Key header, or its reference_id when no header is sent.
+// Requests with neither pass through untouched.
+type Idempotency struct {
+	store IdempotencyStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func NewIdempotency(store IdempotencyStore, ttl time.Duration) *Idempotency {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+
+	return &Idempotency{
+		store: store,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// PurgeExpired deletes the keys whose response is no longer replayed and
+// returns how many it deleted.
+func (m *Idempotency) PurgeExpired(ctx context.Context) (int64, error) {
+	return m.store.PurgeIdempotencyKeys(ctx, m.now())
+}
+
+func (m *Idempotency) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		customerXid, err := GetAuthDetailFromContext(ctx)
+		if err != nil {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+			return
+		}
+
+		requestHash, referenceID, err := hashRequest(r)
+		if err != nil {
+			writerWriteJSONAPIError(ctx, w, reader.BodyError(err))
+			return
+		}
+
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			key = referenceID
+		}
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := m.now()
+		record := &model.IdempotencyRecord{
+			CustomerXid: customerXid,
+			Route:       r.Method + " " + r.URL.Path,
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(m.ttl),
+		}
+
+		existing, err := m.reserve(ctx, record)
+		if err != nil {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewInternalError())
+			return
+		}
+
+		if existing != nil {
+			if existing.RequestHash != record.RequestHash {
+				writerWriteJSONAPIError(ctx, w, commonerr.SetNewError(http.StatusConflict, IdempotencyKeyHeader, "Key already used with a different request"))
+				return
+			}
+
+			if existing.StatusCode == 0 {
+				writerWriteJSONAPIError(ctx, w, commonerr.SetNewError(http.StatusConflict, IdempotencyKeyHeader, "Request with this key still in progress"))
+				return
+			}
+
+			if existing.ContentType != "" {
+				w.Header().Set("Content-Type", existing.ContentType)
+			}
+			w.Header().Set(IdempotencyReplayedHeader, "true")
+			w.WriteHeader(existing.StatusCode)
+			_, _ = w.Write(existing.ResponseBody)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		defer func() {
+			// A server error or panic leaves nothing worth replaying, so the
+			// key is released for the client to retry.
+			if p := recover(); p != nil {
+				_ = m.store.DeleteIdempotencyKey(ctx, record.CustomerXid, record.Route, record.Key)
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			_ = m.store.DeleteIdempotencyKey(ctx, record.CustomerXid, record.Route, record.Key)
+			return
+		}
+
+		record.StatusCode = recorder.status
+		record.ContentType = w.Header().Get("Content-Type")
+		record.ResponseBody = recorder.body.Bytes()
+		_ = m.store.SaveIdempotencyResponse(ctx, record)
+	})
+}
+
+func (m *Idempotency) reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	existing, err := m.store.ReserveIdempotencyKey(ctx, record)
+	if err != nil || existing == nil {
+		return existing, err
+	}
+
+	if existing.StatusCode == 0 && m.now().Sub(existing.CreatedAt) > idempotencyLockTimeout {
+		err = m.store.DeleteIdempotencyKey(ctx, existing.CustomerXid, existing.Route, existing.Key)
+		if err != nil {
+			return nil, err
+		}
+		return m.store.ReserveIdempotencyKey(ctx, record)
+	}
+
+	return existing, nil
+}
+
+// hashRequest fingerprints the method, path and payload of the request, and
+// returns the reference_id of the payload. Form payloads are hashed by their
+// parsed values, since a multipart body differs between retries by its random
+// boundary. Any other body is hashed as is and put back for the handler to
+// read.
+func hashRequest(r *http.Request) (string, string, error) {
+	var referenceID string
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && err != http.ErrNotMultipart {
+			return "", "", err
+		}
+		_, _ = io.WriteString(hash, r.Form.Encode())
+		referenceID = r.Form.Get("reference_id")
+	default:
+		if r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return "", "", err
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			_, _ = hash.Write(body)
+
+			if mediaType == "application/json" {
+				var payload struct {
+					ReferenceID string `json:"reference_id"`
+				}
+				_ = json.Unmarshal(body, &payload)
+				referenceID = payload.ReferenceID
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), referenceID, nil
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,322 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/writer"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+	err     error
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: map[string]*model.IdempotencyRecord{}}
+}
+
+func (s *fakeIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if existing, ok := s.records[record.CustomerXid+record.Route+record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		copied := *existing
+		return &copied, nil
+	}
+	copied := *record
+	s.records[record.CustomerXid+record.Route+record.Key] = &copied
+	return nil, nil
+}
+
+func (s *fakeIdempotencyStore) SaveIdempotencyResponse(ctx context.Context, record *model.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	s.records[record.CustomerXid+record.Route+record.Key] = &copied
+	return nil
+}
+
+func (s *fakeIdempotencyStore) DeleteIdempotencyKey(ctx context.Context, customerXid, route, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[customerXid+route+key]; ok && record.StatusCode == 0 {
+		delete(s.records, customerXid+route+key)
+	}
+	return nil
+}
+
+func (s *fakeIdempotencyStore) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	var purged int64
+	for id, record := range s.records {
+		if record.ExpiresAt.Before(now) {
+			delete(s.records, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func TestIdempotency_Handler(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+
+	newRequest := func(customerXid, path, key string, form url.Values, jsonBody string) *http.Request {
+		if path == "" {
+			path = "/api/v1/wallet/deposits"
+		}
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if jsonBody != "" {
+			req = httptest.NewRequest(http.MethodPost, path, strings.NewReader(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if customerXid != "" {
+			req = req.WithContext(context.WithValue(req.Context(), CONTEXT_AUTH_DETAIL, customerXid))
+		}
+		return req
+	}
+	form := url.Values{"amount": {"1000"}, "reference_id": {"50535246-dcb2-4929-8cc9-004ea06f5241"}}
+	otherForm := url.Values{"amount": {"2000"}, "reference_id": {"50535246-dcb2-4929-8cc9-004ea06f5241"}}
+	jsonBody := `{"amount":1000,"reference_id":"50535246-dcb2-4929-8cc9-004ea06f5241"}`
+
+	type call struct {
+		customerXid  string
+		path         string
+		key          string
+		form         url.Values
+		jsonBody     string
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+	}
+	tests := []struct {
+		name      string
+		status    int
+		calls     []call
+		wantCalls int
+	}{
+		{
+			name:   "Success replay by header",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusAccepted, wantBody: "response 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "Success replay by reference_id",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, form: form, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, form: form, wantStatus: http.StatusAccepted, wantBody: "response 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "Success replay by json reference_id",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, jsonBody: jsonBody, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, jsonBody: jsonBody, wantStatus: http.StatusAccepted, wantBody: "response 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "Success same key other customer",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11", key: "key-1", form: form, wantStatus: http.StatusAccepted, wantBody: "response 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "Success same reference_id other route",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, form: form, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, path: "/api/v1/wallet/withdrawals", form: form, wantStatus: http.StatusAccepted, wantBody: "response 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "Success without key",
+			status: http.StatusOK,
+			calls: []call{
+				{customerXid: mockCustomerXid, form: url.Values{}, wantStatus: http.StatusOK, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, form: url.Values{}, wantStatus: http.StatusOK, wantBody: "response 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "Success server error is not stored",
+			status: http.StatusInternalServerError,
+			calls: []call{
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusInternalServerError, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusInternalServerError, wantBody: "response 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "Failed key reused with different payload",
+			status: http.StatusAccepted,
+			calls: []call{
+				{customerXid: mockCustomerXid, key: "key-1", form: form, wantStatus: http.StatusAccepted, wantBody: "response 1"},
+				{customerXid: mockCustomerXid, key: "key-1", form: otherForm, wantStatus: http.StatusConflict},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "Failed token",
+			status: http.StatusAccepted,
+			calls: []call{
+				{key: "key-1", form: form, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("response " + strconv.Itoa(calls)))
+			})
+			m := NewIdempotency(newFakeIdempotencyStore(), time.Hour)
+
+			for _, c := range tt.calls {
+				rec := httptest.NewRecorder()
+				m.Handler(next).ServeHTTP(rec, newRequest(c.customerXid, c.path, c.key, c.form, c.jsonBody))
+
+				assert.Equal(t, c.wantStatus, rec.Code)
+				if c.wantBody != "" {
+					assert.Equal(t, c.wantBody, rec.Body.String())
+				}
+				assert.Equal(t, c.wantReplayed, rec.Header().Get(IdempotencyReplayedHeader) == "true")
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	store := newFakeIdempotencyStore()
+	m := NewIdempotency(store, time.Hour)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	req := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		return req.WithContext(context.WithValue(req.Context(), CONTEXT_AUTH_DETAIL, mockCustomerXid))
+	}
+	hash, _, _ := hashRequest(req())
+	store.records[mockCustomerXid+"POST /api/v1/wallet"+"key-1"] = &model.IdempotencyRecord{
+		CustomerXid: mockCustomerXid,
+		Route:       "POST /api/v1/wallet",
+		Key:         "key-1",
+		RequestHash: hash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, req())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, 0, calls)
+
+	// A request left in progress past the lock timeout is taken over.
+	now = now.Add(2 * idempotencyLockTimeout)
+	rec = httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, req())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_StoreError(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	store := newFakeIdempotencyStore()
+	store.err = errors.New("database error")
+	m := NewIdempotency(store, time.Hour)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", nil)
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	req = req.WithContext(context.WithValue(req.Context(), CONTEXT_AUTH_DETAIL, "ea0212d3-abd6-406f-8c67-868e814a2436"))
+	rec := httptest.NewRecorder()
+	m.Handler(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestIdempotency_Expired(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	store := newFakeIdempotencyStore()
+	m := NewIdempotency(store, time.Hour)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	req := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		return req.WithContext(context.WithValue(req.Context(), CONTEXT_AUTH_DETAIL, mockCustomerXid))
+	}
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, req())
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, req())
+	assert.Equal(t, "true", rec.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	// Past its ttl the key is purged, and a request with it runs again.
+	now = now.Add(2 * time.Hour)
+	purged, err := m.PurgeExpired(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	rec = httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, req())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
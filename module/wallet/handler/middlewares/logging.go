@@ -0,0 +1,143 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	// Longer request ids sent by clients are replaced rather than logged.
+	maxRequestIDLength = 128
+)
+
+var CONTEXT_REQUEST_ID = "RequestID"
+
+// requestLog collects what is learned about a request further down the chain,
+// such as the customer once the auth middleware has run, for the access log.
+type requestLog struct {
+	customerXid string
+}
+
+type contextRequestLog struct{}
+
+// Logging gives every request an id, taken from its X-Request-ID header or
+// generated, echoes it in the response, and writes one access log line when
+// the request is done. The handlers below it log through
+// logger.FromContext(ctx), which carries the request id.
+type Logging struct {
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewLogging(logger *slog.Logger) *Logging {
+	return &Logging{
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func GetRequestIDFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(CONTEXT_REQUEST_ID).(string)
+	return v
+}
+
+func (m *Logging) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := m.now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := &requestLog{}
+		log := m.logger.With("request_id", requestID)
+		ctx := context.WithValue(r.Context(), CONTEXT_REQUEST_ID, requestID)
+		ctx = context.WithValue(ctx, contextRequestLog{}, entry)
+		ctx = logger.WithContext(ctx, log)
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		log.LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", m.now().Sub(start)),
+			slog.String("customer_xid", entry.customerXid),
+		)
+	})
+}
+
+// withLogCustomer adds the authenticated customer to the request logger and
+// to the access log of the request.
+func withLogCustomer(ctx context.Context) context.Context {
+	customerXid, err := GetAuthDetailFromContext(ctx)
+	if err != nil {
+		return ctx
+	}
+
+	if entry, ok := ctx.Value(contextRequestLog{}).(*requestLog); ok {
+		entry.customerXid = customerXid
+	}
+
+	return logger.WithContext(ctx, logger.FromContext(ctx).With("customer_xid", customerXid))
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
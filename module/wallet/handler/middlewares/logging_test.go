@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogging_Handler(t *testing.T) {
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+
+	tests := []struct {
+		name          string
+		requestID     string
+		customerXid   string
+		status        int
+		wantRequestID string
+		wantLevel     string
+	}{
+		{
+			name:          "Success propagate request id",
+			requestID:     "0b6b2c8e-8f0d-4a4e-9d55-3f8c2c9a1e77",
+			customerXid:   mockCustomerXid,
+			status:        http.StatusAccepted,
+			wantRequestID: "0b6b2c8e-8f0d-4a4e-9d55-3f8c2c9a1e77",
+			wantLevel:     "INFO",
+		},
+		{
+			name:      "Success generate request id",
+			status:    http.StatusOK,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "Success replace invalid request id",
+			requestID: "bad id\n",
+			status:    http.StatusOK,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "Success replace long request id",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+			status:    http.StatusOK,
+			wantLevel: "INFO",
+		},
+		{
+			name:        "Success server error",
+			customerXid: mockCustomerXid,
+			status:      http.StatusInternalServerError,
+			wantLevel:   "ERROR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			m := NewLogging(logger.New(&buf, slog.LevelInfo, logger.FormatJSON))
+			now := time.Now()
+			m.now = func() time.Time {
+				now = now.Add(time.Millisecond)
+				return now
+			}
+
+			var gotRequestID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := r.Context()
+				gotRequestID = GetRequestIDFromContext(ctx)
+				if tt.customerXid != "" {
+					ctx = withLogCustomer(context.WithValue(ctx, CONTEXT_AUTH_DETAIL, tt.customerXid))
+				}
+				logger.FromContext(ctx).Info("inside")
+				w.WriteHeader(tt.status)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/deposits", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			m.Handler(next).ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(RequestIDHeader)
+			assert.Equal(t, gotRequestID, requestID)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Len(t, lines, 2)
+
+			var inside, access map[string]interface{}
+			assert.Nil(t, json.Unmarshal([]byte(lines[0]), &inside))
+			assert.Nil(t, json.Unmarshal([]byte(lines[1]), &access))
+
+			assert.Equal(t, requestID, inside["request_id"])
+			assert.Equal(t, requestID, access["request_id"])
+			assert.Equal(t, tt.wantLevel, access["level"])
+			assert.Equal(t, http.MethodPost, access["method"])
+			assert.Equal(t, "/api/v1/wallet/deposits", access["path"])
+			assert.Equal(t, float64(tt.status), access["status"])
+			assert.Equal(t, float64(time.Millisecond), access["latency"])
+			assert.Equal(t, tt.customerXid, access["customer_xid"])
+			if tt.customerXid != "" {
+				assert.Equal(t, tt.customerXid, inside["customer_xid"])
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+)
+
+// Metrics counts and times every request by its chi route pattern, e.g.
+// /api/v1/wallet/deposits, rather than its raw path. It must be mounted on
+// the root router, since the pattern is only complete once routing is done.
+type Metrics struct {
+	now func() time.Time
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		now: time.Now,
+	}
+}
+
+func (m *Metrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := m.now()
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		var route string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			// A "/" route mounted under a sub router joins as "/api/v1/wallet//".
+			route = path.Clean(rctx.RoutePattern())
+		}
+
+		metrics.ObserveRequest(r.Method, route, status, m.now().Sub(start))
+	})
+}
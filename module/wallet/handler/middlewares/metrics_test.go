@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Handler(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+	registry := metrics.NewRegistry(db, "wallets")
+
+	router := chi.NewRouter()
+	router.Use(NewMetrics().Handler)
+	router.Route("/api/v1", func(v1 chi.Router) {
+		v1.Route("/wallet", func(wallet chi.Router) {
+			wallet.Post("/deposits", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})
+			wallet.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+		})
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/api/v1/wallet/deposits", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/wallet/deposits", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/wallet/", nil),
+		httptest.NewRequest(http.MethodGet, "/wp-login.php", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `mini_wallet_http_requests_total{method="POST",route="/api/v1/wallet/deposits",status="202"} 2`)
+	assert.Contains(t, body, `mini_wallet_http_requests_total{method="GET",route="/api/v1/wallet",status="200"} 1`)
+	assert.Contains(t, body, `mini_wallet_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `mini_wallet_http_request_duration_seconds_count{method="POST",route="/api/v1/wallet/deposits"} 2`)
+	assert.NotContains(t, body, "wp-login")
+}
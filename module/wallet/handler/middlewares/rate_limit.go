@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/ratelimit"
+)
+
+const RetryAfterHeader = "Retry-After"
+
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate ratelimit.Rate) (bool, time.Duration, error)
+}
+
+// RateLimit refuses requests with 429 once their key has spent its token
+// bucket, telling the client in Retry-After when to come back. A store that
+// fails lets the request through rather than take the service down with it.
+type RateLimit struct {
+	store RateLimitStore
+	rate  ratelimit.Rate
+	key   func(r *http.Request) (string, error)
+}
+
+// NewIPRateLimit limits the requests of every client IP, for the routes
+// called before the client has a token. The IP is the last X-Forwarded-For
+// entry when trustForwardedFor is set, which is only safe behind a proxy that
+// appends it.
+func NewIPRateLimit(store RateLimitStore, rate ratelimit.Rate, trustForwardedFor bool) *RateLimit {
+	return &RateLimit{
+		store: store,
+		rate:  rate,
+		key: func(r *http.Request) (string, error) {
+			return "ip:" + clientIP(r, trustForwardedFor), nil
+		},
+	}
+}
+
+// NewCustomerRateLimit limits the requests of every customer. It has to run
+// after the auth middleware.
+func NewCustomerRateLimit(store RateLimitStore, rate ratelimit.Rate) *RateLimit {
+	return &RateLimit{
+		store: store,
+		rate:  rate,
+		key: func(r *http.Request) (string, error) {
+			customerXid, err := GetAuthDetailFromContext(r.Context())
+			if err != nil {
+				return "", err
+			}
+			return "customer:" + customerXid, nil
+		},
+	}
+}
+
+func (m *RateLimit) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !m.rate.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := m.key(r)
+		if err != nil {
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+			return
+		}
+
+		ok, retryAfter, err := m.store.Take(ctx, key, m.rate)
+		if err != nil {
+			logger.FromContext(ctx).Error("rate limit store failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !ok {
+			w.Header().Set(RetryAfterHeader, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writerWriteJSONAPIError(ctx, w, commonerr.SetNewError(http.StatusTooManyRequests, "rate_limited", "Too many requests"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		forwarded := r.Header.Get("X-Forwarded-For")
+		if forwarded != "" {
+			entries := strings.Split(forwarded, ",")
+			return strings.TrimSpace(entries[len(entries)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/writer"
+	"github.com/herwando/mini-wallet/module/wallet/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRateLimitStore struct {
+	keys       []string
+	ok         bool
+	retryAfter time.Duration
+	err        error
+}
+
+func (s *fakeRateLimitStore) Take(ctx context.Context, key string, rate ratelimit.Rate) (bool, time.Duration, error) {
+	s.keys = append(s.keys, key)
+	return s.ok, s.retryAfter, s.err
+}
+
+func TestRateLimit_Handler(t *testing.T) {
+	writerWriteJSONAPIError = writer.WriteJSONAPIError
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	rate := ratelimit.PerMinute(60, 10)
+
+	tests := []struct {
+		name           string
+		middleware     func(store RateLimitStore) *RateLimit
+		store          *fakeRateLimitStore
+		customerXid    string
+		forwardedFor   string
+		wantStatus     int
+		wantKey        string
+		wantRetryAfter string
+	}{
+		{
+			name:       "Success by ip",
+			middleware: func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, rate, false) },
+			store:      &fakeRateLimitStore{ok: true},
+			wantStatus: http.StatusOK,
+			wantKey:    "ip:192.0.2.1",
+		},
+		{
+			name:         "Success by ip ignores untrusted forwarded for",
+			middleware:   func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, rate, false) },
+			store:        &fakeRateLimitStore{ok: true},
+			forwardedFor: "203.0.113.9",
+			wantStatus:   http.StatusOK,
+			wantKey:      "ip:192.0.2.1",
+		},
+		{
+			name:         "Success by trusted forwarded for",
+			middleware:   func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, rate, true) },
+			store:        &fakeRateLimitStore{ok: true},
+			forwardedFor: "203.0.113.9, 198.51.100.7",
+			wantStatus:   http.StatusOK,
+			wantKey:      "ip:198.51.100.7",
+		},
+		{
+			name:        "Success by customer",
+			middleware:  func(store RateLimitStore) *RateLimit { return NewCustomerRateLimit(store, rate) },
+			store:       &fakeRateLimitStore{ok: true},
+			customerXid: mockCustomerXid,
+			wantStatus:  http.StatusOK,
+			wantKey:     "customer:" + mockCustomerXid,
+		},
+		{
+			name:       "Success disabled",
+			middleware: func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, ratelimit.PerMinute(0, 10), false) },
+			store:      &fakeRateLimitStore{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Success store error",
+			middleware: func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, rate, false) },
+			store:      &fakeRateLimitStore{err: errors.New("store error")},
+			wantStatus: http.StatusOK,
+			wantKey:    "ip:192.0.2.1",
+		},
+		{
+			name:           "Failed rate limited",
+			middleware:     func(store RateLimitStore) *RateLimit { return NewIPRateLimit(store, rate, false) },
+			store:          &fakeRateLimitStore{ok: false, retryAfter: 1500 * time.Millisecond},
+			wantStatus:     http.StatusTooManyRequests,
+			wantKey:        "ip:192.0.2.1",
+			wantRetryAfter: "2",
+		},
+		{
+			name:       "Failed token",
+			middleware: func(store RateLimitStore) *RateLimit { return NewCustomerRateLimit(store, rate) },
+			store:      &fakeRateLimitStore{ok: true},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/init", nil)
+			req.RemoteAddr = "192.0.2.1:52100"
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if tt.customerXid != "" {
+				req = req.WithContext(context.WithValue(req.Context(), CONTEXT_AUTH_DETAIL, tt.customerXid))
+			}
+			rec := httptest.NewRecorder()
+			tt.middleware(tt.store).Handler(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantRetryAfter, rec.Header().Get(RetryAfterHeader))
+			if tt.wantKey != "" {
+				assert.Equal(t, []string{tt.wantKey}, tt.store.keys)
+			} else {
+				assert.Empty(t, tt.store.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type RefundHandler struct {
+	usecase RefundUsecase
+}
+
+func NewRefundHandler(usecase RefundUsecase) *RefundHandler {
+	return &RefundHandler{
+		usecase: usecase,
+	}
+}
+
+// Refund gives part or all of the captured withdrawal {id} back to the wallet
+// of the customer {customer_xid}. It is served on the admin API.
+func (h *RefundHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadRefund
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	_, err = middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	refund, err := h.usecase.RefundWithdrawal(ctx, chi.URLParam(r, "customer_xid"), chi.URLParam(r, "id"), payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   refund,
+		Status: "success",
+	}
+
+	if refund.Replayed {
+		writerWriteData(ctx, w, response)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, response)
+}
+
+// Reverse takes the deposit {id} back off the wallet of the customer
+// {customer_xid}. It is served on the admin API.
+func (h *RefundHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadReversal
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	_, err = middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	reversal, err := h.usecase.ReverseDeposit(ctx, chi.URLParam(r, "customer_xid"), chi.URLParam(r, "id"), payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   reversal,
+		Status: "success",
+	}
+
+	if reversal.Replayed {
+		writerWriteData(ctx, w, response)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, response)
+}
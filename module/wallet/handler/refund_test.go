@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestHandlerRefund_Refund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRefundUC := mockUC.NewMockRefundUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockID := "5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d"
+	mockError := errors.New("fake error")
+	mockRefund := &model.Deposit{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		DepositedBy: mockCustomerXid,
+		Status:      1,
+		DepositedAt: time.Now(),
+		Amount:      decimal.NewFromInt(500),
+		ReferenceID: "9b7e2d4c-1a3f-4e5b-8c6d-7f0a1b2c3d44",
+		RefundOf:    mockID,
+	}
+	mockRefundReplayed := *mockRefund
+	mockRefundReplayed.Replayed = true
+	newRequest := func(ctx context.Context, param url.Values) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("customer_xid", mockCustomerXid)
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", "ops-alice")
+	mockParam := url.Values{"reference_id": {mockRefund.ReferenceID}, "amount": {"500"}}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().RefundWithdrawal(gomock.Any(), mockCustomerXid, mockID, gomock.Any()).Return(mockRefund, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Success without amount",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {mockRefund.ReferenceID}}),
+			patch: func() {
+				mockRefundUC.EXPECT().RefundWithdrawal(gomock.Any(), mockCustomerXid, mockID, model.PayloadRefund{ReferenceID: mockRefund.ReferenceID}).Return(mockRefund, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Success replay",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().RefundWithdrawal(gomock.Any(), mockCustomerXid, mockID, gomock.Any()).Return(&mockRefundReplayed, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty reference",
+			r:    newRequest(mockCtx, url.Values{"amount": {"500"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params reference not uuid",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {"ref-1"}, "amount": {"500"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("RefundHandler.Refund() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed params negative amount",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {mockRefund.ReferenceID}, "amount": {"-1"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), mockParam),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().RefundWithdrawal(gomock.Any(), mockCustomerXid, mockID, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewRefundHandler(mockRefundUC)
+			h.Refund(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerRefund_Reverse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRefundUC := mockUC.NewMockRefundUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockID := "2c9a8f1e-6b4d-4a3e-9c1f-0d7e5b6a4f22"
+	mockError := errors.New("fake error")
+	mockReversal := &model.Withdrawal{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		WithdrawnBy: mockCustomerXid,
+		Status:      1,
+		WithdrawnAt: time.Now(),
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "9b7e2d4c-1a3f-4e5b-8c6d-7f0a1b2c3d44",
+		ReversalOf:  mockID,
+	}
+	mockReversalReplayed := *mockReversal
+	mockReversalReplayed.Replayed = true
+	newRequest := func(ctx context.Context, param url.Values) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("customer_xid", mockCustomerXid)
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", "ops-alice")
+	mockParam := url.Values{"reference_id": {mockReversal.ReferenceID}}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().ReverseDeposit(gomock.Any(), mockCustomerXid, mockID, model.PayloadReversal{ReferenceID: mockReversal.ReferenceID}).Return(mockReversal, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Success replay",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().ReverseDeposit(gomock.Any(), mockCustomerXid, mockID, gomock.Any()).Return(&mockReversalReplayed, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty reference",
+			r:    newRequest(mockCtx, url.Values{}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params reference not uuid",
+			r:    newRequest(mockCtx, url.Values{"reference_id": {"ref-1"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("RefundHandler.Reverse() error = %v, want 400", err)
+					}
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), mockParam),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockRefundUC.EXPECT().ReverseDeposit(gomock.Any(), mockCustomerXid, mockID, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewRefundHandler(mockRefundUC)
+			h.Reverse(nil, tt.r)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type TokenHandler struct {
+	usecase TokenUsecase
+}
+
+func NewTokenHandler(usecase TokenUsecase) *TokenHandler {
+	return &TokenHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *TokenHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, err := middlewares.GetAuthClaimsFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	tokenString, err := h.usecase.Refresh(ctx, claims)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data: model.AccountResponse{
+			Token: tokenString,
+		},
+		Status: "success",
+	}
+
+	writerWriteData(ctx, w, response)
+}
+
+func (h *TokenHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, err := middlewares.GetAuthClaimsFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	err = h.usecase.Logout(ctx, claims)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Status: "success",
+	}
+
+	writerWriteData(ctx, w, response)
+}
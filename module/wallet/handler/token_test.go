@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+)
+
+func TestHandlerToken_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTokenUC := mockUC.NewMockTokenUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockError := errors.New("fake error")
+	mockToken := "exampletoken"
+	mockClaims := &model.Claims{
+		CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436",
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthClaims", mockClaims)
+	mockCtxEmpty := context.TODO()
+
+	type args struct {
+		w http.ResponseWriter
+		r *http.Request
+	}
+	tests := []struct {
+		name  string
+		args  args
+		patch func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockTokenUC.EXPECT().Refresh(gomock.Any(), mockClaims).Return(mockToken, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtxEmpty)
+					return req
+				}(),
+			},
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockTokenUC.EXPECT().Refresh(gomock.Any(), mockClaims).Return("", mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewTokenHandler(mockTokenUC)
+			h.Refresh(tt.args.w, tt.args.r)
+		})
+	}
+}
+
+func TestHandlerToken_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTokenUC := mockUC.NewMockTokenUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockError := errors.New("fake error")
+	mockClaims := &model.Claims{
+		CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436",
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthClaims", mockClaims)
+	mockCtxEmpty := context.TODO()
+
+	type args struct {
+		w http.ResponseWriter
+		r *http.Request
+	}
+	tests := []struct {
+		name  string
+		args  args
+		patch func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockTokenUC.EXPECT().Logout(gomock.Any(), mockClaims).Return(nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtxEmpty)
+					return req
+				}(),
+			},
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			args: args{
+				r: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "", nil)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockTokenUC.EXPECT().Logout(gomock.Any(), mockClaims).Return(mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewTokenHandler(mockTokenUC)
+			h.Logout(tt.args.w, tt.args.r)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/currency"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type TransactionHandler struct {
+	usecase TransactionUsecase
+}
+
+func NewTransactionHandler(usecase TransactionUsecase) *TransactionHandler {
+	return &TransactionHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, errMsg := readTransactionFilter(r)
+	if errMsg != nil {
+		writerWriteJSONAPIError(ctx, w, errMsg)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	transactions, err := h.usecase.GetTransactions(ctx, customerXid, filter)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   transactions,
+		Status: "success",
+	}
+
+	writerWriteData(ctx, w, response)
+}
+
+// readTransactionFilter reads the type, currency, from, to, limit and cursor
+// query params of a transaction history request.
+func readTransactionFilter(r *http.Request) (model.TransactionFilter, *commonerr.ErrorMessage) {
+	query := r.URL.Query()
+	var filter model.TransactionFilter
+
+	filter.Type = query.Get("type")
+	if filter.Type != "" && !model.IsValidTransactionType(filter.Type) {
+		return filter, commonerr.SetNewBadRequest("Request invalid", "Params type not valid")
+	}
+
+	filter.Currency = query.Get("currency")
+	if filter.Currency != "" && !currency.IsValid(filter.Currency) {
+		return filter, commonerr.SetNewBadRequest("Request invalid", "Params currency not valid")
+	}
+
+	if from := query.Get("from"); from != "" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params from not valid")
+		}
+		filter.From = &fromTime
+	}
+
+	if to := query.Get("to"); to != "" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params to not valid")
+		}
+		filter.To = &toTime
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt <= 0 {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params limit not valid")
+		}
+		filter.Limit = limitInt
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		transactionCursor, err := model.DecodeTransactionCursor(cursor)
+		if err != nil {
+			return filter, commonerr.SetNewBadRequest("Request invalid", "Params cursor not valid")
+		}
+		filter.Cursor = transactionCursor
+	}
+
+	return filter, nil
+}
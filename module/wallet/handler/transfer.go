@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type TransferHandler struct {
+	usecase TransferUsecase
+}
+
+func NewTransferHandler(usecase TransferUsecase) *TransferHandler {
+	return &TransferHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *TransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadTransfer
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	transfer, err := h.usecase.CreateTransfer(ctx, customerXid, payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   transfer,
+		Status: "success",
+	}
+
+	if transfer.Replayed {
+		writerWriteData(ctx, w, response)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, response)
+}
@@ -15,11 +15,62 @@ type DepositUsecase interface {
 }
 
 type WalletUsecase interface {
-	Enabled(ctx context.Context, customerXid string) (*model.Wallet, error)
-	Disable(ctx context.Context, customerXid string) (*model.Wallet, error)
-	GetWallet(ctx context.Context, customerXid string) (*model.Wallet, error)
+	Enabled(ctx context.Context, customerXid, currency string) (*model.Wallet, error)
+	Disable(ctx context.Context, customerXid, currency string) (*model.Wallet, error)
+	GetWallet(ctx context.Context, customerXid, currency string) (*model.Wallet, error)
+	Close(ctx context.Context, customerXid string, payload model.PayloadClose) (*model.WalletClosure, error)
 }
 
 type WithdrawalUsecase interface {
 	CreateWithdrawal(ctx context.Context, customerXid string, payload model.PayloadWithdrawal) (*model.Withdrawal, error)
+	CaptureWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error)
+	VoidWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error)
+}
+
+type TransactionUsecase interface {
+	GetTransactions(ctx context.Context, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error)
+}
+
+type TransferUsecase interface {
+	CreateTransfer(ctx context.Context, customerXid string, payload model.PayloadTransfer) (*model.Transfer, error)
+}
+
+type ConversionUsecase interface {
+	CreateQuote(ctx context.Context, customerXid string, payload model.PayloadConversionQuote) (*model.ConversionQuote, error)
+	CreateConversion(ctx context.Context, customerXid string, payload model.PayloadConversion) (*model.Conversion, error)
+}
+
+type RefundUsecase interface {
+	RefundWithdrawal(ctx context.Context, customerXid, id string, payload model.PayloadRefund) (*model.Deposit, error)
+	ReverseDeposit(ctx context.Context, customerXid, id string, payload model.PayloadReversal) (*model.Withdrawal, error)
+}
+
+type WebhookUsecase interface {
+	CreateEndpoint(ctx context.Context, customerXid string, payload model.PayloadWebhookEndpoint) (*model.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error)
+}
+
+type AdminUsecase interface {
+	SearchWallets(ctx context.Context, admin, customerXid string) ([]*model.Wallet, error)
+	EnableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error)
+	DisableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error)
+	FreezeWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error)
+	GetWalletStatusHistory(ctx context.Context, admin, id string) ([]*model.WalletStatusChange, error)
+	AdjustBalance(ctx context.Context, admin, id string, payload model.PayloadAdjustment) (*model.Adjustment, error)
+	GetTransactions(ctx context.Context, admin, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error)
+}
+
+type AuditUsecase interface {
+	GetEvents(ctx context.Context, admin string, filter model.AuditEventFilter) (*model.AuditEventList, error)
+	Verify(ctx context.Context, admin string) (*model.AuditVerification, error)
+}
+
+type TokenUsecase interface {
+	Refresh(ctx context.Context, claims *model.Claims) (string, error)
+	Logout(ctx context.Context, claims *model.Claims) error
+}
+
+type HealthUsecase interface {
+	Ready(ctx context.Context) error
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/currency"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
 )
@@ -20,15 +21,22 @@ func NewWalletHandler(usecase WalletUsecase) *WalletHandler {
 
 func (h *WalletHandler) Enabled(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	var payload model.PayloadEnable
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
 	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
 	if err != nil {
 		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
 		return
 	}
 
-	wallet, err := h.usecase.Enabled(ctx, customerXid)
+	wallet, err := h.usecase.Enabled(ctx, customerXid, payload.Currency)
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Wallet", err.Error()))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
@@ -41,16 +49,42 @@ func (h *WalletHandler) Enabled(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WalletHandler) Disable(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(32 << 20)
 	ctx := r.Context()
 	var payload model.PayloadDisable
-	isDisable := r.Form.Get("is_disabled")
-	if isDisable == "true" {
-		payload.IsDisabled = true
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	wallet, err := h.usecase.Disable(ctx, customerXid, payload.Currency)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	response := BasicResponse{
+		Data:   wallet,
+		Status: "success",
 	}
 
-	if !payload.IsDisabled {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params is_disabled empty"))
+	writerWriteData(ctx, w, response)
+}
+
+// Close closes the wallet of the customer, paying its balance out to the
+// destination of the payload.
+func (h *WalletHandler) Close(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadClose
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
@@ -60,14 +94,14 @@ func (h *WalletHandler) Disable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wallet, err := h.usecase.Disable(ctx, customerXid)
+	closure, err := h.usecase.Close(ctx, customerXid, payload)
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Wallet", err.Error()))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
 	response := BasicResponse{
-		Data:   wallet,
+		Data:   closure,
 		Status: "success",
 	}
 
@@ -76,19 +110,21 @@ func (h *WalletHandler) Disable(w http.ResponseWriter, r *http.Request) {
 
 func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	currencyCode := r.URL.Query().Get("currency")
+	if currencyCode != "" && !currency.IsValid(currencyCode) {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params currency not valid"))
+		return
+	}
+
 	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
 	if err != nil {
 		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
 		return
 	}
 
-	wallet, err := h.usecase.GetWallet(ctx, customerXid)
+	wallet, err := h.usecase.GetWallet(ctx, customerXid, currencyCode)
 	if err != nil {
-		if err.Error() == "Disabled" {
-			writerWriteJSONAPIError(ctx, w, commonerr.SetNewNotFound(err.Error()))
-			return
-		}
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Wallet", err.Error()))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
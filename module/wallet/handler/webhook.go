@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
+)
+
+type WebhookHandler struct {
+	usecase WebhookUsecase
+}
+
+func NewWebhookHandler(usecase WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		usecase: usecase,
+	}
+}
+
+// Create registers an endpoint for the events of the customer.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var payload model.PayloadWebhookEndpoint
+	err := readerDecode(r, &payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	endpoint, err := h.usecase.CreateEndpoint(ctx, customerXid, payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteDataAccepted(ctx, w, BasicResponse{
+		Data:   endpoint,
+		Status: "success",
+	})
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	endpoints, err := h.usecase.GetEndpoints(ctx, customerXid)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   endpoints,
+		Status: "success",
+	})
+}
+
+// Delete deletes the endpoint {id}.
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
+
+	endpoint, err := h.usecase.DeleteEndpoint(ctx, customerXid, chi.URLParam(r, "id"))
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   endpoint,
+		Status: "success",
+	})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
+)
+
+func TestHandlerWebhook_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookUC := mockUC.NewMockWebhookUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	mockEndpoint := &model.WebhookEndpoint{
+		ID:        "3f1c2b4a-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+		OwnedBy:   mockCustomerXid,
+		URL:       "https://merchant.example.com/hooks",
+		Secret:    "0123456789abcdef",
+		CreatedAt: time.Now(),
+	}
+	newRequest := func(ctx context.Context, param url.Values) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(ctx)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)
+	mockParam := url.Values{"url": {mockEndpoint.URL}, "secret": {mockEndpoint.Secret}}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockWebhookUC.EXPECT().CreateEndpoint(gomock.Any(), mockCustomerXid, model.PayloadWebhookEndpoint{URL: mockEndpoint.URL, Secret: mockEndpoint.Secret}).Return(mockEndpoint, nil)
+				writerWriteDataAccepted = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed params empty url",
+			r:    newRequest(mockCtx, url.Values{}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed params short secret",
+			r:    newRequest(mockCtx, url.Values{"url": {mockEndpoint.URL}, "secret": {"short"}}),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO(), mockParam),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx, mockParam),
+			patch: func() {
+				mockWebhookUC.EXPECT().CreateEndpoint(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewWebhookHandler(mockWebhookUC)
+			h.Create(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerWebhook_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookUC := mockUC.NewMockWebhookUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	newRequest := func(ctx context.Context) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "", nil)
+		return req.WithContext(ctx)
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockWebhookUC.EXPECT().GetEndpoints(gomock.Any(), mockCustomerXid).Return([]*model.WebhookEndpoint{}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockWebhookUC.EXPECT().GetEndpoints(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewWebhookHandler(mockWebhookUC)
+			h.List(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerWebhook_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookUC := mockUC.NewMockWebhookUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockID := "3f1c2b4a-5d6e-4f70-8a9b-0c1d2e3f4a5b"
+	mockError := errors.New("fake error")
+	newRequest := func(ctx context.Context) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodDelete, "", nil)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	mockCtx := context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockWebhookUC.EXPECT().DeleteEndpoint(gomock.Any(), mockCustomerXid, mockID).Return(&model.WebhookEndpoint{ID: mockID}, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(mockCtx),
+			patch: func() {
+				mockWebhookUC.EXPECT().DeleteEndpoint(gomock.Any(), mockCustomerXid, mockID).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewWebhookHandler(mockWebhookUC)
+			h.Delete(nil, tt.r)
+		})
+	}
+}
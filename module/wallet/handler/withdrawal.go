@@ -3,10 +3,10 @@ package handler
 import (
 	"net/http"
 
+	"github.com/go-chi/chi"
 	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/handler/middlewares"
-	"github.com/shopspring/decimal"
 )
 
 type WithdrawalHandler struct {
@@ -20,44 +20,77 @@ func NewWithdrawalHandler(usecase WithdrawalUsecase) *WithdrawalHandler {
 }
 
 func (h *WithdrawalHandler) Create(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(32 << 20)
 	ctx := r.Context()
 	var payload model.PayloadWithdrawal
-	payload.ReferenceID = r.Form.Get("reference_id")
-	amount, err := decimal.NewFromString(r.Form.Get("amount"))
+	err := readerDecode(r, &payload)
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params amount not valid"))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
-	payload.Amount = amount
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
+	}
 
-	if payload.ReferenceID == "" {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params reference_id empty"))
+	withdrawal, err := h.usecase.CreateWithdrawal(ctx, customerXid, payload)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
-	if !payload.Amount.IsPositive() {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewBadRequest("Request invalid", "Params amount not valid"))
+	response := BasicResponse{
+		Data:   withdrawal,
+		Status: "success",
+	}
+
+	if withdrawal.Replayed {
+		writerWriteData(ctx, w, response)
 		return
 	}
 
+	writerWriteDataAccepted(ctx, w, response)
+}
+
+// Capture takes the amount held by the pending withdrawal {id} off the wallet.
+func (h *WithdrawalHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
 	if err != nil {
 		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
 		return
 	}
 
-	withdrawal, err := h.usecase.CreateWithdrawal(ctx, customerXid, payload)
+	withdrawal, err := h.usecase.CaptureWithdrawal(ctx, customerXid, chi.URLParam(r, "id"))
 	if err != nil {
-		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Withdrawal", err.Error()))
+		writerWriteJSONAPIError(ctx, w, err)
 		return
 	}
 
-	response := BasicResponse{
+	writerWriteData(ctx, w, BasicResponse{
 		Data:   withdrawal,
 		Status: "success",
+	})
+}
+
+// Void releases the amount held by the pending withdrawal {id}.
+func (h *WithdrawalHandler) Void(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerXid, err := middlewares.GetAuthDetailFromContext(ctx)
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, commonerr.SetNewUnprocessableEntity("Token", err.Error()))
+		return
 	}
 
-	writerWriteDataAccepted(ctx, w, response)
+	withdrawal, err := h.usecase.VoidWithdrawal(ctx, customerXid, chi.URLParam(r, "id"))
+	if err != nil {
+		writerWriteJSONAPIError(ctx, w, err)
+		return
+	}
+
+	writerWriteData(ctx, w, BasicResponse{
+		Data:   withdrawal,
+		Status: "success",
+	})
 }
@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	mockUC "github.com/herwando/mini-wallet/module/wallet/handler/_mocks"
 	"github.com/shopspring/decimal"
@@ -35,6 +37,8 @@ func TestHandlerWithdrawal_Create(t *testing.T) {
 		Amount:      decimal.NewFromInt(1000),
 		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
 	}
+	mockWithdrawalReplayed := *mockWithdrawal
+	mockWithdrawalReplayed.Replayed = true
 
 	type args struct {
 		w http.ResponseWriter
@@ -65,6 +69,26 @@ func TestHandlerWithdrawal_Create(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Success replay",
+			args: args{
+				r: func() *http.Request {
+					var param = url.Values{}
+					param.Add("reference_id", "ea0212d3-abd6-406f-8c67-868e814a2436")
+					param.Add("amount", "1000")
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				mockWithdrawalUC.EXPECT().CreateWithdrawal(gomock.Any(), mockCustomerXid, gomock.Any()).Return(&mockWithdrawalReplayed, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
 		{
 			name: "Failed params empty reference",
 			args: args{
@@ -83,6 +107,28 @@ func TestHandlerWithdrawal_Create(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Failed params reference not uuid",
+			args: args{
+				r: func() *http.Request {
+					var param = url.Values{}
+					param.Add("reference_id", "ref-1")
+					param.Add("amount", "1000")
+					req, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(param.Encode()))
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					req.Header.Set("Authorization", mockToken)
+					req = req.WithContext(mockCtx)
+					return req
+				}(),
+			},
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+					if errorMessage, ok := err.(*commonerr.ErrorMessage); !ok || errorMessage.Code != http.StatusBadRequest {
+						t.Errorf("WithdrawalHandler.Create() error = %v, want 400", err)
+					}
+				}
+			},
+		},
 		{
 			name: "Failed params zero amount",
 			args: args{
@@ -150,3 +196,131 @@ func TestHandlerWithdrawal_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerWithdrawal_Capture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWithdrawalUC := mockUC.NewMockWithdrawalUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockID := "5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d"
+	mockError := errors.New("fake error")
+	mockWithdrawal := &model.Withdrawal{
+		ID:          mockID,
+		WithdrawnBy: mockCustomerXid,
+		Status:      1,
+		WithdrawnAt: time.Now(),
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+	}
+	newRequest := func(ctx context.Context) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)),
+			patch: func() {
+				mockWithdrawalUC.EXPECT().CaptureWithdrawal(gomock.Any(), mockCustomerXid, mockID).Return(mockWithdrawal, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)),
+			patch: func() {
+				mockWithdrawalUC.EXPECT().CaptureWithdrawal(gomock.Any(), mockCustomerXid, mockID).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewWithdrawalHandler(mockWithdrawalUC)
+			h.Capture(nil, tt.r)
+		})
+	}
+}
+
+func TestHandlerWithdrawal_Void(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWithdrawalUC := mockUC.NewMockWithdrawalUsecase(ctrl)
+	defer ctrl.Finish()
+
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockID := "5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d"
+	mockError := errors.New("fake error")
+	mockWithdrawal := &model.Withdrawal{
+		ID:          mockID,
+		WithdrawnBy: mockCustomerXid,
+		Status:      4,
+		WithdrawnAt: time.Now(),
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+	}
+	newRequest := func(ctx context.Context) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", mockID)
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+
+	tests := []struct {
+		name  string
+		r     *http.Request
+		patch func()
+	}{
+		{
+			name: "Success",
+			r:    newRequest(context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)),
+			patch: func() {
+				mockWithdrawalUC.EXPECT().VoidWithdrawal(gomock.Any(), mockCustomerXid, mockID).Return(mockWithdrawal, nil)
+				writerWriteData = func(ctx context.Context, w http.ResponseWriter, data interface{}) {
+				}
+			},
+		},
+		{
+			name: "Failed token",
+			r:    newRequest(context.TODO()),
+			patch: func() {
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+		{
+			name: "Failed usecase",
+			r:    newRequest(context.WithValue(context.TODO(), "AuthDetail", mockCustomerXid)),
+			patch: func() {
+				mockWithdrawalUC.EXPECT().VoidWithdrawal(gomock.Any(), mockCustomerXid, mockID).Return(nil, mockError)
+				writerWriteJSONAPIError = func(ctx context.Context, w http.ResponseWriter, err error) {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			h := NewWithdrawalHandler(mockWithdrawalUC)
+			h.Void(nil, tt.r)
+		})
+	}
+}
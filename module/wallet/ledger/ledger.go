@@ -0,0 +1,248 @@
+// Package ledger records every balance change of a wallet as a balanced,
+// double-entry journal entry. A wallet account is credited when money comes in
+// and debited when it goes out, against a system account that stands for the
+// outside world, so the sum of credits minus debits of a wallet account always
+// equals the wallet balance.
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AccountTypeWallet = "wallet"
+	AccountTypeSystem = "system"
+)
+
+const (
+	SystemAccountCash    = "cash"
+	SystemAccountOpening = "opening"
+	// SystemAccountFX is the counterparty of both legs of a conversion, so
+	// each currency of the entry balances on its own.
+	SystemAccountFX = "fx"
+	// SystemAccountAdjustment is the counterparty of the balance corrections
+	// made by operations staff.
+	SystemAccountAdjustment = "adjustment"
+)
+
+const (
+	EntryKindDeposit    = "deposit"
+	EntryKindWithdrawal = "withdrawal"
+	EntryKindTransfer   = "transfer"
+	EntryKindConversion = "conversion"
+	EntryKindRefund     = "refund"
+	EntryKindReversal   = "reversal"
+	EntryKindAdjustment = "adjustment"
+)
+
+const (
+	Debit  = "debit"
+	Credit = "credit"
+)
+
+var (
+	ErrUnbalancedEntry = errors.New("Ledger entry not balanced")
+	ErrBalanceMismatch = errors.New("Wallet balance does not match ledger")
+)
+
+type Posting struct {
+	AccountType string
+	AccountID   string
+	Direction   string
+	Amount      decimal.Decimal
+	Currency    string
+}
+
+type Entry struct {
+	ID          string
+	Kind        string
+	ReferenceID string
+	CreatedAt   time.Time
+	Postings    []Posting
+}
+
+// Tx is the part of *sql.Tx the ledger writes through, so an entry is always
+// committed together with the balance change it describes.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func NewDepositEntry(walletID, depositID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	return &Entry{
+		Kind:        EntryKindDeposit,
+		ReferenceID: depositID,
+		CreatedAt:   createdAt,
+		Postings: []Posting{
+			{AccountType: AccountTypeSystem, AccountID: SystemAccountCash, Direction: Debit, Amount: amount, Currency: currency},
+			{AccountType: AccountTypeWallet, AccountID: walletID, Direction: Credit, Amount: amount, Currency: currency},
+		},
+	}
+}
+
+func NewWithdrawalEntry(walletID, withdrawalID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	return &Entry{
+		Kind:        EntryKindWithdrawal,
+		ReferenceID: withdrawalID,
+		CreatedAt:   createdAt,
+		Postings: []Posting{
+			{AccountType: AccountTypeWallet, AccountID: walletID, Direction: Debit, Amount: amount, Currency: currency},
+			{AccountType: AccountTypeSystem, AccountID: SystemAccountCash, Direction: Credit, Amount: amount, Currency: currency},
+		},
+	}
+}
+
+// NewRefundEntry gives amount of a withdrawal back to the wallet, posting the
+// withdrawal in reverse. refundID is the deposit that books the refund.
+func NewRefundEntry(walletID, refundID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	entry := NewDepositEntry(walletID, refundID, currency, amount, createdAt)
+	entry.Kind = EntryKindRefund
+	return entry
+}
+
+// NewReversalEntry takes a deposit back off the wallet, posting the deposit in
+// reverse. reversalID is the withdrawal that books the reversal.
+func NewReversalEntry(walletID, reversalID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	entry := NewWithdrawalEntry(walletID, reversalID, currency, amount, createdAt)
+	entry.Kind = EntryKindReversal
+	return entry
+}
+
+// NewAdjustmentEntry corrects the wallet balance by amount, crediting the
+// wallet when it is positive and debiting it when it is negative.
+func NewAdjustmentEntry(walletID, adjustmentID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	wallet, system := Credit, Debit
+	if amount.IsNegative() {
+		wallet, system = Debit, Credit
+	}
+
+	return &Entry{
+		Kind:        EntryKindAdjustment,
+		ReferenceID: adjustmentID,
+		CreatedAt:   createdAt,
+		Postings: []Posting{
+			{AccountType: AccountTypeSystem, AccountID: SystemAccountAdjustment, Direction: system, Amount: amount.Abs(), Currency: currency},
+			{AccountType: AccountTypeWallet, AccountID: walletID, Direction: wallet, Amount: amount.Abs(), Currency: currency},
+		},
+	}
+}
+
+func NewTransferEntry(senderWalletID, recipientWalletID, transferID, currency string, amount decimal.Decimal, createdAt time.Time) *Entry {
+	return &Entry{
+		Kind:        EntryKindTransfer,
+		ReferenceID: transferID,
+		CreatedAt:   createdAt,
+		Postings: []Posting{
+			{AccountType: AccountTypeWallet, AccountID: senderWalletID, Direction: Debit, Amount: amount, Currency: currency},
+			{AccountType: AccountTypeWallet, AccountID: recipientWalletID, Direction: Credit, Amount: amount, Currency: currency},
+		},
+	}
+}
+
+func NewConversionEntry(fromWalletID, toWalletID, conversionID, fromCurrency, toCurrency string, amount, convertedAmount decimal.Decimal, createdAt time.Time) *Entry {
+	return &Entry{
+		Kind:        EntryKindConversion,
+		ReferenceID: conversionID,
+		CreatedAt:   createdAt,
+		Postings: []Posting{
+			{AccountType: AccountTypeWallet, AccountID: fromWalletID, Direction: Debit, Amount: amount, Currency: fromCurrency},
+			{AccountType: AccountTypeSystem, AccountID: SystemAccountFX, Direction: Credit, Amount: amount, Currency: fromCurrency},
+			{AccountType: AccountTypeSystem, AccountID: SystemAccountFX, Direction: Debit, Amount: convertedAmount, Currency: toCurrency},
+			{AccountType: AccountTypeWallet, AccountID: toWalletID, Direction: Credit, Amount: convertedAmount, Currency: toCurrency},
+		},
+	}
+}
+
+// Validate checks the entry has at least two positive postings and that, in
+// each currency, its debits equal its credits.
+func (e *Entry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	// Debits add to and credits subtract from the sum of their currency.
+	sums := map[string]decimal.Decimal{}
+	for _, posting := range e.Postings {
+		if !posting.Amount.IsPositive() || posting.Currency == "" {
+			return ErrUnbalancedEntry
+		}
+
+		switch posting.Direction {
+		case Debit:
+			sums[posting.Currency] = sums[posting.Currency].Add(posting.Amount)
+		case Credit:
+			sums[posting.Currency] = sums[posting.Currency].Sub(posting.Amount)
+		default:
+			return ErrUnbalancedEntry
+		}
+	}
+
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return ErrUnbalancedEntry
+		}
+	}
+
+	return nil
+}
+
+func Post(ctx context.Context, tx Tx, entry *Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		ledger_entries (kind, reference_id, created_at)
+		VALUES ($1, $2, $3) RETURNING id`, entry.Kind, entry.ReferenceID, entry.CreatedAt)
+	err := row.Scan(&entry.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, posting := range entry.Postings {
+		_, err = tx.ExecContext(ctx, `INSERT INTO
+			ledger_postings (entry_id, account_type, account_id, direction, amount, currency)
+			VALUES ($1, $2, $3, $4, $5, $6)`, entry.ID, posting.AccountType, posting.AccountID, posting.Direction, posting.Amount, posting.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func WalletBalance(ctx context.Context, tx Tx, walletID string) (decimal.Decimal, error) {
+	query := `
+		SELECT
+			COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)
+		FROM ledger_postings WHERE account_type = $1 AND account_id = $2
+	`
+
+	balance := decimal.Zero
+	row := tx.QueryRowContext(ctx, query, AccountTypeWallet, walletID)
+	if err := row.Scan(&balance); err != nil {
+		return decimal.Zero, err
+	}
+
+	return balance, nil
+}
+
+// VerifyWalletBalance returns ErrBalanceMismatch when the balance stored on the
+// wallet differs from the one derived from its postings.
+func VerifyWalletBalance(ctx context.Context, tx Tx, walletID string, balance decimal.Decimal) error {
+	ledgerBalance, err := WalletBalance(ctx, tx, walletID)
+	if err != nil {
+		return err
+	}
+
+	if !ledgerBalance.Equal(balance) {
+		return ErrBalanceMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package ledger_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	now      = time.Now()
+	walletID = "81401b03-60e0-4f20-afc6-419b3773e7b3"
+	entryID  = "5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"
+	currency = "IDR"
+)
+
+func TestEntry_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		entry   *ledger.Entry
+		wantErr bool
+	}{
+		"success deposit": {
+			entry:   ledger.NewDepositEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr: false,
+		},
+		"success withdrawal": {
+			entry:   ledger.NewWithdrawalEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr: false,
+		},
+		"failed single posting": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Credit, Amount: decimal.NewFromInt(1000), Currency: currency},
+				},
+			},
+			wantErr: true,
+		},
+		"failed not balanced": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeSystem, AccountID: ledger.SystemAccountCash, Direction: ledger.Debit, Amount: decimal.NewFromInt(999), Currency: currency},
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Credit, Amount: decimal.NewFromInt(1000), Currency: currency},
+				},
+			},
+			wantErr: true,
+		},
+		"success transfer": {
+			entry:   ledger.NewTransferEntry(walletID, entryID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr: false,
+		},
+		"success adjustment credit": {
+			entry:   ledger.NewAdjustmentEntry(walletID, entryID, currency, decimal.NewFromInt(250), now),
+			wantErr: false,
+		},
+		"success adjustment debit": {
+			entry:   ledger.NewAdjustmentEntry(walletID, entryID, currency, decimal.NewFromInt(-250), now),
+			wantErr: false,
+		},
+		"failed adjustment zero": {
+			entry:   ledger.NewAdjustmentEntry(walletID, entryID, currency, decimal.Zero, now),
+			wantErr: true,
+		},
+		"success refund": {
+			entry:   ledger.NewRefundEntry(walletID, entryID, currency, decimal.NewFromInt(400), now),
+			wantErr: false,
+		},
+		"success reversal": {
+			entry:   ledger.NewReversalEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr: false,
+		},
+		"success conversion": {
+			entry:   ledger.NewConversionEntry(walletID, entryID, entryID, "USD", currency, decimal.NewFromInt(10), decimal.NewFromInt(155000), now),
+			wantErr: false,
+		},
+		"success balanced per currency": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Debit, Amount: decimal.NewFromInt(10), Currency: "USD"},
+					{AccountType: ledger.AccountTypeSystem, AccountID: ledger.SystemAccountCash, Direction: ledger.Credit, Amount: decimal.NewFromInt(10), Currency: "USD"},
+					{AccountType: ledger.AccountTypeSystem, AccountID: ledger.SystemAccountCash, Direction: ledger.Debit, Amount: decimal.NewFromInt(150000), Currency: currency},
+					{AccountType: ledger.AccountTypeWallet, AccountID: entryID, Direction: ledger.Credit, Amount: decimal.NewFromInt(150000), Currency: currency},
+				},
+			},
+			wantErr: false,
+		},
+		"failed balanced across currencies": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Debit, Amount: decimal.NewFromInt(1000), Currency: "USD"},
+					{AccountType: ledger.AccountTypeWallet, AccountID: entryID, Direction: ledger.Credit, Amount: decimal.NewFromInt(1000), Currency: currency},
+				},
+			},
+			wantErr: true,
+		},
+		"failed missing currency": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeSystem, AccountID: ledger.SystemAccountCash, Direction: ledger.Debit, Amount: decimal.NewFromInt(1000)},
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Credit, Amount: decimal.NewFromInt(1000)},
+				},
+			},
+			wantErr: true,
+		},
+		"failed zero amount": {
+			entry:   ledger.NewDepositEntry(walletID, entryID, currency, decimal.Zero, now),
+			wantErr: true,
+		},
+		"failed unknown direction": {
+			entry: &ledger.Entry{
+				Postings: []ledger.Posting{
+					{AccountType: ledger.AccountTypeSystem, AccountID: ledger.SystemAccountCash, Direction: "sideways", Amount: decimal.NewFromInt(1000), Currency: currency},
+					{AccountType: ledger.AccountTypeWallet, AccountID: walletID, Direction: ledger.Credit, Amount: decimal.NewFromInt(1000), Currency: currency},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.entry.Validate()
+			if tc.wantErr {
+				assert.Equal(t, ledger.ErrUnbalancedEntry, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestLedger_Post(t *testing.T) {
+	testCases := map[string]struct {
+		entry          *ledger.Entry
+		wantErr        bool
+		wantErrEntry   bool
+		wantErrPosting bool
+	}{
+		"success": {
+			entry:   ledger.NewDepositEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr: false,
+		},
+		"failed validate": {
+			entry:   ledger.NewDepositEntry(walletID, entryID, currency, decimal.Zero, now),
+			wantErr: true,
+		},
+		"failed entry": {
+			entry:        ledger.NewDepositEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr:      true,
+			wantErrEntry: true,
+		},
+		"failed posting": {
+			entry:          ledger.NewDepositEntry(walletID, entryID, currency, decimal.NewFromInt(1000), now),
+			wantErr:        true,
+			wantErrPosting: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, sMock, _ := sqlmock.New()
+			sMock.ExpectBegin()
+			entryQuery := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind, reference_id, created_at) VALUES ($1, $2, $3) RETURNING id")).
+				WithArgs(tc.entry.Kind, tc.entry.ReferenceID, tc.entry.CreatedAt)
+			if tc.wantErrEntry {
+				entryQuery.WillReturnError(errors.New("database error"))
+			} else {
+				entryQuery.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(entryID))
+				posting := tc.entry.Postings[0]
+				postingExec := sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)")).
+					WithArgs(entryID, posting.AccountType, posting.AccountID, posting.Direction, posting.Amount, posting.Currency)
+				if tc.wantErrPosting {
+					postingExec.WillReturnError(errors.New("database error"))
+				} else {
+					postingExec.WillReturnResult(sqlmock.NewResult(0, 1))
+					sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)")).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+
+			tx, _ := db.Begin()
+			err := ledger.Post(context.Background(), tx, tc.entry)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, entryID, tc.entry.ID)
+			}
+		})
+	}
+}
+
+func TestLedger_VerifyWalletBalance(t *testing.T) {
+	testCases := map[string]struct {
+		balance       decimal.Decimal
+		ledgerBalance decimal.Decimal
+		err           error
+		wantErr       error
+	}{
+		"success": {
+			balance:       decimal.NewFromInt(1000),
+			ledgerBalance: decimal.NewFromInt(1000),
+		},
+		"failed mismatch": {
+			balance:       decimal.NewFromInt(1000),
+			ledgerBalance: decimal.NewFromInt(900),
+			wantErr:       ledger.ErrBalanceMismatch,
+		},
+		"failed query": {
+			balance: decimal.NewFromInt(1000),
+			err:     errors.New("database error"),
+			wantErr: errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, sMock, _ := sqlmock.New()
+			sMock.ExpectBegin()
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0) FROM ledger_postings WHERE account_type = $1 AND account_id = $2")).
+				WithArgs(ledger.AccountTypeWallet, walletID)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(tc.ledgerBalance))
+			}
+
+			tx, _ := db.Begin()
+			err := ledger.VerifyWalletBalance(context.Background(), tx, walletID, tc.balance)
+
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package limits holds the business limits the transactions of a wallet must
+// stay within: caps on a single transaction, on what is sent out per day and
+// per month, on the balance, and on how many transactions are made per hour.
+package limits
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Names of the limits, as reported by ExceededError.
+const (
+	MaxDeposit         = "max_deposit"
+	MaxWithdrawal      = "max_withdrawal"
+	DailyWithdrawal    = "daily_withdrawal"
+	MonthlyWithdrawal  = "monthly_withdrawal"
+	MaxBalance         = "max_balance"
+	HourlyTransactions = "hourly_transactions"
+)
+
+// Limits caps the transactions of a wallet. A zero field sets no limit.
+type Limits struct {
+	MaxDeposit         decimal.Decimal `json:"max_deposit"`
+	MaxWithdrawal      decimal.Decimal `json:"max_withdrawal"`
+	DailyWithdrawal    decimal.Decimal `json:"daily_withdrawal"`
+	MonthlyWithdrawal  decimal.Decimal `json:"monthly_withdrawal"`
+	MaxBalance         decimal.Decimal `json:"max_balance"`
+	HourlyTransactions int             `json:"hourly_transactions"`
+}
+
+// Override replaces the default limits of one wallet. A field that is not
+// valid keeps the default.
+type Override struct {
+	MaxDeposit         decimal.NullDecimal
+	MaxWithdrawal      decimal.NullDecimal
+	DailyWithdrawal    decimal.NullDecimal
+	MonthlyWithdrawal  decimal.NullDecimal
+	MaxBalance         decimal.NullDecimal
+	HourlyTransactions sql.NullInt64
+}
+
+// Usage is how much of its limits a wallet already used.
+type Usage struct {
+	DailyWithdrawn     decimal.Decimal
+	MonthlyWithdrawn   decimal.Decimal
+	HourlyTransactions int
+}
+
+// ExceededError is returned for a transaction over the limit Limit, with
+// Remaining what the limit still allows.
+type ExceededError struct {
+	Limit     string
+	Remaining decimal.Decimal
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s limit exceeded, %s remaining", e.Limit, e.Remaining)
+}
+
+// Apply returns l with the fields set by o replaced.
+func (l Limits) Apply(o *Override) Limits {
+	if o == nil {
+		return l
+	}
+
+	for _, field := range []struct {
+		limit    *decimal.Decimal
+		override decimal.NullDecimal
+	}{
+		{&l.MaxDeposit, o.MaxDeposit},
+		{&l.MaxWithdrawal, o.MaxWithdrawal},
+		{&l.DailyWithdrawal, o.DailyWithdrawal},
+		{&l.MonthlyWithdrawal, o.MonthlyWithdrawal},
+		{&l.MaxBalance, o.MaxBalance},
+	} {
+		if field.override.Valid {
+			*field.limit = field.override.Decimal
+		}
+	}
+	if o.HourlyTransactions.Valid {
+		l.HourlyTransactions = int(o.HourlyTransactions.Int64)
+	}
+
+	return l
+}
+
+// UsageLimited reports whether the transactions held to l depend on what the
+// wallet already used: how many transactions it made in the hour, and what
+// it sent out in the day and month when withdrawal is set.
+func (l Limits) UsageLimited(withdrawal bool) bool {
+	return l.HourlyTransactions > 0 || withdrawal && (l.DailyWithdrawal.IsPositive() || l.MonthlyWithdrawal.IsPositive())
+}
+
+// Windows returns when the day, month and hour usage is counted over start
+// at now. Days and months start at midnight UTC; the hour is the one before
+// now.
+func Windows(now time.Time) (day, month, hour time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month, now.Add(-time.Hour)
+}
+
+// CheckDeposit returns an ExceededError when depositing amount to a wallet
+// holding balance goes over a limit.
+func (l Limits) CheckDeposit(amount, balance decimal.Decimal, usage Usage) error {
+	if l.MaxDeposit.IsPositive() && amount.GreaterThan(l.MaxDeposit) {
+		return &ExceededError{Limit: MaxDeposit, Remaining: l.MaxDeposit}
+	}
+
+	err := l.CheckCredit(amount, balance)
+	if err != nil {
+		return err
+	}
+
+	return l.checkHourly(usage)
+}
+
+// CheckCredit returns an ExceededError when crediting amount to a wallet
+// holding balance takes it over its balance cap. Unlike a deposit, a credit
+// received from another wallet or refunded is not held to the other limits.
+func (l Limits) CheckCredit(amount, balance decimal.Decimal) error {
+	if l.MaxBalance.IsPositive() && balance.Add(amount).GreaterThan(l.MaxBalance) {
+		return &ExceededError{Limit: MaxBalance, Remaining: remaining(l.MaxBalance, balance)}
+	}
+
+	return nil
+}
+
+// CheckWithdrawal returns an ExceededError when sending amount out of a wallet,
+// by a withdrawal, a transfer or a conversion, goes over a limit.
+func (l Limits) CheckWithdrawal(amount decimal.Decimal, usage Usage) error {
+	if l.MaxWithdrawal.IsPositive() && amount.GreaterThan(l.MaxWithdrawal) {
+		return &ExceededError{Limit: MaxWithdrawal, Remaining: l.MaxWithdrawal}
+	}
+
+	if l.DailyWithdrawal.IsPositive() && usage.DailyWithdrawn.Add(amount).GreaterThan(l.DailyWithdrawal) {
+		return &ExceededError{Limit: DailyWithdrawal, Remaining: remaining(l.DailyWithdrawal, usage.DailyWithdrawn)}
+	}
+
+	if l.MonthlyWithdrawal.IsPositive() && usage.MonthlyWithdrawn.Add(amount).GreaterThan(l.MonthlyWithdrawal) {
+		return &ExceededError{Limit: MonthlyWithdrawal, Remaining: remaining(l.MonthlyWithdrawal, usage.MonthlyWithdrawn)}
+	}
+
+	return l.checkHourly(usage)
+}
+
+func (l Limits) checkHourly(usage Usage) error {
+	if l.HourlyTransactions > 0 && usage.HourlyTransactions >= l.HourlyTransactions {
+		return &ExceededError{Limit: HourlyTransactions, Remaining: decimal.Zero}
+	}
+
+	return nil
+}
+
+// remaining is what is left of limit once used is taken off, never below zero.
+func remaining(limit, used decimal.Decimal) decimal.Decimal {
+	left := limit.Sub(used)
+	if left.IsNegative() {
+		return decimal.Zero
+	}
+
+	return left
+}
+
+// Defaults are the limits of every wallet, by currency, before its override.
+// A currency missing from it has no limits.
+type Defaults map[string]Limits
+
+// NewFileDefaults reads Defaults from a JSON file such as
+// {"IDR": {"max_deposit": "10000000", "daily_withdrawal": "5000000", "hourly_transactions": 30}}.
+func NewFileDefaults(path string) (Defaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults Defaults
+	err = json.Unmarshal(data, &defaults)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for code, l := range defaults {
+		for _, limit := range []decimal.Decimal{l.MaxDeposit, l.MaxWithdrawal, l.DailyWithdrawal, l.MonthlyWithdrawal, l.MaxBalance} {
+			if limit.IsNegative() {
+				return nil, fmt.Errorf("%s: limits of %s must not be negative", path, code)
+			}
+		}
+		if l.HourlyTransactions < 0 {
+			return nil, fmt.Errorf("%s: limits of %s must not be negative", path, code)
+		}
+	}
+
+	return defaults, nil
+}
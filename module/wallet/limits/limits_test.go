@@ -0,0 +1,169 @@
+package limits
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_Apply(t *testing.T) {
+	defaults := Limits{
+		MaxDeposit:         decimal.NewFromInt(1000),
+		DailyWithdrawal:    decimal.NewFromInt(500),
+		HourlyTransactions: 10,
+	}
+
+	assert.Equal(t, defaults, defaults.Apply(nil))
+
+	got := defaults.Apply(&Override{
+		MaxDeposit:         decimal.NullDecimal{Decimal: decimal.NewFromInt(0), Valid: true},
+		MaxBalance:         decimal.NullDecimal{Decimal: decimal.NewFromInt(5000), Valid: true},
+		HourlyTransactions: sql.NullInt64{Int64: 3, Valid: true},
+	})
+	assert.True(t, got.MaxDeposit.IsZero())
+	assert.True(t, got.DailyWithdrawal.Equal(decimal.NewFromInt(500)))
+	assert.True(t, got.MaxBalance.Equal(decimal.NewFromInt(5000)))
+	assert.Equal(t, 3, got.HourlyTransactions)
+}
+
+func TestLimits_CheckDeposit(t *testing.T) {
+	l := Limits{
+		MaxDeposit:         decimal.NewFromInt(1000),
+		MaxBalance:         decimal.NewFromInt(5000),
+		HourlyTransactions: 2,
+	}
+
+	tests := []struct {
+		name          string
+		limits        Limits
+		amount        int64
+		balance       int64
+		usage         Usage
+		wantLimit     string
+		wantRemaining int64
+	}{
+		{name: "Success within limits", limits: l, amount: 1000, balance: 4000, usage: Usage{HourlyTransactions: 1}},
+		{name: "Success no limits", limits: Limits{}, amount: 1000000, balance: 4000, usage: Usage{HourlyTransactions: 100}},
+		{name: "Failed max deposit", limits: l, amount: 1001, balance: 0, wantLimit: MaxDeposit, wantRemaining: 1000},
+		{name: "Failed max balance", limits: l, amount: 1000, balance: 4500, wantLimit: MaxBalance, wantRemaining: 500},
+		{name: "Failed balance already over", limits: l, amount: 1, balance: 6000, wantLimit: MaxBalance, wantRemaining: 0},
+		{name: "Failed hourly transactions", limits: l, amount: 10, balance: 0, usage: Usage{HourlyTransactions: 2}, wantLimit: HourlyTransactions, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.CheckDeposit(decimal.NewFromInt(tt.amount), decimal.NewFromInt(tt.balance), tt.usage)
+			assertExceeded(t, err, tt.wantLimit, tt.wantRemaining)
+		})
+	}
+}
+
+func TestLimits_CheckCredit(t *testing.T) {
+	l := Limits{
+		MaxDeposit: decimal.NewFromInt(1000),
+		MaxBalance: decimal.NewFromInt(5000),
+	}
+
+	tests := []struct {
+		name          string
+		amount        int64
+		balance       int64
+		wantLimit     string
+		wantRemaining int64
+	}{
+		{name: "Success over max deposit", amount: 1001, balance: 0},
+		{name: "Success up to max balance", amount: 1000, balance: 4000},
+		{name: "Failed max balance", amount: 1000, balance: 4500, wantLimit: MaxBalance, wantRemaining: 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := l.CheckCredit(decimal.NewFromInt(tt.amount), decimal.NewFromInt(tt.balance))
+			assertExceeded(t, err, tt.wantLimit, tt.wantRemaining)
+		})
+	}
+}
+
+func TestLimits_CheckWithdrawal(t *testing.T) {
+	l := Limits{
+		MaxWithdrawal:      decimal.NewFromInt(1000),
+		DailyWithdrawal:    decimal.NewFromInt(1500),
+		MonthlyWithdrawal:  decimal.NewFromInt(3000),
+		HourlyTransactions: 5,
+	}
+
+	tests := []struct {
+		name          string
+		amount        int64
+		usage         Usage
+		wantLimit     string
+		wantRemaining int64
+	}{
+		{name: "Success within limits", amount: 1000, usage: Usage{DailyWithdrawn: decimal.NewFromInt(500), MonthlyWithdrawn: decimal.NewFromInt(2000)}},
+		{name: "Failed max withdrawal", amount: 1001, wantLimit: MaxWithdrawal, wantRemaining: 1000},
+		{name: "Failed daily withdrawal", amount: 1000, usage: Usage{DailyWithdrawn: decimal.NewFromInt(600), MonthlyWithdrawn: decimal.NewFromInt(600)}, wantLimit: DailyWithdrawal, wantRemaining: 900},
+		{name: "Failed monthly withdrawal", amount: 600, usage: Usage{DailyWithdrawn: decimal.NewFromInt(0), MonthlyWithdrawn: decimal.NewFromInt(2500)}, wantLimit: MonthlyWithdrawal, wantRemaining: 500},
+		{name: "Failed hourly transactions", amount: 10, usage: Usage{DailyWithdrawn: decimal.NewFromInt(0), MonthlyWithdrawn: decimal.NewFromInt(0), HourlyTransactions: 5}, wantLimit: HourlyTransactions, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := l.CheckWithdrawal(decimal.NewFromInt(tt.amount), tt.usage)
+			assertExceeded(t, err, tt.wantLimit, tt.wantRemaining)
+		})
+	}
+}
+
+func assertExceeded(t *testing.T, err error, wantLimit string, wantRemaining int64) {
+	t.Helper()
+	if wantLimit == "" {
+		assert.Nil(t, err)
+		return
+	}
+
+	exceeded, ok := err.(*ExceededError)
+	if !assert.True(t, ok, "error = %v, want *ExceededError", err) {
+		return
+	}
+	assert.Equal(t, wantLimit, exceeded.Limit)
+	assert.True(t, decimal.NewFromInt(wantRemaining).Equal(exceeded.Remaining), "remaining = %s, want %d", exceeded.Remaining, wantRemaining)
+}
+
+func TestNewFileDefaults(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "limits.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "Success", content: `{"IDR": {"max_deposit": "10000000", "daily_withdrawal": 5000000, "hourly_transactions": 30}}`},
+		{name: "Failed malformed", content: `{"IDR": `, wantErr: true},
+		{name: "Failed negative limit", content: `{"IDR": {"max_balance": "-1"}}`, wantErr: true},
+		{name: "Failed negative count", content: `{"IDR": {"hourly_transactions": -1}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaults, err := NewFileDefaults(write(t, tt.content))
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, defaults["IDR"].MaxDeposit.Equal(decimal.NewFromInt(10000000)))
+			assert.True(t, defaults["IDR"].DailyWithdrawal.Equal(decimal.NewFromInt(5000000)))
+			assert.Equal(t, 30, defaults["IDR"].HourlyTransactions)
+			assert.True(t, defaults["USD"].MaxDeposit.IsZero())
+		})
+	}
+
+	_, err := NewFileDefaults(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shopspring/decimal"
+)
+
+const namespace = "mini_wallet"
+
+const (
+	TransactionDeposit    = "deposit"
+	TransactionWithdrawal = "withdrawal"
+	TransactionTransfer   = "transfer"
+	TransactionConversion = "conversion"
+	TransactionRefund     = "refund"
+	TransactionReversal   = "reversal"
+
+	ReasonBalanceNotEnough = "balance_not_enough"
+	ReasonReferenceIdUsed  = "reference_id_used"
+	ReasonLimitExceeded    = "limit_exceeded"
+	ReasonOther            = "other"
+
+	// Requests chi could not route are counted under one label, so scanners
+	// cannot grow the series by probing random paths.
+	UnmatchedRoute = "unmatched"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	transactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_total",
+		Help:      "Transactions created, by type and currency. Replayed requests are not counted.",
+	}, []string{"type", "currency"})
+
+	transactionAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_amount_total",
+		Help:      "Sum of the amounts of the transactions created, by type and currency.",
+	}, []string{"type", "currency"})
+
+	transactionsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_failed_total",
+		Help:      "Transactions refused or failed, by type and reason.",
+	}, []string{"type", "reason"})
+)
+
+// NewRegistry returns a registry holding the wallet metrics, the Go runtime
+// and process metrics, and the connection pool stats of db.
+func NewRegistry(db *sql.DB, dbName string) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, dbName),
+		httpRequests,
+		httpRequestDuration,
+		transactions,
+		transactionAmount,
+		transactionsFailed,
+	)
+
+	return registry
+}
+
+// Handler serves the metrics of registry in the Prometheus exposition format.
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func ObserveTransaction(transactionType, currency string, amount decimal.Decimal) {
+	transactions.WithLabelValues(transactionType, currency).Inc()
+	transactionAmount.WithLabelValues(transactionType, currency).Add(amount.InexactFloat64())
+}
+
+func ObserveTransactionFailed(transactionType, reason string) {
+	transactionsFailed.WithLabelValues(transactionType, reason).Inc()
+}
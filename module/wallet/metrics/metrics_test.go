@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, UnmatchedRoute, "404"))
+	ObserveRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, UnmatchedRoute, "404")))
+
+	before = testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, "/api/v1/wallet/deposits", "202"))
+	ObserveRequest(http.MethodPost, "/api/v1/wallet/deposits", http.StatusAccepted, time.Millisecond)
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, "/api/v1/wallet/deposits", "202")))
+}
+
+func TestObserveTransaction(t *testing.T) {
+	count := testutil.ToFloat64(transactions.WithLabelValues(TransactionDeposit, "IDR"))
+	amount := testutil.ToFloat64(transactionAmount.WithLabelValues(TransactionDeposit, "IDR"))
+	ObserveTransaction(TransactionDeposit, "IDR", decimal.NewFromInt(1500))
+	assert.Equal(t, count+1, testutil.ToFloat64(transactions.WithLabelValues(TransactionDeposit, "IDR")))
+	assert.Equal(t, amount+1500, testutil.ToFloat64(transactionAmount.WithLabelValues(TransactionDeposit, "IDR")))
+
+	failed := testutil.ToFloat64(transactionsFailed.WithLabelValues(TransactionWithdrawal, ReasonBalanceNotEnough))
+	ObserveTransactionFailed(TransactionWithdrawal, ReasonBalanceNotEnough)
+	assert.Equal(t, failed+1, testutil.ToFloat64(transactionsFailed.WithLabelValues(TransactionWithdrawal, ReasonBalanceNotEnough)))
+}
+
+func TestHandler(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+	ObserveTransaction(TransactionTransfer, "USD", decimal.NewFromInt(10))
+
+	rec := httptest.NewRecorder()
+	Handler(NewRegistry(db, "wallets")).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, string(body), `mini_wallet_transactions_total{currency="USD",type="transfer"}`)
+	assert.Contains(t, string(body), `go_sql_open_connections{db_name="wallets"}`)
+}
@@ -0,0 +1,107 @@
+// Package ratelimit keeps the token buckets requests are rate limited with. A
+// bucket holds up to Burst tokens and refills at Limit tokens per second; each
+// request takes one, and a request finding the bucket empty is refused.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// evictInterval is how often MemoryStore drops the buckets that refilled.
+const evictInterval = time.Minute
+
+// Rate is the size and refill rate of a bucket. A Rate with no Limit limits
+// nothing.
+type Rate struct {
+	Limit float64
+	Burst int
+}
+
+// PerMinute returns a Rate refilling requests tokens a minute, holding burst.
+func PerMinute(requests, burst int) Rate {
+	return Rate{Limit: float64(requests) / 60, Burst: burst}
+}
+
+// Enabled reports whether the rate limits anything.
+func (r Rate) Enabled() bool {
+	return r.Limit > 0 && r.Burst > 0
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket has refilled, and can be forgotten.
+	full time.Time
+}
+
+// MemoryStore keeps the buckets in process memory, so every instance of the
+// service limits on its own. A store shared by the instances implements Take
+// the same way against a common backend.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	evictedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Take takes a token from the bucket of key. When the bucket is empty it
+// reports false and how long until the next token.
+func (s *MemoryStore) Take(ctx context.Context, key string, rate Rate) (bool, time.Duration, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(rate.Burst), b.tokens+elapsed*rate.Limit)
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false, seconds((1 - b.tokens) / rate.Limit), nil
+	}
+	b.tokens--
+	b.full = now.Add(seconds((float64(rate.Burst) - b.tokens) / rate.Limit))
+
+	return true, 0, nil
+}
+
+// evict drops the buckets that have had time to refill, at most once per
+// evictInterval, so keys seen once do not pile up. A dropped bucket is
+// recreated full. It must be called with mu held.
+func (s *MemoryStore) evict(now time.Time) {
+	if now.Sub(s.evictedAt) < evictInterval {
+		return
+	}
+	s.evictedAt = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
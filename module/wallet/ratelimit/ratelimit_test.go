@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	rate := PerMinute(60, 2)
+
+	tests := []struct {
+		name          string
+		after         []time.Duration
+		wantOK        bool
+		wantRetryWait time.Duration
+	}{
+		{
+			name:   "Success within burst",
+			after:  []time.Duration{0},
+			wantOK: true,
+		},
+		{
+			name:          "Failed burst spent",
+			after:         []time.Duration{0, 0, 0},
+			wantOK:        false,
+			wantRetryWait: time.Second,
+		},
+		{
+			name:          "Failed partly refilled",
+			after:         []time.Duration{0, 0, 500 * time.Millisecond},
+			wantOK:        false,
+			wantRetryWait: 500 * time.Millisecond,
+		},
+		{
+			name:   "Success refilled",
+			after:  []time.Duration{0, 0, time.Second},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			s := NewMemoryStore()
+			s.now = func() time.Time { return now }
+
+			var ok bool
+			var retryAfter time.Duration
+			var err error
+			for _, after := range tt.after {
+				now = now.Add(after)
+				ok, retryAfter, err = s.Take(context.Background(), "ip:192.0.2.1", rate)
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantRetryWait, retryAfter)
+		})
+	}
+}
+
+func TestMemoryStore_TakeKeysApart(t *testing.T) {
+	s := NewMemoryStore()
+	rate := PerMinute(1, 1)
+
+	ok, _, _ := s.Take(context.Background(), "customer:a", rate)
+	assert.True(t, ok)
+	ok, _, _ = s.Take(context.Background(), "customer:a", rate)
+	assert.False(t, ok)
+	ok, _, _ = s.Take(context.Background(), "customer:b", rate)
+	assert.True(t, ok)
+}
+
+func TestMemoryStore_Evict(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	_, _, _ = s.Take(context.Background(), "ip:192.0.2.1", PerMinute(60, 10))
+	_, _, _ = s.Take(context.Background(), "customer:a", PerMinute(1, 10))
+	_, _, _ = s.Take(context.Background(), "customer:a", PerMinute(1, 10))
+	assert.Len(t, s.buckets, 2)
+
+	// The first bucket refills in a second, the second one in two minutes.
+	now = now.Add(evictInterval)
+	_, _, _ = s.Take(context.Background(), "ip:192.0.2.2", PerMinute(60, 10))
+	assert.Len(t, s.buckets, 2)
+	assert.NotContains(t, s.buckets, "ip:192.0.2.1")
+	assert.Contains(t, s.buckets, "customer:a")
+}
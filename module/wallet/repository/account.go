@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 )
 
@@ -17,23 +19,26 @@ func NewAccountRepository(db *sql.DB) *AccountRepository {
 	}
 }
 
-func (r *AccountRepository) ExistAccountByCustomerXid(ctx context.Context, customerXid string) (bool, error) {
+// GetAccountByCustomerXid returns the account of the customer, or nil when
+// there is none.
+func (r *AccountRepository) GetAccountByCustomerXid(ctx context.Context, customerXid string) (*model.Account, error) {
 	query := `
 		SELECT
-			id
+			id,
+			anonymized_at
 		FROM accounts WHERE id = $1
 	`
 
 	account := model.Account{}
 	row := r.db.QueryRowContext(ctx, query, customerXid)
-	if err := row.Scan(&account.CustomerXid); err != nil {
+	if err := row.Scan(&account.CustomerXid, &account.AnonymizedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return false, nil
+			return nil, nil
 		}
-		return false, err
+		return nil, err
 	}
 
-	return true, nil
+	return &account, nil
 }
 
 func (r *AccountRepository) CreateAccount(ctx context.Context, account *model.Account) error {
@@ -47,3 +52,149 @@ func (r *AccountRepository) CreateAccount(ctx context.Context, account *model.Ac
 
 	return nil
 }
+
+// GetAnonymizableAccounts returns up to limit customers not anonymized yet
+// whose wallets are all in status, the last of them since before
+// closedBefore.
+func (r *AccountRepository) GetAnonymizableAccounts(ctx context.Context, status int, closedBefore time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT
+			accounts.id
+		FROM accounts JOIN wallets ON wallets.owned_by = accounts.id
+		WHERE accounts.anonymized_at IS NULL
+		GROUP BY accounts.id HAVING bool_and(wallets.status = $1) AND max(wallets.closed_at) < $2
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, closedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customerXids := []string{}
+	for rows.Next() {
+		var customerXid string
+		if err := rows.Scan(&customerXid); err != nil {
+			return nil, err
+		}
+		customerXids = append(customerXids, customerXid)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return customerXids, nil
+}
+
+// surrogateQueries replace the customer xid $1 by its surrogate $2 wherever a
+// transaction of the customer, or a record about them, names them.
+var surrogateQueries = []string{
+	`UPDATE wallets SET owned_by = $2 WHERE owned_by = $1`,
+	`UPDATE deposits SET deposited_by = $2 WHERE deposited_by = $1`,
+	`UPDATE withdrawals SET withdrawn_by = $2 WHERE withdrawn_by = $1`,
+	`UPDATE transfers SET transferred_by = $2 WHERE transferred_by = $1`,
+	`UPDATE transfers SET transferred_to = $2 WHERE transferred_to = $1`,
+	`UPDATE conversions SET converted_by = $2 WHERE converted_by = $1`,
+	`UPDATE conversion_quotes SET quoted_for = $2 WHERE quoted_for = $1`,
+	`UPDATE balance_discrepancies SET customer_xid = $2 WHERE customer_xid = $1`,
+	`UPDATE revoked_tokens SET customer_xid = $2 WHERE customer_xid = $1`,
+	`UPDATE wallet_status_history SET actor_id = $2 WHERE actor_type = 'customer' AND actor_id = $1`,
+}
+
+// AnonymizeAccount erases what is kept of the customer beyond their
+// transactions: their webhook endpoints and events, with the deliveries still
+// due to them, the responses kept for their idempotency keys and the payout
+// destinations of their withdrawals. The transactions themselves are kept for
+// the books, under a random surrogate in place of the customer xid. It returns
+// false when the account was already anonymized.
+//
+// Two things still name the customer, deliberately. The account row keeps the
+// customer xid, with nothing else, so the customer can not be registered
+// again over their old transactions. The audit log keeps it too: the table is
+// append-only and every event is hashed into its chain, so rewriting an event
+// would break the chain it is kept to prove.
+func (r *AccountRepository) AnonymizeAccount(ctx context.Context, customerXid string, anonymizedAt time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE accounts SET anonymized_at = $1 WHERE id = $2 AND anonymized_at IS NULL`, anonymizedAt, customerXid)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	anonymized, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	if anonymized == 0 {
+		_ = tx.Rollback()
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE owned_by = $1`, customerXid)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	// The events carry the customer xid in their payload; their deliveries
+	// went with the endpoints.
+	_, err = tx.ExecContext(ctx, `DELETE FROM webhook_events WHERE customer_xid = $1`, customerXid)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE customer_xid = $1`, customerXid)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE withdrawals SET destination = '' WHERE withdrawn_by = $1 AND destination <> ''`, customerXid)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	var surrogate string
+	err = tx.QueryRowContext(ctx, `SELECT gen_random_uuid()`).Scan(&surrogate)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	for _, query := range surrogateQueries {
+		_, err = tx.ExecContext(ctx, query, customerXid, surrogate)
+		if err != nil {
+			_ = tx.Rollback()
+			return false, err
+		}
+	}
+
+	err = audit.Record(ctx, tx, &model.AuditEvent{
+		ActorType:    audit.ActorSystem,
+		Action:       audit.ActionAccountAnonymized,
+		CustomerXid:  customerXid,
+		ResourceType: audit.ResourceAccount,
+		ResourceID:   customerXid,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	return true, nil
+}
@@ -3,8 +3,10 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
@@ -62,28 +64,86 @@ func TestAccount_CreateAccount(t *testing.T) {
 	}
 }
 
-func TestAccount_ExistAccountByCustomerXid(t *testing.T) {
+func TestAccount_GetAccountByCustomerXid(t *testing.T) {
+	anonymizedAt := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	anonymized := *account
+	anonymized.AnonymizedAt = &anonymizedAt
+
 	testCases := map[string]struct {
-		req     string
-		want    bool
+		req          string
+		anonymizedAt *time.Time
+		want         *model.Account
+		wantErr      bool
+		err          error
+	}{
+		"success": {
+			req:     account.CustomerXid,
+			want:    account,
+			wantErr: false,
+		},
+		"success anonymized": {
+			req:          account.CustomerXid,
+			anonymizedAt: &anonymizedAt,
+			want:         &anonymized,
+			wantErr:      false,
+		},
+		"success sql no row": {
+			req:     account.CustomerXid,
+			want:    nil,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			req:     account.CustomerXid,
+			want:    nil,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAccountRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, anonymized_at FROM accounts WHERE id = $1")).
+				WithArgs(tc.req)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"id", "anonymized_at"}).AddRow(account.CustomerXid, tc.anonymizedAt))
+			}
+
+			result, err := repo.GetAccountByCustomerXid(context.Background(), tc.req)
+			assert.Equal(t, tc.want, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestAccount_GetAnonymizableAccounts(t *testing.T) {
+	closedBefore := time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		result  []string
 		wantErr bool
 		err     error
 	}{
 		"success": {
-			req:     account.CustomerXid,
-			want:    true,
+			result:  []string{account.CustomerXid},
 			wantErr: false,
 		},
 		"failed scan": {
-			req:     account.CustomerXid,
-			want:    false,
 			wantErr: true,
 		},
-		"failed sql no row": {
-			req:     account.CustomerXid,
-			want:    false,
+		"failed query": {
 			wantErr: true,
-			err:     sql.ErrNoRows,
+			err:     sql.ErrConnDone,
 		},
 	}
 
@@ -94,23 +154,218 @@ func TestAccount_ExistAccountByCustomerXid(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewAccountRepository(db)
 
-			column := []string{"id"}
-			row := sMock.NewRows(column).AddRow(account.CustomerXid)
+			row := sMock.NewRows([]string{"id"}).AddRow(account.CustomerXid)
+			if tc.wantErr {
+				row = sMock.NewRows([]string{"id", "id"}).AddRow(account.CustomerXid, account.CustomerXid)
+			}
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT accounts.id FROM accounts JOIN wallets ON wallets.owned_by = accounts.id WHERE accounts.anonymized_at IS NULL GROUP BY accounts.id HAVING bool_and(wallets.status = $1) AND max(wallets.closed_at) < $2 LIMIT $3")).
+				WithArgs(4, closedBefore, 100)
 			if tc.err != nil {
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1")).
-					WithArgs(tc.req).WillReturnError(tc.err)
+				query.WillReturnError(tc.err)
 			} else {
-				if tc.wantErr {
-					column = []string{"id", "id"}
-					row = sMock.NewRows(column).AddRow(account.CustomerXid, account.CustomerXid)
-				}
+				query.WillReturnRows(row)
+			}
+
+			result, err := repo.GetAnonymizableAccounts(context.Background(), 4, closedBefore, 100)
 
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1")).
-					WithArgs(tc.req).WillReturnRows(row)
+			assert.Equal(t, tc.result, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
 			}
+		})
+	}
+}
 
-			result, _ := repo.ExistAccountByCustomerXid(context.Background(), tc.req)
-			assert.Equal(t, tc.want, result)
+func TestAccount_AnonymizeAccount(t *testing.T) {
+	anonymizedAt := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	surrogateXid := "0d5c3b6e-8f2a-4e71-9b4d-2a6c8e1f3b57"
+	testCases := map[string]struct {
+		result           bool
+		wantErr          bool
+		wantErrTx        bool
+		alreadyAnonymous bool
+		wantErrAccount   bool
+		wantErrEndpoints bool
+		wantErrEvents    bool
+		wantErrKeys      bool
+		wantErrPayouts   bool
+		wantErrSurrogate bool
+		wantErrReplace   bool
+		wantErrAudit     bool
+		wantErrCommit    bool
+		err              error
+	}{
+		"success": {
+			result:  true,
+			wantErr: false,
+		},
+		"success already anonymized": {
+			result:           false,
+			wantErr:          false,
+			alreadyAnonymous: true,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed account": {
+			wantErr:        true,
+			wantErrAccount: true,
+			err:            errors.New("database error"),
+		},
+		"failed endpoints": {
+			wantErr:          true,
+			wantErrEndpoints: true,
+			err:              errors.New("database error"),
+		},
+		"failed webhook events": {
+			wantErr:       true,
+			wantErrEvents: true,
+			err:           errors.New("database error"),
+		},
+		"failed idempotency keys": {
+			wantErr:     true,
+			wantErrKeys: true,
+			err:         errors.New("database error"),
+		},
+		"failed payout destinations": {
+			wantErr:        true,
+			wantErrPayouts: true,
+			err:            errors.New("database error"),
+		},
+		"failed surrogate": {
+			wantErr:          true,
+			wantErrSurrogate: true,
+			err:              errors.New("database error"),
+		},
+		"failed replace customer xid": {
+			wantErr:        true,
+			wantErrReplace: true,
+			err:            errors.New("database error"),
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAccountRepository(db)
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				update := sMock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET anonymized_at = $1 WHERE id = $2 AND anonymized_at IS NULL")).
+					WithArgs(anonymizedAt, account.CustomerXid)
+				switch {
+				case tc.wantErrAccount:
+					update.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				case tc.alreadyAnonymous:
+					update.WillReturnResult(sqlmock.NewResult(0, 0))
+					sMock.ExpectRollback()
+				default:
+					update.WillReturnResult(sqlmock.NewResult(0, 1))
+					endpoints := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM webhook_endpoints WHERE owned_by = $1")).
+						WithArgs(account.CustomerXid)
+					if tc.wantErrEndpoints {
+						endpoints.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					endpoints.WillReturnResult(sqlmock.NewResult(0, 2))
+					events := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM webhook_events WHERE customer_xid = $1")).
+						WithArgs(account.CustomerXid)
+					if tc.wantErrEvents {
+						events.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					events.WillReturnResult(sqlmock.NewResult(0, 3))
+					keys := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE customer_xid = $1")).
+						WithArgs(account.CustomerXid)
+					if tc.wantErrKeys {
+						keys.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					keys.WillReturnResult(sqlmock.NewResult(0, 5))
+					payouts := sMock.ExpectExec(regexp.QuoteMeta("UPDATE withdrawals SET destination = '' WHERE withdrawn_by = $1 AND destination <> ''")).
+						WithArgs(account.CustomerXid)
+					if tc.wantErrPayouts {
+						payouts.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					payouts.WillReturnResult(sqlmock.NewResult(0, 1))
+					surrogate := sMock.ExpectQuery(regexp.QuoteMeta("SELECT gen_random_uuid()"))
+					if tc.wantErrSurrogate {
+						surrogate.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					surrogate.WillReturnRows(sqlmock.NewRows([]string{"gen_random_uuid"}).AddRow(surrogateXid))
+					wallets := sMock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET owned_by = $2 WHERE owned_by = $1")).
+						WithArgs(account.CustomerXid, surrogateXid)
+					if tc.wantErrReplace {
+						wallets.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					wallets.WillReturnResult(sqlmock.NewResult(0, 2))
+					for _, query := range []string{
+						"UPDATE deposits SET deposited_by = $2 WHERE deposited_by = $1",
+						"UPDATE withdrawals SET withdrawn_by = $2 WHERE withdrawn_by = $1",
+						"UPDATE transfers SET transferred_by = $2 WHERE transferred_by = $1",
+						"UPDATE transfers SET transferred_to = $2 WHERE transferred_to = $1",
+						"UPDATE conversions SET converted_by = $2 WHERE converted_by = $1",
+						"UPDATE conversion_quotes SET quoted_for = $2 WHERE quoted_for = $1",
+						"UPDATE balance_discrepancies SET customer_xid = $2 WHERE customer_xid = $1",
+						"UPDATE revoked_tokens SET customer_xid = $2 WHERE customer_xid = $1",
+						"UPDATE wallet_status_history SET actor_id = $2 WHERE actor_type = 'customer' AND actor_id = $1",
+					} {
+						sMock.ExpectExec(regexp.QuoteMeta(query)).
+							WithArgs(account.CustomerXid, surrogateXid).
+							WillReturnResult(sqlmock.NewResult(0, 1))
+					}
+					if tc.wantErrAudit {
+						expectAuditEvent(sMock, tc.err)
+						sMock.ExpectRollback()
+						break
+					}
+					expectAuditEvent(sMock, nil)
+					if tc.wantErrCommit {
+						sMock.ExpectCommit().WillReturnError(tc.err)
+					} else {
+						sMock.ExpectCommit()
+					}
+				}
+			}
+
+			result, err := repo.AnonymizeAccount(context.Background(), account.CustomerXid, anonymizedAt)
+
+			assert.Equal(t, tc.result, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
 		})
 	}
 }
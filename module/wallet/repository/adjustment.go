@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+)
+
+type AdjustmentRepository struct {
+	db *sql.DB
+}
+
+func NewAdjustmentRepository(db *sql.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{
+		db: db,
+	}
+}
+
+// CreateAdjustment records the adjustment and applies it to the wallet
+// balance, with its ledger entry, in one transaction.
+func (r *AdjustmentRepository) CreateAdjustment(ctx context.Context, adjustment *model.Adjustment, wallet *model.Wallet) (*model.Adjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		adjustments (wallet_id, adjusted_by, amount, currency, reason, reference_id, adjusted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, wallet.ID, adjustment.AdjustedBy, adjustment.Amount, adjustment.Currency, adjustment.Reason, adjustment.ReferenceID, adjustment.AdjustedAt)
+	err = row.Scan(&adjustment.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// An adjustment taking money off may not dig into what pending
+	// withdrawals hold, and is guarded by the status the wallet was read in so
+	// it can not land on a wallet closed since.
+	row = tx.QueryRowContext(ctx, `UPDATE wallets
+		SET balance = balance + $1 WHERE id = $2 AND status = $3 AND balance + $1 >= held_balance RETURNING balance, held_balance`, adjustment.Amount, wallet.ID, wallet.Status)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletUpdateError(ctx, tx, wallet, commonerr.ErrBalanceNotEnough)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewAdjustmentEntry(wallet.ID, adjustment.ID, adjustment.Currency, adjustment.Amount, adjustment.AdjustedAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Sub(adjustment.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionBalanceAdjusted, audit.ResourceAdjustment, adjustment.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	adjustment.WalletID = wallet.ID
+	adjustment.Balance = wallet.Balance
+	return adjustment, nil
+}
@@ -0,0 +1,173 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustment_CreateAdjustment(t *testing.T) {
+	adjustment := &model.Adjustment{
+		ID:          "4c1d2e3f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		AdjustedBy:  "ops-alice",
+		AdjustedAt:  now,
+		Amount:      decimal.NewFromInt(-2500),
+		Currency:    "IDR",
+		Reason:      "Duplicate bank credit",
+		ReferenceID: "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+	}
+	balance := walletEnable.Balance.Add(adjustment.Amount)
+
+	testCases := map[string]struct {
+		wantErr         bool
+		wantErrTx       bool
+		wantErrInsert   bool
+		wantErrWallet   bool
+		wantErrMismatch bool
+		wantErrAudit    bool
+		wantErrCommit   bool
+		walletStatus    int
+		err             error
+		resultErr       error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed reference id used": {
+			wantErr:       true,
+			wantErrInsert: true,
+			err:           &pq.Error{Code: "23505"},
+			resultErr:     commonerr.ErrReferenceIdUsed,
+		},
+		"failed insert": {
+			wantErr:       true,
+			wantErrInsert: true,
+			err:           errors.New("database error"),
+		},
+		"failed balance not enough": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  1,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrBalanceNotEnough,
+		},
+		"failed wallet closed": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  4,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed ledger mismatch": {
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAdjustmentRepository(db)
+			wallet := *walletEnable
+			req := *adjustment
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO adjustments (wallet_id, adjusted_by, amount, currency, reason, reference_id, adjusted_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+					WithArgs(wallet.ID, adjustment.AdjustedBy, adjustment.Amount, adjustment.Currency, adjustment.Reason, adjustment.ReferenceID, adjustment.AdjustedAt)
+				if tc.wantErrInsert {
+					insert.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(adjustment.ID))
+					update := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND balance + $1 >= held_balance RETURNING balance, held_balance")).
+						WithArgs(adjustment.Amount, wallet.ID, wallet.Status)
+					if tc.wantErrWallet {
+						update.WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, wallet.ID, tc.walletStatus)
+						}
+						sMock.ExpectRollback()
+					} else {
+						update.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(balance, walletEnable.HeldBalance))
+						if tc.wantErrMismatch {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							sMock.ExpectRollback()
+						} else if tc.wantErrAudit {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, nil)
+							if tc.wantErrCommit {
+								sMock.ExpectCommit().WillReturnError(tc.err)
+							} else {
+								sMock.ExpectCommit()
+							}
+						}
+					}
+				}
+			}
+
+			result, err := repo.CreateAdjustment(context.Background(), &req, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				if !assert.Nil(t, err) {
+					return
+				}
+				want := *adjustment
+				want.WalletID = wallet.ID
+				want.Balance = balance
+				if !reflect.DeepEqual(result, &want) {
+					t.Errorf("Adjustment.CreateAdjustment() = %v, want %v", result, &want)
+				}
+				assert.True(t, wallet.Balance.Equal(balance))
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
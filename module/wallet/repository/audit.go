@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+const auditEventColumns = `seq, chain, chain_seq, actor_type, actor_id, action, customer_xid, wallet_id, resource_type, resource_id,
+	before, after, reason, request_id, ip, created_at, prev_hash, hash`
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// RecordAuditEvent records an event of its own, for the actions that change
+// nothing else, such as staff reading a customer's transactions.
+func (r *AuditRepository) RecordAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = audit.Record(ctx, tx, event)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns a page of the events matching filter, newest first.
+func (r *AuditRepository) GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]*model.AuditEvent, error) {
+	var clause string
+	var args []interface{}
+	for _, cond := range []struct {
+		column string
+		value  string
+	}{
+		{"customer_xid", filter.CustomerXid},
+		{"wallet_id", filter.WalletID},
+		{"actor_id", filter.ActorID},
+		{"action", filter.Action},
+	} {
+		if cond.value != "" {
+			args = append(args, cond.value)
+			clause += fmt.Sprintf(" AND %s = $%d", cond.column, len(args))
+		}
+	}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		clause += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		clause += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+
+	if filter.Before > 0 {
+		args = append(args, filter.Before)
+		clause += fmt.Sprintf(" AND seq < $%d", len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE TRUE` + clause + fmt.Sprintf(" ORDER BY seq DESC LIMIT $%d", len(args))
+
+	return r.queryAuditEvents(ctx, query, args...)
+}
+
+// GetAuditEventsAfter returns up to limit events following the event
+// chainSeq of chain, ordered by chain and place in it.
+func (r *AuditRepository) GetAuditEventsAfter(ctx context.Context, chain string, chainSeq int64, limit int) ([]*model.AuditEvent, error) {
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE (chain, chain_seq) > ($1, $2) ORDER BY chain, chain_seq LIMIT $3`
+
+	return r.queryAuditEvents(ctx, query, chain, chainSeq, limit)
+}
+
+func (r *AuditRepository) queryAuditEvents(ctx context.Context, query string, args ...interface{}) ([]*model.AuditEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*model.AuditEvent{}
+	for rows.Next() {
+		event := &model.AuditEvent{}
+		var before, after []byte
+		if err := rows.Scan(&event.Seq, &event.Chain, &event.ChainSeq, &event.ActorType, &event.ActorID, &event.Action, &event.CustomerXid, &event.WalletID, &event.ResourceType, &event.ResourceID,
+			&before, &after, &event.Reason, &event.RequestID, &event.IP, &event.CreatedAt, &event.PrevHash, &event.Hash); err != nil {
+			return nil, err
+		}
+		event.Before, event.After = before, after
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
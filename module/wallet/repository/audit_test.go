@@ -0,0 +1,292 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	auditEventColumns = []string{"seq", "chain", "chain_seq", "actor_type", "actor_id", "action", "customer_xid", "wallet_id", "resource_type", "resource_id",
+		"before", "after", "reason", "request_id", "ip", "created_at", "prev_hash", "hash"}
+	auditEvent = &model.AuditEvent{
+		Seq:          42,
+		Chain:        "wallet:81401b03-60e0-4f20-afc6-419b3773e7b3",
+		ChainSeq:     7,
+		ActorType:    audit.ActorAdmin,
+		ActorID:      "ops-alice",
+		Action:       audit.ActionWalletDisabled,
+		CustomerXid:  "ea0212d3-abd6-406f-8c67-868e814a2436",
+		WalletID:     "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		ResourceType: audit.ResourceWallet,
+		ResourceID:   "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Before:       []byte(`{"status":1,"balance":"10000","held_balance":"0"}`),
+		After:        []byte(`{"status":2,"balance":"10000","held_balance":"0"}`),
+		Reason:       "Suspected account takeover",
+		RequestID:    "9f86d081884c7d65",
+		IP:           "203.0.113.7",
+		CreatedAt:    now,
+		PrevHash:     "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		Hash:         "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+	}
+)
+
+// expectAuditEvent expects an audit event appended to its chain, the insert
+// failing with err when it is not nil.
+func expectAuditEvent(sMock sqlmock.Sqlmock, err error) {
+	sMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).WillReturnResult(sqlmock.NewResult(0, 0))
+	sMock.ExpectQuery(regexp.QuoteMeta("SELECT chain_seq, hash FROM audit_events WHERE chain = $1 ORDER BY chain_seq DESC LIMIT 1")).
+		WillReturnRows(sMock.NewRows([]string{"chain_seq", "hash"}).AddRow(auditEvent.ChainSeq, auditEvent.Hash))
+	insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO audit_events (chain, chain_seq, actor_type, actor_id, action, customer_xid, wallet_id, resource_type, resource_id, before, after, reason, request_id, ip, created_at, prev_hash, hash)"))
+	if err != nil {
+		insert.WillReturnError(err)
+	} else {
+		insert.WillReturnRows(sMock.NewRows([]string{"seq"}).AddRow(auditEvent.Seq + 1))
+	}
+}
+
+// captureArg matches any argument, handing it to set.
+type captureArg func(v driver.Value)
+
+func (c captureArg) Match(v driver.Value) bool {
+	c(v)
+	return true
+}
+
+// captureAuditEvents expects n audit events appended in a row, each to a
+// chain whose last event is chainSeq with the hash hash. It returns the
+// events as they are inserted.
+func captureAuditEvents(sMock sqlmock.Sqlmock, chainSeq int64, hash string, n int) []*model.AuditEvent {
+	events := make([]*model.AuditEvent, n)
+	for i := range events {
+		event := &model.AuditEvent{}
+		events[i] = event
+		str := func(field *string) captureArg {
+			return func(v driver.Value) { *field, _ = v.(string) }
+		}
+		raw := func(field *json.RawMessage) captureArg {
+			return func(v driver.Value) {
+				if s, ok := v.(string); ok {
+					*field = json.RawMessage(s)
+				}
+			}
+		}
+
+		sMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).WillReturnResult(sqlmock.NewResult(0, 0))
+		sMock.ExpectQuery(regexp.QuoteMeta("SELECT chain_seq, hash FROM audit_events WHERE chain = $1 ORDER BY chain_seq DESC LIMIT 1")).
+			WillReturnRows(sMock.NewRows([]string{"chain_seq", "hash"}).AddRow(chainSeq, hash))
+		sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO audit_events (chain, chain_seq, actor_type, actor_id, action, customer_xid, wallet_id, resource_type, resource_id, before, after, reason, request_id, ip, created_at, prev_hash, hash)")).
+			WithArgs(
+				str(&event.Chain),
+				captureArg(func(v driver.Value) { event.ChainSeq, _ = v.(int64) }),
+				str(&event.ActorType), str(&event.ActorID), str(&event.Action), str(&event.CustomerXid), str(&event.WalletID),
+				str(&event.ResourceType), str(&event.ResourceID), raw(&event.Before), raw(&event.After),
+				str(&event.Reason), str(&event.RequestID), str(&event.IP),
+				captureArg(func(v driver.Value) { event.CreatedAt, _ = v.(time.Time) }),
+				str(&event.PrevHash), str(&event.Hash),
+			).WillReturnRows(sMock.NewRows([]string{"seq"}).AddRow(int64(i + 1)))
+	}
+
+	return events
+}
+
+// verifyAuditEvents reports whether each of the events follows on from the
+// event chainSeq with the hash hash in its chain.
+func verifyAuditEvents(chainSeq int64, hash string, events []*model.AuditEvent) bool {
+	for _, event := range events {
+		head := &model.AuditEvent{Chain: event.Chain, ChainSeq: chainSeq, Hash: hash}
+		if audit.Verify(head, []*model.AuditEvent{event}) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func auditEventRow(sMock sqlmock.Sqlmock) *sqlmock.Rows {
+	return sMock.NewRows(auditEventColumns).AddRow(auditEvent.Seq, auditEvent.Chain, auditEvent.ChainSeq, auditEvent.ActorType, auditEvent.ActorID, auditEvent.Action, auditEvent.CustomerXid,
+		auditEvent.WalletID, auditEvent.ResourceType, auditEvent.ResourceID, []byte(auditEvent.Before), []byte(auditEvent.After), auditEvent.Reason,
+		auditEvent.RequestID, auditEvent.IP, auditEvent.CreatedAt, auditEvent.PrevHash, auditEvent.Hash)
+}
+
+func TestAudit_RecordAuditEvent(t *testing.T) {
+	testCases := map[string]struct {
+		wantErr       bool
+		wantErrTx     bool
+		wantErrAudit  bool
+		wantErrCommit bool
+		err           error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAuditRepository(db)
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				if tc.wantErrAudit {
+					expectAuditEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+				} else {
+					expectAuditEvent(sMock, nil)
+					if tc.wantErrCommit {
+						sMock.ExpectCommit().WillReturnError(tc.err)
+					} else {
+						sMock.ExpectCommit()
+					}
+				}
+			}
+
+			err := repo.RecordAuditEvent(context.Background(), &model.AuditEvent{Action: audit.ActionWalletsSearched, CustomerXid: auditEvent.CustomerXid})
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAudit_GetAuditEvents(t *testing.T) {
+	testCases := map[string]struct {
+		filter  model.AuditEventFilter
+		query   string
+		args    []interface{}
+		result  []*model.AuditEvent
+		wantErr bool
+		err     error
+	}{
+		"success unfiltered": {
+			filter: model.AuditEventFilter{Limit: 50},
+			query:  "FROM audit_events WHERE TRUE ORDER BY seq DESC LIMIT $1",
+			args:   []interface{}{50},
+			result: []*model.AuditEvent{auditEvent},
+		},
+		"success filtered": {
+			filter: model.AuditEventFilter{CustomerXid: auditEvent.CustomerXid, Action: auditEvent.Action, From: &now, Before: 43, Limit: 10},
+			query:  "FROM audit_events WHERE TRUE AND customer_xid = $1 AND action = $2 AND created_at >= $3 AND seq < $4 ORDER BY seq DESC LIMIT $5",
+			args:   []interface{}{auditEvent.CustomerXid, auditEvent.Action, now, 43, 10},
+			result: []*model.AuditEvent{auditEvent},
+		},
+		"failed query": {
+			filter:  model.AuditEventFilter{Limit: 50},
+			query:   "FROM audit_events WHERE TRUE ORDER BY seq DESC LIMIT $1",
+			args:    []interface{}{50},
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAuditRepository(db)
+
+			var args []driver.Value
+			for _, arg := range tc.args {
+				args = append(args, arg)
+			}
+			query := sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).WithArgs(args...)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(auditEventRow(sMock))
+			}
+
+			result, err := repo.GetAuditEvents(context.Background(), tc.filter)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Audit.GetAuditEvents() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAudit_GetAuditEventsAfter(t *testing.T) {
+	testCases := map[string]struct {
+		result  []*model.AuditEvent
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result: []*model.AuditEvent{auditEvent},
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewAuditRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("FROM audit_events WHERE (chain, chain_seq) > ($1, $2) ORDER BY chain, chain_seq LIMIT $3")).WithArgs(auditEvent.Chain, 6, 500)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(auditEventRow(sMock))
+			}
+
+			result, err := repo.GetAuditEventsAfter(context.Background(), auditEvent.Chain, 6, 500)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Audit.GetAuditEventsAfter() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
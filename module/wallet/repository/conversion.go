@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+type ConversionRepository struct {
+	db *sql.DB
+}
+
+func NewConversionRepository(db *sql.DB) *ConversionRepository {
+	return &ConversionRepository{
+		db: db,
+	}
+}
+
+func (r *ConversionRepository) CreateQuote(ctx context.Context, quote *model.ConversionQuote) (*model.ConversionQuote, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO
+		conversion_quotes (quoted_for, from_currency, to_currency, amount, converted_amount, rate, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, quote.QuotedFor, quote.FromCurrency, quote.ToCurrency, quote.Amount, quote.ConvertedAmount, quote.Rate, quote.CreatedAt, quote.ExpiresAt)
+	err := row.Scan(&quote.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// GetQuote returns the quote of the customer, nil when the customer has no
+// quote with that id.
+func (r *ConversionRepository) GetQuote(ctx context.Context, customerXid, quoteId string) (*model.ConversionQuote, error) {
+	query := `
+		SELECT
+			id, quoted_for, from_currency, to_currency, amount, converted_amount, rate, created_at, expires_at, used_at IS NOT NULL
+		FROM conversion_quotes WHERE id = $1 AND quoted_for = $2
+	`
+
+	quote := &model.ConversionQuote{}
+	row := r.db.QueryRowContext(ctx, query, quoteId, customerXid)
+	if err := row.Scan(&quote.ID, &quote.QuotedFor, &quote.FromCurrency, &quote.ToCurrency, &quote.Amount, &quote.ConvertedAmount, &quote.Rate, &quote.CreatedAt, &quote.ExpiresAt, &quote.Used); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (r *ConversionRepository) GetConversionByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Conversion, error) {
+	query := `
+		SELECT
+			c.id, c.converted_by, COALESCE(c.quote_id::text, ''), c.status, c.converted_at, c.from_currency, c.to_currency,
+			c.amount, c.converted_amount, c.rate, c.reference_id, w.id, d.id
+		FROM conversions c
+		JOIN withdrawals w ON w.conversion_id = c.id
+		JOIN deposits d ON d.conversion_id = c.id
+		WHERE c.converted_by = $1 AND c.reference_id = $2
+	`
+
+	conversion := &model.Conversion{}
+	row := r.db.QueryRowContext(ctx, query, customerXid, referenceId)
+	if err := row.Scan(&conversion.ID, &conversion.ConvertedBy, &conversion.QuoteID, &conversion.Status, &conversion.ConvertedAt, &conversion.FromCurrency, &conversion.ToCurrency,
+		&conversion.Amount, &conversion.ConvertedAmount, &conversion.Rate, &conversion.ReferenceID, &conversion.WithdrawalID, &conversion.DepositID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return conversion, nil
+}
+
+// CreateConversion records the conversion with its withdrawal from source and
+// its deposit to target, moves the money between both wallets and spends the
+// quote of the conversion, if any, all in one transaction.
+func (r *ConversionRepository) CreateConversion(ctx context.Context, conversion *model.Conversion, source *model.Wallet, target *model.Wallet) (*model.Conversion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkUsageLimits(ctx, tx, source, conversion.Amount, true, conversion.ConvertedAt, source, target)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	quoteID := sql.NullString{String: conversion.QuoteID, Valid: conversion.QuoteID != ""}
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		conversions (converted_by, quote_id, status, converted_at, from_currency, to_currency, amount, converted_amount, rate, reference_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`, conversion.ConvertedBy, quoteID, conversion.Status, conversion.ConvertedAt,
+		conversion.FromCurrency, conversion.ToCurrency, conversion.Amount, conversion.ConvertedAmount, conversion.Rate, conversion.ReferenceID)
+	err = row.Scan(&conversion.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// Spending the quote in the same statement that checks it keeps two
+	// conversions racing for one quote from both using it.
+	if quoteID.Valid {
+		row = tx.QueryRowContext(ctx, `UPDATE conversion_quotes
+			SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND expires_at > $1 RETURNING id`, conversion.ConvertedAt, conversion.QuoteID)
+		err = row.Scan(&conversion.QuoteID)
+		if err != nil {
+			_ = tx.Rollback()
+			if err == sql.ErrNoRows {
+				return nil, commonerr.ErrQuoteUsed
+			}
+			return nil, err
+		}
+	}
+
+	// The legs use the conversion id as their reference id, which no request
+	// of the customer can already have taken.
+	row = tx.QueryRowContext(ctx, `INSERT INTO
+		withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, conversion_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $5) RETURNING id`, conversion.ConvertedBy, conversion.Status, conversion.ConvertedAt, conversion.Amount, conversion.ID, conversion.FromCurrency)
+	err = row.Scan(&conversion.WithdrawalID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	row = tx.QueryRowContext(ctx, `INSERT INTO
+		deposits (deposited_by, status, deposited_at, amount, reference_id, currency, conversion_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $5) RETURNING id`, conversion.ConvertedBy, conversion.Status, conversion.ConvertedAt, conversion.ConvertedAmount, conversion.ID, conversion.ToCurrency)
+	err = row.Scan(&conversion.DepositID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// Both wallet rows are locked in id order, as transfers do.
+	debit := func() error {
+		row := tx.QueryRowContext(ctx, `UPDATE wallets
+			SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance`, conversion.Amount, source.ID, source.Status)
+		err := row.Scan(&source.Balance)
+		if err == sql.ErrNoRows {
+			return walletUpdateError(ctx, tx, source, commonerr.ErrBalanceNotEnough)
+		}
+		return err
+	}
+	credit := func() error {
+		row := tx.QueryRowContext(ctx, `UPDATE wallets
+			SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance`, conversion.ConvertedAmount, target.ID, target.Status, target.MaxBalance)
+		err := row.Scan(&target.Balance)
+		if err == sql.ErrNoRows {
+			return walletCreditError(ctx, tx, target)
+		}
+		return err
+	}
+
+	updates := []func() error{debit, credit}
+	if target.ID < source.ID {
+		updates = []func() error{credit, debit}
+	}
+	for _, update := range updates {
+		err = update()
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewConversionEntry(source.ID, target.ID, conversion.ID, conversion.FromCurrency, conversion.ToCurrency, conversion.Amount, conversion.ConvertedAmount, conversion.ConvertedAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	for _, wallet := range []*model.Wallet{source, target} {
+		err = verifyWalletBalance(ctx, tx, wallet)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Each wallet gets its own event, source first, so the chain shows both
+	// sides of the move.
+	sourceBefore := audit.StateOf(source)
+	sourceBefore.Balance = source.Balance.Add(conversion.Amount)
+	targetBefore := audit.StateOf(target)
+	targetBefore.Balance = target.Balance.Sub(conversion.ConvertedAmount)
+	events := []*model.AuditEvent{
+		audit.NewWalletEvent(audit.ActionConversionCreated, audit.ResourceConversion, conversion.ID, source, sourceBefore),
+		audit.NewWalletEvent(audit.ActionConversionCreated, audit.ResourceConversion, conversion.ID, target, targetBefore),
+	}
+	for _, event := range events {
+		err = audit.Record(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewConversionEvent(conversion, source, target))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return conversion, nil
+}
@@ -0,0 +1,408 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	quote = &model.ConversionQuote{
+		ID:              "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+		QuotedFor:       "ea0212d3-abd6-406f-8c67-868e814a2436",
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(155000),
+		Rate:            decimal.NewFromInt(15500),
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(30 * time.Second),
+	}
+	conversion = &model.Conversion{
+		ID:              "9c1d2e3f-4a5b-4c6d-8e7f-0a1b2c3d4e5f",
+		ConvertedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		QuoteID:         "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+		Status:          1,
+		ConvertedAt:     now,
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(155000),
+		Rate:            decimal.NewFromInt(15500),
+		ReferenceID:     "50535246-dcb2-4929-8cc9-004ea06f5241",
+		WithdrawalID:    "0d9e8f7a-6b5c-4d3e-8f2a-1b0c9d8e7f6a",
+		DepositID:       "1e0f9a8b-7c6d-4e5f-9a3b-2c1d0e9f8a7b",
+	}
+	walletSource = &model.Wallet{
+		ID:        "c5e1d2e3-4b5a-4c6d-8e7f-9a0b1c2d3e4f",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(100),
+		Currency:  "USD",
+	}
+)
+
+func TestConversion_CreateQuote(t *testing.T) {
+	testCases := map[string]struct {
+		result  *model.ConversionQuote
+		wantErr bool
+	}{
+		"success": {
+			result:  quote,
+			wantErr: false,
+		},
+		"failed scan": {
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewConversionRepository(db)
+
+			row := sMock.NewRows([]string{"id"}).AddRow(quote.ID)
+			if tc.wantErr {
+				row = sMock.NewRows([]string{"id", "id"}).AddRow(quote.ID, quote.ID)
+			}
+
+			sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO conversion_quotes (quoted_for, from_currency, to_currency, amount, converted_amount, rate, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id")).
+				WithArgs(quote.QuotedFor, quote.FromCurrency, quote.ToCurrency, quote.Amount, quote.ConvertedAmount, quote.Rate, quote.CreatedAt, quote.ExpiresAt).WillReturnRows(row)
+
+			req := *quote
+			result, err := repo.CreateQuote(context.Background(), &req)
+
+			if tc.result != nil && !reflect.DeepEqual(*result, *tc.result) {
+				t.Errorf("Conversion.CreateQuote() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.Nil(t, result)
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestConversion_GetQuote(t *testing.T) {
+	testCases := map[string]struct {
+		result  *model.ConversionQuote
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  quote,
+			wantErr: false,
+		},
+		"failed scan": {
+			wantErr: true,
+		},
+		"failed sql no row": {
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewConversionRepository(db)
+
+			query := regexp.QuoteMeta("SELECT id, quoted_for, from_currency, to_currency, amount, converted_amount, rate, created_at, expires_at, used_at IS NOT NULL FROM conversion_quotes WHERE id = $1 AND quoted_for = $2")
+			column := []string{"id", "quoted_for", "from_currency", "to_currency", "amount", "converted_amount", "rate", "created_at", "expires_at", "used"}
+			row := sMock.NewRows(column).AddRow(quote.ID, quote.QuotedFor, quote.FromCurrency, quote.ToCurrency, quote.Amount, quote.ConvertedAmount, quote.Rate, quote.CreatedAt, quote.ExpiresAt, false)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(query).WithArgs(quote.ID, quote.QuotedFor).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					row = sMock.NewRows([]string{"id", "id"}).AddRow(quote.ID, quote.ID)
+				}
+				sMock.ExpectQuery(query).WithArgs(quote.ID, quote.QuotedFor).WillReturnRows(row)
+			}
+
+			result, err := repo.GetQuote(context.Background(), quote.QuotedFor, quote.ID)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Conversion.GetQuote() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestConversion_GetConversionByReferenceId(t *testing.T) {
+	testCases := map[string]struct {
+		result  *model.Conversion
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  conversion,
+			wantErr: false,
+		},
+		"failed scan": {
+			wantErr: true,
+		},
+		"failed sql no row": {
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewConversionRepository(db)
+
+			query := regexp.QuoteMeta("SELECT c.id, c.converted_by, COALESCE(c.quote_id::text, ''), c.status, c.converted_at, c.from_currency, c.to_currency, c.amount, c.converted_amount, c.rate, c.reference_id, w.id, d.id FROM conversions c JOIN withdrawals w ON w.conversion_id = c.id JOIN deposits d ON d.conversion_id = c.id WHERE c.converted_by = $1 AND c.reference_id = $2")
+			column := []string{"id", "converted_by", "quote_id", "status", "converted_at", "from_currency", "to_currency", "amount", "converted_amount", "rate", "reference_id", "withdrawal_id", "deposit_id"}
+			row := sMock.NewRows(column).AddRow(conversion.ID, conversion.ConvertedBy, conversion.QuoteID, conversion.Status, conversion.ConvertedAt, conversion.FromCurrency, conversion.ToCurrency,
+				conversion.Amount, conversion.ConvertedAmount, conversion.Rate, conversion.ReferenceID, conversion.WithdrawalID, conversion.DepositID)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(query).WithArgs(conversion.ConvertedBy, conversion.ReferenceID).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					row = sMock.NewRows([]string{"id", "id"}).AddRow(conversion.ID, conversion.ID)
+				}
+				sMock.ExpectQuery(query).WithArgs(conversion.ConvertedBy, conversion.ReferenceID).WillReturnRows(row)
+			}
+
+			result, err := repo.GetConversionByReferenceId(context.Background(), conversion.ConvertedBy, conversion.ReferenceID)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Conversion.GetConversionByReferenceId() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestConversion_CreateConversion(t *testing.T) {
+	testCases := map[string]struct {
+		wantErr           bool
+		wantErrTx         bool
+		wantErrConversion bool
+		wantErrQuote      bool
+		wantErrBalance    bool
+		wantErrLedger     bool
+		wantErrAudit      bool
+		wantErrWebhook    bool
+		wantErrCommit     bool
+		walletStatus      int
+		err               error
+		resultErr         error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed reference id used": {
+			wantErr:           true,
+			wantErrConversion: true,
+			err:               &pq.Error{Code: "23505"},
+			resultErr:         commonerr.ErrReferenceIdUsed,
+		},
+		"failed quote used": {
+			wantErr:      true,
+			wantErrQuote: true,
+			err:          sql.ErrNoRows,
+			resultErr:    commonerr.ErrQuoteUsed,
+		},
+		"failed balance not enough": {
+			wantErr:        true,
+			wantErrBalance: true,
+			walletStatus:   1,
+			err:            sql.ErrNoRows,
+			resultErr:      commonerr.ErrBalanceNotEnough,
+		},
+		"failed source frozen": {
+			wantErr:        true,
+			wantErrBalance: true,
+			walletStatus:   3,
+			err:            sql.ErrNoRows,
+			resultErr:      commonerr.ErrWalletTransition,
+		},
+		"failed ledger": {
+			wantErr:       true,
+			wantErrLedger: true,
+			err:           errors.New("database error"),
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed webhook": {
+			wantErr:        true,
+			wantErrWebhook: true,
+			err:            errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewConversionRepository(db)
+			var events []*model.AuditEvent
+			source := *walletSource
+			target := *walletEnable
+
+			expect := func() {
+				if tc.wantErrTx {
+					sMock.ExpectBegin().WillReturnError(tc.err)
+					return
+				}
+				sMock.ExpectBegin()
+
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO conversions (converted_by, quote_id, status, converted_at, from_currency, to_currency, amount, converted_amount, rate, reference_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id")).
+					WithArgs(conversion.ConvertedBy, conversion.QuoteID, conversion.Status, conversion.ConvertedAt, conversion.FromCurrency, conversion.ToCurrency, conversion.Amount, conversion.ConvertedAmount, conversion.Rate, conversion.ReferenceID)
+				if tc.wantErrConversion {
+					insert.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(conversion.ID))
+
+				spend := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE conversion_quotes SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND expires_at > $1 RETURNING id")).
+					WithArgs(conversion.ConvertedAt, conversion.QuoteID)
+				if tc.wantErrQuote {
+					spend.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				spend.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(conversion.QuoteID))
+
+				sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, conversion_id) VALUES ($1, $2, $3, $4, $5, $6, $5) RETURNING id")).
+					WithArgs(conversion.ConvertedBy, conversion.Status, conversion.ConvertedAt, conversion.Amount, conversion.ID, conversion.FromCurrency).
+					WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(conversion.WithdrawalID))
+				sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id, currency, conversion_id) VALUES ($1, $2, $3, $4, $5, $6, $5) RETURNING id")).
+					WithArgs(conversion.ConvertedBy, conversion.Status, conversion.ConvertedAt, conversion.ConvertedAmount, conversion.ID, conversion.ToCurrency).
+					WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(conversion.DepositID))
+
+				// The IDR wallet has the lower id, so it is credited first.
+				sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance")).
+					WithArgs(conversion.ConvertedAmount, target.ID, target.Status, target.MaxBalance).
+					WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(target.Balance.Add(conversion.ConvertedAmount)))
+				debit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance")).
+					WithArgs(conversion.Amount, source.ID, source.Status)
+				if tc.wantErrBalance {
+					debit.WillReturnError(tc.err)
+					expectWalletStatus(sMock, source.ID, tc.walletStatus)
+					sMock.ExpectRollback()
+					return
+				}
+				debit.WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(source.Balance.Sub(conversion.Amount)))
+
+				entry := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind, reference_id, created_at) VALUES ($1, $2, $3) RETURNING id")).
+					WithArgs("conversion", conversion.ID, conversion.ConvertedAt)
+				if tc.wantErrLedger {
+					entry.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				entry.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow("5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"))
+				for i := 0; i < 4; i++ {
+					sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)")).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				for _, balance := range []decimal.Decimal{source.Balance.Sub(conversion.Amount), target.Balance.Add(conversion.ConvertedAmount)} {
+					sMock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0) FROM ledger_postings WHERE account_type = $1 AND account_id = $2")).
+						WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(balance))
+				}
+
+				if tc.wantErrAudit {
+					expectAuditEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				events = captureAuditEvents(sMock, auditEvent.ChainSeq, auditEvent.Hash, 2)
+
+				if tc.wantErrWebhook {
+					expectWebhookEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				expectWebhookEvent(sMock, nil)
+
+				if tc.wantErrCommit {
+					sMock.ExpectCommit().WillReturnError(tc.err)
+					return
+				}
+				sMock.ExpectCommit()
+			}
+			expect()
+
+			req := *conversion
+			req.ID, req.WithdrawalID, req.DepositID = "", "", ""
+			result, err := repo.CreateConversion(context.Background(), &req, &source, &target)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+			if tc.wantErrBalance {
+				assert.Equal(t, tc.walletStatus, source.Status)
+			}
+			if tc.wantErr {
+				assert.Nil(t, result)
+				assert.NotNil(t, err)
+				return
+			}
+
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, conversion.ID, result.ID)
+			assert.Equal(t, conversion.WithdrawalID, result.WithdrawalID)
+			assert.Equal(t, conversion.DepositID, result.DepositID)
+			assert.True(t, decimal.NewFromInt(90).Equal(source.Balance))
+			assert.True(t, verifyAuditEvents(auditEvent.ChainSeq, auditEvent.Hash, events))
+			assert.Equal(t, []string{source.ID, target.ID}, []string{events[0].WalletID, events[1].WalletID})
+			assert.Equal(t, audit.ActionConversionCreated, events[1].Action)
+			assert.Equal(t, conversion.ID, events[1].ResourceID)
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 )
 
 type DepositRepository struct {
@@ -17,16 +21,17 @@ func NewDepositRepository(db *sql.DB) *DepositRepository {
 	}
 }
 
-func (r *DepositRepository) GetDepositByReferenceId(ctx context.Context, referenceId string) (*model.Deposit, error) {
+func (r *DepositRepository) GetDepositByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Deposit, error) {
 	query := `
 		SELECT
-			id, deposited_by, status, deposited_at, amount, reference_id
-		FROM deposits WHERE reference_id = $1
+			id, deposited_by, status, deposited_at, amount, reference_id, currency,
+			COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '')
+		FROM deposits WHERE deposited_by = $1 AND reference_id = $2
 	`
 
 	deposit := &model.Deposit{}
-	row := r.db.QueryRowContext(ctx, query, referenceId)
-	if err := row.Scan(&deposit.ID, &deposit.DepositedBy, &deposit.Status, &deposit.DepositedAt, &deposit.Amount, &deposit.ReferenceID); err != nil {
+	row := r.db.QueryRowContext(ctx, query, customerXid, referenceId)
+	if err := row.Scan(&deposit.ID, &deposit.DepositedBy, &deposit.Status, &deposit.DepositedAt, &deposit.Amount, &deposit.ReferenceID, &deposit.Currency, &deposit.ConversionID, &deposit.RefundOf); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -36,24 +41,118 @@ func (r *DepositRepository) GetDepositByReferenceId(ctx context.Context, referen
 	return deposit, nil
 }
 
+// GetDepositById returns the deposit of the customer, nil when the customer
+// has no deposit with that id.
+func (r *DepositRepository) GetDepositById(ctx context.Context, customerXid, id string) (*model.Deposit, error) {
+	query := `
+		SELECT
+			id, deposited_by, status, deposited_at, amount, reference_id, currency,
+			COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '')
+		FROM deposits WHERE id = $1 AND deposited_by = $2
+	`
+
+	deposit := &model.Deposit{}
+	row := r.db.QueryRowContext(ctx, query, id, customerXid)
+	if err := row.Scan(&deposit.ID, &deposit.DepositedBy, &deposit.Status, &deposit.DepositedAt, &deposit.Amount, &deposit.ReferenceID, &deposit.Currency, &deposit.ConversionID, &deposit.RefundOf); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+func (r *DepositRepository) GetDepositsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Deposit, error) {
+	query := `
+		SELECT
+			id, deposited_by, status, deposited_at, amount, reference_id, currency,
+			COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '')
+		FROM deposits WHERE deposited_by = $1
+	`
+	clause, args := transactionFilterClause(filter, "deposited_at", []interface{}{customerXid})
+
+	rows, err := r.db.QueryContext(ctx, query+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deposits := []*model.Deposit{}
+	for rows.Next() {
+		deposit := &model.Deposit{}
+		if err := rows.Scan(&deposit.ID, &deposit.DepositedBy, &deposit.Status, &deposit.DepositedAt, &deposit.Amount, &deposit.ReferenceID, &deposit.Currency, &deposit.ConversionID, &deposit.RefundOf); err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
 func (r *DepositRepository) CreateDeposit(ctx context.Context, deposit *model.Deposit, wallet *model.Wallet) (*model.Deposit, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	err = checkUsageLimits(ctx, tx, wallet, deposit.Amount, false, deposit.DepositedAt, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
 	row := tx.QueryRowContext(ctx, `INSERT INTO
-		deposits (deposited_by, status, deposited_at, amount, reference_id) 
-		VALUES ($1, $2, $3, $4, $5) RETURNING id`, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID)
+		deposits (deposited_by, status, deposited_at, amount, reference_id, currency) 
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID, deposit.Currency)
 	err = row.Scan(&deposit.ID)
 	if err != nil {
 		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
 		return nil, err
 	}
 
+	// The balance is incremented in place so concurrent deposits never overwrite
+	// each other with a value computed from a stale read, and only while it stays
+	// within MaxBalance so they can not together take the wallet over it.
 	row = tx.QueryRowContext(ctx, `UPDATE wallets
-		SET balance = $1 WHERE id = $2 RETURNING id`, wallet.Balance, wallet.ID)
-	err = row.Scan(&wallet.ID)
+		SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance, held_balance`, deposit.Amount, wallet.ID, wallet.Status, wallet.MaxBalance)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletCreditError(ctx, tx, wallet)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewDepositEntry(wallet.ID, deposit.ID, deposit.Currency, deposit.Amount, deposit.DepositedAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Sub(deposit.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionDepositCreated, audit.ResourceDeposit, deposit.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewDepositEvent(deposit, wallet))
 	if err != nil {
 		_ = tx.Rollback()
 		return nil, err
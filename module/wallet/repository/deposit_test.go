@@ -3,15 +3,20 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
 	"github.com/herwando/mini-wallet/module/wallet/repository"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,9 +29,23 @@ var (
 		DepositedAt: now,
 		Amount:      decimal.NewFromInt(1000),
 		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:    "IDR",
 	}
 )
 
+// expectLedgerEntry expects a two posting ledger entry followed by the balance
+// verification query returning ledgerBalance.
+func expectLedgerEntry(sMock sqlmock.Sqlmock, ledgerBalance decimal.Decimal) {
+	sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind, reference_id, created_at) VALUES ($1, $2, $3) RETURNING id")).
+		WillReturnRows(sMock.NewRows([]string{"id"}).AddRow("5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"))
+	sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_type, account_id, direction, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sMock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0) FROM ledger_postings WHERE account_type = $1 AND account_id = $2")).
+		WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(ledgerBalance))
+}
+
 func TestDeposit_GetDepositByReferenceId(t *testing.T) {
 	testCases := map[string]struct {
 		req     string
@@ -57,23 +76,23 @@ func TestDeposit_GetDepositByReferenceId(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewDepositRepository(db)
 
-			column := []string{"id", "deposited_by", "status", "deposited_at", "amount", "reference_id"}
-			row := sMock.NewRows(column).AddRow(deposit.ID, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID)
+			column := []string{"id", "deposited_by", "status", "deposited_at", "amount", "reference_id", "currency", "conversion_id", "refund_of"}
+			row := sMock.NewRows(column).AddRow(deposit.ID, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID, deposit.Currency, "", "")
 
 			if tc.err != nil {
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, deposited_by, status, deposited_at, amount, reference_id FROM deposits WHERE reference_id = $1")).
-					WithArgs(tc.req).WillReturnError(tc.err)
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 AND reference_id = $2")).
+					WithArgs(deposit.DepositedBy, tc.req).WillReturnError(tc.err)
 			} else {
 				if tc.wantErr {
 					column = []string{"id", "id"}
 					row = sMock.NewRows(column).AddRow(deposit.ID, deposit.ID)
 				}
 
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, deposited_by, status, deposited_at, amount, reference_id FROM deposits WHERE reference_id = $1")).
-					WithArgs(tc.req).WillReturnRows(row)
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 AND reference_id = $2")).
+					WithArgs(deposit.DepositedBy, tc.req).WillReturnRows(row)
 			}
 
-			result, _ := repo.GetDepositByReferenceId(context.Background(), tc.req)
+			result, _ := repo.GetDepositByReferenceId(context.Background(), deposit.DepositedBy, tc.req)
 
 			if !reflect.DeepEqual(result, tc.result) {
 				t.Errorf("Wallet.GetDepositByReferenceId() = %v, want %v", result, tc.result)
@@ -82,17 +101,117 @@ func TestDeposit_GetDepositByReferenceId(t *testing.T) {
 	}
 }
 
+func TestDeposit_GetDepositsByCustomerXid(t *testing.T) {
+	from := now.Add(-24 * time.Hour)
+	to := now
+	testCases := map[string]struct {
+		filter  model.TransactionFilter
+		query   string
+		args    []driver.Value
+		result  []*model.Deposit
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 ORDER BY deposited_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{deposit.DepositedBy, 11},
+			result:  []*model.Deposit{deposit},
+			wantErr: false,
+		},
+		"success with currency": {
+			filter:  model.TransactionFilter{Currency: "IDR", Limit: 11},
+			query:   "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 AND currency = $2 ORDER BY deposited_at DESC, id DESC LIMIT $3",
+			args:    []driver.Value{deposit.DepositedBy, "IDR", 11},
+			result:  []*model.Deposit{deposit},
+			wantErr: false,
+		},
+		"success with filter": {
+			filter: model.TransactionFilter{
+				From:   &from,
+				To:     &to,
+				Cursor: &model.TransactionCursor{TransactedAt: now, ID: deposit.ID},
+				Limit:  11,
+			},
+			query:   "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 AND deposited_at >= $2 AND deposited_at <= $3 AND (deposited_at, id) < ($4, $5::uuid) ORDER BY deposited_at DESC, id DESC LIMIT $6",
+			args:    []driver.Value{deposit.DepositedBy, from, to, now, deposit.ID, 11},
+			result:  []*model.Deposit{deposit},
+			wantErr: false,
+		},
+		"failed query": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 ORDER BY deposited_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{deposit.DepositedBy, 11},
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+		"failed scan": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE deposited_by = $1 ORDER BY deposited_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{deposit.DepositedBy, 11},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewDepositRepository(db)
+
+			column := []string{"id", "deposited_by", "status", "deposited_at", "amount", "reference_id", "currency", "conversion_id", "refund_of"}
+			row := sMock.NewRows(column).AddRow(deposit.ID, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID, deposit.Currency, "", "")
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(deposit.ID, deposit.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnRows(row)
+			}
+
+			result, err := repo.GetDepositsByCustomerXid(context.Background(), deposit.DepositedBy, tc.filter)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Deposit.GetDepositsByCustomerXid() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
 func TestDeposit_CreateDeposit(t *testing.T) {
+	// walletCapped may take 500 more. When its credit is refused, the wallet is
+	// read again with a concurrent deposit of 200 in, leaving 300 to the cap.
+	walletCapped := *walletEnable
+	walletCapped.MaxBalance = walletEnable.Balance.Add(decimal.NewFromInt(500))
+
 	testCases := map[string]struct {
-		reqDeposit     *model.Deposit
-		reqWallet      *model.Wallet
-		result         *model.Deposit
-		wantErr        bool
-		wantErrTx      bool
-		wantErrDeposit bool
-		wantErrWallet  bool
-		wantErrCommit  bool
-		err            error
+		reqDeposit      *model.Deposit
+		reqWallet       *model.Wallet
+		result          *model.Deposit
+		wantErr         bool
+		wantErrTx       bool
+		wantErrDeposit  bool
+		wantErrWallet   bool
+		wantErrLedger   bool
+		wantErrMismatch bool
+		wantErrAudit    bool
+		wantErrWebhook  bool
+		wantErrCommit   bool
+		walletStatus    int
+		err             error
+		resultErr       error
 	}{
 		"success": {
 			reqDeposit:     deposit,
@@ -111,6 +230,14 @@ func TestDeposit_CreateDeposit(t *testing.T) {
 			wantErrTx:  true,
 			err:        errors.New("database error"),
 		},
+		"failed reference id used": {
+			reqDeposit:     deposit,
+			reqWallet:      walletEnable,
+			wantErr:        true,
+			wantErrDeposit: true,
+			err:            &pq.Error{Code: "23505"},
+			resultErr:      commonerr.ErrReferenceIdUsed,
+		},
 		"failed deposit": {
 			reqDeposit:     deposit,
 			reqWallet:      walletEnable,
@@ -128,6 +255,51 @@ func TestDeposit_CreateDeposit(t *testing.T) {
 			wantErrWallet:  true,
 			err:            errors.New("database error"),
 		},
+		"failed max balance": {
+			reqDeposit:    deposit,
+			reqWallet:     &walletCapped,
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  1,
+			err:           sql.ErrNoRows,
+			resultErr:     &limits.ExceededError{Limit: limits.MaxBalance, Remaining: decimal.NewFromInt(300)},
+		},
+		"failed wallet closed": {
+			reqDeposit:    deposit,
+			reqWallet:     walletEnable,
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  4,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed ledger": {
+			reqDeposit:    deposit,
+			reqWallet:     walletEnable,
+			wantErr:       true,
+			wantErrLedger: true,
+			err:           errors.New("database error"),
+		},
+		"failed ledger mismatch": {
+			reqDeposit:      deposit,
+			reqWallet:       walletEnable,
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			reqDeposit:   deposit,
+			reqWallet:    walletEnable,
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed webhook": {
+			reqDeposit:     deposit,
+			reqWallet:      walletEnable,
+			wantErr:        true,
+			wantErrWebhook: true,
+			err:            errors.New("database error"),
+		},
 		"failed commit": {
 			reqDeposit:     deposit,
 			reqWallet:      walletEnable,
@@ -155,31 +327,63 @@ func TestDeposit_CreateDeposit(t *testing.T) {
 				column := []string{"id"}
 				row := sMock.NewRows(column).AddRow(deposit.ID)
 				if tc.wantErrDeposit {
-					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
-						WithArgs(tc.reqDeposit.DepositedBy, tc.reqDeposit.Status, tc.reqDeposit.DepositedAt, tc.reqDeposit.Amount, tc.reqDeposit.ReferenceID).WillReturnError(tc.err)
+					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id, currency) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id")).
+						WithArgs(tc.reqDeposit.DepositedBy, tc.reqDeposit.Status, tc.reqDeposit.DepositedAt, tc.reqDeposit.Amount, tc.reqDeposit.ReferenceID, tc.reqDeposit.Currency).WillReturnError(tc.err)
 					sMock.ExpectRollback()
 				} else {
-					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
-						WithArgs(tc.reqDeposit.DepositedBy, tc.reqDeposit.Status, tc.reqDeposit.DepositedAt, tc.reqDeposit.Amount, tc.reqDeposit.ReferenceID).WillReturnRows(row)
-					row = sMock.NewRows(column).AddRow(walletEnable.ID)
+					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id, currency) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id")).
+						WithArgs(tc.reqDeposit.DepositedBy, tc.reqDeposit.Status, tc.reqDeposit.DepositedAt, tc.reqDeposit.Amount, tc.reqDeposit.ReferenceID, tc.reqDeposit.Currency).WillReturnRows(row)
+					row = sMock.NewRows([]string{"balance", "held_balance"}).AddRow(walletEnable.Balance, walletEnable.HeldBalance)
 					if tc.wantErrWallet {
-						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE id = $2 RETURNING id")).
-							WithArgs(tc.reqWallet.Balance, tc.reqWallet.ID).WillReturnError(tc.err)
+						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance, held_balance")).
+							WithArgs(tc.reqDeposit.Amount, tc.reqWallet.ID, tc.reqWallet.Status, tc.reqWallet.MaxBalance).WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletBalance(sMock, tc.reqWallet.ID, tc.walletStatus, tc.reqWallet.Balance.Add(decimal.NewFromInt(200)))
+						}
 						sMock.ExpectRollback()
 					} else {
-						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE id = $2 RETURNING id")).
-							WithArgs(tc.reqWallet.Balance, tc.reqWallet.ID).WillReturnRows(row)
-						if tc.wantErrCommit {
-							sMock.ExpectCommit().WillReturnError(tc.err)
+						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance, held_balance")).
+							WithArgs(tc.reqDeposit.Amount, tc.reqWallet.ID, tc.reqWallet.Status, tc.reqWallet.MaxBalance).WillReturnRows(row)
+						if tc.wantErrLedger {
+							sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind, reference_id, created_at) VALUES ($1, $2, $3) RETURNING id")).
+								WillReturnError(tc.err)
+							sMock.ExpectRollback()
+						} else if tc.wantErrMismatch {
+							expectLedgerEntry(sMock, walletEnable.Balance.Add(decimal.NewFromInt(1)))
+							sMock.ExpectRollback()
+						} else if tc.wantErrAudit {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else if tc.wantErrWebhook {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							expectAuditEvent(sMock, nil)
+							expectWebhookEvent(sMock, tc.err)
 							sMock.ExpectRollback()
 						} else {
-							sMock.ExpectCommit()
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							expectAuditEvent(sMock, nil)
+							expectWebhookEvent(sMock, nil)
+							if tc.wantErrCommit {
+								sMock.ExpectCommit().WillReturnError(tc.err)
+								sMock.ExpectRollback()
+							} else {
+								sMock.ExpectCommit()
+							}
 						}
 					}
 				}
 			}
 
-			result, err := repo.CreateDeposit(context.Background(), tc.reqDeposit, tc.reqWallet)
+			wallet := *tc.reqWallet
+			result, err := repo.CreateDeposit(context.Background(), tc.reqDeposit, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+			if tc.walletStatus != 0 {
+				assert.Equal(t, tc.walletStatus, wallet.Status)
+			}
 
 			if !reflect.DeepEqual(result, tc.result) {
 				t.Errorf("Wallet.CreateDeposit() = %v, want %v", result, tc.result)
@@ -193,3 +397,69 @@ func TestDeposit_CreateDeposit(t *testing.T) {
 		})
 	}
 }
+
+func TestDeposit_GetDepositById(t *testing.T) {
+	testCases := map[string]struct {
+		req     string
+		result  *model.Deposit
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			req:     deposit.ID,
+			result:  deposit,
+			wantErr: false,
+		},
+		"failed scan": {
+			req:     deposit.ID,
+			wantErr: true,
+		},
+		"failed sql no row": {
+			req:     deposit.ID,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			req:     deposit.ID,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewDepositRepository(db)
+
+			column := []string{"id", "deposited_by", "status", "deposited_at", "amount", "reference_id", "currency", "conversion_id", "refund_of"}
+			row := sMock.NewRows(column).AddRow(deposit.ID, deposit.DepositedBy, deposit.Status, deposit.DepositedAt, deposit.Amount, deposit.ReferenceID, deposit.Currency, "", "")
+
+			query := "SELECT id, deposited_by, status, deposited_at, amount, reference_id, currency, COALESCE(conversion_id::text, ''), COALESCE(refund_of::text, '') FROM deposits WHERE id = $1 AND deposited_by = $2"
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(tc.req, deposit.DepositedBy).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(deposit.ID, deposit.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(tc.req, deposit.DepositedBy).WillReturnRows(row)
+			}
+
+			result, err := repo.GetDepositById(context.Background(), deposit.DepositedBy, tc.req)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Deposit.GetDepositById() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
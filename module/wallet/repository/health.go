@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type HealthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	return &HealthRepository{
+		db: db,
+	}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// GetMigrationVersion reads the schema_migrations table golang-migrate keeps.
+// A database that was never migrated reports version 0.
+func (r *HealthRepository) GetMigrationVersion(ctx context.Context) (uint64, bool, error) {
+	var (
+		version uint64
+		dirty   bool
+	)
+
+	row := r.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	if err := row.Scan(&version, &dirty); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
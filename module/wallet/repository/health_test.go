@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Ping(t *testing.T) {
+	testCases := map[string]struct {
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed ping": {
+			wantErr: true,
+			err:     errors.New("connection refused"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewHealthRepository(db)
+
+			sMock.ExpectPing().WillReturnError(tc.err)
+
+			err := repo.Ping(context.Background())
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestHealth_GetMigrationVersion(t *testing.T) {
+	testCases := map[string]struct {
+		version uint64
+		dirty   bool
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			version: 20261018100400,
+			wantErr: false,
+		},
+		"success dirty": {
+			version: 20261018100400,
+			dirty:   true,
+			wantErr: false,
+		},
+		"success never migrated": {
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("relation \"schema_migrations\" does not exist"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewHealthRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations LIMIT 1"))
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"version", "dirty"}).AddRow(tc.version, tc.dirty))
+			}
+
+			version, dirty, err := repo.GetMigrationVersion(context.Background())
+
+			assert.Equal(t, tc.version, version)
+			assert.Equal(t, tc.dirty, dirty)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
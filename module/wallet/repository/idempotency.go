@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// ReserveIdempotencyKey stores the record as in progress and returns nil, or
+// returns the record already stored under the same customer, route and key. A
+// stored record that has expired is replaced as if there were none.
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO
+		idempotency_keys (customer_xid, route, idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (customer_xid, route, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = 0, content_type = '', response_body = NULL,
+		created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`, record.CustomerXid, record.Route, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if inserted == 1 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT
+			customer_xid, route, idempotency_key, request_hash, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE customer_xid = $1 AND route = $2 AND idempotency_key = $3
+	`
+
+	existing := &model.IdempotencyRecord{}
+	row := r.db.QueryRowContext(ctx, query, record.CustomerXid, record.Route, record.Key)
+	if err := row.Scan(&existing.CustomerXid, &existing.Route, &existing.Key, &existing.RequestHash, &existing.StatusCode, &existing.ContentType, &existing.ResponseBody, &existing.CreatedAt, &existing.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func (r *IdempotencyRepository) SaveIdempotencyResponse(ctx context.Context, record *model.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE customer_xid = $4 AND route = $5 AND idempotency_key = $6`, record.StatusCode, record.ContentType, record.ResponseBody, record.CustomerXid, record.Route, record.Key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, customerXid, route, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys
+		WHERE customer_xid = $1 AND route = $2 AND idempotency_key = $3 AND status_code = 0`, customerXid, route, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes the keys that expired before now and returns
+// how many it deleted.
+func (r *IdempotencyRepository) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
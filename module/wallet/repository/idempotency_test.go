@@ -0,0 +1,222 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	idempotencyRecord = &model.IdempotencyRecord{
+		CustomerXid:  "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Route:        "POST /api/v1/wallet/deposits",
+		Key:          "50535246-dcb2-4929-8cc9-004ea06f5241",
+		RequestHash:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		StatusCode:   202,
+		ContentType:  "application/json",
+		ResponseBody: []byte(`{"status":"success"}`),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(24 * time.Hour),
+	}
+)
+
+func TestIdempotency_ReserveIdempotencyKey(t *testing.T) {
+	testCases := map[string]struct {
+		result       *model.IdempotencyRecord
+		wantErr      bool
+		wantExisting bool
+		err          error
+	}{
+		"success reserved": {
+			result:  nil,
+			wantErr: false,
+		},
+		"success existing": {
+			result:       idempotencyRecord,
+			wantErr:      false,
+			wantExisting: true,
+		},
+		"failed insert": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+		"failed select": {
+			wantErr:      true,
+			wantExisting: true,
+			err:          errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewIdempotencyRepository(db)
+
+			insert := sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys (customer_xid, route, idempotency_key, request_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (customer_xid, route, idempotency_key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = 0, content_type = '', response_body = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at WHERE idempotency_keys.expires_at <= EXCLUDED.created_at")).
+				WithArgs(idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key, idempotencyRecord.RequestHash, idempotencyRecord.CreatedAt, idempotencyRecord.ExpiresAt)
+			if !tc.wantExisting {
+				if tc.err != nil {
+					insert.WillReturnError(tc.err)
+				} else {
+					insert.WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			} else {
+				insert.WillReturnResult(sqlmock.NewResult(0, 0))
+				query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT customer_xid, route, idempotency_key, request_hash, status_code, content_type, response_body, created_at, expires_at FROM idempotency_keys WHERE customer_xid = $1 AND route = $2 AND idempotency_key = $3")).
+					WithArgs(idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key)
+				if tc.err != nil {
+					query.WillReturnError(tc.err)
+				} else {
+					column := []string{"customer_xid", "route", "idempotency_key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at"}
+					query.WillReturnRows(sMock.NewRows(column).AddRow(idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key, idempotencyRecord.RequestHash, idempotencyRecord.StatusCode, idempotencyRecord.ContentType, idempotencyRecord.ResponseBody, idempotencyRecord.CreatedAt, idempotencyRecord.ExpiresAt))
+				}
+			}
+
+			result, err := repo.ReserveIdempotencyKey(context.Background(), idempotencyRecord)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Idempotency.ReserveIdempotencyKey() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestIdempotency_SaveIdempotencyResponse(t *testing.T) {
+	testCases := map[string]struct {
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed exec": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewIdempotencyRepository(db)
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3 WHERE customer_xid = $4 AND route = $5 AND idempotency_key = $6")).
+				WithArgs(idempotencyRecord.StatusCode, idempotencyRecord.ContentType, idempotencyRecord.ResponseBody, idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			err := repo.SaveIdempotencyResponse(context.Background(), idempotencyRecord)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestIdempotency_DeleteIdempotencyKey(t *testing.T) {
+	testCases := map[string]struct {
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed exec": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewIdempotencyRepository(db)
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE customer_xid = $1 AND route = $2 AND idempotency_key = $3 AND status_code = 0")).
+				WithArgs(idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			err := repo.DeleteIdempotencyKey(context.Background(), idempotencyRecord.CustomerXid, idempotencyRecord.Route, idempotencyRecord.Key)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestIdempotency_PurgeIdempotencyKeys(t *testing.T) {
+	testCases := map[string]struct {
+		want    int64
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			want:    3,
+			wantErr: false,
+		},
+		"failed exec": {
+			want:    0,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewIdempotencyRepository(db)
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE expires_at < $1")).
+				WithArgs(now)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tc.want))
+			}
+
+			result, err := repo.PurgeIdempotencyKeys(context.Background(), now)
+
+			assert.Equal(t, tc.want, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+type LimitRepository struct {
+	db *sql.DB
+}
+
+func NewLimitRepository(db *sql.DB) *LimitRepository {
+	return &LimitRepository{
+		db: db,
+	}
+}
+
+// GetWalletLimits returns the limits overridden for the wallet, nil when it
+// has no override.
+func (r *LimitRepository) GetWalletLimits(ctx context.Context, walletId string) (*limits.Override, error) {
+	query := `
+		SELECT
+			max_deposit, max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance, hourly_transactions
+		FROM wallet_limits WHERE wallet_id = $1
+	`
+
+	override := &limits.Override{}
+	row := r.db.QueryRowContext(ctx, query, walletId)
+	if err := row.Scan(&override.MaxDeposit, &override.MaxWithdrawal, &override.DailyWithdrawal, &override.MonthlyWithdrawal, &override.MaxBalance, &override.HourlyTransactions); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// GetWithdrawnAmount sums what the customer sent out in currency since since:
+// the withdrawals in one of statuses, less what was refunded of them,
+// including the legs of their conversions, and the transfers in one of
+// statuses they made. Reversals are not withdrawals the customer asked for, so
+// they do not count.
+func (r *LimitRepository) GetWithdrawnAmount(ctx context.Context, customerXid, currency string, statuses []int, since time.Time) (decimal.Decimal, error) {
+	return getWithdrawnAmount(ctx, r.db, customerXid, currency, statuses, since)
+}
+
+// CountTransactions counts the deposits, withdrawals, transfers and
+// conversions the customer made in currency since since. A conversion counts
+// once, in the currency it is made from, rather than as its two legs; refunds
+// and reversals are not counted.
+func (r *LimitRepository) CountTransactions(ctx context.Context, customerXid, currency string, since time.Time) (int, error) {
+	return countTransactions(ctx, r.db, customerXid, currency, since)
+}
+
+// queryRower is what the usage of a wallet is read through: the database, or
+// the transaction holding the lock of the wallet.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func getWithdrawnAmount(ctx context.Context, q queryRower, customerXid, currency string, statuses []int, since time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(amount - refunded_amount), 0) FROM withdrawals
+				WHERE withdrawn_by = $1 AND currency = $2 AND withdrawn_at >= $3 AND status = ANY($4) AND reversal_of IS NULL) +
+			(SELECT COALESCE(SUM(amount), 0) FROM transfers
+				WHERE transferred_by = $1 AND currency = $2 AND transferred_at >= $3 AND status = ANY($4))
+	`
+
+	var amount decimal.Decimal
+	row := q.QueryRowContext(ctx, query, customerXid, currency, since, pq.Array(statuses))
+	if err := row.Scan(&amount); err != nil {
+		return decimal.Zero, err
+	}
+
+	return amount, nil
+}
+
+func countTransactions(ctx context.Context, q queryRower, customerXid, currency string, since time.Time) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM deposits
+				WHERE deposited_by = $1 AND currency = $2 AND deposited_at >= $3 AND conversion_id IS NULL AND refund_of IS NULL) +
+			(SELECT COUNT(*) FROM withdrawals
+				WHERE withdrawn_by = $1 AND currency = $2 AND withdrawn_at >= $3 AND conversion_id IS NULL AND reversal_of IS NULL) +
+			(SELECT COUNT(*) FROM transfers
+				WHERE transferred_by = $1 AND currency = $2 AND transferred_at >= $3) +
+			(SELECT COUNT(*) FROM conversions
+				WHERE converted_by = $1 AND from_currency = $2 AND converted_at >= $3)
+	`
+
+	var count int
+	row := q.QueryRowContext(ctx, query, customerXid, currency, since)
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// checkUsageLimits checks depositing, or when withdrawal is set sending out,
+// amount at now against the Limits of wallet that depend on its usage, once
+// tx holds the lock of wallet and of the other wallets of the transaction.
+// The usage the usecase read before the transaction does not count the
+// transactions made concurrently; read under the lock, it counts every one
+// committed before, so concurrent transactions can not together go over a
+// limit. The wallets are locked in id order, as transfers update them.
+func checkUsageLimits(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount decimal.Decimal, withdrawal bool, now time.Time, wallets ...*model.Wallet) error {
+	if wallet.Limits == nil || !wallet.Limits.UsageLimited(withdrawal) {
+		return nil
+	}
+
+	ids := make([]string, 0, len(wallets))
+	for _, w := range wallets {
+		ids = append(ids, w.ID)
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, balance FROM wallets WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	balance := wallet.Balance
+	for rows.Next() {
+		var id string
+		var b decimal.Decimal
+		if err := rows.Scan(&id, &b); err != nil {
+			return err
+		}
+		if id == wallet.ID {
+			balance = b
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	l := *wallet.Limits
+	day, month, hour := limits.Windows(now)
+	var usage limits.Usage
+	if withdrawal && l.DailyWithdrawal.IsPositive() {
+		usage.DailyWithdrawn, err = getWithdrawnAmount(ctx, tx, wallet.OwnedBy, wallet.Currency, wallet.WithdrawnStatuses, day)
+		if err != nil {
+			return err
+		}
+	}
+	if withdrawal && l.MonthlyWithdrawal.IsPositive() {
+		usage.MonthlyWithdrawn, err = getWithdrawnAmount(ctx, tx, wallet.OwnedBy, wallet.Currency, wallet.WithdrawnStatuses, month)
+		if err != nil {
+			return err
+		}
+	}
+	if l.HourlyTransactions > 0 {
+		usage.HourlyTransactions, err = countTransactions(ctx, tx, wallet.OwnedBy, wallet.Currency, hour)
+		if err != nil {
+			return err
+		}
+	}
+
+	if withdrawal {
+		return l.CheckWithdrawal(amount, usage)
+	}
+	return l.CheckDeposit(amount, balance, usage)
+}
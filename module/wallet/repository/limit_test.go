@@ -0,0 +1,238 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit_GetWalletLimits(t *testing.T) {
+	testCases := map[string]struct {
+		want    *limits.Override
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			want: &limits.Override{
+				MaxDeposit:         decimal.NullDecimal{Decimal: decimal.NewFromInt(1000000), Valid: true},
+				HourlyTransactions: sql.NullInt64{Int64: 10, Valid: true},
+			},
+			wantErr: false,
+		},
+		"success no override": {
+			want:    nil,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			want:    nil,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewLimitRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT max_deposit, max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance, hourly_transactions FROM wallet_limits WHERE wallet_id = $1")).
+				WithArgs(walletEnable.ID)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"max_deposit", "max_withdrawal", "daily_withdrawal", "monthly_withdrawal", "max_balance", "hourly_transactions"}).
+					AddRow("1000000", nil, nil, nil, nil, 10))
+			}
+
+			got, err := repo.GetWalletLimits(context.Background(), walletEnable.ID)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLimit_GetWithdrawnAmount(t *testing.T) {
+	testCases := map[string]struct {
+		want    decimal.Decimal
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			want:    decimal.NewFromInt(2500),
+			wantErr: false,
+		},
+		"failed query": {
+			want:    decimal.Zero,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewLimitRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT (SELECT COALESCE(SUM(amount - refunded_amount), 0) FROM withdrawals WHERE withdrawn_by = $1 AND currency = $2 AND withdrawn_at >= $3 AND status = ANY($4) AND reversal_of IS NULL) + (SELECT COALESCE(SUM(amount), 0) FROM transfers WHERE transferred_by = $1 AND currency = $2 AND transferred_at >= $3 AND status = ANY($4))")).
+				WithArgs(account.CustomerXid, "IDR", now, "{1,3}")
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("2500"))
+			}
+
+			got, err := repo.GetWithdrawnAmount(context.Background(), account.CustomerXid, "IDR", []int{1, 3}, now)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.True(t, tc.want.Equal(got), "amount = %s, want %s", got, tc.want)
+		})
+	}
+}
+
+func TestLimit_CountTransactions(t *testing.T) {
+	testCases := map[string]struct {
+		want    int
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			want:    4,
+			wantErr: false,
+		},
+		"failed query": {
+			want:    0,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewLimitRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT (SELECT COUNT(*) FROM deposits WHERE deposited_by = $1 AND currency = $2 AND deposited_at >= $3 AND conversion_id IS NULL AND refund_of IS NULL) + (SELECT COUNT(*) FROM withdrawals WHERE withdrawn_by = $1 AND currency = $2 AND withdrawn_at >= $3 AND conversion_id IS NULL AND reversal_of IS NULL) + (SELECT COUNT(*) FROM transfers WHERE transferred_by = $1 AND currency = $2 AND transferred_at >= $3) + (SELECT COUNT(*) FROM conversions WHERE converted_by = $1 AND from_currency = $2 AND converted_at >= $3)")).
+				WithArgs(account.CustomerXid, "IDR", now)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+			}
+
+			got, err := repo.CountTransactions(context.Background(), account.CustomerXid, "IDR", now)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLimit_CheckUsageLimitsUnderLock(t *testing.T) {
+	withdrawnQuery := "SELECT (SELECT COALESCE(SUM(amount - refunded_amount), 0) FROM withdrawals"
+	countQuery := "SELECT (SELECT COUNT(*) FROM deposits"
+
+	testCases := map[string]struct {
+		limits    limits.Limits
+		deposit   bool
+		usage     string
+		query     string
+		errLock   error
+		resultErr error
+	}{
+		"failed daily withdrawal": {
+			limits:    limits.Limits{DailyWithdrawal: decimal.NewFromInt(10000)},
+			usage:     "9500",
+			query:     withdrawnQuery,
+			resultErr: &limits.ExceededError{Limit: limits.DailyWithdrawal, Remaining: decimal.NewFromInt(500)},
+		},
+		"failed monthly withdrawal": {
+			limits:    limits.Limits{MonthlyWithdrawal: decimal.NewFromInt(10000)},
+			usage:     "10000",
+			query:     withdrawnQuery,
+			resultErr: &limits.ExceededError{Limit: limits.MonthlyWithdrawal, Remaining: decimal.Zero},
+		},
+		"failed hourly deposit": {
+			limits:    limits.Limits{HourlyTransactions: 4},
+			deposit:   true,
+			usage:     "4",
+			query:     countQuery,
+			resultErr: &limits.ExceededError{Limit: limits.HourlyTransactions, Remaining: decimal.Zero},
+		},
+		"failed lock": {
+			limits:    limits.Limits{DailyWithdrawal: decimal.NewFromInt(10000)},
+			errLock:   errors.New("database error"),
+			resultErr: errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+
+			sMock.ExpectBegin()
+			lock := sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ANY($1) ORDER BY id FOR UPDATE")).
+				WithArgs(`{"` + walletEnable.ID + `"}`)
+			if tc.errLock != nil {
+				lock.WillReturnError(tc.errLock)
+			} else {
+				lock.WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(walletEnable.ID, "10000"))
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WillReturnRows(sqlmock.NewRows([]string{"usage"}).AddRow(tc.usage))
+			}
+			sMock.ExpectRollback()
+
+			wallet := *walletEnable
+			wallet.Limits = &tc.limits
+			wallet.WithdrawnStatuses = []int{1, 3}
+
+			var err error
+			if tc.deposit {
+				_, err = repository.NewDepositRepository(db).CreateDeposit(context.Background(), deposit, &wallet)
+			} else {
+				_, err = repository.NewWithdrawalRepository(db).CreateWithdrawal(context.Background(), withdrawal, &wallet)
+			}
+
+			if want, ok := tc.resultErr.(*limits.ExceededError); ok {
+				got, ok := err.(*limits.ExceededError)
+				if assert.True(t, ok, "err = %v, want %v", err, want) {
+					assert.Equal(t, want.Limit, got.Limit)
+					assert.True(t, want.Remaining.Equal(got.Remaining), "remaining = %s, want %s", got.Remaining, want.Remaining)
+				}
+			} else {
+				assert.Equal(t, tc.resultErr, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+// firstWalletID sorts before every wallet id gen_random_uuid hands out.
+const firstWalletID = "00000000-0000-0000-0000-000000000000"
+
+type ReconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		db: db,
+	}
+}
+
+// GetWalletReconciliations reads up to limit wallets after afterID, in id
+// order, each with the balance its transactions of status add up to. Refunds
+// and the incoming side of conversions are deposits, and reversals, the
+// outgoing side of conversions and closure payouts are withdrawals, so the
+// expected balance is deposits plus incoming transfers, less withdrawals and
+// outgoing transfers, plus adjustments. An empty afterID starts from the first
+// wallet. Each row is read from one snapshot, so a transaction committing
+// meanwhile shows on both sides or on neither.
+func (r *ReconciliationRepository) GetWalletReconciliations(ctx context.Context, status int, afterID string, limit int) ([]*model.WalletReconciliation, error) {
+	if afterID == "" {
+		afterID = firstWalletID
+	}
+
+	query := `
+		SELECT
+			wallets.id, wallets.owned_by, wallets.currency, wallets.balance,
+			COALESCE((SELECT SUM(amount) FROM deposits
+				WHERE deposited_by = wallets.owned_by AND currency = wallets.currency AND status = $1), 0)
+			- COALESCE((SELECT SUM(amount) FROM withdrawals
+				WHERE withdrawn_by = wallets.owned_by AND currency = wallets.currency AND status = $1), 0)
+			+ COALESCE((SELECT SUM(amount) FROM transfers
+				WHERE transferred_to = wallets.owned_by AND currency = wallets.currency AND status = $1), 0)
+			- COALESCE((SELECT SUM(amount) FROM transfers
+				WHERE transferred_by = wallets.owned_by AND currency = wallets.currency AND status = $1), 0)
+			+ COALESCE((SELECT SUM(amount) FROM adjustments WHERE wallet_id = wallets.id), 0)
+		FROM wallets WHERE wallets.id > $2 ORDER BY wallets.id LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reconciliations := []*model.WalletReconciliation{}
+	for rows.Next() {
+		reconciliation := &model.WalletReconciliation{}
+		if err := rows.Scan(&reconciliation.WalletID, &reconciliation.CustomerXid, &reconciliation.Currency, &reconciliation.Balance, &reconciliation.ExpectedBalance); err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reconciliations, nil
+}
+
+// CreateBalanceDiscrepancies records the discrepancies of a reconciliation,
+// all of them or none.
+func (r *ReconciliationRepository) CreateBalanceDiscrepancies(ctx context.Context, discrepancies []*model.BalanceDiscrepancy) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, discrepancy := range discrepancies {
+		_, err = tx.ExecContext(ctx, `INSERT INTO
+			balance_discrepancies (wallet_id, customer_xid, currency, balance, expected_balance, drift, reconciled_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, discrepancy.WalletID, discrepancy.CustomerXid, discrepancy.Currency, discrepancy.Balance, discrepancy.ExpectedBalance, discrepancy.Drift, discrepancy.ReconciledAt)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
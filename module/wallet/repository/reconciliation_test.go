@@ -0,0 +1,192 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const reconciliationQuery = "FROM wallets WHERE wallets.id > $2 ORDER BY wallets.id LIMIT $3"
+
+func TestReconciliation_GetWalletReconciliations(t *testing.T) {
+	testCases := map[string]struct {
+		afterID     string
+		wantAfterID string
+		want        []*model.WalletReconciliation
+		wantErr     bool
+		wantErrScan bool
+		err         error
+	}{
+		"success from the first wallet": {
+			afterID:     "",
+			wantAfterID: "00000000-0000-0000-0000-000000000000",
+			want: []*model.WalletReconciliation{
+				{
+					WalletID:        walletEnable.ID,
+					CustomerXid:     walletEnable.OwnedBy,
+					Currency:        "IDR",
+					Balance:         decimal.NewFromInt(10000),
+					ExpectedBalance: decimal.NewFromInt(9000),
+				},
+			},
+			wantErr: false,
+		},
+		"success after a wallet": {
+			afterID:     walletEnable.ID,
+			wantAfterID: walletEnable.ID,
+			want:        []*model.WalletReconciliation{},
+			wantErr:     false,
+		},
+		"failed query": {
+			afterID:     walletEnable.ID,
+			wantAfterID: walletEnable.ID,
+			wantErr:     true,
+			err:         errors.New("database error"),
+		},
+		"failed scan": {
+			afterID:     walletEnable.ID,
+			wantAfterID: walletEnable.ID,
+			wantErr:     true,
+			wantErrScan: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewReconciliationRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta(reconciliationQuery)).WithArgs(1, tc.wantAfterID, 500)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else if tc.wantErrScan {
+				query.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(walletEnable.ID))
+			} else {
+				rows := sMock.NewRows([]string{"id", "owned_by", "currency", "balance", "expected_balance"})
+				for _, r := range tc.want {
+					rows.AddRow(r.WalletID, r.CustomerXid, r.Currency, r.Balance, r.ExpectedBalance)
+				}
+				query.WillReturnRows(rows)
+			}
+
+			result, err := repo.GetWalletReconciliations(context.Background(), 1, tc.afterID, 500)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.want, result)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReconciliation_CreateBalanceDiscrepancies(t *testing.T) {
+	reconciledAt := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	discrepancies := []*model.BalanceDiscrepancy{
+		{
+			WalletReconciliation: model.WalletReconciliation{
+				WalletID:        walletEnable.ID,
+				CustomerXid:     walletEnable.OwnedBy,
+				Currency:        "IDR",
+				Balance:         decimal.NewFromInt(10000),
+				ExpectedBalance: decimal.NewFromInt(9000),
+			},
+			Drift:        decimal.NewFromInt(1000),
+			ReconciledAt: reconciledAt,
+		},
+		{
+			WalletReconciliation: model.WalletReconciliation{
+				WalletID:        walletDisable.ID,
+				CustomerXid:     walletDisable.OwnedBy,
+				Currency:        "IDR",
+				Balance:         decimal.Zero,
+				ExpectedBalance: decimal.NewFromInt(500),
+			},
+			Drift:        decimal.NewFromInt(-500),
+			ReconciledAt: reconciledAt,
+		},
+	}
+	testCases := map[string]struct {
+		wantErr       bool
+		wantErrTx     bool
+		wantErrInsert bool
+		wantErrCommit bool
+		err           error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed insert": {
+			wantErr:       true,
+			wantErrInsert: true,
+			err:           errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewReconciliationRepository(db)
+
+			insert := regexp.QuoteMeta("INSERT INTO balance_discrepancies (wallet_id, customer_xid, currency, balance, expected_balance, drift, reconciled_at) VALUES ($1, $2, $3, $4, $5, $6, $7)")
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				d := discrepancies[0]
+				sMock.ExpectExec(insert).
+					WithArgs(d.WalletID, d.CustomerXid, d.Currency, d.Balance, d.ExpectedBalance, d.Drift, d.ReconciledAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				d = discrepancies[1]
+				exec := sMock.ExpectExec(insert).
+					WithArgs(d.WalletID, d.CustomerXid, d.Currency, d.Balance, d.ExpectedBalance, d.Drift, d.ReconciledAt)
+				if tc.wantErrInsert {
+					exec.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, 1))
+					if tc.wantErrCommit {
+						sMock.ExpectCommit().WillReturnError(tc.err)
+					} else {
+						sMock.ExpectCommit()
+					}
+				}
+			}
+
+			err := repo.CreateBalanceDiscrepancies(context.Background(), discrepancies)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
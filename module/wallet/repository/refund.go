@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+)
+
+// reversalOfKey is the unique index allowing a deposit one reversal.
+const reversalOfKey = "withdrawals_reversal_of_key"
+
+type RefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) *RefundRepository {
+	return &RefundRepository{
+		db: db,
+	}
+}
+
+// RefundWithdrawal records refund, a deposit pointing at the withdrawal, adds
+// its amount to the refunded amount of the withdrawal and to the wallet
+// balance, all in one transaction.
+func (r *RefundRepository) RefundWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, refund *model.Deposit, wallet *model.Wallet) (*model.Deposit, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		deposits (deposited_by, status, deposited_at, amount, reference_id, currency, refund_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, refund.DepositedBy, refund.Status, refund.DepositedAt, refund.Amount, refund.ReferenceID, refund.Currency, withdrawal.ID)
+	err = row.Scan(&refund.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// The check and the increment are one statement, which takes the row
+	// lock, so parallel refunds can not together refund more than the
+	// withdrawal took.
+	row = tx.QueryRowContext(ctx, `UPDATE withdrawals
+		SET refunded_amount = refunded_amount + $1 WHERE id = $2 AND status = $3 AND amount - refunded_amount >= $1 RETURNING refunded_amount`, refund.Amount, withdrawal.ID, withdrawal.Status)
+	err = row.Scan(&withdrawal.RefundedAmount)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, commonerr.ErrRefundExceedsAmount
+		}
+		return nil, err
+	}
+
+	row = tx.QueryRowContext(ctx, `UPDATE wallets
+		SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance, held_balance`, refund.Amount, wallet.ID, wallet.Status, wallet.MaxBalance)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletCreditError(ctx, tx, wallet)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewRefundEntry(wallet.ID, refund.ID, refund.Currency, refund.Amount, refund.DepositedAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Sub(refund.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalRefunded, audit.ResourceWithdrawal, withdrawal.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	refund.RefundOf = withdrawal.ID
+	return refund, nil
+}
+
+// ReverseDeposit records reversal, a withdrawal pointing at the deposit, and
+// takes the amount of the deposit back off the wallet balance, all in one
+// transaction.
+func (r *RefundRepository) ReverseDeposit(ctx context.Context, deposit *model.Deposit, reversal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, reversal_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, reversal.WithdrawnBy, reversal.Status, reversal.WithdrawnAt, reversal.Amount, reversal.ReferenceID, reversal.Currency, deposit.ID)
+	err = row.Scan(&reversal.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolationOf(err, reversalOfKey) {
+			return nil, commonerr.ErrDepositAlreadyReversed
+		}
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// The money of the deposit may be spent or held by now, in which case
+	// there is nothing left to reverse.
+	row = tx.QueryRowContext(ctx, `UPDATE wallets
+		SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance`, reversal.Amount, wallet.ID, wallet.Status)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletUpdateError(ctx, tx, wallet, commonerr.ErrBalanceNotEnough)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewReversalEntry(wallet.ID, reversal.ID, reversal.Currency, reversal.Amount, reversal.WithdrawnAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Add(reversal.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionDepositReversed, audit.ResourceDeposit, deposit.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	reversal.ReversalOf = deposit.ID
+	return reversal, nil
+}
@@ -0,0 +1,344 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefund_RefundWithdrawal(t *testing.T) {
+	refund := &model.Deposit{
+		ID:          "2d9e4f6a-8b1c-4d3e-a5f7-9b0c1d2e3f4a",
+		DepositedBy: withdrawal.WithdrawnBy,
+		Status:      1,
+		DepositedAt: now,
+		Amount:      decimal.NewFromInt(400),
+		ReferenceID: "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+		Currency:    "IDR",
+	}
+	balance := walletEnable.Balance.Add(refund.Amount)
+
+	testCases := map[string]struct {
+		wantErr           bool
+		wantErrTx         bool
+		wantErrDeposit    bool
+		wantErrWithdrawal bool
+		wantErrWallet     bool
+		wantErrMismatch   bool
+		wantErrAudit      bool
+		wantErrCommit     bool
+		walletStatus      int
+		err               error
+		resultErr         error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed reference id used": {
+			wantErr:        true,
+			wantErrDeposit: true,
+			err:            &pq.Error{Code: "23505"},
+			resultErr:      commonerr.ErrReferenceIdUsed,
+		},
+		"failed deposit": {
+			wantErr:        true,
+			wantErrDeposit: true,
+			err:            errors.New("database error"),
+		},
+		"failed refund exceeds amount": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               sql.ErrNoRows,
+			resultErr:         commonerr.ErrRefundExceedsAmount,
+		},
+		"failed withdrawal": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               errors.New("database error"),
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed wallet closed": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  4,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed ledger mismatch": {
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewRefundRepository(db)
+			original := *withdrawal
+			wallet := *walletEnable
+			req := *refund
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO deposits (deposited_by, status, deposited_at, amount, reference_id, currency, refund_of) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+					WithArgs(refund.DepositedBy, refund.Status, refund.DepositedAt, refund.Amount, refund.ReferenceID, refund.Currency, original.ID)
+				if tc.wantErrDeposit {
+					insert.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(refund.ID))
+					refunded := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE withdrawals SET refunded_amount = refunded_amount + $1 WHERE id = $2 AND status = $3 AND amount - refunded_amount >= $1 RETURNING refunded_amount")).
+						WithArgs(refund.Amount, original.ID, original.Status)
+					if tc.wantErrWithdrawal {
+						refunded.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+					} else {
+						refunded.WillReturnRows(sMock.NewRows([]string{"refunded_amount"}).AddRow(refund.Amount))
+						credit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance, held_balance")).
+							WithArgs(refund.Amount, wallet.ID, wallet.Status, wallet.MaxBalance)
+						if tc.wantErrWallet {
+							credit.WillReturnError(tc.err)
+							if tc.err == sql.ErrNoRows {
+								expectWalletBalance(sMock, wallet.ID, tc.walletStatus, wallet.Balance)
+							}
+							sMock.ExpectRollback()
+						} else {
+							credit.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(balance, walletEnable.HeldBalance))
+							if tc.wantErrMismatch {
+								expectLedgerEntry(sMock, walletEnable.Balance)
+								sMock.ExpectRollback()
+							} else if tc.wantErrAudit {
+								expectLedgerEntry(sMock, balance)
+								expectAuditEvent(sMock, tc.err)
+								sMock.ExpectRollback()
+							} else {
+								expectLedgerEntry(sMock, balance)
+								expectAuditEvent(sMock, nil)
+								if tc.wantErrCommit {
+									sMock.ExpectCommit().WillReturnError(tc.err)
+								} else {
+									sMock.ExpectCommit()
+								}
+							}
+						}
+					}
+				}
+			}
+
+			result, err := repo.RefundWithdrawal(context.Background(), &original, &req, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				if !assert.Nil(t, err) {
+					return
+				}
+				want := *refund
+				want.RefundOf = original.ID
+				if !reflect.DeepEqual(result, &want) {
+					t.Errorf("Refund.RefundWithdrawal() = %v, want %v", result, &want)
+				}
+				assert.True(t, original.RefundedAmount.Equal(refund.Amount))
+				assert.True(t, wallet.Balance.Equal(balance))
+			}
+		})
+	}
+}
+
+func TestRefund_ReverseDeposit(t *testing.T) {
+	reversal := &model.Withdrawal{
+		ID:          "3e0f5a7b-9c2d-4e4f-b6a8-0c1d2e3f4a5b",
+		WithdrawnBy: deposit.DepositedBy,
+		Status:      1,
+		WithdrawnAt: now,
+		Amount:      deposit.Amount,
+		ReferenceID: "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+		Currency:    "IDR",
+	}
+	balance := walletEnable.Balance.Sub(reversal.Amount)
+
+	testCases := map[string]struct {
+		wantErr           bool
+		wantErrTx         bool
+		wantErrWithdrawal bool
+		wantErrWallet     bool
+		wantErrMismatch   bool
+		wantErrAudit      bool
+		wantErrCommit     bool
+		walletStatus      int
+		err               error
+		resultErr         error
+	}{
+		"success": {
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed already reversed": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               &pq.Error{Code: "23505", Constraint: "withdrawals_reversal_of_key"},
+			resultErr:         commonerr.ErrDepositAlreadyReversed,
+		},
+		"failed reference id used": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               &pq.Error{Code: "23505", Constraint: "withdrawals_withdrawn_by_reference_id_key"},
+			resultErr:         commonerr.ErrReferenceIdUsed,
+		},
+		"failed withdrawal": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               errors.New("database error"),
+		},
+		"failed balance not enough": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  1,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrBalanceNotEnough,
+		},
+		"failed wallet frozen": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  3,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed ledger mismatch": {
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewRefundRepository(db)
+			wallet := *walletEnable
+			req := *reversal
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, reversal_of) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+					WithArgs(reversal.WithdrawnBy, reversal.Status, reversal.WithdrawnAt, reversal.Amount, reversal.ReferenceID, reversal.Currency, deposit.ID)
+				if tc.wantErrWithdrawal {
+					insert.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(reversal.ID))
+					debit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance")).
+						WithArgs(reversal.Amount, wallet.ID, wallet.Status)
+					if tc.wantErrWallet {
+						debit.WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, wallet.ID, tc.walletStatus)
+						}
+						sMock.ExpectRollback()
+					} else {
+						debit.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(balance, walletEnable.HeldBalance))
+						if tc.wantErrMismatch {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							sMock.ExpectRollback()
+						} else if tc.wantErrAudit {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, nil)
+							if tc.wantErrCommit {
+								sMock.ExpectCommit().WillReturnError(tc.err)
+							} else {
+								sMock.ExpectCommit()
+							}
+						}
+					}
+				}
+			}
+
+			result, err := repo.ReverseDeposit(context.Background(), deposit, &req, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				if !assert.Nil(t, err) {
+					return
+				}
+				want := *reversal
+				want.ReversalOf = deposit.ID
+				if !reflect.DeepEqual(result, &want) {
+					t.Errorf("Refund.ReverseDeposit() = %v, want %v", result, &want)
+				}
+				assert.True(t, wallet.Balance.Equal(balance))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type TokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+// RevokeToken records the token as revoked. Revoking the same token twice is
+// not an error; it reports false when the token was already revoked.
+func (r *TokenRepository) RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO
+		revoked_tokens (jti, customer_xid, expires_at)
+		VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`, jti, customerXid, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// PurgeRevokedTokens deletes the revocations of the tokens that expired
+// before now, which no longer need to be rejected, and returns how many it
+// deleted.
+func (r *TokenRepository) PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *TokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `
+		SELECT
+			jti
+		FROM revoked_tokens WHERE jti = $1
+	`
+
+	row := r.db.QueryRowContext(ctx, query, jti)
+	if err := row.Scan(&jti); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	jti = "4f1c2a9b8e7d6c5b4a3f2e1d0c9b8a7f"
+)
+
+func TestToken_RevokeToken(t *testing.T) {
+	testCases := map[string]struct {
+		affected int64
+		want     bool
+		wantErr  bool
+		err      error
+	}{
+		"success": {
+			affected: 1,
+			want:     true,
+			wantErr:  false,
+		},
+		"success already revoked": {
+			affected: 0,
+			want:     false,
+			wantErr:  false,
+		},
+		"failed exec": {
+			want:    false,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTokenRepository(db)
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO revoked_tokens (jti, customer_xid, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING")).
+				WithArgs(jti, account.CustomerXid, now)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tc.affected))
+			}
+
+			result, err := repo.RevokeToken(context.Background(), jti, account.CustomerXid, now)
+
+			assert.Equal(t, tc.want, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestToken_IsTokenRevoked(t *testing.T) {
+	testCases := map[string]struct {
+		want    bool
+		wantErr bool
+		err     error
+	}{
+		"success revoked": {
+			want:    true,
+			wantErr: false,
+		},
+		"success not revoked": {
+			want:    false,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			want:    false,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTokenRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT jti FROM revoked_tokens WHERE jti = $1")).
+				WithArgs(jti)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"jti"}).AddRow(jti))
+			}
+
+			result, err := repo.IsTokenRevoked(context.Background(), jti)
+
+			assert.Equal(t, tc.want, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestToken_PurgeRevokedTokens(t *testing.T) {
+	testCases := map[string]struct {
+		want    int64
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			want:    2,
+			wantErr: false,
+		},
+		"failed exec": {
+			want:    0,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTokenRepository(db)
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("DELETE FROM revoked_tokens WHERE expires_at < $1")).
+				WithArgs(now)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tc.want))
+			}
+
+			result, err := repo.PurgeRevokedTokens(context.Background(), now)
+
+			assert.Equal(t, tc.want, result)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate key,
+// such as a reference id the customer already used.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// isUniqueViolationOf reports whether err is Postgres rejecting a duplicate
+// key of the named constraint or unique index.
+func isUniqueViolationOf(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == constraint
+}
+
+// transactionFilterClause appends the currency, date range, cursor, ordering
+// and limit of filter to a query already filtered by owner. timeColumn is the
+// table specific timestamp column, e.g. deposited_at.
+func transactionFilterClause(filter model.TransactionFilter, timeColumn string, args []interface{}) (string, []interface{}) {
+	var clause string
+	if filter.Currency != "" {
+		args = append(args, filter.Currency)
+		clause += fmt.Sprintf(" AND currency = $%d", len(args))
+	}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		clause += fmt.Sprintf(" AND %s >= $%d", timeColumn, len(args))
+	}
+
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		clause += fmt.Sprintf(" AND %s <= $%d", timeColumn, len(args))
+	}
+
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.TransactedAt, filter.Cursor.ID)
+		clause += fmt.Sprintf(" AND (%s, id) < ($%d, $%d::uuid)", timeColumn, len(args)-1, len(args))
+	}
+
+	args = append(args, filter.Limit)
+	clause += fmt.Sprintf(" ORDER BY %s DESC, id DESC LIMIT $%d", timeColumn, len(args))
+
+	return clause, args
+}
+
+// verifyWalletBalance checks the wallet balance against the ledger, logging
+// any mismatch.
+func verifyWalletBalance(ctx context.Context, tx *sql.Tx, wallet *model.Wallet) error {
+	err := ledger.VerifyWalletBalance(ctx, tx, wallet.ID, wallet.Balance)
+	if err == ledger.ErrBalanceMismatch {
+		logger.FromContext(ctx).Error("ledger does not match wallet balance", "wallet_id", wallet.ID, "balance", wallet.Balance.String())
+	}
+
+	return err
+}
+
+// walletUpdateError explains why an update of wallet, guarded by the status the
+// wallet was read in, matched no row. When the wallet has moved to another
+// status since, that status is set on wallet and ErrWalletTransition returned.
+// Otherwise the update failed its own condition and otherwise is returned,
+// such as ErrBalanceNotEnough for a debit.
+func walletUpdateError(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, otherwise error) error {
+	var status int
+	err := tx.QueryRowContext(ctx, `SELECT status FROM wallets WHERE id = $1`, wallet.ID).Scan(&status)
+	if err != nil {
+		return err
+	}
+
+	if status != wallet.Status {
+		wallet.Status = status
+		return commonerr.ErrWalletTransition
+	}
+
+	return otherwise
+}
+
+// walletCreditError explains why a credit of wallet, guarded by the status the
+// wallet was read in and by its MaxBalance, matched no row: either the wallet
+// moved to another status, as for walletUpdateError, or the credit would take
+// it over MaxBalance, which a limits.ExceededError reports.
+func walletCreditError(ctx context.Context, tx *sql.Tx, wallet *model.Wallet) error {
+	var status int
+	var balance decimal.Decimal
+	err := tx.QueryRowContext(ctx, `SELECT status, balance FROM wallets WHERE id = $1`, wallet.ID).Scan(&status, &balance)
+	if err != nil {
+		return err
+	}
+
+	if status != wallet.Status {
+		wallet.Status = status
+		return commonerr.ErrWalletTransition
+	}
+
+	remaining := wallet.MaxBalance.Sub(balance)
+	if remaining.IsNegative() {
+		remaining = decimal.Zero
+	}
+
+	return &limits.ExceededError{Limit: limits.MaxBalance, Remaining: remaining}
+}
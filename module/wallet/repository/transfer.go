@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{
+		db: db,
+	}
+}
+
+func (r *TransferRepository) GetTransferByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Transfer, error) {
+	query := `
+		SELECT
+			id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency
+		FROM transfers WHERE transferred_by = $1 AND reference_id = $2
+	`
+
+	transfer := &model.Transfer{}
+	row := r.db.QueryRowContext(ctx, query, customerXid, referenceId)
+	if err := row.Scan(&transfer.ID, &transfer.TransferredBy, &transfer.TransferredTo, &transfer.Status, &transfer.TransferredAt, &transfer.Amount, &transfer.ReferenceID, &transfer.Currency); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// GetTransfersByCustomerXid returns transfers sent or received by the customer.
+// A transfer_in or transfer_out filter type narrows it to one direction.
+func (r *TransferRepository) GetTransfersByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Transfer, error) {
+	owner := "(transferred_by = $1 OR transferred_to = $1)"
+	switch filter.Type {
+	case model.TransactionTypeTransferIn:
+		owner = "transferred_to = $1"
+	case model.TransactionTypeTransferOut:
+		owner = "transferred_by = $1"
+	}
+
+	query := `
+		SELECT
+			id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency
+		FROM transfers WHERE ` + owner + `
+	`
+	clause, args := transactionFilterClause(filter, "transferred_at", []interface{}{customerXid})
+
+	rows, err := r.db.QueryContext(ctx, query+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []*model.Transfer{}
+	for rows.Next() {
+		transfer := &model.Transfer{}
+		if err := rows.Scan(&transfer.ID, &transfer.TransferredBy, &transfer.TransferredTo, &transfer.Status, &transfer.TransferredAt, &transfer.Amount, &transfer.ReferenceID, &transfer.Currency); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (r *TransferRepository) CreateTransfer(ctx context.Context, transfer *model.Transfer, sender *model.Wallet, recipient *model.Wallet) (*model.Transfer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkUsageLimits(ctx, tx, sender, transfer.Amount, true, transfer.TransferredAt, sender, recipient)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		transfers (transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, transfer.TransferredBy, transfer.TransferredTo, transfer.Status, transfer.TransferredAt, transfer.Amount, transfer.ReferenceID, transfer.Currency)
+	err = row.Scan(&transfer.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// Both wallet rows are locked in id order, so two opposite transfers between
+	// the same wallets can not deadlock each other.
+	debit := func() error {
+		row := tx.QueryRowContext(ctx, `UPDATE wallets
+			SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance`, transfer.Amount, sender.ID, sender.Status)
+		err := row.Scan(&sender.Balance)
+		if err == sql.ErrNoRows {
+			return walletUpdateError(ctx, tx, sender, commonerr.ErrBalanceNotEnough)
+		}
+		return err
+	}
+	credit := func() error {
+		row := tx.QueryRowContext(ctx, `UPDATE wallets
+			SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance`, transfer.Amount, recipient.ID, recipient.Status, recipient.MaxBalance)
+		err := row.Scan(&recipient.Balance)
+		if err == sql.ErrNoRows {
+			return walletCreditError(ctx, tx, recipient)
+		}
+		return err
+	}
+
+	updates := []func() error{debit, credit}
+	if recipient.ID < sender.ID {
+		updates = []func() error{credit, debit}
+	}
+	for _, update := range updates {
+		err = update()
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewTransferEntry(sender.ID, recipient.ID, transfer.ID, transfer.Currency, transfer.Amount, transfer.TransferredAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	for _, wallet := range []*model.Wallet{sender, recipient} {
+		err = verifyWalletBalance(ctx, tx, wallet)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Each wallet gets its own event, sender first, so the chain shows both
+	// sides of the move.
+	senderBefore := audit.StateOf(sender)
+	senderBefore.Balance = sender.Balance.Add(transfer.Amount)
+	recipientBefore := audit.StateOf(recipient)
+	recipientBefore.Balance = recipient.Balance.Sub(transfer.Amount)
+	events := []*model.AuditEvent{
+		audit.NewWalletEvent(audit.ActionTransferCreated, audit.ResourceTransfer, transfer.ID, sender, senderBefore),
+		audit.NewWalletEvent(audit.ActionTransferCreated, audit.ResourceTransfer, transfer.ID, recipient, recipientBefore),
+	}
+	for _, event := range events {
+		err = audit.Record(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for _, event := range webhook.NewTransferEvents(transfer, sender, recipient) {
+		err = webhook.Enqueue(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return transfer, nil
+}
@@ -0,0 +1,357 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	transfer = &model.Transfer{
+		ID:            "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+		TransferredBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		TransferredTo: "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11",
+		Status:        1,
+		TransferredAt: now,
+		Amount:        decimal.NewFromInt(1000),
+		ReferenceID:   "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+		Currency:      "IDR",
+	}
+	walletRecipient = &model.Wallet{
+		ID:        "f3c2b1a0-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		OwnedBy:   "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(500),
+	}
+)
+
+func TestTransfer_GetTransferByReferenceId(t *testing.T) {
+	testCases := map[string]struct {
+		req     string
+		result  *model.Transfer
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			req:     transfer.ReferenceID,
+			result:  transfer,
+			wantErr: false,
+		},
+		"failed scan": {
+			req:     transfer.ReferenceID,
+			wantErr: true,
+		},
+		"failed sql no row": {
+			req:     transfer.ReferenceID,
+			wantErr: true,
+			err:     sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTransferRepository(db)
+
+			column := []string{"id", "transferred_by", "transferred_to", "status", "transferred_at", "amount", "reference_id", "currency"}
+			row := sMock.NewRows(column).AddRow(transfer.ID, transfer.TransferredBy, transfer.TransferredTo, transfer.Status, transfer.TransferredAt, transfer.Amount, transfer.ReferenceID, transfer.Currency)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE transferred_by = $1 AND reference_id = $2")).
+					WithArgs(transfer.TransferredBy, tc.req).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(transfer.ID, transfer.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE transferred_by = $1 AND reference_id = $2")).
+					WithArgs(transfer.TransferredBy, tc.req).WillReturnRows(row)
+			}
+
+			result, _ := repo.GetTransferByReferenceId(context.Background(), transfer.TransferredBy, tc.req)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Transfer.GetTransferByReferenceId() = %v, want %v", result, tc.result)
+			}
+		})
+	}
+}
+
+func TestTransfer_GetTransfersByCustomerXid(t *testing.T) {
+	testCases := map[string]struct {
+		filter  model.TransactionFilter
+		query   string
+		args    []driver.Value
+		result  []*model.Transfer
+		wantErr bool
+		err     error
+	}{
+		"success both directions": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE (transferred_by = $1 OR transferred_to = $1) ORDER BY transferred_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{transfer.TransferredBy, 11},
+			result:  []*model.Transfer{transfer},
+			wantErr: false,
+		},
+		"success transfer in": {
+			filter:  model.TransactionFilter{Type: model.TransactionTypeTransferIn, Limit: 11},
+			query:   "SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE transferred_to = $1 ORDER BY transferred_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{transfer.TransferredBy, 11},
+			result:  []*model.Transfer{transfer},
+			wantErr: false,
+		},
+		"success transfer out": {
+			filter:  model.TransactionFilter{Type: model.TransactionTypeTransferOut, Limit: 11},
+			query:   "SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE transferred_by = $1 ORDER BY transferred_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{transfer.TransferredBy, 11},
+			result:  []*model.Transfer{transfer},
+			wantErr: false,
+		},
+		"failed query": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE (transferred_by = $1 OR transferred_to = $1) ORDER BY transferred_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{transfer.TransferredBy, 11},
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+		"failed scan": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency FROM transfers WHERE (transferred_by = $1 OR transferred_to = $1) ORDER BY transferred_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{transfer.TransferredBy, 11},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTransferRepository(db)
+
+			column := []string{"id", "transferred_by", "transferred_to", "status", "transferred_at", "amount", "reference_id", "currency"}
+			row := sMock.NewRows(column).AddRow(transfer.ID, transfer.TransferredBy, transfer.TransferredTo, transfer.Status, transfer.TransferredAt, transfer.Amount, transfer.ReferenceID, transfer.Currency)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(transfer.ID, transfer.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnRows(row)
+			}
+
+			result, err := repo.GetTransfersByCustomerXid(context.Background(), transfer.TransferredBy, tc.filter)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Transfer.GetTransfersByCustomerXid() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestTransfer_CreateTransfer(t *testing.T) {
+	testCases := map[string]struct {
+		result          *model.Transfer
+		wantErr         bool
+		wantErrTx       bool
+		wantErrTransfer bool
+		wantErrDebit    bool
+		wantErrCredit   bool
+		wantErrAudit    bool
+		wantErrWebhook  bool
+		wantErrCommit   bool
+		walletStatus    int
+		err             error
+		resultErr       error
+	}{
+		"success": {
+			result:  transfer,
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed transfer": {
+			wantErr:         true,
+			wantErrTransfer: true,
+			err:             errors.New("database error"),
+		},
+		"failed reference id used": {
+			wantErr:         true,
+			wantErrTransfer: true,
+			err:             &pq.Error{Code: "23505"},
+			resultErr:       commonerr.ErrReferenceIdUsed,
+		},
+		"failed balance not enough": {
+			wantErr:      true,
+			wantErrDebit: true,
+			walletStatus: 1,
+			err:          sql.ErrNoRows,
+			resultErr:    commonerr.ErrBalanceNotEnough,
+		},
+		"failed sender frozen": {
+			wantErr:      true,
+			wantErrDebit: true,
+			walletStatus: 3,
+			err:          sql.ErrNoRows,
+			resultErr:    commonerr.ErrWalletTransition,
+		},
+		"failed credit": {
+			wantErr:       true,
+			wantErrCredit: true,
+			err:           errors.New("database error"),
+		},
+		"failed recipient disabled": {
+			wantErr:       true,
+			wantErrCredit: true,
+			walletStatus:  2,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed webhook": {
+			wantErr:        true,
+			wantErrWebhook: true,
+			err:            errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewTransferRepository(db)
+			var events []*model.AuditEvent
+			sender := *walletEnable
+			recipient := *walletRecipient
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO transfers (transferred_by, transferred_to, status, transferred_at, amount, reference_id, currency) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+					WithArgs(transfer.TransferredBy, transfer.TransferredTo, transfer.Status, transfer.TransferredAt, transfer.Amount, transfer.ReferenceID, transfer.Currency)
+				if tc.wantErrTransfer {
+					insert.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(transfer.ID))
+					// The sender wallet id sorts first, so it is debited first.
+					debit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance")).
+						WithArgs(transfer.Amount, sender.ID, sender.Status)
+					if tc.wantErrDebit {
+						debit.WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, sender.ID, tc.walletStatus)
+						}
+						sMock.ExpectRollback()
+					} else {
+						debit.WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(decimal.NewFromInt(9000)))
+						credit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND status = $3 AND ($4::numeric = 0 OR balance + $1 <= $4) RETURNING balance")).
+							WithArgs(transfer.Amount, recipient.ID, recipient.Status, recipient.MaxBalance)
+						if tc.wantErrCredit {
+							credit.WillReturnError(tc.err)
+							if tc.err == sql.ErrNoRows {
+								expectWalletBalance(sMock, recipient.ID, tc.walletStatus, recipient.Balance)
+							}
+							sMock.ExpectRollback()
+						} else {
+							credit.WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(decimal.NewFromInt(1500)))
+							expectLedgerEntry(sMock, decimal.NewFromInt(9000))
+							sMock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0) FROM ledger_postings WHERE account_type = $1 AND account_id = $2")).
+								WillReturnRows(sMock.NewRows([]string{"balance"}).AddRow(decimal.NewFromInt(1500)))
+							if tc.wantErrAudit {
+								expectAuditEvent(sMock, tc.err)
+								sMock.ExpectRollback()
+							} else {
+								events = captureAuditEvents(sMock, auditEvent.ChainSeq, auditEvent.Hash, 2)
+								if tc.wantErrWebhook {
+									expectWebhookEvent(sMock, tc.err)
+									sMock.ExpectRollback()
+								} else if tc.wantErrCommit {
+									expectWebhookEvent(sMock, nil)
+									expectWebhookEvent(sMock, nil)
+									sMock.ExpectCommit().WillReturnError(tc.err)
+								} else {
+									expectWebhookEvent(sMock, nil)
+									expectWebhookEvent(sMock, nil)
+									sMock.ExpectCommit()
+								}
+							}
+						}
+					}
+				}
+			}
+
+			data := *transfer
+			result, err := repo.CreateTransfer(context.Background(), &data, &sender, &recipient)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Transfer.CreateTransfer() = %v, want %v", result, tc.result)
+			}
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+			if tc.wantErrDebit && tc.walletStatus != 0 {
+				assert.Equal(t, tc.walletStatus, sender.Status)
+			}
+			if tc.wantErrCredit && tc.walletStatus != 0 {
+				assert.Equal(t, tc.walletStatus, recipient.Status)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.True(t, decimal.NewFromInt(9000).Equal(sender.Balance))
+				assert.True(t, decimal.NewFromInt(1500).Equal(recipient.Balance))
+				assert.True(t, verifyAuditEvents(auditEvent.ChainSeq, auditEvent.Hash, events))
+				assert.Equal(t, []string{sender.ID, recipient.ID}, []string{events[0].WalletID, events[1].WalletID})
+				assert.Equal(t, audit.ActionTransferCreated, events[0].Action)
+				assert.JSONEq(t, `{"status":1,"balance":"10000","held_balance":"0"}`, string(events[0].Before))
+				assert.JSONEq(t, `{"status":1,"balance":"9000","held_balance":"0"}`, string(events[0].After))
+				assert.JSONEq(t, `{"status":1,"balance":"500","held_balance":"0"}`, string(events[1].Before))
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
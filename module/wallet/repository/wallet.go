@@ -3,8 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 )
 
 type WalletRepository struct {
@@ -17,39 +22,326 @@ func NewWalletRepository(db *sql.DB) *WalletRepository {
 	}
 }
 
+// CreateWallet writes the wallet, its move out of the pending status and the
+// audit event of its creation.
 func (r *WalletRepository) CreateWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error) {
-	row := r.db.QueryRowContext(ctx, `INSERT INTO
-		wallets (owned_by, status, enabled_at, balance) 
-		VALUES ($1, $2, $3, $4) RETURNING id`, wallet.OwnedBy, wallet.Status, wallet.EnabledAt, wallet.Balance)
-	err := row.Scan(&wallet.ID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		wallets (owned_by, status, enabled_at, balance, currency) 
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`, wallet.OwnedBy, wallet.Status, wallet.EnabledAt, wallet.Balance, wallet.Currency)
+	err = row.Scan(&wallet.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrWalletAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	// Status 0 is pending, which a wallet is in until it is written.
+	err = insertStatusChange(ctx, tx, wallet.ID, 0, wallet.Status, "", wallet.EnabledAt)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWalletCreated, audit.ResourceWallet, wallet.ID, wallet, audit.WalletState{}))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// UpdateStatusWallet moves the wallet from the status from to its status,
+// writing when it was enabled, disabled or closed, the transition to its status
+// history, the event telling its owner about it and its audit event. It fails
+// with ErrWalletTransition when the wallet is no longer in from.
+func (r *WalletRepository) UpdateStatusWallet(ctx context.Context, wallet *model.Wallet, from int, reason, event string) (*model.Wallet, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `UPDATE wallets
+		SET status = $1, enabled_at = $2, disabled_at = $3, closed_at = $4
+		WHERE id = $5 AND status = $6 RETURNING balance, held_balance`, wallet.Status, wallet.EnabledAt, wallet.DisabledAt, wallet.ClosedAt, wallet.ID, from)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, commonerr.ErrWalletTransition
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	err = insertStatusChange(ctx, tx, wallet.ID, from, wallet.Status, reason, now)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// The wallet events are named after the status moved to, as are their
+	// audit actions.
+	before := audit.StateOf(wallet)
+	before.Status = from
+	auditEvent := audit.NewWalletEvent(event, audit.ResourceWallet, wallet.ID, wallet, before)
+	auditEvent.Reason = reason
+	err = audit.Record(ctx, tx, auditEvent)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewWalletEvent(event, wallet, now))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// CloseWallet closes the wallet for good, moving it from the status from.
+// When payout is given the balance is first withdrawn by it, in the same
+// transaction, so the wallet never closes with money in it: the wallet must
+// hold exactly the payout amount, or nothing without one, and no hold.
+func (r *WalletRepository) CloseWallet(ctx context.Context, wallet *model.Wallet, from int, payout *model.Withdrawal) (*model.Wallet, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status int
+	row := tx.QueryRowContext(ctx, `SELECT status, balance, held_balance FROM wallets WHERE id = $1 FOR UPDATE`, wallet.ID)
+	err = row.Scan(&status, &wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if status != from {
+		_ = tx.Rollback()
+		return nil, commonerr.ErrWalletTransition
+	}
+	if !wallet.HeldBalance.IsZero() {
+		_ = tx.Rollback()
+		return nil, commonerr.ErrWalletHoldsPending
+	}
+	if (payout == nil && !wallet.Balance.IsZero()) || (payout != nil && !wallet.Balance.Equal(payout.Amount)) {
+		_ = tx.Rollback()
+		return nil, commonerr.ErrWalletNotEmpty
+	}
+
+	// The payout is made while the wallet is still open.
+	closedStatus := wallet.Status
+	wallet.Status = from
+
+	if payout != nil {
+		before := audit.StateOf(wallet)
+		row = tx.QueryRowContext(ctx, `INSERT INTO
+			withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, destination)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, payout.WithdrawnBy, payout.Status, payout.WithdrawnAt, payout.Amount, payout.ReferenceID, payout.Currency, payout.Destination)
+		err = row.Scan(&payout.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			if isUniqueViolation(err) {
+				return nil, commonerr.ErrReferenceIdUsed
+			}
+			return nil, err
+		}
+
+		row = tx.QueryRowContext(ctx, `UPDATE wallets
+			SET balance = balance - $1 WHERE id = $2 RETURNING balance, held_balance`, payout.Amount, wallet.ID)
+		err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		err = ledger.Post(ctx, tx, ledger.NewWithdrawalEntry(wallet.ID, payout.ID, payout.Currency, payout.Amount, payout.WithdrawnAt))
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		err = verifyWalletBalance(ctx, tx, wallet)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalCreated, audit.ResourceWithdrawal, payout.ID, wallet, before))
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		err = webhook.Enqueue(ctx, tx, webhook.NewWithdrawalEvent(payout, wallet, payout.WithdrawnAt))
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	before := audit.StateOf(wallet)
+	wallet.Status = closedStatus
+
+	_, err = tx.ExecContext(ctx, `UPDATE wallets SET status = $1, closed_at = $2 WHERE id = $3`, wallet.Status, wallet.ClosedAt, wallet.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	err = insertStatusChange(ctx, tx, wallet.ID, from, wallet.Status, "", now)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWalletClosed, audit.ResourceWallet, wallet.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewWalletEvent(webhook.EventWalletClosed, wallet, now))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
 	return wallet, nil
 }
 
-func (r *WalletRepository) UpdateStatusWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error) {
-	row := r.db.QueryRowContext(ctx, `UPDATE wallets
-		SET status = $1 WHERE id = $2 RETURNING id`, wallet.Status, wallet.ID)
-	err := row.Scan(&wallet.ID)
+// GetWalletStatusHistory returns the transitions of the wallet, oldest first.
+func (r *WalletRepository) GetWalletStatusHistory(ctx context.Context, walletID string) ([]*model.WalletStatusChange, error) {
+	query := `
+		SELECT
+			id, wallet_id, from_status, to_status, reason, actor_type, actor_id, changed_at
+		FROM wallet_status_history WHERE wallet_id = $1 ORDER BY changed_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	changes := []*model.WalletStatusChange{}
+	for rows.Next() {
+		change := &model.WalletStatusChange{}
+		if err := rows.Scan(&change.ID, &change.WalletID, &change.From, &change.To, &change.Reason, &change.ActorType, &change.ActorID, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// insertStatusChange writes the transition of the wallet from a status to
+// another to its history, along with the actor of ctx.
+func insertStatusChange(ctx context.Context, tx *sql.Tx, walletID string, from, to int, reason string, changedAt time.Time) error {
+	actorType, actorID := audit.ActorFromContext(ctx)
+	_, err := tx.ExecContext(ctx, `INSERT INTO
+		wallet_status_history (wallet_id, from_status, to_status, reason, actor_type, actor_id, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`, walletID, from, to, reason, actorType, actorID, changedAt)
+
+	return err
+}
+
+func (r *WalletRepository) GetWalletByCustomerXid(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
+	query := `
+		SELECT
+			id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency
+		FROM wallets WHERE owned_by = $1 AND currency = $2
+	`
+
+	wallet := &model.Wallet{}
+	row := r.db.QueryRowContext(ctx, query, customerXid, currency)
+	if err := row.Scan(&wallet.ID, &wallet.OwnedBy, &wallet.Status, &wallet.EnabledAt, &wallet.DisabledAt, &wallet.ClosedAt, &wallet.Balance, &wallet.HeldBalance, &wallet.Currency); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 
 	return wallet, nil
 }
 
-func (r *WalletRepository) GetWalletByCustomerXid(ctx context.Context, customerXid string) (*model.Wallet, error) {
+// GetWalletsByCustomerXid returns every wallet of the customer, one per
+// currency, ordered by currency.
+func (r *WalletRepository) GetWalletsByCustomerXid(ctx context.Context, customerXid string) ([]*model.Wallet, error) {
+	query := `
+		SELECT
+			id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency
+		FROM wallets WHERE owned_by = $1 ORDER BY currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, customerXid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []*model.Wallet{}
+	for rows.Next() {
+		wallet := &model.Wallet{}
+		if err := rows.Scan(&wallet.ID, &wallet.OwnedBy, &wallet.Status, &wallet.EnabledAt, &wallet.DisabledAt, &wallet.ClosedAt, &wallet.Balance, &wallet.HeldBalance, &wallet.Currency); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// GetWalletById returns the wallet with that id whoever owns it, nil when
+// there is none.
+func (r *WalletRepository) GetWalletById(ctx context.Context, id string) (*model.Wallet, error) {
 	query := `
 		SELECT
-			id, owned_by, status, enabled_at, balance
-		FROM wallets WHERE owned_by = $1
+			id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency
+		FROM wallets WHERE id = $1
 	`
 
 	wallet := &model.Wallet{}
-	row := r.db.QueryRowContext(ctx, query, customerXid)
-	if err := row.Scan(&wallet.ID, &wallet.OwnedBy, &wallet.Status, &wallet.EnabledAt, &wallet.Balance); err != nil {
+	row := r.db.QueryRowContext(ctx, query, id)
+	if err := row.Scan(&wallet.ID, &wallet.OwnedBy, &wallet.Status, &wallet.EnabledAt, &wallet.DisabledAt, &wallet.ClosedAt, &wallet.Balance, &wallet.HeldBalance, &wallet.Currency); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
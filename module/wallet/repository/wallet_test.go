@@ -3,15 +3,20 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"reflect"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -19,35 +24,92 @@ import (
 var (
 	now          = time.Now()
 	walletEnable = &model.Wallet{
-		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
-		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
-		Status:    1,
-		EnabledAt: now,
-		Balance:   decimal.NewFromInt(10000),
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(0),
+		Currency:    "IDR",
 	}
 	walletDisable = &model.Wallet{
-		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
-		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
-		Status:    2,
-		EnabledAt: now,
-		Balance:   decimal.NewFromInt(0),
+		ID:         "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:    "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:     2,
+		EnabledAt:  now,
+		DisabledAt: &now,
+		Balance:    decimal.NewFromInt(0),
+		Currency:   "IDR",
 	}
 )
 
+func expectStatusChange(sMock sqlmock.Sqlmock, from, to int, err error) {
+	exec := sMock.ExpectExec(regexp.QuoteMeta("INSERT INTO wallet_status_history (wallet_id, from_status, to_status, reason, actor_type, actor_id, changed_at) VALUES ($1, $2, $3, $4, $5, $6, $7)")).
+		WithArgs(walletEnable.ID, from, to, sqlmock.AnyArg(), audit.ActorSystem, "", sqlmock.AnyArg())
+	if err != nil {
+		exec.WillReturnError(err)
+	} else {
+		exec.WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func expectWalletStatus(sMock sqlmock.Sqlmock, walletID string, status int) {
+	sMock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM wallets WHERE id = $1")).
+		WithArgs(walletID).
+		WillReturnRows(sMock.NewRows([]string{"status"}).AddRow(status))
+}
+
+func expectWalletBalance(sMock sqlmock.Sqlmock, walletID string, status int, balance decimal.Decimal) {
+	sMock.ExpectQuery(regexp.QuoteMeta("SELECT status, balance FROM wallets WHERE id = $1")).
+		WithArgs(walletID).
+		WillReturnRows(sMock.NewRows([]string{"status", "balance"}).AddRow(status, balance))
+}
+
 func TestWallet_CreateWallet(t *testing.T) {
 	testCases := map[string]struct {
-		req     *model.Wallet
-		result  *model.Wallet
-		wantErr bool
+		req            *model.Wallet
+		result         *model.Wallet
+		wantErr        bool
+		wantErrTx      bool
+		wantErrWallet  bool
+		wantErrHistory bool
+		wantErrAudit   bool
+		wantErrCommit  bool
+		err            error
 	}{
 		"success": {
 			req:     walletEnable,
 			result:  walletEnable,
 			wantErr: false,
 		},
+		"failed history": {
+			req:            walletEnable,
+			wantErr:        true,
+			wantErrHistory: true,
+			err:            errors.New("database error"),
+		},
+		"failed tx": {
+			req:       walletEnable,
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
 		"failed scan": {
-			req:     walletEnable,
-			wantErr: true,
+			req:           walletEnable,
+			wantErr:       true,
+			wantErrWallet: true,
+		},
+		"failed audit": {
+			req:          walletEnable,
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			req:           walletEnable,
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
 		},
 	}
 
@@ -58,15 +120,38 @@ func TestWallet_CreateWallet(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewWalletRepository(db)
 
-			column := []string{"id"}
-			row := sMock.NewRows(column).AddRow(walletEnable.ID)
-			if tc.wantErr {
-				column = []string{"id", "id"}
-				row = sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.ID)
-			}
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				column := []string{"id"}
+				row := sMock.NewRows(column).AddRow(walletEnable.ID)
+				if tc.wantErrWallet {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.ID)
+				}
 
-			sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO wallets (owned_by, status, enabled_at, balance) VALUES ($1, $2, $3, $4) RETURNING id")).
-				WithArgs(walletEnable.OwnedBy, walletEnable.Status, walletEnable.EnabledAt, walletEnable.Balance).WillReturnRows(row)
+				sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO wallets (owned_by, status, enabled_at, balance, currency) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
+					WithArgs(walletEnable.OwnedBy, walletEnable.Status, walletEnable.EnabledAt, walletEnable.Balance, walletEnable.Currency).WillReturnRows(row)
+				if tc.wantErrWallet {
+					sMock.ExpectRollback()
+				} else if tc.wantErrHistory {
+					expectStatusChange(sMock, 0, 1, tc.err)
+					sMock.ExpectRollback()
+				} else if tc.wantErrAudit {
+					expectStatusChange(sMock, 0, 1, nil)
+					expectAuditEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+				} else {
+					expectStatusChange(sMock, 0, 1, nil)
+					expectAuditEvent(sMock, nil)
+					if tc.wantErrCommit {
+						sMock.ExpectCommit().WillReturnError(tc.err)
+					} else {
+						sMock.ExpectCommit()
+					}
+				}
+			}
 
 			result, err := repo.CreateWallet(context.Background(), tc.req)
 
@@ -78,24 +163,69 @@ func TestWallet_CreateWallet(t *testing.T) {
 			} else {
 				assert.Nil(t, err)
 			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
 		})
 	}
 }
 
 func TestWallet_UpdateStatusWallet(t *testing.T) {
 	testCases := map[string]struct {
-		req     *model.Wallet
-		result  *model.Wallet
-		wantErr bool
+		req            *model.Wallet
+		result         *model.Wallet
+		wantErr        bool
+		wantErrTx      bool
+		wantErrWallet  bool
+		wantErrHistory bool
+		wantErrAudit   bool
+		wantErrWebhook bool
+		wantErrCommit  bool
+		err            error
 	}{
 		"success": {
 			req:     walletDisable,
 			result:  walletDisable,
 			wantErr: false,
 		},
+		"failed status moved": {
+			req:           walletDisable,
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           commonerr.ErrWalletTransition,
+		},
+		"failed history": {
+			req:            walletDisable,
+			wantErr:        true,
+			wantErrHistory: true,
+			err:            errors.New("database error"),
+		},
+		"failed tx": {
+			req:       walletDisable,
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
 		"failed scan": {
-			req:     walletEnable,
-			wantErr: true,
+			req:           walletDisable,
+			wantErr:       true,
+			wantErrWallet: true,
+		},
+		"failed audit": {
+			req:          walletDisable,
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed webhook": {
+			req:            walletDisable,
+			wantErr:        true,
+			wantErrWebhook: true,
+			err:            errors.New("database error"),
+		},
+		"failed commit": {
+			req:           walletDisable,
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
 		},
 	}
 
@@ -106,17 +236,45 @@ func TestWallet_UpdateStatusWallet(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewWalletRepository(db)
 
-			column := []string{"id"}
-			row := sMock.NewRows(column).AddRow(walletDisable.ID)
-			if tc.wantErr {
-				column = []string{"id", "id"}
-				row = sMock.NewRows(column).AddRow(walletDisable.ID, walletDisable.ID)
-			}
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				column := []string{"balance", "held_balance"}
+				row := sMock.NewRows(column).AddRow(walletDisable.Balance, walletDisable.HeldBalance)
+				if tc.wantErrWallet {
+					row = sMock.NewRows(column)
+				}
 
-			sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET status = $1 WHERE id = $2 RETURNING id")).
-				WithArgs(walletDisable.Status, walletDisable.ID).WillReturnRows(row)
+				sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET status = $1, enabled_at = $2, disabled_at = $3, closed_at = $4 WHERE id = $5 AND status = $6 RETURNING balance, held_balance")).
+					WithArgs(walletDisable.Status, walletDisable.EnabledAt, walletDisable.DisabledAt, walletDisable.ClosedAt, walletDisable.ID, walletEnable.Status).WillReturnRows(row)
+				if tc.wantErrWallet {
+					sMock.ExpectRollback()
+				} else if tc.wantErrHistory {
+					expectStatusChange(sMock, 1, 2, tc.err)
+					sMock.ExpectRollback()
+				} else if tc.wantErrAudit {
+					expectStatusChange(sMock, 1, 2, nil)
+					expectAuditEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+				} else if tc.wantErrWebhook {
+					expectStatusChange(sMock, 1, 2, nil)
+					expectAuditEvent(sMock, nil)
+					expectWebhookEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+				} else {
+					expectStatusChange(sMock, 1, 2, nil)
+					expectAuditEvent(sMock, nil)
+					expectWebhookEvent(sMock, nil)
+					if tc.wantErrCommit {
+						sMock.ExpectCommit().WillReturnError(tc.err)
+					} else {
+						sMock.ExpectCommit()
+					}
+				}
+			}
 
-			result, err := repo.UpdateStatusWallet(context.Background(), tc.req)
+			result, err := repo.UpdateStatusWallet(context.Background(), tc.req, walletEnable.Status, "", webhook.EventWalletDisabled)
 
 			if !reflect.DeepEqual(result, tc.result) {
 				t.Errorf("Wallet.UpdateStatusWallet() = %v, want %v", result, tc.result)
@@ -126,6 +284,10 @@ func TestWallet_UpdateStatusWallet(t *testing.T) {
 			} else {
 				assert.Nil(t, err)
 			}
+			if tc.err == commonerr.ErrWalletTransition {
+				assert.Equal(t, tc.err, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
 		})
 	}
 }
@@ -160,11 +322,78 @@ func TestWallet_GetWalletByCustomerXid(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewWalletRepository(db)
 
-			column := []string{"id", "owned_by", "status", "enabled_at", "balance"}
-			row := sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.OwnedBy, walletEnable.Status, walletEnable.EnabledAt, walletEnable.Balance)
+			column := []string{"id", "owned_by", "status", "enabled_at", "disabled_at", "closed_at", "balance", "held_balance", "currency"}
+			row := sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.OwnedBy, walletEnable.Status, walletEnable.EnabledAt, walletEnable.DisabledAt, walletEnable.ClosedAt, walletEnable.Balance, walletEnable.HeldBalance, walletEnable.Currency)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE owned_by = $1 AND currency = $2")).
+					WithArgs(tc.req, walletEnable.Currency).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE owned_by = $1 AND currency = $2")).
+					WithArgs(tc.req, walletEnable.Currency).WillReturnRows(row)
+			}
+
+			result, _ := repo.GetWalletByCustomerXid(context.Background(), tc.req, walletEnable.Currency)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Wallet.GetWalletByCustomerXid() = %v, want %v", result, tc.result)
+			}
+		})
+	}
+}
+
+func TestWallet_GetWalletsByCustomerXid(t *testing.T) {
+	walletUSD := &model.Wallet{
+		ID:          "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f",
+		OwnedBy:     walletEnable.OwnedBy,
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.RequireFromString("12.5"),
+		HeldBalance: decimal.RequireFromString("2.5"),
+		Currency:    "USD",
+	}
+	testCases := map[string]struct {
+		req     string
+		result  []*model.Wallet
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			req:     walletEnable.OwnedBy,
+			result:  []*model.Wallet{walletEnable, walletUSD},
+			wantErr: false,
+		},
+		"failed scan": {
+			req:     walletEnable.OwnedBy,
+			wantErr: true,
+		},
+		"failed query": {
+			req:     walletEnable.OwnedBy,
+			wantErr: true,
+			err:     sql.ErrConnDone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWalletRepository(db)
+
+			column := []string{"id", "owned_by", "status", "enabled_at", "disabled_at", "closed_at", "balance", "held_balance", "currency"}
+			row := sMock.NewRows(column)
+			for _, wallet := range []*model.Wallet{walletEnable, walletUSD} {
+				row.AddRow(wallet.ID, wallet.OwnedBy, wallet.Status, wallet.EnabledAt, wallet.DisabledAt, wallet.ClosedAt, wallet.Balance, wallet.HeldBalance, wallet.Currency)
+			}
 
 			if tc.err != nil {
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, balance FROM wallets WHERE owned_by = $1")).
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE owned_by = $1 ORDER BY currency")).
 					WithArgs(tc.req).WillReturnError(tc.err)
 			} else {
 				if tc.wantErr {
@@ -172,15 +401,335 @@ func TestWallet_GetWalletByCustomerXid(t *testing.T) {
 					row = sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.ID)
 				}
 
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, balance FROM wallets WHERE owned_by = $1")).
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE owned_by = $1 ORDER BY currency")).
 					WithArgs(tc.req).WillReturnRows(row)
 			}
 
-			result, _ := repo.GetWalletByCustomerXid(context.Background(), tc.req)
+			result, err := repo.GetWalletsByCustomerXid(context.Background(), tc.req)
 
 			if !reflect.DeepEqual(result, tc.result) {
-				t.Errorf("Wallet.GetWalletByCustomerXid() = %v, want %v", result, tc.result)
+				t.Errorf("Wallet.GetWalletsByCustomerXid() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestWallet_GetWalletById(t *testing.T) {
+	testCases := map[string]struct {
+		req     string
+		result  *model.Wallet
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			req:     walletEnable.ID,
+			result:  walletEnable,
+			wantErr: false,
+		},
+		"failed scan": {
+			req:     walletEnable.ID,
+			wantErr: true,
+		},
+		"failed sql no row": {
+			req:     walletEnable.ID,
+			wantErr: true,
+			err:     sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWalletRepository(db)
+
+			column := []string{"id", "owned_by", "status", "enabled_at", "disabled_at", "closed_at", "balance", "held_balance", "currency"}
+			row := sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.OwnedBy, walletEnable.Status, walletEnable.EnabledAt, walletEnable.DisabledAt, walletEnable.ClosedAt, walletEnable.Balance, walletEnable.HeldBalance, walletEnable.Currency)
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE id = $1")).
+					WithArgs(tc.req).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(walletEnable.ID, walletEnable.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, status, enabled_at, disabled_at, closed_at, balance, held_balance, currency FROM wallets WHERE id = $1")).
+					WithArgs(tc.req).WillReturnRows(row)
+			}
+
+			result, _ := repo.GetWalletById(context.Background(), tc.req)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Wallet.GetWalletById() = %v, want %v", result, tc.result)
+			}
+		})
+	}
+}
+
+func TestWallet_GetWalletStatusHistory(t *testing.T) {
+	change := &model.WalletStatusChange{
+		ID:        "3b0f1c8e-2d4a-4f6b-9c1d-7e8f9a0b1c2d",
+		WalletID:  walletEnable.ID,
+		From:      1,
+		To:        3,
+		Reason:    "chargeback investigation",
+		ActorType: audit.ActorAdmin,
+		ActorID:   "staff-1",
+		ChangedAt: now,
+	}
+	testCases := map[string]struct {
+		result  []*model.WalletStatusChange
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  []*model.WalletStatusChange{change},
+			wantErr: false,
+		},
+		"failed scan": {
+			wantErr: true,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     sql.ErrConnDone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWalletRepository(db)
+
+			column := []string{"id", "wallet_id", "from_status", "to_status", "reason", "actor_type", "actor_id", "changed_at"}
+			row := sMock.NewRows(column).AddRow(change.ID, change.WalletID, change.From, change.To, change.Reason, change.ActorType, change.ActorID, change.ChangedAt)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, wallet_id, from_status, to_status, reason, actor_type, actor_id, changed_at FROM wallet_status_history WHERE wallet_id = $1 ORDER BY changed_at, id")).
+				WithArgs(walletEnable.ID)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					row = sMock.NewRows([]string{"id", "id"}).AddRow(change.ID, change.ID)
+				}
+				query.WillReturnRows(row)
+			}
+
+			result, err := repo.GetWalletStatusHistory(context.Background(), walletEnable.ID)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Wallet.GetWalletStatusHistory() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestWallet_CloseWallet(t *testing.T) {
+	closedAt := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	payout := &model.Withdrawal{
+		ID:          "9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a",
+		WithdrawnBy: walletEnable.OwnedBy,
+		Status:      1,
+		WithdrawnAt: closedAt,
+		Amount:      decimal.NewFromInt(10000),
+		ReferenceID: "c4a3e9b2-5d1f-4e6a-9b7c-8d0e1f2a3b4c",
+		Currency:    "IDR",
+		Destination: "BCA 1234567890",
+	}
+	testCases := map[string]struct {
+		payout         *model.Withdrawal
+		status         int
+		balance        decimal.Decimal
+		heldBalance    decimal.Decimal
+		wantErr        bool
+		wantErrTx      bool
+		wantErrPayout  bool
+		wantErrHistory bool
+		wantErrAudit   bool
+		wantErrWebhook bool
+		wantErrCommit  bool
+		err            error
+	}{
+		"success": {
+			status:  2,
+			wantErr: false,
+		},
+		"success with payout": {
+			payout:  payout,
+			status:  2,
+			balance: payout.Amount,
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed status moved": {
+			status:  1,
+			wantErr: true,
+			err:     commonerr.ErrWalletTransition,
+		},
+		"failed holds pending": {
+			status:      2,
+			heldBalance: decimal.NewFromInt(500),
+			wantErr:     true,
+			err:         commonerr.ErrWalletHoldsPending,
+		},
+		"failed not empty": {
+			status:  2,
+			balance: decimal.NewFromInt(500),
+			wantErr: true,
+			err:     commonerr.ErrWalletNotEmpty,
+		},
+		"failed balance moved since payout": {
+			payout:  payout,
+			status:  2,
+			balance: payout.Amount.Add(decimal.NewFromInt(500)),
+			wantErr: true,
+			err:     commonerr.ErrWalletNotEmpty,
+		},
+		"failed payout reference id used": {
+			payout:        payout,
+			status:        2,
+			balance:       payout.Amount,
+			wantErr:       true,
+			wantErrPayout: true,
+			err:           commonerr.ErrReferenceIdUsed,
+		},
+		"failed history": {
+			status:         2,
+			wantErr:        true,
+			wantErrHistory: true,
+			err:            errors.New("database error"),
+		},
+		"failed audit": {
+			status:       2,
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed webhook": {
+			status:         2,
+			wantErr:        true,
+			wantErrWebhook: true,
+			err:            errors.New("database error"),
+		},
+		"failed commit": {
+			status:        2,
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWalletRepository(db)
+			wallet := &model.Wallet{
+				ID:       walletEnable.ID,
+				OwnedBy:  walletEnable.OwnedBy,
+				Status:   4,
+				ClosedAt: &closedAt,
+				Currency: "IDR",
+			}
+			var req *model.Withdrawal
+			if tc.payout != nil {
+				p := *tc.payout
+				req = &p
+			}
+
+			expect := func() {
+				if tc.wantErrTx {
+					sMock.ExpectBegin().WillReturnError(tc.err)
+					return
+				}
+				sMock.ExpectBegin()
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT status, balance, held_balance FROM wallets WHERE id = $1 FOR UPDATE")).
+					WithArgs(wallet.ID).WillReturnRows(sMock.NewRows([]string{"status", "balance", "held_balance"}).AddRow(tc.status, tc.balance, tc.heldBalance))
+				if tc.status != 2 || !tc.heldBalance.IsZero() || (req == nil && !tc.balance.IsZero()) || (req != nil && !tc.balance.Equal(req.Amount)) {
+					sMock.ExpectRollback()
+					return
+				}
+
+				if req != nil {
+					insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, destination) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+						WithArgs(req.WithdrawnBy, req.Status, req.WithdrawnAt, req.Amount, req.ReferenceID, req.Currency, req.Destination)
+					if tc.wantErrPayout {
+						insert.WillReturnError(&pq.Error{Code: "23505"})
+						sMock.ExpectRollback()
+						return
+					}
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(payout.ID))
+					sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 RETURNING balance, held_balance")).
+						WithArgs(req.Amount, wallet.ID).WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(decimal.Zero, decimal.Zero))
+					expectLedgerEntry(sMock, decimal.Zero)
+					expectAuditEvent(sMock, nil)
+					expectWebhookEvent(sMock, nil)
+				}
+
+				sMock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET status = $1, closed_at = $2 WHERE id = $3")).
+					WithArgs(4, &closedAt, wallet.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				if tc.wantErrHistory {
+					expectStatusChange(sMock, 2, 4, tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				expectStatusChange(sMock, 2, 4, nil)
+				if tc.wantErrAudit {
+					expectAuditEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				expectAuditEvent(sMock, nil)
+				if tc.wantErrWebhook {
+					expectWebhookEvent(sMock, tc.err)
+					sMock.ExpectRollback()
+					return
+				}
+				expectWebhookEvent(sMock, nil)
+				if tc.wantErrCommit {
+					sMock.ExpectCommit().WillReturnError(tc.err)
+				} else {
+					sMock.ExpectCommit()
+				}
+			}
+			expect()
+
+			result, err := repo.CloseWallet(context.Background(), wallet, 2, req)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 4, result.Status)
+				assert.True(t, result.Balance.IsZero())
+				if req != nil {
+					assert.Equal(t, payout.ID, req.ID)
+				}
+			}
+			if _, ok := tc.err.(*commonerr.DomainError); ok {
+				assert.Equal(t, tc.err, err)
 			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
 		})
 	}
 }
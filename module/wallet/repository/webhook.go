@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookRepository) CreateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO
+		webhook_endpoints (owned_by, url, secret, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id`, endpoint.OwnedBy, endpoint.URL, endpoint.Secret, endpoint.CreatedAt)
+	err := row.Scan(&endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// GetWebhookEndpoints returns the endpoints of the customer, oldest first,
+// without their secret.
+func (r *WebhookRepository) GetWebhookEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error) {
+	query := `
+		SELECT
+			id, owned_by, url, created_at
+		FROM webhook_endpoints WHERE owned_by = $1 ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, customerXid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*model.WebhookEndpoint{}
+	for rows.Next() {
+		endpoint := &model.WebhookEndpoint{}
+		if err := rows.Scan(&endpoint.ID, &endpoint.OwnedBy, &endpoint.URL, &endpoint.CreatedAt); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// DeleteWebhookEndpoint deletes the endpoint of the customer along with its
+// deliveries, and returns it, nil when the customer has no endpoint with that
+// id.
+func (r *WebhookRepository) DeleteWebhookEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error) {
+	endpoint := &model.WebhookEndpoint{}
+	row := r.db.QueryRowContext(ctx, `DELETE FROM webhook_endpoints
+		WHERE id = $1 AND owned_by = $2 RETURNING id, owned_by, url, created_at`, id, customerXid)
+	if err := row.Scan(&endpoint.ID, &endpoint.OwnedBy, &endpoint.URL, &endpoint.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries due by now,
+// pushing their next attempt to leaseUntil so that no other dispatcher sends
+// them meanwhile, under a new claim id. A dispatcher that dies with them
+// leaves them to be retried once the lease is over.
+func (r *WebhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d SET next_attempt_at = $1, claim_id = gen_random_uuid()
+		FROM webhook_events ev, webhook_endpoints ep
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED
+		) AND ev.id = d.event_id AND ep.id = d.endpoint_id
+		RETURNING d.id, ev.id, ev.event_type, ev.payload, ev.created_at, ep.url, ep.secret, d.status, d.attempts, d.next_attempt_at, d.claim_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, leaseUntil, webhook.DeliveryPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &model.WebhookDelivery{}
+		if err := rows.Scan(&delivery.ID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.EventCreatedAt, &delivery.URL, &delivery.Secret, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.ClaimID); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery writes the outcome of an attempt to send the delivery
+// and reports whether it was written. It is not when the delivery was claimed
+// again since, its lease having run out.
+func (r *WebhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5, claim_id = NULL
+		WHERE id = $6 AND claim_id = $7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt, delivery.ID, delivery.ClaimID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
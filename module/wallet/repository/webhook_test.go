@@ -0,0 +1,297 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/repository"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	webhookEndpoint = &model.WebhookEndpoint{
+		ID:        "3f1c2b4a-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		URL:       "https://merchant.example.com/hooks",
+		Secret:    "0123456789abcdef0123456789abcdef",
+		CreatedAt: now,
+	}
+	webhookDelivery = &model.WebhookDelivery{
+		ID:             "0e1b3d4c-8f3a-4d9e-9f0e-5b1f3d2c7a10",
+		EventID:        "9b2f1e4a-2c3d-4e5f-8a9b-0c1d2e3f4a5b",
+		EventType:      webhook.EventDepositSucceeded,
+		Payload:        json.RawMessage(`{"id":"5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"}`),
+		EventCreatedAt: now,
+		URL:            "https://merchant.example.com/hooks",
+		Secret:         "0123456789abcdef0123456789abcdef",
+		Status:         webhook.DeliveryPending,
+		Attempts:       0,
+		NextAttemptAt:  now,
+		ClaimID:        "5f2d8c1a-7e4b-4a9c-b3d6-1e0f2a4b6c8d",
+	}
+)
+
+// expectWebhookEvent expects the outbox insert of a webhook event, failing
+// with err when it is not nil.
+func expectWebhookEvent(sMock sqlmock.Sqlmock, err error) {
+	exec := sMock.ExpectExec(regexp.QuoteMeta("WITH event AS ( INSERT INTO webhook_events (event_type, customer_xid, payload, created_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at ) INSERT INTO webhook_deliveries"))
+	if err != nil {
+		exec.WillReturnError(err)
+	} else {
+		exec.WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func TestWebhook_CreateWebhookEndpoint(t *testing.T) {
+	testCases := map[string]struct {
+		result  *model.WebhookEndpoint
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  webhookEndpoint,
+			wantErr: false,
+		},
+		"failed insert": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWebhookRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO webhook_endpoints (owned_by, url, secret, created_at) VALUES ($1, $2, $3, $4) RETURNING id")).
+				WithArgs(webhookEndpoint.OwnedBy, webhookEndpoint.URL, webhookEndpoint.Secret, webhookEndpoint.CreatedAt)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(webhookEndpoint.ID))
+			}
+
+			req := *webhookEndpoint
+			req.ID = ""
+			result, err := repo.CreateWebhookEndpoint(context.Background(), &req)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.result, result)
+			}
+		})
+	}
+}
+
+func TestWebhook_GetWebhookEndpoints(t *testing.T) {
+	listed := *webhookEndpoint
+	listed.Secret = ""
+
+	testCases := map[string]struct {
+		result  []*model.WebhookEndpoint
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  []*model.WebhookEndpoint{&listed},
+			wantErr: false,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWebhookRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, owned_by, url, created_at FROM webhook_endpoints WHERE owned_by = $1 ORDER BY created_at, id")).
+				WithArgs(webhookEndpoint.OwnedBy)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"id", "owned_by", "url", "created_at"}).
+					AddRow(webhookEndpoint.ID, webhookEndpoint.OwnedBy, webhookEndpoint.URL, webhookEndpoint.CreatedAt))
+			}
+
+			result, err := repo.GetWebhookEndpoints(context.Background(), webhookEndpoint.OwnedBy)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestWebhook_DeleteWebhookEndpoint(t *testing.T) {
+	deleted := *webhookEndpoint
+	deleted.Secret = ""
+
+	testCases := map[string]struct {
+		result  *model.WebhookEndpoint
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  &deleted,
+			wantErr: false,
+		},
+		"success not found": {
+			result:  nil,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWebhookRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("DELETE FROM webhook_endpoints WHERE id = $1 AND owned_by = $2 RETURNING id, owned_by, url, created_at")).
+				WithArgs(webhookEndpoint.ID, webhookEndpoint.OwnedBy)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"id", "owned_by", "url", "created_at"}).
+					AddRow(webhookEndpoint.ID, webhookEndpoint.OwnedBy, webhookEndpoint.URL, webhookEndpoint.CreatedAt))
+			}
+
+			result, err := repo.DeleteWebhookEndpoint(context.Background(), webhookEndpoint.OwnedBy, webhookEndpoint.ID)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestWebhook_ClaimWebhookDeliveries(t *testing.T) {
+	leaseUntil := now.Add(5 * time.Minute)
+
+	testCases := map[string]struct {
+		result  []*model.WebhookDelivery
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  []*model.WebhookDelivery{webhookDelivery},
+			wantErr: false,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWebhookRepository(db)
+
+			query := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE webhook_deliveries d SET next_attempt_at = $1, claim_id = gen_random_uuid() FROM webhook_events ev, webhook_endpoints ep WHERE d.id IN ( SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED ) AND ev.id = d.event_id AND ep.id = d.endpoint_id RETURNING d.id, ev.id, ev.event_type, ev.payload, ev.created_at, ep.url, ep.secret, d.status, d.attempts, d.next_attempt_at, d.claim_id")).
+				WithArgs(leaseUntil, webhook.DeliveryPending, now, 50)
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(sMock.NewRows([]string{"id", "event_id", "event_type", "payload", "created_at", "url", "secret", "status", "attempts", "next_attempt_at", "claim_id"}).
+					AddRow(webhookDelivery.ID, webhookDelivery.EventID, webhookDelivery.EventType, []byte(webhookDelivery.Payload), webhookDelivery.EventCreatedAt, webhookDelivery.URL, webhookDelivery.Secret, webhookDelivery.Status, webhookDelivery.Attempts, webhookDelivery.NextAttemptAt, webhookDelivery.ClaimID))
+			}
+
+			result, err := repo.ClaimWebhookDeliveries(context.Background(), now, leaseUntil, 50)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestWebhook_UpdateWebhookDelivery(t *testing.T) {
+	testCases := map[string]struct {
+		affected int64
+		result   bool
+		wantErr  bool
+		err      error
+	}{
+		"success": {
+			affected: 1,
+			result:   true,
+			wantErr:  false,
+		},
+		"success claimed again": {
+			affected: 0,
+			result:   false,
+			wantErr:  false,
+		},
+		"failed exec": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWebhookRepository(db)
+
+			delivery := *webhookDelivery
+			delivery.Status = webhook.DeliveryDelivered
+			delivery.DeliveredAt = &now
+
+			exec := sMock.ExpectExec(regexp.QuoteMeta("UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5, claim_id = NULL WHERE id = $6 AND claim_id = $7")).
+				WithArgs(delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt, delivery.ID, delivery.ClaimID)
+			if tc.err != nil {
+				exec.WillReturnError(tc.err)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tc.affected))
+			}
+
+			result, err := repo.UpdateWebhookDelivery(context.Background(), &delivery)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -3,8 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/ledger"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 )
 
 type WithdrawalRepository struct {
@@ -12,8 +17,14 @@ type WithdrawalRepository struct {
 }
 
 type WithdrawalRepositoryInterface interface {
-	GetWithdrawalByReferenceId(ctx context.Context, referenceId string) (*model.Withdrawal, error)
+	GetWithdrawalByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Withdrawal, error)
+	GetWithdrawalsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Withdrawal, error)
 	CreateWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error)
+	GetWithdrawalById(ctx context.Context, customerXid, id string) (*model.Withdrawal, error)
+	GetExpiredWithdrawals(ctx context.Context, status int, now time.Time, limit int) ([]*model.Withdrawal, error)
+	CreatePendingWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error)
+	CaptureWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error)
+	ReleaseWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error)
 }
 
 func NewWithdrawalRepository(db *sql.DB) *WithdrawalRepository {
@@ -22,16 +33,17 @@ func NewWithdrawalRepository(db *sql.DB) *WithdrawalRepository {
 	}
 }
 
-func (r *WithdrawalRepository) GetWithdrawalByReferenceId(ctx context.Context, referenceId string) (*model.Withdrawal, error) {
+func (r *WithdrawalRepository) GetWithdrawalByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Withdrawal, error) {
 	query := `
 		SELECT
-			id, withdrawn_by, status, withdrawn_at, amount, reference_id
-		FROM withdrawals WHERE reference_id = $1
+			id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at,
+			refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '')
+		FROM withdrawals WHERE withdrawn_by = $1 AND reference_id = $2
 	`
 
 	withdrawal := &model.Withdrawal{}
-	row := r.db.QueryRowContext(ctx, query, referenceId)
-	if err := row.Scan(&withdrawal.ID, &withdrawal.WithdrawnBy, &withdrawal.Status, &withdrawal.WithdrawnAt, &withdrawal.Amount, &withdrawal.ReferenceID); err != nil {
+	row := r.db.QueryRowContext(ctx, query, customerXid, referenceId)
+	if err := row.Scan(&withdrawal.ID, &withdrawal.WithdrawnBy, &withdrawal.Status, &withdrawal.WithdrawnAt, &withdrawal.Amount, &withdrawal.ReferenceID, &withdrawal.Currency, &withdrawal.ExpiresAt, &withdrawal.SettledAt, &withdrawal.RefundedAmount, &withdrawal.ConversionID, &withdrawal.ReversalOf); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -41,24 +53,96 @@ func (r *WithdrawalRepository) GetWithdrawalByReferenceId(ctx context.Context, r
 	return withdrawal, nil
 }
 
+func (r *WithdrawalRepository) GetWithdrawalsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT
+			id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at,
+			refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '')
+		FROM withdrawals WHERE withdrawn_by = $1
+	`
+	clause, args := transactionFilterClause(filter, "withdrawn_at", []interface{}{customerXid})
+
+	rows, err := r.db.QueryContext(ctx, query+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	withdrawals := []*model.Withdrawal{}
+	for rows.Next() {
+		withdrawal := &model.Withdrawal{}
+		if err := rows.Scan(&withdrawal.ID, &withdrawal.WithdrawnBy, &withdrawal.Status, &withdrawal.WithdrawnAt, &withdrawal.Amount, &withdrawal.ReferenceID, &withdrawal.Currency, &withdrawal.ExpiresAt, &withdrawal.SettledAt, &withdrawal.RefundedAmount, &withdrawal.ConversionID, &withdrawal.ReversalOf); err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
 func (r *WithdrawalRepository) CreateWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	err = checkUsageLimits(ctx, tx, wallet, withdrawal.Amount, true, withdrawal.WithdrawnAt, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
 	row := tx.QueryRowContext(ctx, `INSERT INTO
-		withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id) 
-		VALUES ($1, $2, $3, $4, $5) RETURNING id`, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID)
+		withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency) 
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID, withdrawal.Currency)
 	err = row.Scan(&withdrawal.ID)
 	if err != nil {
 		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
 		return nil, err
 	}
 
+	// The balance check and the decrement happen in one statement, which takes
+	// the row lock, so parallel withdrawals can not both pass the check and
+	// overdraw the wallet.
 	row = tx.QueryRowContext(ctx, `UPDATE wallets
-		SET balance = $1 WHERE id = $2 RETURNING id`, wallet.Balance, wallet.ID)
-	err = row.Scan(&wallet.ID)
+		SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance`, withdrawal.Amount, wallet.ID, wallet.Status)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletUpdateError(ctx, tx, wallet, commonerr.ErrBalanceNotEnough)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewWithdrawalEntry(wallet.ID, withdrawal.ID, withdrawal.Currency, withdrawal.Amount, withdrawal.WithdrawnAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Add(withdrawal.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalCreated, audit.ResourceWithdrawal, withdrawal.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewWithdrawalEvent(withdrawal, wallet, withdrawal.WithdrawnAt))
 	if err != nil {
 		_ = tx.Rollback()
 		return nil, err
@@ -72,3 +156,227 @@ func (r *WithdrawalRepository) CreateWithdrawal(ctx context.Context, withdrawal
 
 	return withdrawal, nil
 }
+
+// GetWithdrawalById returns the withdrawal of the customer, nil when the
+// customer has no withdrawal with that id.
+func (r *WithdrawalRepository) GetWithdrawalById(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	query := `
+		SELECT
+			id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at,
+			refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '')
+		FROM withdrawals WHERE id = $1 AND withdrawn_by = $2
+	`
+
+	withdrawal := &model.Withdrawal{}
+	row := r.db.QueryRowContext(ctx, query, id, customerXid)
+	if err := row.Scan(&withdrawal.ID, &withdrawal.WithdrawnBy, &withdrawal.Status, &withdrawal.WithdrawnAt, &withdrawal.Amount, &withdrawal.ReferenceID, &withdrawal.Currency, &withdrawal.ExpiresAt, &withdrawal.SettledAt, &withdrawal.RefundedAmount, &withdrawal.ConversionID, &withdrawal.ReversalOf); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// GetExpiredWithdrawals returns up to limit withdrawals still in status whose
+// hold lapsed at or before now, the oldest first.
+func (r *WithdrawalRepository) GetExpiredWithdrawals(ctx context.Context, status int, now time.Time, limit int) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT
+			id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at,
+			refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '')
+		FROM withdrawals WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	withdrawals := []*model.Withdrawal{}
+	for rows.Next() {
+		withdrawal := &model.Withdrawal{}
+		if err := rows.Scan(&withdrawal.ID, &withdrawal.WithdrawnBy, &withdrawal.Status, &withdrawal.WithdrawnAt, &withdrawal.Amount, &withdrawal.ReferenceID, &withdrawal.Currency, &withdrawal.ExpiresAt, &withdrawal.SettledAt, &withdrawal.RefundedAmount, &withdrawal.ConversionID, &withdrawal.ReversalOf); err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// CreatePendingWithdrawal records the withdrawal and holds its amount on the
+// wallet. Nothing is posted to the ledger until the withdrawal is captured.
+func (r *WithdrawalRepository) CreatePendingWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkUsageLimits(ctx, tx, wallet, withdrawal.Amount, true, withdrawal.WithdrawnAt, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO
+		withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID, withdrawal.Currency, withdrawal.ExpiresAt)
+	err = row.Scan(&withdrawal.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return nil, err
+	}
+
+	// As with a withdrawal, the check and the hold are one statement so
+	// parallel requests can not hold more than the wallet has.
+	row = tx.QueryRowContext(ctx, `UPDATE wallets
+		SET held_balance = held_balance + $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance`, withdrawal.Amount, wallet.ID, wallet.Status)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletUpdateError(ctx, tx, wallet, commonerr.ErrBalanceNotEnough)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.HeldBalance = wallet.HeldBalance.Sub(withdrawal.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalHeld, audit.ResourceWithdrawal, withdrawal.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// CaptureWithdrawal moves the withdrawal from status from to its status, takes
+// the held amount off the wallet balance and posts it to the ledger.
+func (r *WithdrawalRepository) CaptureWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = settleWithdrawal(ctx, tx, withdrawal, from)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `UPDATE wallets
+		SET balance = balance - $1, held_balance = held_balance - $1 WHERE id = $2 AND status = $3 RETURNING balance, held_balance`, withdrawal.Amount, wallet.ID, wallet.Status)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = walletUpdateError(ctx, tx, wallet, err)
+		}
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = ledger.Post(ctx, tx, ledger.NewWithdrawalEntry(wallet.ID, withdrawal.ID, withdrawal.Currency, withdrawal.Amount, *withdrawal.SettledAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = verifyWalletBalance(ctx, tx, wallet)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.Balance = wallet.Balance.Add(withdrawal.Amount)
+	before.HeldBalance = wallet.HeldBalance.Add(withdrawal.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalCaptured, audit.ResourceWithdrawal, withdrawal.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = webhook.Enqueue(ctx, tx, webhook.NewWithdrawalEvent(withdrawal, wallet, *withdrawal.SettledAt))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// ReleaseWithdrawal moves the withdrawal from status from to its status, voided
+// or expired, and gives the held amount back to the wallet.
+func (r *WithdrawalRepository) ReleaseWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = settleWithdrawal(ctx, tx, withdrawal, from)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `UPDATE wallets
+		SET held_balance = held_balance - $1 WHERE id = $2 RETURNING balance, held_balance`, withdrawal.Amount, wallet.ID)
+	err = row.Scan(&wallet.Balance, &wallet.HeldBalance)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	before := audit.StateOf(wallet)
+	before.HeldBalance = wallet.HeldBalance.Add(withdrawal.Amount)
+	err = audit.Record(ctx, tx, audit.NewWalletEvent(audit.ActionWithdrawalReleased, audit.ResourceWithdrawal, withdrawal.ID, wallet, before))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// settleWithdrawal writes the status and settled_at of the withdrawal if it is
+// still in status from. Of a capture and a void racing for one withdrawal, only
+// the first to take the row lock finds it there.
+func settleWithdrawal(ctx context.Context, tx *sql.Tx, withdrawal *model.Withdrawal, from int) error {
+	row := tx.QueryRowContext(ctx, `UPDATE withdrawals
+		SET status = $1, settled_at = $2 WHERE id = $3 AND status = $4 RETURNING id`, withdrawal.Status, withdrawal.SettledAt, withdrawal.ID, from)
+	err := row.Scan(&withdrawal.ID)
+	if err == sql.ErrNoRows {
+		return commonerr.ErrWithdrawalTransition
+	}
+
+	return err
+}
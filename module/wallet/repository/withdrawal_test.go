@@ -3,27 +3,45 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/repository"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
 	withdrawal = &model.Withdrawal{
-		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
-		WithdrawnBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
-		Status:      1,
-		WithdrawnAt: now,
-		Amount:      decimal.NewFromInt(1000),
-		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		ID:             "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		WithdrawnBy:    "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:         1,
+		WithdrawnAt:    now,
+		Amount:         decimal.NewFromInt(1000),
+		ReferenceID:    "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:       "IDR",
+		RefundedAmount: decimal.NewFromInt(0),
+	}
+	expiresAt         = now.Add(time.Hour)
+	pendingWithdrawal = &model.Withdrawal{
+		ID:             "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		WithdrawnBy:    "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:         3,
+		WithdrawnAt:    now,
+		Amount:         decimal.NewFromInt(1000),
+		ReferenceID:    "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:       "IDR",
+		ExpiresAt:      &expiresAt,
+		RefundedAmount: decimal.NewFromInt(0),
 	}
 )
 
@@ -57,23 +75,23 @@ func TestWithdrawal_GetWithdrawalByReferenceId(t *testing.T) {
 			db := sqlxDB.DB
 			repo := repository.NewWithdrawalRepository(db)
 
-			column := []string{"id", "withdrawn_by", "status", "withdrawn_at", "amount", "reference_id"}
-			row := sMock.NewRows(column).AddRow(withdrawal.ID, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID)
+			column := []string{"id", "withdrawn_by", "status", "withdrawn_at", "amount", "reference_id", "currency", "expires_at", "settled_at", "refunded_amount", "conversion_id", "reversal_of"}
+			row := sMock.NewRows(column).AddRow(withdrawal.ID, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID, withdrawal.Currency, nil, nil, withdrawal.RefundedAmount, "", "")
 
 			if tc.err != nil {
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id FROM withdrawals WHERE reference_id = $1")).
-					WithArgs(tc.req).WillReturnError(tc.err)
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 AND reference_id = $2")).
+					WithArgs(withdrawal.WithdrawnBy, tc.req).WillReturnError(tc.err)
 			} else {
 				if tc.wantErr {
 					column = []string{"id", "id"}
 					row = sMock.NewRows(column).AddRow(deposit.ID, deposit.ID)
 				}
 
-				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id FROM withdrawals WHERE reference_id = $1")).
-					WithArgs(tc.req).WillReturnRows(row)
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 AND reference_id = $2")).
+					WithArgs(withdrawal.WithdrawnBy, tc.req).WillReturnRows(row)
 			}
 
-			result, _ := repo.GetWithdrawalByReferenceId(context.Background(), tc.req)
+			result, _ := repo.GetWithdrawalByReferenceId(context.Background(), withdrawal.WithdrawnBy, tc.req)
 
 			if !reflect.DeepEqual(result, tc.result) {
 				t.Errorf("Wallet.GetWithdrawalByReferenceId() = %v, want %v", result, tc.result)
@@ -82,6 +100,88 @@ func TestWithdrawal_GetWithdrawalByReferenceId(t *testing.T) {
 	}
 }
 
+func TestWithdrawal_GetWithdrawalsByCustomerXid(t *testing.T) {
+	from := now.Add(-24 * time.Hour)
+	to := now
+	testCases := map[string]struct {
+		filter  model.TransactionFilter
+		query   string
+		args    []driver.Value
+		result  []*model.Withdrawal
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 ORDER BY withdrawn_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{withdrawal.WithdrawnBy, 11},
+			result:  []*model.Withdrawal{withdrawal},
+			wantErr: false,
+		},
+		"success with filter": {
+			filter: model.TransactionFilter{
+				From:   &from,
+				To:     &to,
+				Cursor: &model.TransactionCursor{TransactedAt: now, ID: withdrawal.ID},
+				Limit:  11,
+			},
+			query:   "SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 AND withdrawn_at >= $2 AND withdrawn_at <= $3 AND (withdrawn_at, id) < ($4, $5::uuid) ORDER BY withdrawn_at DESC, id DESC LIMIT $6",
+			args:    []driver.Value{withdrawal.WithdrawnBy, from, to, now, withdrawal.ID, 11},
+			result:  []*model.Withdrawal{withdrawal},
+			wantErr: false,
+		},
+		"failed query": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 ORDER BY withdrawn_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{withdrawal.WithdrawnBy, 11},
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+		"failed scan": {
+			filter:  model.TransactionFilter{Limit: 11},
+			query:   "SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE withdrawn_by = $1 ORDER BY withdrawn_at DESC, id DESC LIMIT $2",
+			args:    []driver.Value{withdrawal.WithdrawnBy, 11},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+
+			column := []string{"id", "withdrawn_by", "status", "withdrawn_at", "amount", "reference_id", "currency", "expires_at", "settled_at", "refunded_amount", "conversion_id", "reversal_of"}
+			row := sMock.NewRows(column).AddRow(withdrawal.ID, withdrawal.WithdrawnBy, withdrawal.Status, withdrawal.WithdrawnAt, withdrawal.Amount, withdrawal.ReferenceID, withdrawal.Currency, nil, nil, withdrawal.RefundedAmount, "", "")
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(withdrawal.ID, withdrawal.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta(tc.query)).
+					WithArgs(tc.args...).WillReturnRows(row)
+			}
+
+			result, err := repo.GetWithdrawalsByCustomerXid(context.Background(), withdrawal.WithdrawnBy, tc.filter)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Withdrawal.GetWithdrawalsByCustomerXid() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
 func TestWithdrawal_CreateWithdrawal(t *testing.T) {
 	testCases := map[string]struct {
 		reqWithdrawal     *model.Withdrawal
@@ -91,8 +191,13 @@ func TestWithdrawal_CreateWithdrawal(t *testing.T) {
 		wantErrTx         bool
 		wantErrWithdrawal bool
 		wantErrWallet     bool
+		wantErrLedger     bool
+		wantErrMismatch   bool
+		wantErrAudit      bool
 		wantErrCommit     bool
+		walletStatus      int
 		err               error
+		resultErr         error
 	}{
 		"success": {
 			reqWithdrawal:     withdrawal,
@@ -111,6 +216,14 @@ func TestWithdrawal_CreateWithdrawal(t *testing.T) {
 			wantErrTx:     true,
 			err:           errors.New("database error"),
 		},
+		"failed reference id used": {
+			reqWithdrawal:     withdrawal,
+			reqWallet:         walletEnable,
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               &pq.Error{Code: "23505"},
+			resultErr:         commonerr.ErrReferenceIdUsed,
+		},
 		"failed withdrawal": {
 			reqWithdrawal:     withdrawal,
 			reqWallet:         walletEnable,
@@ -128,6 +241,46 @@ func TestWithdrawal_CreateWithdrawal(t *testing.T) {
 			wantErrWallet:     true,
 			err:               errors.New("database error"),
 		},
+		"failed balance not enough": {
+			reqWithdrawal:     withdrawal,
+			reqWallet:         walletEnable,
+			wantErr:           true,
+			wantErrTx:         false,
+			wantErrWithdrawal: false,
+			wantErrWallet:     true,
+			walletStatus:      1,
+			err:               sql.ErrNoRows,
+			resultErr:         commonerr.ErrBalanceNotEnough,
+		},
+		"failed wallet frozen": {
+			reqWithdrawal: withdrawal,
+			reqWallet:     walletEnable,
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  3,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed ledger": {
+			reqWithdrawal: withdrawal,
+			reqWallet:     walletEnable,
+			wantErr:       true,
+			wantErrLedger: true,
+			err:           errors.New("database error"),
+		},
+		"failed ledger mismatch": {
+			reqWithdrawal:   withdrawal,
+			reqWallet:       walletEnable,
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			reqWithdrawal: withdrawal,
+			reqWallet:     walletEnable,
+			wantErr:       true,
+			wantErrAudit:  true,
+			err:           errors.New("database error"),
+		},
 		"failed commit": {
 			reqWithdrawal:     withdrawal,
 			reqWallet:         walletEnable,
@@ -155,40 +308,566 @@ func TestWithdrawal_CreateWithdrawal(t *testing.T) {
 				column := []string{"id"}
 				row := sMock.NewRows(column).AddRow(withdrawal.ID)
 				if tc.wantErrWithdrawal {
-					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
-						WithArgs(tc.reqWithdrawal.WithdrawnBy, tc.reqWithdrawal.Status, tc.reqWithdrawal.WithdrawnAt, tc.reqWithdrawal.Amount, tc.reqWithdrawal.ReferenceID).WillReturnError(tc.err)
+					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id")).
+						WithArgs(tc.reqWithdrawal.WithdrawnBy, tc.reqWithdrawal.Status, tc.reqWithdrawal.WithdrawnAt, tc.reqWithdrawal.Amount, tc.reqWithdrawal.ReferenceID, tc.reqWithdrawal.Currency).WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id")).
+						WithArgs(tc.reqWithdrawal.WithdrawnBy, tc.reqWithdrawal.Status, tc.reqWithdrawal.WithdrawnAt, tc.reqWithdrawal.Amount, tc.reqWithdrawal.ReferenceID, tc.reqWithdrawal.Currency).WillReturnRows(row)
+					row = sMock.NewRows([]string{"balance", "held_balance"}).AddRow(walletEnable.Balance, walletEnable.HeldBalance)
+					if tc.wantErrWallet {
+						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance")).
+							WithArgs(tc.reqWithdrawal.Amount, tc.reqWallet.ID, tc.reqWallet.Status).WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, tc.reqWallet.ID, tc.walletStatus)
+						}
+						sMock.ExpectRollback()
+					} else {
+						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance")).
+							WithArgs(tc.reqWithdrawal.Amount, tc.reqWallet.ID, tc.reqWallet.Status).WillReturnRows(row)
+						if tc.wantErrLedger {
+							sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind, reference_id, created_at) VALUES ($1, $2, $3) RETURNING id")).
+								WillReturnError(tc.err)
+							sMock.ExpectRollback()
+						} else if tc.wantErrMismatch {
+							expectLedgerEntry(sMock, walletEnable.Balance.Add(decimal.NewFromInt(1)))
+							sMock.ExpectRollback()
+						} else if tc.wantErrAudit {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							expectAuditEvent(sMock, nil)
+							expectWebhookEvent(sMock, nil)
+							if tc.wantErrCommit {
+								sMock.ExpectCommit().WillReturnError(tc.err)
+								sMock.ExpectRollback()
+							} else {
+								sMock.ExpectCommit()
+							}
+						}
+					}
+				}
+			}
+
+			wallet := *tc.reqWallet
+			result, err := repo.CreateWithdrawal(context.Background(), tc.reqWithdrawal, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Wallet.CreateWithdrawal() = %v, want %v", result, tc.result)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestWithdrawal_GetWithdrawalById(t *testing.T) {
+	testCases := map[string]struct {
+		req     string
+		result  *model.Withdrawal
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			req:     pendingWithdrawal.ID,
+			result:  pendingWithdrawal,
+			wantErr: false,
+		},
+		"failed scan": {
+			req:     pendingWithdrawal.ID,
+			wantErr: true,
+		},
+		"failed sql no row": {
+			req:     pendingWithdrawal.ID,
+			wantErr: false,
+			err:     sql.ErrNoRows,
+		},
+		"failed query": {
+			req:     pendingWithdrawal.ID,
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+
+			column := []string{"id", "withdrawn_by", "status", "withdrawn_at", "amount", "reference_id", "currency", "expires_at", "settled_at", "refunded_amount", "conversion_id", "reversal_of"}
+			row := sMock.NewRows(column).AddRow(pendingWithdrawal.ID, pendingWithdrawal.WithdrawnBy, pendingWithdrawal.Status, pendingWithdrawal.WithdrawnAt, pendingWithdrawal.Amount, pendingWithdrawal.ReferenceID, pendingWithdrawal.Currency, *pendingWithdrawal.ExpiresAt, nil, pendingWithdrawal.RefundedAmount, "", "")
+
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE id = $1 AND withdrawn_by = $2")).
+					WithArgs(tc.req, pendingWithdrawal.WithdrawnBy).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(pendingWithdrawal.ID, pendingWithdrawal.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta("SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE id = $1 AND withdrawn_by = $2")).
+					WithArgs(tc.req, pendingWithdrawal.WithdrawnBy).WillReturnRows(row)
+			}
+
+			result, err := repo.GetWithdrawalById(context.Background(), pendingWithdrawal.WithdrawnBy, tc.req)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Withdrawal.GetWithdrawalById() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestWithdrawal_GetExpiredWithdrawals(t *testing.T) {
+	testCases := map[string]struct {
+		result  []*model.Withdrawal
+		wantErr bool
+		err     error
+	}{
+		"success": {
+			result:  []*model.Withdrawal{pendingWithdrawal},
+			wantErr: false,
+		},
+		"failed query": {
+			wantErr: true,
+			err:     errors.New("database error"),
+		},
+		"failed scan": {
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+
+			column := []string{"id", "withdrawn_by", "status", "withdrawn_at", "amount", "reference_id", "currency", "expires_at", "settled_at", "refunded_amount", "conversion_id", "reversal_of"}
+			row := sMock.NewRows(column).AddRow(pendingWithdrawal.ID, pendingWithdrawal.WithdrawnBy, pendingWithdrawal.Status, pendingWithdrawal.WithdrawnAt, pendingWithdrawal.Amount, pendingWithdrawal.ReferenceID, pendingWithdrawal.Currency, *pendingWithdrawal.ExpiresAt, nil, pendingWithdrawal.RefundedAmount, "", "")
+
+			query := "SELECT id, withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at, settled_at, refunded_amount, COALESCE(conversion_id::text, ''), COALESCE(reversal_of::text, '') FROM withdrawals WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at LIMIT $3"
+			if tc.err != nil {
+				sMock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, expiresAt, 100).WillReturnError(tc.err)
+			} else {
+				if tc.wantErr {
+					column = []string{"id", "id"}
+					row = sMock.NewRows(column).AddRow(pendingWithdrawal.ID, pendingWithdrawal.ID)
+				}
+
+				sMock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, expiresAt, 100).WillReturnRows(row)
+			}
+
+			result, err := repo.GetExpiredWithdrawals(context.Background(), 3, expiresAt, 100)
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Withdrawal.GetExpiredWithdrawals() = %v, want %v", result, tc.result)
+			}
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestWithdrawal_CreatePendingWithdrawal(t *testing.T) {
+	testCases := map[string]struct {
+		result            *model.Withdrawal
+		wantErr           bool
+		wantErrTx         bool
+		wantErrWithdrawal bool
+		wantErrWallet     bool
+		wantErrAudit      bool
+		wantErrCommit     bool
+		walletStatus      int
+		err               error
+		resultErr         error
+	}{
+		"success": {
+			result:  pendingWithdrawal,
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed reference id used": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               &pq.Error{Code: "23505"},
+			resultErr:         commonerr.ErrReferenceIdUsed,
+		},
+		"failed withdrawal": {
+			wantErr:           true,
+			wantErrWithdrawal: true,
+			err:               errors.New("database error"),
+		},
+		"failed balance not enough": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  1,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrBalanceNotEnough,
+		},
+		"failed wallet disabled": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  2,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+			wallet := *walletEnable
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				insert := sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id, currency, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id")).
+					WithArgs(pendingWithdrawal.WithdrawnBy, pendingWithdrawal.Status, pendingWithdrawal.WithdrawnAt, pendingWithdrawal.Amount, pendingWithdrawal.ReferenceID, pendingWithdrawal.Currency, pendingWithdrawal.ExpiresAt)
+				if tc.wantErrWithdrawal {
+					insert.WillReturnError(tc.err)
 					sMock.ExpectRollback()
 				} else {
-					sMock.ExpectQuery(regexp.QuoteMeta("INSERT INTO withdrawals (withdrawn_by, status, withdrawn_at, amount, reference_id) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
-						WithArgs(tc.reqWithdrawal.WithdrawnBy, tc.reqWithdrawal.Status, tc.reqWithdrawal.WithdrawnAt, tc.reqWithdrawal.Amount, tc.reqWithdrawal.ReferenceID).WillReturnRows(row)
-					row = sMock.NewRows(column).AddRow(walletEnable.ID)
+					insert.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(pendingWithdrawal.ID))
+					hold := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET held_balance = held_balance + $1 WHERE id = $2 AND status = $3 AND balance - held_balance >= $1 RETURNING balance, held_balance")).
+						WithArgs(pendingWithdrawal.Amount, wallet.ID, wallet.Status)
 					if tc.wantErrWallet {
-						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE id = $2 RETURNING id")).
-							WithArgs(tc.reqWallet.Balance, tc.reqWallet.ID).WillReturnError(tc.err)
+						hold.WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, wallet.ID, tc.walletStatus)
+						}
 						sMock.ExpectRollback()
 					} else {
-						sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE id = $2 RETURNING id")).
-							WithArgs(tc.reqWallet.Balance, tc.reqWallet.ID).WillReturnRows(row)
-						if tc.wantErrCommit {
+						hold.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(wallet.Balance, pendingWithdrawal.Amount))
+						if tc.wantErrAudit {
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else if tc.wantErrCommit {
+							expectAuditEvent(sMock, nil)
 							sMock.ExpectCommit().WillReturnError(tc.err)
+						} else {
+							expectAuditEvent(sMock, nil)
+							sMock.ExpectCommit()
+						}
+					}
+				}
+			}
+
+			result, err := repo.CreatePendingWithdrawal(context.Background(), pendingWithdrawal, &wallet)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Withdrawal.CreatePendingWithdrawal() = %v, want %v", result, tc.result)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.True(t, wallet.HeldBalance.Equal(pendingWithdrawal.Amount))
+			}
+		})
+	}
+}
+
+func TestWithdrawal_CaptureWithdrawal(t *testing.T) {
+	settledAt := now.Add(time.Minute)
+	captured := *pendingWithdrawal
+	captured.Status = 1
+	captured.SettledAt = &settledAt
+	balance := walletEnable.Balance.Sub(captured.Amount)
+
+	testCases := map[string]struct {
+		result          *model.Withdrawal
+		wantErr         bool
+		wantErrTx       bool
+		wantErrStatus   bool
+		wantErrWallet   bool
+		wantErrMismatch bool
+		wantErrAudit    bool
+		wantErrCommit   bool
+		walletStatus    int
+		err             error
+		resultErr       error
+	}{
+		"success": {
+			result:  &captured,
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed not pending": {
+			wantErr:       true,
+			wantErrStatus: true,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWithdrawalTransition,
+		},
+		"failed status": {
+			wantErr:       true,
+			wantErrStatus: true,
+			err:           errors.New("database error"),
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed wallet frozen": {
+			wantErr:       true,
+			wantErrWallet: true,
+			walletStatus:  3,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWalletTransition,
+		},
+		"failed ledger mismatch": {
+			wantErr:         true,
+			wantErrMismatch: true,
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+			wallet := *walletEnable
+			wallet.HeldBalance = captured.Amount
+			req := captured
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				status := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE withdrawals SET status = $1, settled_at = $2 WHERE id = $3 AND status = $4 RETURNING id")).
+					WithArgs(captured.Status, captured.SettledAt, captured.ID, 3)
+				if tc.wantErrStatus {
+					status.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					status.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(captured.ID))
+					debit := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1, held_balance = held_balance - $1 WHERE id = $2 AND status = $3 RETURNING balance, held_balance")).
+						WithArgs(captured.Amount, wallet.ID, wallet.Status)
+					if tc.wantErrWallet {
+						debit.WillReturnError(tc.err)
+						if tc.err == sql.ErrNoRows {
+							expectWalletStatus(sMock, wallet.ID, tc.walletStatus)
+						}
+						sMock.ExpectRollback()
+					} else {
+						debit.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(balance, decimal.Zero))
+						if tc.wantErrMismatch {
+							expectLedgerEntry(sMock, walletEnable.Balance)
+							sMock.ExpectRollback()
+						} else if tc.wantErrAudit {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, tc.err)
 							sMock.ExpectRollback()
 						} else {
+							expectLedgerEntry(sMock, balance)
+							expectAuditEvent(sMock, nil)
+							expectWebhookEvent(sMock, nil)
+							if tc.wantErrCommit {
+								sMock.ExpectCommit().WillReturnError(tc.err)
+							} else {
+								sMock.ExpectCommit()
+							}
+						}
+					}
+				}
+			}
+
+			result, err := repo.CaptureWithdrawal(context.Background(), &req, &wallet, 3)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
+
+			if !reflect.DeepEqual(result, tc.result) {
+				t.Errorf("Withdrawal.CaptureWithdrawal() = %v, want %v", result, tc.result)
+			}
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.True(t, wallet.Balance.Equal(balance))
+				assert.True(t, wallet.HeldBalance.IsZero())
+			}
+		})
+	}
+}
+
+func TestWithdrawal_ReleaseWithdrawal(t *testing.T) {
+	settledAt := now.Add(time.Minute)
+	voided := *pendingWithdrawal
+	voided.Status = 4
+	voided.SettledAt = &settledAt
+
+	testCases := map[string]struct {
+		result        *model.Withdrawal
+		wantErr       bool
+		wantErrTx     bool
+		wantErrStatus bool
+		wantErrWallet bool
+		wantErrAudit  bool
+		wantErrCommit bool
+		err           error
+		resultErr     error
+	}{
+		"success": {
+			result:  &voided,
+			wantErr: false,
+		},
+		"failed tx": {
+			wantErr:   true,
+			wantErrTx: true,
+			err:       errors.New("database error"),
+		},
+		"failed not pending": {
+			wantErr:       true,
+			wantErrStatus: true,
+			err:           sql.ErrNoRows,
+			resultErr:     commonerr.ErrWithdrawalTransition,
+		},
+		"failed wallet": {
+			wantErr:       true,
+			wantErrWallet: true,
+			err:           errors.New("database error"),
+		},
+		"failed audit": {
+			wantErr:      true,
+			wantErrAudit: true,
+			err:          errors.New("database error"),
+		},
+		"failed commit": {
+			wantErr:       true,
+			wantErrCommit: true,
+			err:           errors.New("database error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB, sMock, _ := sqlmock.New()
+			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+			db := sqlxDB.DB
+			repo := repository.NewWithdrawalRepository(db)
+			wallet := *walletEnable
+			wallet.HeldBalance = voided.Amount
+			req := voided
+
+			if tc.wantErrTx {
+				sMock.ExpectBegin().WillReturnError(tc.err)
+			} else {
+				sMock.ExpectBegin()
+				status := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE withdrawals SET status = $1, settled_at = $2 WHERE id = $3 AND status = $4 RETURNING id")).
+					WithArgs(voided.Status, voided.SettledAt, voided.ID, 3)
+				if tc.wantErrStatus {
+					status.WillReturnError(tc.err)
+					sMock.ExpectRollback()
+				} else {
+					status.WillReturnRows(sMock.NewRows([]string{"id"}).AddRow(voided.ID))
+					release := sMock.ExpectQuery(regexp.QuoteMeta("UPDATE wallets SET held_balance = held_balance - $1 WHERE id = $2 RETURNING balance, held_balance")).
+						WithArgs(voided.Amount, wallet.ID)
+					if tc.wantErrWallet {
+						release.WillReturnError(tc.err)
+						sMock.ExpectRollback()
+					} else {
+						release.WillReturnRows(sMock.NewRows([]string{"balance", "held_balance"}).AddRow(wallet.Balance, decimal.Zero))
+						if tc.wantErrAudit {
+							expectAuditEvent(sMock, tc.err)
+							sMock.ExpectRollback()
+						} else if tc.wantErrCommit {
+							expectAuditEvent(sMock, nil)
+							sMock.ExpectCommit().WillReturnError(tc.err)
+						} else {
+							expectAuditEvent(sMock, nil)
 							sMock.ExpectCommit()
 						}
 					}
 				}
 			}
 
-			result, err := repo.CreateWithdrawal(context.Background(), tc.reqWithdrawal, tc.reqWallet)
+			result, err := repo.ReleaseWithdrawal(context.Background(), &req, &wallet, 3)
+
+			if tc.resultErr != nil {
+				assert.Equal(t, tc.resultErr, err)
+			}
 
 			if !reflect.DeepEqual(result, tc.result) {
-				t.Errorf("Wallet.CreateWithdrawal() = %v, want %v", result, tc.result)
+				t.Errorf("Withdrawal.ReleaseWithdrawal() = %v, want %v", result, tc.result)
 			}
 
 			if tc.wantErr {
 				assert.NotNil(t, err)
 			} else {
 				assert.Nil(t, err)
+				assert.True(t, wallet.Balance.Equal(walletEnable.Balance))
+				assert.True(t, wallet.HeldBalance.IsZero())
 			}
 		})
 	}
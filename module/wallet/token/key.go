@@ -0,0 +1,66 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) == 0 {
+		return Key{}, fmt.Errorf("token key %q secret empty", id)
+	}
+
+	return Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   secret,
+		VerifyKey: secret,
+	}, nil
+}
+
+// NewKeyFromPEM builds an RS256 or EdDSA key from a PEM encoded private key,
+// or a verify-only key when the PEM holds a public key.
+func NewKeyFromPEM(id, algorithm string, data []byte) (Key, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			return Key{ID: id, Method: jwt.SigningMethodRS256, SignKey: privateKey, VerifyKey: &privateKey.PublicKey}, nil
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("token key %q: %w", id, err)
+		}
+		return Key{ID: id, Method: jwt.SigningMethodRS256, VerifyKey: publicKey}, nil
+	case AlgorithmEdDSA:
+		if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			return Key{ID: id, Method: jwt.SigningMethodEdDSA, SignKey: privateKey, VerifyKey: privateKey.(crypto.Signer).Public()}, nil
+		}
+
+		publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("token key %q: %w", id, err)
+		}
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, VerifyKey: publicKey}, nil
+	}
+
+	return Key{}, fmt.Errorf("token key %q algorithm %q not supported", id, algorithm)
+}
+
+func NewRSAKey(id string, privateKey *rsa.PrivateKey) Key {
+	return Key{ID: id, Method: jwt.SigningMethodRS256, SignKey: privateKey, VerifyKey: &privateKey.PublicKey}
+}
+
+func NewEdDSAKey(id string, privateKey ed25519.PrivateKey) Key {
+	return Key{ID: id, Method: jwt.SigningMethodEdDSA, SignKey: privateKey, VerifyKey: privateKey.Public()}
+}
@@ -0,0 +1,121 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultRevocationCacheTTL = 30 * time.Second
+
+// RevocationStore keeps the IDs of tokens that were revoked before they
+// expired.
+type RevocationStore interface {
+	RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error)
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RevocationCache sits in front of a RevocationStore so the auth middleware
+// does not query it on every request. A revoked token is remembered until it
+// expires. A token found not revoked is remembered for ttl, which bounds how
+// long a revocation made by another instance can go unnoticed here.
+type RevocationCache struct {
+	store RevocationStore
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	revoked   map[string]time.Time
+	valid     map[string]time.Time
+	evictedAt time.Time
+}
+
+func NewRevocationCache(store RevocationStore, ttl time.Duration) *RevocationCache {
+	if ttl <= 0 {
+		ttl = DefaultRevocationCacheTTL
+	}
+
+	return &RevocationCache{
+		store:   store,
+		ttl:     ttl,
+		now:     time.Now,
+		revoked: map[string]time.Time{},
+		valid:   map[string]time.Time{},
+	}
+}
+
+// RevokeToken revokes the token in the store and reports whether this call
+// revoked it, false when it was already revoked.
+func (c *RevocationCache) RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error) {
+	revoked, err := c.store.RevokeToken(ctx, jti, customerXid, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict(c.now())
+	delete(c.valid, jti)
+	c.revoked[jti] = expiresAt
+
+	return revoked, nil
+}
+
+// PurgeRevokedTokens deletes the expired revocations from the store. The
+// cache drops its own entries as they expire.
+func (c *RevocationCache) PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	return c.store.PurgeRevokedTokens(ctx, now)
+}
+
+func (c *RevocationCache) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	if _, ok := c.revoked[jti]; ok {
+		c.mu.Unlock()
+		return true, nil
+	}
+	if until, ok := c.valid[jti]; ok && now.Before(until) {
+		c.mu.Unlock()
+		return false, nil
+	}
+	c.mu.Unlock()
+
+	revoked, err := c.store.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict(now)
+	if revoked {
+		// The expiry is unknown here; keep it for one ttl like a valid entry.
+		c.revoked[jti] = now.Add(c.ttl)
+	} else {
+		c.valid[jti] = now.Add(c.ttl)
+	}
+
+	return revoked, nil
+}
+
+// evict drops entries that no longer need to be remembered, at most once per
+// ttl. It must be called with mu held.
+func (c *RevocationCache) evict(now time.Time) {
+	if now.Sub(c.evictedAt) < c.ttl {
+		return
+	}
+	c.evictedAt = now
+
+	for jti, expiresAt := range c.revoked {
+		if !now.Before(expiresAt) {
+			delete(c.revoked, jti)
+		}
+	}
+	for jti, until := range c.valid {
+		if !now.Before(until) {
+			delete(c.valid, jti)
+		}
+	}
+}
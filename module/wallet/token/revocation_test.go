@@ -0,0 +1,93 @@
+package token_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/token"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRevocationStore struct {
+	revoked map[string]bool
+	lookups int
+	err     error
+}
+
+func (s *fakeRevocationStore) RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.revoked[jti] {
+		return false, nil
+	}
+	s.revoked[jti] = true
+	return true, nil
+}
+
+func (s *fakeRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.lookups++
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.revoked[jti], nil
+}
+
+func (s *fakeRevocationStore) PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	return 0, s.err
+}
+
+func TestRevocationCache_IsTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeRevocationStore{revoked: map[string]bool{"revoked-elsewhere": true}}
+	cache := token.NewRevocationCache(store, time.Hour)
+
+	revoked, err := cache.IsTokenRevoked(ctx, "valid")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+
+	// The second lookup of a valid token is answered from the cache.
+	revoked, err = cache.IsTokenRevoked(ctx, "valid")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, store.lookups)
+
+	revoked, err = cache.IsTokenRevoked(ctx, "revoked-elsewhere")
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+
+	// Revoking through the cache takes effect immediately, even for a token
+	// cached as valid.
+	revoked, err = cache.RevokeToken(ctx, "valid", customerXid, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+	revoked, err = cache.IsTokenRevoked(ctx, "valid")
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, 2, store.lookups)
+
+	// Revoking it again reports it was already revoked.
+	revoked, err = cache.RevokeToken(ctx, "valid", customerXid, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+}
+
+func TestRevocationCache_StoreError(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeRevocationStore{revoked: map[string]bool{}, err: errors.New("database error")}
+	cache := token.NewRevocationCache(store, time.Hour)
+
+	_, err := cache.IsTokenRevoked(ctx, "valid")
+	assert.NotNil(t, err)
+
+	_, err = cache.RevokeToken(ctx, "valid", customerXid, time.Now().Add(time.Hour))
+	assert.NotNil(t, err)
+
+	// A failed revocation must not be cached as revoked.
+	store.err = nil
+	revoked, err := cache.IsTokenRevoked(ctx, "valid")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+}
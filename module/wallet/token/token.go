@@ -0,0 +1,196 @@
+// Package token issues and verifies the JWT access tokens handed out by the
+// init endpoint. Tokens are signed with the active key and carry its key ID in
+// the "kid" header, so a key can be rotated by making a new key active while
+// the retired ones stay around to verify tokens issued before the switch.
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+// RoleAdmin is the role of the tokens of operations staff, the only tokens
+// the admin API accepts.
+const RoleAdmin = "admin"
+
+const (
+	DefaultKeyID = "default"
+	DefaultTTL   = 24 * time.Hour
+)
+
+var (
+	ErrMalformedToken = errors.New("Token malformed")
+	ErrInvalidToken   = errors.New("Token invalid")
+)
+
+// Key is a signing key pair identified by ID. SignKey is nil for keys that are
+// only kept to verify tokens issued before a rotation.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+type Config struct {
+	Keys        []Key
+	ActiveKeyID string
+	TTL         time.Duration
+	Issuer      string
+	Audience    string
+}
+
+type Service struct {
+	keys      map[string]Key
+	activeKey Key
+	methods   []string
+	ttl       time.Duration
+	issuer    string
+	audience  string
+	now       func() time.Time
+}
+
+func NewService(config Config) (*Service, error) {
+	s := &Service{
+		keys:     map[string]Key{},
+		ttl:      config.TTL,
+		issuer:   config.Issuer,
+		audience: config.Audience,
+		now:      time.Now,
+	}
+	if s.ttl <= 0 {
+		s.ttl = DefaultTTL
+	}
+
+	methods := map[string]bool{}
+	for _, key := range config.Keys {
+		if key.ID == "" || key.Method == nil || key.VerifyKey == nil {
+			return nil, fmt.Errorf("token key %q incomplete", key.ID)
+		}
+		if _, ok := s.keys[key.ID]; ok {
+			return nil, fmt.Errorf("token key %q duplicated", key.ID)
+		}
+		s.keys[key.ID] = key
+
+		if !methods[key.Method.Alg()] {
+			methods[key.Method.Alg()] = true
+			s.methods = append(s.methods, key.Method.Alg())
+		}
+	}
+
+	activeKey, ok := s.keys[config.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("token active key %q not found", config.ActiveKeyID)
+	}
+	if activeKey.SignKey == nil {
+		return nil, fmt.Errorf("token active key %q can not sign", config.ActiveKeyID)
+	}
+	s.activeKey = activeKey
+
+	return s, nil
+}
+
+// Issue signs a token for the customer with the active key. Every token gets a
+// random "jti" so it can be revoked on its own.
+func (s *Service) Issue(customerXid string) (string, error) {
+	return s.issue(customerXid, "")
+}
+
+// IssueAdmin signs a token for the staff member, which the admin API accepts
+// and the customer API refuses.
+func (s *Service) IssueAdmin(staffID string) (string, error) {
+	return s.issue(staffID, RoleAdmin)
+}
+
+func (s *Service) issue(subject, role string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	claims := &model.Claims{
+		CustomerXid: subject,
+		Role:        role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+
+	token := jwt.NewWithClaims(s.activeKey.Method, claims)
+	token.Header["kid"] = s.activeKey.ID
+
+	return token.SignedString(s.activeKey.SignKey)
+}
+
+// Parse verifies the token signature against the key named by its "kid" header
+// and checks its expiry, issuer and audience. Tokens without a "kid" are
+// verified with the active key.
+func (s *Service) Parse(tokenStr string) (*model.Claims, error) {
+	claims := &model.Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(s.methods), jwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(tokenStr, claims, s.verifyKey)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorMalformed != 0 {
+			return nil, ErrMalformedToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	now := s.now()
+	if !claims.VerifyExpiresAt(now, true) || !claims.VerifyNotBefore(now, false) {
+		return nil, ErrInvalidToken
+	}
+	if s.issuer != "" && !claims.VerifyIssuer(s.issuer, true) {
+		return nil, ErrInvalidToken
+	}
+	if s.audience != "" && !claims.VerifyAudience(s.audience, true) {
+		return nil, ErrInvalidToken
+	}
+	if claims.CustomerXid == "" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (s *Service) verifyKey(token *jwt.Token) (interface{}, error) {
+	key := s.activeKey
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		key, ok = s.keys[id]
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	// The algorithm must be the one the key was made for, otherwise an RSA
+	// public key could be used as an HMAC secret.
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.VerifyKey, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,321 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/token"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	customerXid = "ea0212d3-abd6-406f-8c67-868e814a2436"
+	issuer      = "mini-wallet"
+	audience    = "mini-wallet-api"
+)
+
+func newService(t *testing.T, activeKeyID string, keys ...token.Key) *token.Service {
+	service, err := token.NewService(token.Config{
+		Keys:        keys,
+		ActiveKeyID: activeKeyID,
+		TTL:         time.Hour,
+		Issuer:      issuer,
+		Audience:    audience,
+	})
+	if err != nil {
+		t.Fatalf("token.NewService() error = %v", err)
+	}
+
+	return service
+}
+
+func sign(t *testing.T, key token.Key, claims *model.Claims) string {
+	tkn := jwt.NewWithClaims(key.Method, claims)
+	tkn.Header["kid"] = key.ID
+	tokenString, err := tkn.SignedString(key.SignKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	return tokenString
+}
+
+func validClaims() *model.Claims {
+	return &model.Claims{
+		CustomerXid: customerXid,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "4f1c2a9b8e7d6c5b4a3f2e1d0c9b8a7f",
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestService_IssueAndParse(t *testing.T) {
+	hmacKey, _ := token.NewHMACKey("hmac", []byte("secret"))
+	rsaPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	testCases := map[string]struct {
+		key token.Key
+	}{
+		"success HS256": {
+			key: hmacKey,
+		},
+		"success RS256": {
+			key: token.NewRSAKey("rsa", rsaPrivateKey),
+		},
+		"success EdDSA": {
+			key: token.NewEdDSAKey("ed", edPrivateKey),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			service := newService(t, tc.key.ID, tc.key)
+
+			tokenString, err := service.Issue(customerXid)
+			assert.Nil(t, err)
+
+			claims, err := service.Parse(tokenString)
+			assert.Nil(t, err)
+			assert.Equal(t, customerXid, claims.CustomerXid)
+			assert.Empty(t, claims.Role)
+			assert.Equal(t, issuer, claims.Issuer)
+			assert.NotEmpty(t, claims.ID)
+			assert.True(t, claims.ExpiresAt.After(time.Now()))
+		})
+	}
+}
+
+func TestService_IssueAdmin(t *testing.T) {
+	hmacKey, _ := token.NewHMACKey("hmac", []byte("secret"))
+	service := newService(t, hmacKey.ID, hmacKey)
+
+	tokenString, err := service.IssueAdmin("ops-alice")
+	assert.Nil(t, err)
+
+	claims, err := service.Parse(tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, "ops-alice", claims.CustomerXid)
+	assert.Equal(t, token.RoleAdmin, claims.Role)
+}
+
+func TestService_Parse(t *testing.T) {
+	hmacKey, _ := token.NewHMACKey("hmac", []byte("secret"))
+	otherKey, _ := token.NewHMACKey("hmac", []byte("other secret"))
+	unknownKey, _ := token.NewHMACKey("unknown", []byte("secret"))
+	rsaPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaKey := token.NewRSAKey("rsa", rsaPrivateKey)
+	rsaPublicKey, _ := x509.MarshalPKIXPublicKey(&rsaPrivateKey.PublicKey)
+	// An HS256 token keyed with the RSA public key, as in an algorithm
+	// confusion attack against the RS256 key.
+	confusedKey, _ := token.NewHMACKey("rsa", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicKey}))
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "someone-else"
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"someone-else"}
+	noCustomer := validClaims()
+	noCustomer.CustomerXid = ""
+	noID := validClaims()
+	noID.ID = ""
+
+	testCases := map[string]struct {
+		token  string
+		result string
+		err    error
+	}{
+		"success": {
+			token:  sign(t, hmacKey, validClaims()),
+			result: customerXid,
+		},
+		"failed expired": {
+			token: sign(t, hmacKey, expired),
+			err:   token.ErrInvalidToken,
+		},
+		"failed without expiry": {
+			token: sign(t, hmacKey, noExpiry),
+			err:   token.ErrInvalidToken,
+		},
+		"failed issuer": {
+			token: sign(t, hmacKey, wrongIssuer),
+			err:   token.ErrInvalidToken,
+		},
+		"failed audience": {
+			token: sign(t, hmacKey, wrongAudience),
+			err:   token.ErrInvalidToken,
+		},
+		"failed without customer": {
+			token: sign(t, hmacKey, noCustomer),
+			err:   token.ErrInvalidToken,
+		},
+		"failed without jti": {
+			token: sign(t, hmacKey, noID),
+			err:   token.ErrInvalidToken,
+		},
+		"failed signature": {
+			token: sign(t, otherKey, validClaims()),
+			err:   token.ErrInvalidToken,
+		},
+		"failed unknown key id": {
+			token: sign(t, unknownKey, validClaims()),
+			err:   token.ErrInvalidToken,
+		},
+		"failed algorithm confusion": {
+			token: sign(t, confusedKey, validClaims()),
+			err:   token.ErrInvalidToken,
+		},
+		"failed malformed": {
+			token: "not-a-token",
+			err:   token.ErrMalformedToken,
+		},
+	}
+
+	service := newService(t, hmacKey.ID, hmacKey, rsaKey)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			claims, err := service.Parse(tc.token)
+
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				assert.Equal(t, tc.result, claims.CustomerXid)
+			}
+		})
+	}
+}
+
+func TestService_Rotation(t *testing.T) {
+	_, oldPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldKey := token.NewEdDSAKey("2026-01", oldPrivateKey)
+	newKey := token.NewEdDSAKey("2026-10", newPrivateKey)
+
+	oldService := newService(t, oldKey.ID, oldKey)
+	oldToken, _ := oldService.Issue(customerXid)
+
+	// After the rotation the old key is only kept to verify.
+	oldKey.SignKey = nil
+	service := newService(t, newKey.ID, newKey, oldKey)
+
+	claims, err := service.Parse(oldToken)
+	assert.Nil(t, err)
+	assert.Equal(t, customerXid, claims.CustomerXid)
+
+	newToken, _ := service.Issue(customerXid)
+	tkn, _, _ := jwt.NewParser().ParseUnverified(newToken, &model.Claims{})
+	assert.Equal(t, newKey.ID, tkn.Header["kid"])
+
+	_, err = oldService.Parse(newToken)
+	assert.Equal(t, token.ErrInvalidToken, err)
+}
+
+func TestNewService(t *testing.T) {
+	hmacKey, _ := token.NewHMACKey("hmac", []byte("secret"))
+	verifyOnlyKey := hmacKey
+	verifyOnlyKey.SignKey = nil
+
+	testCases := map[string]struct {
+		config  token.Config
+		wantErr bool
+	}{
+		"success": {
+			config:  token.Config{Keys: []token.Key{hmacKey}, ActiveKeyID: hmacKey.ID},
+			wantErr: false,
+		},
+		"failed active key not found": {
+			config:  token.Config{Keys: []token.Key{hmacKey}, ActiveKeyID: "missing"},
+			wantErr: true,
+		},
+		"failed active key can not sign": {
+			config:  token.Config{Keys: []token.Key{verifyOnlyKey}, ActiveKeyID: hmacKey.ID},
+			wantErr: true,
+		},
+		"failed duplicated key": {
+			config:  token.Config{Keys: []token.Key{hmacKey, hmacKey}, ActiveKeyID: hmacKey.ID},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := token.NewService(tc.config)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestNewKeyFromPEM(t *testing.T) {
+	rsaPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPublicKey, _ := x509.MarshalPKIXPublicKey(&rsaPrivateKey.PublicKey)
+	edPublicKey, edPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPrivateDER, _ := x509.MarshalPKCS8PrivateKey(edPrivateKey)
+	edPublicDER, _ := x509.MarshalPKIXPublicKey(edPublicKey)
+
+	testCases := map[string]struct {
+		algorithm string
+		data      []byte
+		canSign   bool
+		wantErr   bool
+	}{
+		"success RS256 private": {
+			algorithm: token.AlgorithmRS256,
+			data:      pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivateKey)}),
+			canSign:   true,
+		},
+		"success RS256 public": {
+			algorithm: token.AlgorithmRS256,
+			data:      pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicKey}),
+		},
+		"success EdDSA private": {
+			algorithm: token.AlgorithmEdDSA,
+			data:      pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edPrivateDER}),
+			canSign:   true,
+		},
+		"success EdDSA public": {
+			algorithm: token.AlgorithmEdDSA,
+			data:      pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPublicDER}),
+		},
+		"failed not a key": {
+			algorithm: token.AlgorithmRS256,
+			data:      []byte("not a key"),
+			wantErr:   true,
+		},
+		"failed algorithm": {
+			algorithm: token.AlgorithmHS256,
+			data:      pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicKey}),
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			key, err := token.NewKeyFromPEM("kid", tc.algorithm, tc.data)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.algorithm, key.Method.Alg())
+			assert.NotNil(t, key.VerifyKey)
+			assert.Equal(t, tc.canSign, key.SignKey != nil)
+		})
+	}
+}
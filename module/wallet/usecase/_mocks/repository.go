@@ -7,9 +7,12 @@ package mock_usecase
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/herwando/mini-wallet/module/wallet/entity/model"
+	limits "github.com/herwando/mini-wallet/module/wallet/limits"
+	decimal "github.com/shopspring/decimal"
 )
 
 // MockAccountRepository is a mock of AccountRepository interface.
@@ -35,6 +38,21 @@ func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AnonymizeAccount mocks base method.
+func (m *MockAccountRepository) AnonymizeAccount(ctx context.Context, customerXid string, anonymizedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeAccount", ctx, customerXid, anonymizedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeAccount indicates an expected call of AnonymizeAccount.
+func (mr *MockAccountRepositoryMockRecorder) AnonymizeAccount(ctx, customerXid, anonymizedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeAccount", reflect.TypeOf((*MockAccountRepository)(nil).AnonymizeAccount), ctx, customerXid, anonymizedAt)
+}
+
 // CreateAccount mocks base method.
 func (m *MockAccountRepository) CreateAccount(ctx context.Context, account *model.Account) error {
 	m.ctrl.T.Helper()
@@ -49,19 +67,34 @@ func (mr *MockAccountRepositoryMockRecorder) CreateAccount(ctx, account interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockAccountRepository)(nil).CreateAccount), ctx, account)
 }
 
-// ExistAccountByCustomerXid mocks base method.
-func (m *MockAccountRepository) ExistAccountByCustomerXid(ctx context.Context, customerXid string) (bool, error) {
+// GetAccountByCustomerXid mocks base method.
+func (m *MockAccountRepository) GetAccountByCustomerXid(ctx context.Context, customerXid string) (*model.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExistAccountByCustomerXid", ctx, customerXid)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetAccountByCustomerXid", ctx, customerXid)
+	ret0, _ := ret[0].(*model.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByCustomerXid indicates an expected call of GetAccountByCustomerXid.
+func (mr *MockAccountRepositoryMockRecorder) GetAccountByCustomerXid(ctx, customerXid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByCustomerXid", reflect.TypeOf((*MockAccountRepository)(nil).GetAccountByCustomerXid), ctx, customerXid)
+}
+
+// GetAnonymizableAccounts mocks base method.
+func (m *MockAccountRepository) GetAnonymizableAccounts(ctx context.Context, status int, closedBefore time.Time, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnonymizableAccounts", ctx, status, closedBefore, limit)
+	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ExistAccountByCustomerXid indicates an expected call of ExistAccountByCustomerXid.
-func (mr *MockAccountRepositoryMockRecorder) ExistAccountByCustomerXid(ctx, customerXid interface{}) *gomock.Call {
+// GetAnonymizableAccounts indicates an expected call of GetAnonymizableAccounts.
+func (mr *MockAccountRepositoryMockRecorder) GetAnonymizableAccounts(ctx, status, closedBefore, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistAccountByCustomerXid", reflect.TypeOf((*MockAccountRepository)(nil).ExistAccountByCustomerXid), ctx, customerXid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnonymizableAccounts", reflect.TypeOf((*MockAccountRepository)(nil).GetAnonymizableAccounts), ctx, status, closedBefore, limit)
 }
 
 // MockDepositRepository is a mock of DepositRepository interface.
@@ -102,19 +135,49 @@ func (mr *MockDepositRepositoryMockRecorder) CreateDeposit(ctx, deposit, wallet
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeposit", reflect.TypeOf((*MockDepositRepository)(nil).CreateDeposit), ctx, deposit, wallet)
 }
 
+// GetDepositById mocks base method.
+func (m *MockDepositRepository) GetDepositById(ctx context.Context, customerXid, id string) (*model.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositById", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositById indicates an expected call of GetDepositById.
+func (mr *MockDepositRepositoryMockRecorder) GetDepositById(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositById", reflect.TypeOf((*MockDepositRepository)(nil).GetDepositById), ctx, customerXid, id)
+}
+
 // GetDepositByReferenceId mocks base method.
-func (m *MockDepositRepository) GetDepositByReferenceId(ctx context.Context, referenceId string) (*model.Deposit, error) {
+func (m *MockDepositRepository) GetDepositByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Deposit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDepositByReferenceId", ctx, referenceId)
+	ret := m.ctrl.Call(m, "GetDepositByReferenceId", ctx, customerXid, referenceId)
 	ret0, _ := ret[0].(*model.Deposit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetDepositByReferenceId indicates an expected call of GetDepositByReferenceId.
-func (mr *MockDepositRepositoryMockRecorder) GetDepositByReferenceId(ctx, referenceId interface{}) *gomock.Call {
+func (mr *MockDepositRepositoryMockRecorder) GetDepositByReferenceId(ctx, customerXid, referenceId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositByReferenceId", reflect.TypeOf((*MockDepositRepository)(nil).GetDepositByReferenceId), ctx, referenceId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositByReferenceId", reflect.TypeOf((*MockDepositRepository)(nil).GetDepositByReferenceId), ctx, customerXid, referenceId)
+}
+
+// GetDepositsByCustomerXid mocks base method.
+func (m *MockDepositRepository) GetDepositsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositsByCustomerXid", ctx, customerXid, filter)
+	ret0, _ := ret[0].([]*model.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositsByCustomerXid indicates an expected call of GetDepositsByCustomerXid.
+func (mr *MockDepositRepositoryMockRecorder) GetDepositsByCustomerXid(ctx, customerXid, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositsByCustomerXid", reflect.TypeOf((*MockDepositRepository)(nil).GetDepositsByCustomerXid), ctx, customerXid, filter)
 }
 
 // MockWalletRepository is a mock of WalletRepository interface.
@@ -140,6 +203,21 @@ func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CloseWallet mocks base method.
+func (m *MockWalletRepository) CloseWallet(ctx context.Context, wallet *model.Wallet, from int, payout *model.Withdrawal) (*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseWallet", ctx, wallet, from, payout)
+	ret0, _ := ret[0].(*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseWallet indicates an expected call of CloseWallet.
+func (mr *MockWalletRepositoryMockRecorder) CloseWallet(ctx, wallet, from, payout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWallet", reflect.TypeOf((*MockWalletRepository)(nil).CloseWallet), ctx, wallet, from, payout)
+}
+
 // CreateWallet mocks base method.
 func (m *MockWalletRepository) CreateWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
@@ -156,33 +234,78 @@ func (mr *MockWalletRepositoryMockRecorder) CreateWallet(ctx, wallet interface{}
 }
 
 // GetWalletByCustomerXid mocks base method.
-func (m *MockWalletRepository) GetWalletByCustomerXid(ctx context.Context, customerXid string) (*model.Wallet, error) {
+func (m *MockWalletRepository) GetWalletByCustomerXid(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletByCustomerXid", ctx, customerXid)
+	ret := m.ctrl.Call(m, "GetWalletByCustomerXid", ctx, customerXid, currency)
 	ret0, _ := ret[0].(*model.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWalletByCustomerXid indicates an expected call of GetWalletByCustomerXid.
-func (mr *MockWalletRepositoryMockRecorder) GetWalletByCustomerXid(ctx, customerXid interface{}) *gomock.Call {
+func (mr *MockWalletRepositoryMockRecorder) GetWalletByCustomerXid(ctx, customerXid, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByCustomerXid", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletByCustomerXid), ctx, customerXid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByCustomerXid", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletByCustomerXid), ctx, customerXid, currency)
+}
+
+// GetWalletById mocks base method.
+func (m *MockWalletRepository) GetWalletById(ctx context.Context, id string) (*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletById", ctx, id)
+	ret0, _ := ret[0].(*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletById indicates an expected call of GetWalletById.
+func (mr *MockWalletRepositoryMockRecorder) GetWalletById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletById", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletById), ctx, id)
+}
+
+// GetWalletStatusHistory mocks base method.
+func (m *MockWalletRepository) GetWalletStatusHistory(ctx context.Context, walletID string) ([]*model.WalletStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletStatusHistory", ctx, walletID)
+	ret0, _ := ret[0].([]*model.WalletStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletStatusHistory indicates an expected call of GetWalletStatusHistory.
+func (mr *MockWalletRepositoryMockRecorder) GetWalletStatusHistory(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletStatusHistory", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletStatusHistory), ctx, walletID)
+}
+
+// GetWalletsByCustomerXid mocks base method.
+func (m *MockWalletRepository) GetWalletsByCustomerXid(ctx context.Context, customerXid string) ([]*model.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletsByCustomerXid", ctx, customerXid)
+	ret0, _ := ret[0].([]*model.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletsByCustomerXid indicates an expected call of GetWalletsByCustomerXid.
+func (mr *MockWalletRepositoryMockRecorder) GetWalletsByCustomerXid(ctx, customerXid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsByCustomerXid", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletsByCustomerXid), ctx, customerXid)
 }
 
 // UpdateStatusWallet mocks base method.
-func (m *MockWalletRepository) UpdateStatusWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error) {
+func (m *MockWalletRepository) UpdateStatusWallet(ctx context.Context, wallet *model.Wallet, from int, reason, event string) (*model.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusWallet", ctx, wallet)
+	ret := m.ctrl.Call(m, "UpdateStatusWallet", ctx, wallet, from, reason, event)
 	ret0, _ := ret[0].(*model.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateStatusWallet indicates an expected call of UpdateStatusWallet.
-func (mr *MockWalletRepositoryMockRecorder) UpdateStatusWallet(ctx, wallet interface{}) *gomock.Call {
+func (mr *MockWalletRepositoryMockRecorder) UpdateStatusWallet(ctx, wallet, from, reason, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWallet", reflect.TypeOf((*MockWalletRepository)(nil).UpdateStatusWallet), ctx, wallet)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWallet", reflect.TypeOf((*MockWalletRepository)(nil).UpdateStatusWallet), ctx, wallet, from, reason, event)
 }
 
 // MockWithdrawalRepository is a mock of WithdrawalRepository interface.
@@ -208,6 +331,36 @@ func (m *MockWithdrawalRepository) EXPECT() *MockWithdrawalRepositoryMockRecorde
 	return m.recorder
 }
 
+// CaptureWithdrawal mocks base method.
+func (m *MockWithdrawalRepository) CaptureWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureWithdrawal", ctx, withdrawal, wallet, from)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureWithdrawal indicates an expected call of CaptureWithdrawal.
+func (mr *MockWithdrawalRepositoryMockRecorder) CaptureWithdrawal(ctx, withdrawal, wallet, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).CaptureWithdrawal), ctx, withdrawal, wallet, from)
+}
+
+// CreatePendingWithdrawal mocks base method.
+func (m *MockWithdrawalRepository) CreatePendingWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingWithdrawal", ctx, withdrawal, wallet)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingWithdrawal indicates an expected call of CreatePendingWithdrawal.
+func (mr *MockWithdrawalRepositoryMockRecorder) CreatePendingWithdrawal(ctx, withdrawal, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).CreatePendingWithdrawal), ctx, withdrawal, wallet)
+}
+
 // CreateWithdrawal mocks base method.
 func (m *MockWithdrawalRepository) CreateWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
 	m.ctrl.T.Helper()
@@ -223,17 +376,725 @@ func (mr *MockWithdrawalRepositoryMockRecorder) CreateWithdrawal(ctx, withdrawal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).CreateWithdrawal), ctx, withdrawal, wallet)
 }
 
+// GetExpiredWithdrawals mocks base method.
+func (m *MockWithdrawalRepository) GetExpiredWithdrawals(ctx context.Context, status int, now time.Time, limit int) ([]*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredWithdrawals", ctx, status, now, limit)
+	ret0, _ := ret[0].([]*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredWithdrawals indicates an expected call of GetExpiredWithdrawals.
+func (mr *MockWithdrawalRepositoryMockRecorder) GetExpiredWithdrawals(ctx, status, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredWithdrawals", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetExpiredWithdrawals), ctx, status, now, limit)
+}
+
+// GetWithdrawalById mocks base method.
+func (m *MockWithdrawalRepository) GetWithdrawalById(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalById", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalById indicates an expected call of GetWithdrawalById.
+func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalById(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalById", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalById), ctx, customerXid, id)
+}
+
 // GetWithdrawalByReferenceId mocks base method.
-func (m *MockWithdrawalRepository) GetWithdrawalByReferenceId(ctx context.Context, referenceId string) (*model.Withdrawal, error) {
+func (m *MockWithdrawalRepository) GetWithdrawalByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Withdrawal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWithdrawalByReferenceId", ctx, referenceId)
+	ret := m.ctrl.Call(m, "GetWithdrawalByReferenceId", ctx, customerXid, referenceId)
 	ret0, _ := ret[0].(*model.Withdrawal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWithdrawalByReferenceId indicates an expected call of GetWithdrawalByReferenceId.
-func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalByReferenceId(ctx, referenceId interface{}) *gomock.Call {
+func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalByReferenceId(ctx, customerXid, referenceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByReferenceId", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalByReferenceId), ctx, customerXid, referenceId)
+}
+
+// GetWithdrawalsByCustomerXid mocks base method.
+func (m *MockWithdrawalRepository) GetWithdrawalsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalsByCustomerXid", ctx, customerXid, filter)
+	ret0, _ := ret[0].([]*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalsByCustomerXid indicates an expected call of GetWithdrawalsByCustomerXid.
+func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalsByCustomerXid(ctx, customerXid, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByCustomerXid", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalsByCustomerXid), ctx, customerXid, filter)
+}
+
+// ReleaseWithdrawal mocks base method.
+func (m *MockWithdrawalRepository) ReleaseWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseWithdrawal", ctx, withdrawal, wallet, from)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseWithdrawal indicates an expected call of ReleaseWithdrawal.
+func (mr *MockWithdrawalRepositoryMockRecorder) ReleaseWithdrawal(ctx, withdrawal, wallet, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).ReleaseWithdrawal), ctx, withdrawal, wallet, from)
+}
+
+// MockTransferRepository is a mock of TransferRepository interface.
+type MockTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferRepositoryMockRecorder
+}
+
+// MockTransferRepositoryMockRecorder is the mock recorder for MockTransferRepository.
+type MockTransferRepositoryMockRecorder struct {
+	mock *MockTransferRepository
+}
+
+// NewMockTransferRepository creates a new mock instance.
+func NewMockTransferRepository(ctrl *gomock.Controller) *MockTransferRepository {
+	mock := &MockTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferRepository) EXPECT() *MockTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateTransfer mocks base method.
+func (m *MockTransferRepository) CreateTransfer(ctx context.Context, transfer *model.Transfer, sender, recipient *model.Wallet) (*model.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, transfer, sender, recipient)
+	ret0, _ := ret[0].(*model.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockTransferRepositoryMockRecorder) CreateTransfer(ctx, transfer, sender, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockTransferRepository)(nil).CreateTransfer), ctx, transfer, sender, recipient)
+}
+
+// GetTransferByReferenceId mocks base method.
+func (m *MockTransferRepository) GetTransferByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferByReferenceId", ctx, customerXid, referenceId)
+	ret0, _ := ret[0].(*model.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferByReferenceId indicates an expected call of GetTransferByReferenceId.
+func (mr *MockTransferRepositoryMockRecorder) GetTransferByReferenceId(ctx, customerXid, referenceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferByReferenceId", reflect.TypeOf((*MockTransferRepository)(nil).GetTransferByReferenceId), ctx, customerXid, referenceId)
+}
+
+// GetTransfersByCustomerXid mocks base method.
+func (m *MockTransferRepository) GetTransfersByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfersByCustomerXid", ctx, customerXid, filter)
+	ret0, _ := ret[0].([]*model.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfersByCustomerXid indicates an expected call of GetTransfersByCustomerXid.
+func (mr *MockTransferRepositoryMockRecorder) GetTransfersByCustomerXid(ctx, customerXid, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfersByCustomerXid", reflect.TypeOf((*MockTransferRepository)(nil).GetTransfersByCustomerXid), ctx, customerXid, filter)
+}
+
+// MockConversionRepository is a mock of ConversionRepository interface.
+type MockConversionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversionRepositoryMockRecorder
+}
+
+// MockConversionRepositoryMockRecorder is the mock recorder for MockConversionRepository.
+type MockConversionRepositoryMockRecorder struct {
+	mock *MockConversionRepository
+}
+
+// NewMockConversionRepository creates a new mock instance.
+func NewMockConversionRepository(ctrl *gomock.Controller) *MockConversionRepository {
+	mock := &MockConversionRepository{ctrl: ctrl}
+	mock.recorder = &MockConversionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversionRepository) EXPECT() *MockConversionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateConversion mocks base method.
+func (m *MockConversionRepository) CreateConversion(ctx context.Context, conversion *model.Conversion, source, target *model.Wallet) (*model.Conversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConversion", ctx, conversion, source, target)
+	ret0, _ := ret[0].(*model.Conversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConversion indicates an expected call of CreateConversion.
+func (mr *MockConversionRepositoryMockRecorder) CreateConversion(ctx, conversion, source, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConversion", reflect.TypeOf((*MockConversionRepository)(nil).CreateConversion), ctx, conversion, source, target)
+}
+
+// CreateQuote mocks base method.
+func (m *MockConversionRepository) CreateQuote(ctx context.Context, quote *model.ConversionQuote) (*model.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, quote)
+	ret0, _ := ret[0].(*model.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockConversionRepositoryMockRecorder) CreateQuote(ctx, quote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockConversionRepository)(nil).CreateQuote), ctx, quote)
+}
+
+// GetConversionByReferenceId mocks base method.
+func (m *MockConversionRepository) GetConversionByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Conversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversionByReferenceId", ctx, customerXid, referenceId)
+	ret0, _ := ret[0].(*model.Conversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversionByReferenceId indicates an expected call of GetConversionByReferenceId.
+func (mr *MockConversionRepositoryMockRecorder) GetConversionByReferenceId(ctx, customerXid, referenceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversionByReferenceId", reflect.TypeOf((*MockConversionRepository)(nil).GetConversionByReferenceId), ctx, customerXid, referenceId)
+}
+
+// GetQuote mocks base method.
+func (m *MockConversionRepository) GetQuote(ctx context.Context, customerXid, quoteId string) (*model.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", ctx, customerXid, quoteId)
+	ret0, _ := ret[0].(*model.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockConversionRepositoryMockRecorder) GetQuote(ctx, customerXid, quoteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockConversionRepository)(nil).GetQuote), ctx, customerXid, quoteId)
+}
+
+// MockRefundRepository is a mock of RefundRepository interface.
+type MockRefundRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundRepositoryMockRecorder
+}
+
+// MockRefundRepositoryMockRecorder is the mock recorder for MockRefundRepository.
+type MockRefundRepositoryMockRecorder struct {
+	mock *MockRefundRepository
+}
+
+// NewMockRefundRepository creates a new mock instance.
+func NewMockRefundRepository(ctrl *gomock.Controller) *MockRefundRepository {
+	mock := &MockRefundRepository{ctrl: ctrl}
+	mock.recorder = &MockRefundRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundRepository) EXPECT() *MockRefundRepositoryMockRecorder {
+	return m.recorder
+}
+
+// RefundWithdrawal mocks base method.
+func (m *MockRefundRepository) RefundWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, refund *model.Deposit, wallet *model.Wallet) (*model.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundWithdrawal", ctx, withdrawal, refund, wallet)
+	ret0, _ := ret[0].(*model.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundWithdrawal indicates an expected call of RefundWithdrawal.
+func (mr *MockRefundRepositoryMockRecorder) RefundWithdrawal(ctx, withdrawal, refund, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundWithdrawal", reflect.TypeOf((*MockRefundRepository)(nil).RefundWithdrawal), ctx, withdrawal, refund, wallet)
+}
+
+// ReverseDeposit mocks base method.
+func (m *MockRefundRepository) ReverseDeposit(ctx context.Context, deposit *model.Deposit, reversal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseDeposit", ctx, deposit, reversal, wallet)
+	ret0, _ := ret[0].(*model.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseDeposit indicates an expected call of ReverseDeposit.
+func (mr *MockRefundRepositoryMockRecorder) ReverseDeposit(ctx, deposit, reversal, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseDeposit", reflect.TypeOf((*MockRefundRepository)(nil).ReverseDeposit), ctx, deposit, reversal, wallet)
+}
+
+// MockAdjustmentRepository is a mock of AdjustmentRepository interface.
+type MockAdjustmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdjustmentRepositoryMockRecorder
+}
+
+// MockAdjustmentRepositoryMockRecorder is the mock recorder for MockAdjustmentRepository.
+type MockAdjustmentRepositoryMockRecorder struct {
+	mock *MockAdjustmentRepository
+}
+
+// NewMockAdjustmentRepository creates a new mock instance.
+func NewMockAdjustmentRepository(ctrl *gomock.Controller) *MockAdjustmentRepository {
+	mock := &MockAdjustmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAdjustmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdjustmentRepository) EXPECT() *MockAdjustmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockAdjustmentRepository) CreateAdjustment(ctx context.Context, adjustment *model.Adjustment, wallet *model.Wallet) (*model.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", ctx, adjustment, wallet)
+	ret0, _ := ret[0].(*model.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockAdjustmentRepositoryMockRecorder) CreateAdjustment(ctx, adjustment, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockAdjustmentRepository)(nil).CreateAdjustment), ctx, adjustment, wallet)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditRepository) GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]*model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]*model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditRepositoryMockRecorder) GetAuditEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditRepository)(nil).GetAuditEvents), ctx, filter)
+}
+
+// GetAuditEventsAfter mocks base method.
+func (m *MockAuditRepository) GetAuditEventsAfter(ctx context.Context, chain string, chainSeq int64, limit int) ([]*model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEventsAfter", ctx, chain, chainSeq, limit)
+	ret0, _ := ret[0].([]*model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEventsAfter indicates an expected call of GetAuditEventsAfter.
+func (mr *MockAuditRepositoryMockRecorder) GetAuditEventsAfter(ctx, chain, chainSeq, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEventsAfter", reflect.TypeOf((*MockAuditRepository)(nil).GetAuditEventsAfter), ctx, chain, chainSeq, limit)
+}
+
+// RecordAuditEvent mocks base method.
+func (m *MockAuditRepository) RecordAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAuditEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAuditEvent indicates an expected call of RecordAuditEvent.
+func (mr *MockAuditRepositoryMockRecorder) RecordAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAuditEvent", reflect.TypeOf((*MockAuditRepository)(nil).RecordAuditEvent), ctx, event)
+}
+
+// MockLimitRepository is a mock of LimitRepository interface.
+type MockLimitRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimitRepositoryMockRecorder
+}
+
+// MockLimitRepositoryMockRecorder is the mock recorder for MockLimitRepository.
+type MockLimitRepositoryMockRecorder struct {
+	mock *MockLimitRepository
+}
+
+// NewMockLimitRepository creates a new mock instance.
+func NewMockLimitRepository(ctrl *gomock.Controller) *MockLimitRepository {
+	mock := &MockLimitRepository{ctrl: ctrl}
+	mock.recorder = &MockLimitRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimitRepository) EXPECT() *MockLimitRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountTransactions mocks base method.
+func (m *MockLimitRepository) CountTransactions(ctx context.Context, customerXid, currency string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTransactions", ctx, customerXid, currency, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTransactions indicates an expected call of CountTransactions.
+func (mr *MockLimitRepositoryMockRecorder) CountTransactions(ctx, customerXid, currency, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTransactions", reflect.TypeOf((*MockLimitRepository)(nil).CountTransactions), ctx, customerXid, currency, since)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockLimitRepository) GetWalletLimits(ctx context.Context, walletId string) (*limits.Override, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletId)
+	ret0, _ := ret[0].(*limits.Override)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockLimitRepositoryMockRecorder) GetWalletLimits(ctx, walletId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockLimitRepository)(nil).GetWalletLimits), ctx, walletId)
+}
+
+// GetWithdrawnAmount mocks base method.
+func (m *MockLimitRepository) GetWithdrawnAmount(ctx context.Context, customerXid, currency string, statuses []int, since time.Time) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawnAmount", ctx, customerXid, currency, statuses, since)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawnAmount indicates an expected call of GetWithdrawnAmount.
+func (mr *MockLimitRepositoryMockRecorder) GetWithdrawnAmount(ctx, customerXid, currency, statuses, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawnAmount", reflect.TypeOf((*MockLimitRepository)(nil).GetWithdrawnAmount), ctx, customerXid, currency, statuses, since)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]*model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimWebhookDeliveries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimWebhookDeliveries), ctx, now, leaseUntil, limit)
+}
+
+// CreateWebhookEndpoint mocks base method.
+func (m *MockWebhookRepository) CreateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookEndpoint", ctx, endpoint)
+	ret0, _ := ret[0].(*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookEndpoint indicates an expected call of CreateWebhookEndpoint.
+func (mr *MockWebhookRepositoryMockRecorder) CreateWebhookEndpoint(ctx, endpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookEndpoint", reflect.TypeOf((*MockWebhookRepository)(nil).CreateWebhookEndpoint), ctx, endpoint)
+}
+
+// DeleteWebhookEndpoint mocks base method.
+func (m *MockWebhookRepository) DeleteWebhookEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookEndpoint", ctx, customerXid, id)
+	ret0, _ := ret[0].(*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhookEndpoint indicates an expected call of DeleteWebhookEndpoint.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhookEndpoint(ctx, customerXid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookEndpoint", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhookEndpoint), ctx, customerXid, id)
+}
+
+// GetWebhookEndpoints mocks base method.
+func (m *MockWebhookRepository) GetWebhookEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEndpoints", ctx, customerXid)
+	ret0, _ := ret[0].([]*model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEndpoints indicates an expected call of GetWebhookEndpoints.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookEndpoints(ctx, customerXid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEndpoints", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookEndpoints), ctx, customerXid)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateWebhookDelivery), ctx, delivery)
+}
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+	mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+	mock := &MockTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockTokenRepositoryMockRecorder) IsTokenRevoked(ctx, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockTokenRepository)(nil).IsTokenRevoked), ctx, jti)
+}
+
+// PurgeRevokedTokens mocks base method.
+func (m *MockTokenRepository) PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRevokedTokens", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeRevokedTokens indicates an expected call of PurgeRevokedTokens.
+func (mr *MockTokenRepositoryMockRecorder) PurgeRevokedTokens(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRevokedTokens", reflect.TypeOf((*MockTokenRepository)(nil).PurgeRevokedTokens), ctx, now)
+}
+
+// RevokeToken mocks base method.
+func (m *MockTokenRepository) RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, jti, customerXid, expiresAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockTokenRepositoryMockRecorder) RevokeToken(ctx, jti, customerXid, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockTokenRepository)(nil).RevokeToken), ctx, jti, customerXid, expiresAt)
+}
+
+// MockReconciliationRepository is a mock of ReconciliationRepository interface.
+type MockReconciliationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationRepositoryMockRecorder
+}
+
+// MockReconciliationRepositoryMockRecorder is the mock recorder for MockReconciliationRepository.
+type MockReconciliationRepositoryMockRecorder struct {
+	mock *MockReconciliationRepository
+}
+
+// NewMockReconciliationRepository creates a new mock instance.
+func NewMockReconciliationRepository(ctrl *gomock.Controller) *MockReconciliationRepository {
+	mock := &MockReconciliationRepository{ctrl: ctrl}
+	mock.recorder = &MockReconciliationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationRepository) EXPECT() *MockReconciliationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBalanceDiscrepancies mocks base method.
+func (m *MockReconciliationRepository) CreateBalanceDiscrepancies(ctx context.Context, discrepancies []*model.BalanceDiscrepancy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceDiscrepancies", ctx, discrepancies)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBalanceDiscrepancies indicates an expected call of CreateBalanceDiscrepancies.
+func (mr *MockReconciliationRepositoryMockRecorder) CreateBalanceDiscrepancies(ctx, discrepancies interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceDiscrepancies", reflect.TypeOf((*MockReconciliationRepository)(nil).CreateBalanceDiscrepancies), ctx, discrepancies)
+}
+
+// GetWalletReconciliations mocks base method.
+func (m *MockReconciliationRepository) GetWalletReconciliations(ctx context.Context, status int, afterID string, limit int) ([]*model.WalletReconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletReconciliations", ctx, status, afterID, limit)
+	ret0, _ := ret[0].([]*model.WalletReconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletReconciliations indicates an expected call of GetWalletReconciliations.
+func (mr *MockReconciliationRepositoryMockRecorder) GetWalletReconciliations(ctx, status, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletReconciliations", reflect.TypeOf((*MockReconciliationRepository)(nil).GetWalletReconciliations), ctx, status, afterID, limit)
+}
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetMigrationVersion mocks base method.
+func (m *MockHealthRepository) GetMigrationVersion(ctx context.Context) (uint64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrationVersion", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMigrationVersion indicates an expected call of GetMigrationVersion.
+func (mr *MockHealthRepositoryMockRecorder) GetMigrationVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrationVersion", reflect.TypeOf((*MockHealthRepository)(nil).GetMigrationVersion), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHealthRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByReferenceId", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalByReferenceId), ctx, referenceId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHealthRepository)(nil).Ping), ctx)
 }
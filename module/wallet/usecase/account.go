@@ -4,46 +4,85 @@ import (
 	"context"
 	"time"
 
-	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 )
 
+// DefaultAccountRetention is how long the data of a customer whose wallets
+// are all closed is kept before it is anonymized, when no retention is set.
+const DefaultAccountRetention = 90 * 24 * time.Hour
+
+// anonymizeBatchSize is how many accounts AnonymizeClosedAccounts reads at a
+// time.
+const anonymizeBatchSize = 100
+
+type TokenIssuer interface {
+	Issue(customerXid string) (string, error)
+}
+
 type AccountUsecase struct {
-	repo AccountRepository
+	repo      AccountRepository
+	tokens    TokenIssuer
+	retention time.Duration
 }
 
-var (
-	jwtKey = []byte("my_secret_key")
-)
+func NewAccountUsecase(repo AccountRepository, tokens TokenIssuer, retention time.Duration) *AccountUsecase {
+	if retention <= 0 {
+		retention = DefaultAccountRetention
+	}
 
-func NewAccountUsecase(repo AccountRepository) *AccountUsecase {
 	return &AccountUsecase{
-		repo: repo,
+		repo:      repo,
+		tokens:    tokens,
+		retention: retention,
 	}
 }
 
+// Init creates the account of the customer when there is none and issues
+// them a token. An anonymized customer gets no token: their account is kept
+// only for the transactions recorded against it.
 func (h *AccountUsecase) Init(ctx context.Context, payload model.Account) (string, error) {
-	exist, err := h.repo.ExistAccountByCustomerXid(ctx, payload.CustomerXid)
+	account, err := h.repo.GetAccountByCustomerXid(ctx, payload.CustomerXid)
 	if err != nil {
 		return "", err
 	}
 
-	if !exist {
+	if account == nil {
 		err := h.repo.CreateAccount(ctx, &payload)
 		if err != nil {
 			return "", err
 		}
+	} else if account.AnonymizedAt != nil {
+		return "", commonerr.ErrAccountAnonymized
 	}
 
-	expirationTime := time.Date(2023, 12, 31, 20, 34, 58, 651387237, time.UTC)
-	claims := &model.Claims{
-		CustomerXid: payload.CustomerXid,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
-	}
+	return h.tokens.Issue(payload.CustomerXid)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-	return tokenString, err
+// AnonymizeClosedAccounts anonymizes the customers whose wallets have all been
+// closed for longer than the retention, and returns how many it anonymized.
+// Their transactions are kept under a surrogate, and the audit log as is.
+func (h *AccountUsecase) AnonymizeClosedAccounts(ctx context.Context) (int, error) {
+	anonymized := 0
+	for {
+		now := time.Now()
+		customerXids, err := h.repo.GetAnonymizableAccounts(ctx, ClosedStatus, now.Add(-h.retention), anonymizeBatchSize)
+		if err != nil {
+			return anonymized, err
+		}
+
+		for _, customerXid := range customerXids {
+			ok, err := h.repo.AnonymizeAccount(ctx, customerXid, now)
+			if err != nil {
+				return anonymized, err
+			}
+			if ok {
+				anonymized++
+			}
+		}
+
+		if len(customerXids) < anonymizeBatchSize {
+			return anonymized, nil
+		}
+	}
 }
@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/token"
 	"github.com/herwando/mini-wallet/module/wallet/usecase"
 	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestUsecaseAccount_Init(t *testing.T) {
@@ -21,16 +25,27 @@ func TestUsecaseAccount_Init(t *testing.T) {
 	mockAccount := model.Account{
 		CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436",
 	}
+	mockAnonymizedAt := time.Now()
+	mockAnonymized := mockAccount
+	mockAnonymized.AnonymizedAt = &mockAnonymizedAt
+	mockKey, _ := token.NewHMACKey(token.DefaultKeyID, []byte("secret"))
+	mockTokens, _ := token.NewService(token.Config{
+		Keys:        []token.Key{mockKey},
+		ActiveKeyID: token.DefaultKeyID,
+		Issuer:      "mini-wallet",
+		Audience:    "mini-wallet-api",
+	})
 
 	type args struct {
 		ctx     context.Context
 		payload model.Account
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		patch   func()
+		name      string
+		args      args
+		wantErr   bool
+		resultErr error
+		patch     func()
 	}{
 		{
 			name: "Success with create",
@@ -40,7 +55,7 @@ func TestUsecaseAccount_Init(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockAccountDB.EXPECT().ExistAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(false, nil)
+				mockAccountDB.EXPECT().GetAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(nil, nil)
 				mockAccountDB.EXPECT().CreateAccount(gomock.Any(), &mockAccount).Return(nil)
 			},
 		},
@@ -52,18 +67,30 @@ func TestUsecaseAccount_Init(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockAccountDB.EXPECT().ExistAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(true, nil)
+				mockAccountDB.EXPECT().GetAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(&mockAccount, nil)
 			},
 		},
 		{
-			name: "Failed on ExistAccountByCustomerXid cause databaseErr",
+			name: "Failed on GetAccountByCustomerXid cause databaseErr",
 			args: args{
 				ctx:     mockCtx,
 				payload: mockAccount,
 			},
 			wantErr: true,
 			patch: func() {
-				mockAccountDB.EXPECT().ExistAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(false, mockError)
+				mockAccountDB.EXPECT().GetAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed on anonymized account",
+			args: args{
+				ctx:     mockCtx,
+				payload: mockAccount,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrAccountAnonymized,
+			patch: func() {
+				mockAccountDB.EXPECT().GetAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(&mockAnonymized, nil)
 			},
 		},
 		{
@@ -74,7 +101,7 @@ func TestUsecaseAccount_Init(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockAccountDB.EXPECT().ExistAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(false, nil)
+				mockAccountDB.EXPECT().GetAccountByCustomerXid(gomock.Any(), mockAccount.CustomerXid).Return(nil, nil)
 				mockAccountDB.EXPECT().CreateAccount(gomock.Any(), &mockAccount).Return(mockError)
 			},
 		},
@@ -82,12 +109,95 @@ func TestUsecaseAccount_Init(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
-			uc := usecase.NewAccountUsecase(mockAccountDB)
-			_, err := uc.Init(tt.args.ctx, tt.args.payload)
+			uc := usecase.NewAccountUsecase(mockAccountDB, mockTokens, 0)
+			tokenString, err := uc.Init(tt.args.ctx, tt.args.payload)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.Init() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.resultErr != nil {
+				assert.Equal(t, tt.resultErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			claims, err := mockTokens.Parse(tokenString)
+			assert.Nil(t, err)
+			assert.Equal(t, mockAccount.CustomerXid, claims.CustomerXid)
+		})
+	}
+}
+
+func TestUsecaseAccount_AnonymizeClosedAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountDB := mockDB.NewMockAccountRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	retention := 24 * time.Hour
+	mockCustomerXids := []string{
+		"ea0212d3-abd6-406f-8c67-868e814a2436",
+		"5b1c1f0e-3a4d-4c2b-9e8f-7a6b5c4d3e2f",
+	}
+	closedBefore := gomock.AssignableToTypeOf(time.Time{})
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+		patch   func()
+	}{
+		{
+			name: "Success",
+			want: 1,
+			patch: func() {
+				mockAccountDB.EXPECT().GetAnonymizableAccounts(gomock.Any(), usecase.ClosedStatus, closedBefore, gomock.Any()).DoAndReturn(func(ctx context.Context, status int, closedBefore time.Time, limit int) ([]string, error) {
+					if age := time.Since(closedBefore); age < retention || age > retention+time.Minute {
+						t.Errorf("GetAnonymizableAccounts() closedBefore = %v, want %v ago", closedBefore, retention)
+					}
+					return mockCustomerXids, nil
+				})
+				mockAccountDB.EXPECT().AnonymizeAccount(gomock.Any(), mockCustomerXids[0], gomock.Any()).Return(true, nil)
+				mockAccountDB.EXPECT().AnonymizeAccount(gomock.Any(), mockCustomerXids[1], gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name: "Success nothing to anonymize",
+			want: 0,
+			patch: func() {
+				mockAccountDB.EXPECT().GetAnonymizableAccounts(gomock.Any(), usecase.ClosedStatus, closedBefore, gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:    "Failed on GetAnonymizableAccounts",
+			wantErr: true,
+			patch: func() {
+				mockAccountDB.EXPECT().GetAnonymizableAccounts(gomock.Any(), usecase.ClosedStatus, closedBefore, gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed on AnonymizeAccount",
+			want:    1,
+			wantErr: true,
+			patch: func() {
+				mockAccountDB.EXPECT().GetAnonymizableAccounts(gomock.Any(), usecase.ClosedStatus, closedBefore, gomock.Any()).Return(mockCustomerXids, nil)
+				mockAccountDB.EXPECT().AnonymizeAccount(gomock.Any(), mockCustomerXids[0], gomock.Any()).Return(true, nil)
+				mockAccountDB.EXPECT().AnonymizeAccount(gomock.Any(), mockCustomerXids[1], gomock.Any()).Return(false, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAccountUsecase(mockAccountDB, nil, retention)
+			got, err := uc.AnonymizeClosedAccounts(mockCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.AnonymizeClosedAccounts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
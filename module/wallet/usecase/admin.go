@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+// AdminUsecase serves operations staff, who act on the wallets of any
+// customer. Every action is audited along with the staff member who made it:
+// changes in their own transaction, reads with an event of their own.
+type AdminUsecase struct {
+	walletRepo     WalletRepository
+	adjustmentRepo AdjustmentRepository
+	auditRepo      AuditRepository
+	transactions   *TransactionUsecase
+}
+
+func NewAdminUsecase(walletRepo WalletRepository, adjustmentRepo AdjustmentRepository, auditRepo AuditRepository, transactions *TransactionUsecase) *AdminUsecase {
+	return &AdminUsecase{
+		walletRepo:     walletRepo,
+		adjustmentRepo: adjustmentRepo,
+		auditRepo:      auditRepo,
+		transactions:   transactions,
+	}
+}
+
+// SearchWallets returns every wallet of the customer, whatever its status.
+func (h *AdminUsecase) SearchWallets(ctx context.Context, admin, customerXid string) ([]*model.Wallet, error) {
+	wallets, err := h.walletRepo.GetWalletsByCustomerXid(ctx, customerXid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wallet := range wallets {
+		wallet.StatusMessage = walletStatusMessage(wallet.Status)
+		wallet.AvailableBalance = wallet.Available()
+	}
+
+	err = h.auditRead(ctx, admin, audit.ActionWalletsSearched, customerXid)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// EnableWallet enables the wallet {id} on behalf of its owner.
+func (h *AdminUsecase) EnableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	return h.setWalletStatus(ctx, admin, id, EnabledStatus, payload.Reason)
+}
+
+// DisableWallet disables the wallet {id} until it is enabled again.
+func (h *AdminUsecase) DisableWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	return h.setWalletStatus(ctx, admin, id, DisabledStatus, payload.Reason)
+}
+
+// FreezeWallet freezes the wallet {id}. Unlike a disabled wallet, its owner
+// can not enable it again; only staff lift the freeze.
+func (h *AdminUsecase) FreezeWallet(ctx context.Context, admin, id string, payload model.PayloadAdminStatus) (*model.Wallet, error) {
+	return h.setWalletStatus(ctx, admin, id, FrozenStatus, payload.Reason)
+}
+
+// GetWalletStatusHistory returns the transitions of the wallet {id}, oldest
+// first.
+func (h *AdminUsecase) GetWalletStatusHistory(ctx context.Context, admin, id string) ([]*model.WalletStatusChange, error) {
+	wallet, err := h.getWallet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := h.walletRepo.GetWalletStatusHistory(ctx, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		change.FromStatus = walletStatusMessage(change.From)
+		change.ToStatus = walletStatusMessage(change.To)
+	}
+
+	err = h.auditRead(ctx, admin, audit.ActionWalletStatusHistoryListed, wallet.OwnedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (h *AdminUsecase) setWalletStatus(ctx context.Context, admin, id string, status int, reason string) (*model.Wallet, error) {
+	wallet, err := h.getWallet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet.Status == status {
+		switch status {
+		case EnabledStatus:
+			return nil, commonerr.ErrWalletAlreadyEnabled
+		case DisabledStatus:
+			return nil, commonerr.ErrWalletAlreadyDisabled
+		}
+		return nil, commonerr.ErrWalletTransition
+	}
+
+	wallet, err = transitionWallet(ctx, h.walletRepo, wallet, status, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.AvailableBalance = wallet.Available()
+
+	return wallet, nil
+}
+
+// AdjustBalance corrects the balance of the wallet {id} by the amount of the
+// payload, booked as an adjustment rather than written over the balance.
+func (h *AdminUsecase) AdjustBalance(ctx context.Context, admin, id string, payload model.PayloadAdjustment) (*model.Adjustment, error) {
+	wallet, err := h.getWallet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A closed wallet was paid out for good; its balance stays zero.
+	if wallet.Status == ClosedStatus {
+		return nil, commonerr.ErrWalletClosed
+	}
+
+	_, err = lookupAmountCurrency(wallet.Currency, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &model.Adjustment{
+		AdjustedBy:  admin,
+		AdjustedAt:  time.Now(),
+		Amount:      payload.Amount,
+		Currency:    wallet.Currency,
+		Reason:      payload.Reason,
+		ReferenceID: payload.ReferenceID,
+	}
+
+	adjustment, err = h.adjustmentRepo.CreateAdjustment(audit.WithReason(ctx, payload.Reason), adjustment, wallet)
+	if err == commonerr.ErrWalletTransition && wallet.Status == ClosedStatus {
+		err = commonerr.ErrWalletClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// GetTransactions returns a page of the transactions of the customer, as the
+// customer would see them but whatever the status of their wallets.
+func (h *AdminUsecase) GetTransactions(ctx context.Context, admin, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error) {
+	wallets, err := h.walletRepo.GetWalletsByCustomerXid(ctx, customerXid)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wallets) == 0 {
+		return nil, commonerr.ErrWalletNotFound
+	}
+
+	transactions, err := h.transactions.listTransactions(ctx, customerXid, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.auditRead(ctx, admin, audit.ActionTransactionsListed, customerXid)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (h *AdminUsecase) getWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, commonerr.ErrWalletNotFound
+	}
+
+	wallet, err := h.walletRepo.GetWalletById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, commonerr.ErrWalletNotFound
+	}
+
+	return wallet, nil
+}
+
+// auditRead records the staff member admin reading the data of the customer.
+// A read that can not be audited fails rather than go unrecorded.
+func (h *AdminUsecase) auditRead(ctx context.Context, admin, action, customerXid string) error {
+	return h.auditRepo.RecordAuditEvent(ctx, &model.AuditEvent{
+		ActorType:   audit.ActorAdmin,
+		ActorID:     admin,
+		Action:      action,
+		CustomerXid: customerXid,
+	})
+}
@@ -0,0 +1,610 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+	"github.com/shopspring/decimal"
+)
+
+func TestUsecaseAdmin_SearchWallets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockAdjustmentDB := mockDB.NewMockAdjustmentRepository(ctrl)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+
+	tests := []struct {
+		name       string
+		wantErr    bool
+		wantStatus []string
+		patch      func()
+	}{
+		{
+			name:       "Success",
+			wantErr:    false,
+			wantStatus: []string{"enabled", "disabled"},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{
+					{ID: "81401b03-60e0-4f20-afc6-419b3773e7b3", Status: usecase.EnabledStatus, Currency: "IDR"},
+					{ID: "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f", Status: usecase.DisabledStatus, Currency: "USD"},
+				}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), &model.AuditEvent{
+					ActorType:   audit.ActorAdmin,
+					ActorID:     "ops-alice",
+					Action:      audit.ActionWalletsSearched,
+					CustomerXid: mockCustomerXid,
+				}).Return(nil)
+			},
+		},
+		{
+			name:       "Success no wallet",
+			wantErr:    false,
+			wantStatus: []string{},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "Failed RecordAuditEvent",
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(mockError)
+			},
+		},
+		{
+			name:    "Failed GetWalletsByCustomerXid",
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAdminUsecase(mockWalletDB, mockAdjustmentDB, mockAuditDB, nil)
+			got, err := uc.SearchWallets(context.Background(), "ops-alice", mockCustomerXid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.SearchWallets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantStatus) {
+				t.Errorf("Usecase.SearchWallets() = %d wallets, want %d", len(got), len(tt.wantStatus))
+				return
+			}
+			for i, wallet := range got {
+				if wallet.StatusMessage != tt.wantStatus[i] {
+					t.Errorf("Usecase.SearchWallets() status = %v, want %v", wallet.StatusMessage, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUsecaseAdmin_SetWalletStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockAdjustmentDB := mockDB.NewMockAdjustmentRepository(ctrl)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    usecase.EnabledStatus,
+		EnabledAt: time.Now(),
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockWalletDisable := *mockWallet
+	mockWalletDisable.Status = usecase.DisabledStatus
+	mockWalletFrozen := *mockWallet
+	mockWalletFrozen.Status = usecase.FrozenStatus
+	mockWalletClosed := *mockWallet
+	mockWalletClosed.Status = usecase.ClosedStatus
+	mockPayload := model.PayloadAdminStatus{Reason: "Suspected account takeover"}
+
+	tests := []struct {
+		name       string
+		disable    bool
+		freeze     bool
+		id         string
+		wantErr    bool
+		resultErr  error
+		wantStatus string
+		patch      func()
+	}{
+		{
+			name:       "Success disable",
+			disable:    true,
+			id:         mockWallet.ID,
+			wantErr:    false,
+			wantStatus: "disabled",
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), &wallet, usecase.EnabledStatus, mockPayload.Reason, webhook.EventWalletDisabled).Return(&wallet, nil)
+			},
+		},
+		{
+			name:       "Success freeze",
+			freeze:     true,
+			id:         mockWallet.ID,
+			wantErr:    false,
+			wantStatus: "frozen",
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), &wallet, usecase.EnabledStatus, mockPayload.Reason, webhook.EventWalletFrozen).Return(&wallet, nil)
+			},
+		},
+		{
+			name:       "Success lift freeze",
+			disable:    false,
+			id:         mockWallet.ID,
+			wantErr:    false,
+			wantStatus: "enabled",
+			patch: func() {
+				wallet := mockWalletFrozen
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), &wallet, usecase.FrozenStatus, mockPayload.Reason, webhook.EventWalletEnabled).Return(&wallet, nil)
+			},
+		},
+		{
+			name:      "Failed already frozen",
+			freeze:    true,
+			id:        mockWallet.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletTransition,
+			patch: func() {
+				wallet := mockWalletFrozen
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+			},
+		},
+		{
+			name:      "Failed wallet closed",
+			disable:   false,
+			id:        mockWallet.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletClosed,
+			patch: func() {
+				wallet := mockWalletClosed
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+			},
+		},
+		{
+			name:       "Success enable",
+			disable:    false,
+			id:         mockWallet.ID,
+			wantErr:    false,
+			wantStatus: "enabled",
+			patch: func() {
+				wallet := mockWalletDisable
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), &wallet, usecase.DisabledStatus, mockPayload.Reason, webhook.EventWalletEnabled).Return(&wallet, nil)
+			},
+		},
+		{
+			name:      "Failed id not uuid",
+			disable:   true,
+			id:        "not-a-uuid",
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed wallet not found",
+			disable:   true,
+			id:        mockWallet.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:    "Failed GetWalletById",
+			disable: true,
+			id:      mockWallet.ID,
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(nil, mockError)
+			},
+		},
+		{
+			name:      "Failed already disabled",
+			disable:   true,
+			id:        mockWallet.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletAlreadyDisabled,
+			patch: func() {
+				wallet := mockWalletDisable
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+			},
+		},
+		{
+			name:      "Failed already enabled",
+			disable:   false,
+			id:        mockWallet.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletAlreadyEnabled,
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+			},
+		},
+		{
+			name:    "Failed UpdateStatusWallet",
+			disable: true,
+			id:      mockWallet.ID,
+			wantErr: true,
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), &wallet, usecase.EnabledStatus, mockPayload.Reason, webhook.EventWalletDisabled).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAdminUsecase(mockWalletDB, mockAdjustmentDB, mockAuditDB, nil)
+			var got *model.Wallet
+			var err error
+			if tt.freeze {
+				got, err = uc.FreezeWallet(context.Background(), "ops-alice", tt.id, mockPayload)
+			} else if tt.disable {
+				got, err = uc.DisableWallet(context.Background(), "ops-alice", tt.id, mockPayload)
+			} else {
+				got, err = uc.EnableWallet(context.Background(), "ops-alice", tt.id, mockPayload)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.SetWalletStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.SetWalletStatus() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.StatusMessage != tt.wantStatus {
+				t.Errorf("Usecase.SetWalletStatus() status = %v, want %v", got.StatusMessage, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUsecaseAdmin_GetWalletStatusHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockAdjustmentDB := mockDB.NewMockAdjustmentRepository(ctrl)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:       "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:  "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:   usecase.FrozenStatus,
+		Currency: "IDR",
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		wantErr  bool
+		wantFrom []string
+		wantTo   []string
+		patch    func()
+	}{
+		{
+			name:     "Success",
+			id:       mockWallet.ID,
+			wantErr:  false,
+			wantFrom: []string{"pending", "enabled"},
+			wantTo:   []string{"enabled", "frozen"},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletStatusHistory(gomock.Any(), mockWallet.ID).Return([]*model.WalletStatusChange{
+					{WalletID: mockWallet.ID, From: usecase.PendingWalletStatus, To: usecase.EnabledStatus},
+					{WalletID: mockWallet.ID, From: usecase.EnabledStatus, To: usecase.FrozenStatus, Reason: "Chargeback investigation"},
+				}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), &model.AuditEvent{
+					ActorType:   audit.ActorAdmin,
+					ActorID:     "ops-alice",
+					Action:      audit.ActionWalletStatusHistoryListed,
+					CustomerXid: mockWallet.OwnedBy,
+				}).Return(nil)
+			},
+		},
+		{
+			name:    "Failed wallet not found",
+			id:      mockWallet.ID,
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:    "Failed GetWalletStatusHistory",
+			id:      mockWallet.ID,
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletStatusHistory(gomock.Any(), mockWallet.ID).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed RecordAuditEvent",
+			id:      mockWallet.ID,
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletStatusHistory(gomock.Any(), mockWallet.ID).Return([]*model.WalletStatusChange{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAdminUsecase(mockWalletDB, mockAdjustmentDB, mockAuditDB, nil)
+			got, err := uc.GetWalletStatusHistory(context.Background(), "ops-alice", tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.GetWalletStatusHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantTo) {
+				t.Errorf("Usecase.GetWalletStatusHistory() = %d changes, want %d", len(got), len(tt.wantTo))
+				return
+			}
+			for i, change := range got {
+				if change.FromStatus != tt.wantFrom[i] || change.ToStatus != tt.wantTo[i] {
+					t.Errorf("Usecase.GetWalletStatusHistory() = %v to %v, want %v to %v", change.FromStatus, change.ToStatus, tt.wantFrom[i], tt.wantTo[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUsecaseAdmin_AdjustBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockAdjustmentDB := mockDB.NewMockAdjustmentRepository(ctrl)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    usecase.DisabledStatus,
+		EnabledAt: time.Now(),
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockPayload := model.PayloadAdjustment{
+		ReferenceID: "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+		Amount:      decimal.NewFromInt(-2500),
+		Reason:      "Duplicate bank credit",
+	}
+	mockWalletClosed := *mockWallet
+	mockWalletClosed.Status = usecase.ClosedStatus
+	mockPayloadPrecision := mockPayload
+	mockPayloadPrecision.Amount = decimal.RequireFromString("10.555")
+	mockAdjustment := &model.Adjustment{
+		ID:          "4c1d2e3f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		WalletID:    mockWallet.ID,
+		AdjustedBy:  "ops-alice",
+		Amount:      mockPayload.Amount,
+		Currency:    "IDR",
+		Reason:      mockPayload.Reason,
+		ReferenceID: mockPayload.ReferenceID,
+		Balance:     decimal.NewFromInt(7500),
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		payload   model.PayloadAdjustment
+		wantErr   bool
+		resultErr error
+		patch     func()
+	}{
+		{
+			name:    "Success",
+			id:      mockWallet.ID,
+			payload: mockPayload,
+			wantErr: false,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockAdjustmentDB.EXPECT().CreateAdjustment(gomock.Any(), gomock.Any(), mockWallet).DoAndReturn(func(_ context.Context, adjustment *model.Adjustment, _ *model.Wallet) (*model.Adjustment, error) {
+					if adjustment.AdjustedBy != "ops-alice" || !adjustment.Amount.Equal(mockPayload.Amount) || adjustment.Currency != "IDR" || adjustment.Reason != mockPayload.Reason {
+						t.Errorf("CreateAdjustment() got %v, want the payload by ops-alice in IDR", adjustment)
+					}
+					return mockAdjustment, nil
+				})
+			},
+		},
+		{
+			name:      "Failed wallet not found",
+			id:        mockWallet.ID,
+			payload:   mockPayload,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed amount precision",
+			id:        mockWallet.ID,
+			payload:   mockPayloadPrecision,
+			wantErr:   true,
+			resultErr: commonerr.ErrAmountPrecision,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+			},
+		},
+		{
+			name:      "Failed balance not enough",
+			id:        mockWallet.ID,
+			payload:   mockPayload,
+			wantErr:   true,
+			resultErr: commonerr.ErrBalanceNotEnough,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockAdjustmentDB.EXPECT().CreateAdjustment(gomock.Any(), gomock.Any(), mockWallet).Return(nil, commonerr.ErrBalanceNotEnough)
+			},
+		},
+		{
+			name:      "Failed wallet closed",
+			id:        mockWallet.ID,
+			payload:   mockPayload,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletClosed,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&mockWalletClosed, nil)
+			},
+		},
+		{
+			name:      "Failed wallet closed since read",
+			id:        mockWallet.ID,
+			payload:   mockPayload,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletClosed,
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(&wallet, nil)
+				mockAdjustmentDB.EXPECT().CreateAdjustment(gomock.Any(), gomock.Any(), &wallet).DoAndReturn(func(_ context.Context, _ *model.Adjustment, wallet *model.Wallet) (*model.Adjustment, error) {
+					wallet.Status = usecase.ClosedStatus
+					return nil, commonerr.ErrWalletTransition
+				})
+			},
+		},
+		{
+			name:    "Failed CreateAdjustment",
+			id:      mockWallet.ID,
+			payload: mockPayload,
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletById(gomock.Any(), mockWallet.ID).Return(mockWallet, nil)
+				mockAdjustmentDB.EXPECT().CreateAdjustment(gomock.Any(), gomock.Any(), mockWallet).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAdminUsecase(mockWalletDB, mockAdjustmentDB, mockAuditDB, nil)
+			_, err := uc.AdjustBalance(context.Background(), "ops-alice", tt.id, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.AdjustBalance() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.AdjustBalance() error = %v, want %v", err, tt.resultErr)
+			}
+		})
+	}
+}
+
+func TestUsecaseAdmin_GetTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockAdjustmentDB := mockDB.NewMockAdjustmentRepository(ctrl)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	mockDepositDB := mockDB.NewMockDepositRepository(ctrl)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockTransferDB := mockDB.NewMockTransferRepository(ctrl)
+	defer ctrl.Finish()
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	mockFilter := model.TransactionFilter{Type: model.TransactionTypeDeposit}
+
+	tests := []struct {
+		name      string
+		wantErr   bool
+		resultErr error
+		wantCount int
+		patch     func()
+	}{
+		{
+			name:      "Success disabled wallet",
+			wantErr:   false,
+			wantCount: 1,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{{Status: usecase.DisabledStatus, Currency: "IDR"}}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Deposit{
+					{ID: "e5e4c2d1-3f0e-4b5c-9a51-1c4f2f0e8a11", Status: 1, DepositedAt: time.Now(), Amount: decimal.NewFromInt(1000), Currency: "IDR"},
+				}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), &model.AuditEvent{
+					ActorType:   audit.ActorAdmin,
+					ActorID:     "ops-alice",
+					Action:      audit.ActionTransactionsListed,
+					CustomerXid: mockCustomerXid,
+				}).Return(nil)
+			},
+		},
+		{
+			name:    "Failed RecordAuditEvent",
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{{Status: usecase.EnabledStatus, Currency: "IDR"}}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Deposit{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(mockError)
+			},
+		},
+		{
+			name:      "Failed wallet not found",
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{}, nil)
+			},
+		},
+		{
+			name:    "Failed GetWalletsByCustomerXid",
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed GetDepositsByCustomerXid",
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{{Status: usecase.EnabledStatus, Currency: "IDR"}}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			transactions := usecase.NewTransactionUsecase(mockDepositDB, mockWithdrawalDB, mockTransferDB, mockWalletDB)
+			uc := usecase.NewAdminUsecase(mockWalletDB, mockAdjustmentDB, mockAuditDB, transactions)
+			got, err := uc.GetTransactions(context.Background(), "ops-alice", mockCustomerXid, mockFilter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.GetTransactions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.GetTransactions() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && len(got.Transactions) != tt.wantCount {
+				t.Errorf("Usecase.GetTransactions() = %d transactions, want %d", len(got.Transactions), tt.wantCount)
+			}
+		})
+	}
+}
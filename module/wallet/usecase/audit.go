@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+const (
+	defaultAuditEventLimit = 50
+	maxAuditEventLimit     = 500
+
+	// verifyBatchSize is how many events Verify reads at a time.
+	verifyBatchSize = 500
+)
+
+// AuditUsecase serves auditors, staff reading the audit log and checking it
+// has not been tampered with. Reading the log is itself audited.
+type AuditUsecase struct {
+	repo AuditRepository
+}
+
+func NewAuditUsecase(repo AuditRepository) *AuditUsecase {
+	return &AuditUsecase{
+		repo: repo,
+	}
+}
+
+// GetEvents returns a page of the events matching filter, newest first, with
+// the cursor of the next page when there may be one.
+func (h *AuditUsecase) GetEvents(ctx context.Context, admin string, filter model.AuditEventFilter) (*model.AuditEventList, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditEventLimit
+	}
+	if filter.Limit > maxAuditEventLimit {
+		filter.Limit = maxAuditEventLimit
+	}
+
+	events, err := h.repo.GetAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.repo.RecordAuditEvent(ctx, &model.AuditEvent{
+		ActorType:   audit.ActorAdmin,
+		ActorID:     admin,
+		Action:      audit.ActionAuditEventsListed,
+		CustomerXid: filter.CustomerXid,
+		WalletID:    filter.WalletID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &model.AuditEventList{Events: events}
+	if len(events) == filter.Limit {
+		list.NextCursor = strconv.FormatInt(events[len(events)-1].Seq, 10)
+	}
+
+	return list, nil
+}
+
+// Verify walks every chain from its first event, stopping at the first
+// event that does not follow from the one before it.
+func (h *AuditUsecase) Verify(ctx context.Context, admin string) (*model.AuditVerification, error) {
+	result := &model.AuditVerification{Valid: true}
+	var head *model.AuditEvent
+	for {
+		var chain string
+		var chainSeq int64
+		if head != nil {
+			chain, chainSeq = head.Chain, head.ChainSeq
+		}
+		events, err := h.repo.GetAuditEventsAfter(ctx, chain, chainSeq, verifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if broken := audit.Verify(head, events); broken != nil {
+			for _, event := range events {
+				if event == broken {
+					break
+				}
+				result.Events++
+				head = event
+			}
+			result.Valid = false
+			result.BrokenSeq = broken.Seq
+			result.BrokenChain = broken.Chain
+			break
+		}
+
+		if len(events) == 0 {
+			break
+		}
+
+		result.Events += int64(len(events))
+		head = events[len(events)-1]
+		if len(events) < verifyBatchSize {
+			break
+		}
+	}
+	if head != nil {
+		result.LastHash = head.Hash
+	}
+
+	err := h.repo.RecordAuditEvent(ctx, &model.AuditEvent{
+		ActorType: audit.ActorAdmin,
+		ActorID:   admin,
+		Action:    audit.ActionAuditEventsVerified,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,201 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/audit"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+)
+
+// auditChain returns n events chained from the first, as Record writes them.
+func auditChain(n int) []*model.AuditEvent {
+	var events []*model.AuditEvent
+	prevHash := ""
+	for seq := int64(1); seq <= int64(n); seq++ {
+		event := &model.AuditEvent{
+			Seq:       seq,
+			Chain:     "wallet:81401b03-60e0-4f20-afc6-419b3773e7b3",
+			ChainSeq:  seq,
+			WalletID:  "81401b03-60e0-4f20-afc6-419b3773e7b3",
+			ActorType: audit.ActorSystem,
+			Action:    audit.ActionWithdrawalReleased,
+			CreatedAt: time.Date(2026, 10, 18, 10, 0, int(seq), 0, time.UTC),
+			PrevHash:  prevHash,
+		}
+		event.Hash = audit.Hash(event)
+		prevHash = event.Hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestUsecaseAudit_GetEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockError := errors.New("fake error")
+	events := auditChain(3)
+
+	tests := []struct {
+		name       string
+		filter     model.AuditEventFilter
+		wantErr    bool
+		wantCursor string
+		patch      func()
+	}{
+		{
+			name:       "Success full page",
+			filter:     model.AuditEventFilter{CustomerXid: mockCustomerXid, Limit: 2},
+			wantCursor: "2",
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEvents(gomock.Any(), model.AuditEventFilter{CustomerXid: mockCustomerXid, Limit: 2}).Return([]*model.AuditEvent{events[2], events[1]}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), &model.AuditEvent{
+					ActorType:   audit.ActorAdmin,
+					ActorID:     "ops-alice",
+					Action:      audit.ActionAuditEventsListed,
+					CustomerXid: mockCustomerXid,
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "Success default limit",
+			filter: model.AuditEventFilter{},
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEvents(gomock.Any(), model.AuditEventFilter{Limit: 50}).Return(events, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:   "Success capped limit",
+			filter: model.AuditEventFilter{Limit: 10000},
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEvents(gomock.Any(), model.AuditEventFilter{Limit: 500}).Return(events, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "Failed GetAuditEvents",
+			wantErr: true,
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEvents(gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed RecordAuditEvent",
+			wantErr: true,
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEvents(gomock.Any(), gomock.Any()).Return(events, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAuditUsecase(mockAuditDB)
+			got, err := uc.GetEvents(context.Background(), "ops-alice", tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.GetEvents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil && got.NextCursor != tt.wantCursor {
+				t.Errorf("Usecase.GetEvents() cursor = %v, want %v", got.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestUsecaseAudit_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuditDB := mockDB.NewMockAuditRepository(ctrl)
+	defer ctrl.Finish()
+	mockError := errors.New("fake error")
+
+	tests := []struct {
+		name    string
+		want    model.AuditVerification
+		wantErr bool
+		patch   func()
+	}{
+		{
+			name: "Success valid",
+			want: model.AuditVerification{Valid: true, Events: 3},
+			patch: func() {
+				events := auditChain(3)
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return(events, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), &model.AuditEvent{
+					ActorType: audit.ActorAdmin,
+					ActorID:   "ops-alice",
+					Action:    audit.ActionAuditEventsVerified,
+				}).Return(nil)
+			},
+		},
+		{
+			name: "Success across batches",
+			want: model.AuditVerification{Valid: true, Events: 500},
+			patch: func() {
+				events := auditChain(500)
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return(events, nil)
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), events[499].Chain, int64(500), 500).Return([]*model.AuditEvent{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Success empty",
+			want: model.AuditVerification{Valid: true},
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return([]*model.AuditEvent{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Success tampered",
+			want: model.AuditVerification{Valid: false, Events: 1, BrokenSeq: 2, BrokenChain: "wallet:81401b03-60e0-4f20-afc6-419b3773e7b3"},
+			patch: func() {
+				events := auditChain(3)
+				events[1].Reason = "edited"
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return(events, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "Failed GetAuditEventsAfter",
+			wantErr: true,
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed RecordAuditEvent",
+			wantErr: true,
+			patch: func() {
+				mockAuditDB.EXPECT().GetAuditEventsAfter(gomock.Any(), "", int64(0), 500).Return([]*model.AuditEvent{}, nil)
+				mockAuditDB.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Return(mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewAuditUsecase(mockAuditDB)
+			got, err := uc.Verify(context.Background(), "ops-alice")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got == nil {
+				return
+			}
+			if got.Valid != tt.want.Valid || got.Events != tt.want.Events || got.BrokenSeq != tt.want.BrokenSeq || got.BrokenChain != tt.want.BrokenChain {
+				t.Errorf("Usecase.Verify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
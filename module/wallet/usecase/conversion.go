@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/fx"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultQuoteTTL is how long a quote locks its rate when none is configured.
+const DefaultQuoteTTL = 30 * time.Second
+
+type ConversionUsecase struct {
+	repo       ConversionRepository
+	walletRepo WalletRepository
+	limits     *LimitChecker
+	rates      fx.RateProvider
+	quoteTTL   time.Duration
+}
+
+func NewConversionUsecase(repo ConversionRepository, walletRepo WalletRepository, limits *LimitChecker, rates fx.RateProvider, quoteTTL time.Duration) *ConversionUsecase {
+	if quoteTTL <= 0 {
+		quoteTTL = DefaultQuoteTTL
+	}
+
+	return &ConversionUsecase{
+		repo:       repo,
+		walletRepo: walletRepo,
+		limits:     limits,
+		rates:      rates,
+		quoteTTL:   quoteTTL,
+	}
+}
+
+// CreateQuote prices the conversion at the current rate and locks that rate
+// for the quote TTL.
+func (h *ConversionUsecase) CreateQuote(ctx context.Context, customerXid string, payload model.PayloadConversionQuote) (*model.ConversionQuote, error) {
+	quote, err := h.price(ctx, payload.FromCurrency, payload.ToCurrency, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.QuotedFor = customerXid
+	quote.CreatedAt = time.Now()
+	quote.ExpiresAt = quote.CreatedAt.Add(h.quoteTTL)
+
+	return h.repo.CreateQuote(ctx, quote)
+}
+
+func (h *ConversionUsecase) CreateConversion(ctx context.Context, customerXid string, payload model.PayloadConversion) (*model.Conversion, error) {
+	conversion, err := h.createConversion(ctx, customerXid, payload)
+
+	currency, amount := payload.FromCurrency, payload.Amount
+	if conversion != nil {
+		currency, amount = conversion.FromCurrency, conversion.Amount
+	}
+	observeTransaction(metrics.TransactionConversion, currency, amount, conversion != nil && conversion.Replayed, err)
+
+	return conversion, err
+}
+
+func (h *ConversionUsecase) createConversion(ctx context.Context, customerXid string, payload model.PayloadConversion) (*model.Conversion, error) {
+	var quote *model.ConversionQuote
+	var err error
+	if payload.QuoteID != "" {
+		quote, err = h.repo.GetQuote(ctx, customerXid, payload.QuoteID)
+		if err != nil {
+			return nil, err
+		}
+		if quote == nil {
+			return nil, commonerr.ErrQuoteNotFound
+		}
+	} else {
+		quote, err = h.price(ctx, payload.FromCurrency, payload.ToCurrency, payload.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, quote.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if source == nil {
+		return nil, commonerr.ErrWalletNotFound
+	} else {
+		if source.Status != EnabledStatus {
+			return nil, walletStatusError(source.Status)
+		}
+	}
+
+	target, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, quote.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		return nil, commonerr.ErrWalletNotFound
+	} else {
+		if target.Status != EnabledStatus {
+			return nil, walletStatusError(target.Status)
+		}
+	}
+
+	conversion, err := h.repo.GetConversionByReferenceId(ctx, customerXid, payload.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversion != nil {
+		return replayConversion(ctx, conversion, quote, payload)
+	}
+
+	if quote.Used {
+		return nil, commonerr.ErrQuoteUsed
+	}
+
+	now := time.Now()
+	if quote.ID != "" && !now.Before(quote.ExpiresAt) {
+		return nil, commonerr.ErrQuoteExpired
+	}
+
+	if source.Available().LessThan(quote.Amount) {
+		return nil, commonerr.ErrBalanceNotEnough
+	}
+
+	err = h.limits.CheckWithdrawal(ctx, source, quote.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.limits.CheckCredit(ctx, target, quote.ConvertedAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &model.Conversion{
+		ConvertedBy:     customerXid,
+		QuoteID:         quote.ID,
+		Status:          SuccessStatus,
+		ConvertedAt:     now,
+		FromCurrency:    quote.FromCurrency,
+		ToCurrency:      quote.ToCurrency,
+		Amount:          quote.Amount,
+		ConvertedAmount: quote.ConvertedAmount,
+		Rate:            quote.Rate,
+		ReferenceID:     payload.ReferenceID,
+	}
+
+	conversion, err = h.repo.CreateConversion(ctx, data, source, target)
+	err = walletWriteError(ctx, err, source, target)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		conversion, err = h.repo.GetConversionByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if conversion == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayConversion(ctx, conversion, quote, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conversion.StatusMessage = "success"
+	logger.FromContext(ctx).Info("conversion created", "conversion_id", conversion.ID, "amount", conversion.Amount.String(),
+		"from_currency", conversion.FromCurrency, "to_currency", conversion.ToCurrency, "rate", conversion.Rate.String())
+
+	return conversion, nil
+}
+
+// price quotes amount of from in to at the current rate. The converted amount
+// is rounded down to the minor units of to, so the wallet never gets more
+// than the rate gives.
+func (h *ConversionUsecase) price(ctx context.Context, from, to string, amount decimal.Decimal) (*model.ConversionQuote, error) {
+	source, err := lookupAmountCurrency(from, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := lookupCurrency(to)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.Code == target.Code {
+		return nil, commonerr.ErrConversionSameCurrency
+	}
+
+	rate, err := h.rates.Rate(ctx, source.Code, target.Code)
+	if errors.Is(err, fx.ErrRateNotFound) {
+		return nil, commonerr.ErrRateUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	convertedAmount := amount.Mul(rate).Truncate(target.MinorUnits)
+	if !convertedAmount.IsPositive() {
+		return nil, commonerr.ErrConversionAmountTooLow
+	}
+
+	return &model.ConversionQuote{
+		FromCurrency:    source.Code,
+		ToCurrency:      target.Code,
+		Amount:          amount,
+		ConvertedAmount: convertedAmount,
+		Rate:            rate,
+	}, nil
+}
+
+// replayConversion returns the conversion already made with the payload
+// reference id when it was made from the same quote, or from the same
+// currencies and amount when no quote was given.
+func replayConversion(ctx context.Context, conversion *model.Conversion, quote *model.ConversionQuote, payload model.PayloadConversion) (*model.Conversion, error) {
+	same := conversion.QuoteID == payload.QuoteID
+	if payload.QuoteID == "" {
+		same = same && conversion.FromCurrency == quote.FromCurrency && conversion.ToCurrency == quote.ToCurrency && conversion.Amount.Equal(quote.Amount)
+	}
+	if !same {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	conversion.StatusMessage = transactionStatusMessage(conversion.Status)
+	conversion.Replayed = true
+
+	return conversion, nil
+}
@@ -0,0 +1,452 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/fx"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+var mockRates = fx.NewStaticRateProvider("USD", map[string]decimal.Decimal{
+	"IDR": decimal.NewFromInt(15500),
+	"JPY": decimal.RequireFromString("149.5"),
+})
+
+func TestUsecaseConversion_CreateQuote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockConversionDB := mockDB.NewMockConversionRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		payload     model.PayloadConversionQuote
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantErr       bool
+		resultErr     error
+		wantConverted string
+		patch         func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "USD", ToCurrency: "IDR", Amount: decimal.RequireFromString("10.01")},
+			},
+			wantErr:       false,
+			wantConverted: "155155",
+			patch: func() {
+				mockConversionDB.EXPECT().CreateQuote(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, quote *model.ConversionQuote) (*model.ConversionQuote, error) {
+					if quote.QuotedFor != mockCustomerXid || quote.ExpiresAt.Sub(quote.CreatedAt) != time.Minute {
+						t.Errorf("CreateQuote() quote = %+v", quote)
+					}
+					return quote, nil
+				})
+			},
+		},
+		{
+			name: "Success rounded down to minor units",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "IDR", ToCurrency: "JPY", Amount: decimal.NewFromInt(100000)},
+			},
+			wantErr:       false,
+			wantConverted: "964",
+			patch: func() {
+				mockConversionDB.EXPECT().CreateQuote(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, quote *model.ConversionQuote) (*model.ConversionQuote, error) {
+					return quote, nil
+				})
+			},
+		},
+		{
+			name: "Failed same currency",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "IDR", ToCurrency: "IDR", Amount: decimal.NewFromInt(1000)},
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrConversionSameCurrency,
+			patch:     func() {},
+		},
+		{
+			name: "Failed amount precision",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "JPY", ToCurrency: "IDR", Amount: decimal.RequireFromString("10.5")},
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrAmountPrecision,
+			patch:     func() {},
+		},
+		{
+			name: "Failed rate unavailable",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "USD", ToCurrency: "EUR", Amount: decimal.NewFromInt(10)},
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrRateUnavailable,
+			patch:     func() {},
+		},
+		{
+			name: "Failed amount too low",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "IDR", ToCurrency: "USD", Amount: decimal.NewFromInt(100)},
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrConversionAmountTooLow,
+			patch:     func() {},
+		},
+		{
+			name: "Failed on CreateQuote",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadConversionQuote{FromCurrency: "USD", ToCurrency: "IDR", Amount: decimal.NewFromInt(10)},
+			},
+			wantErr: true,
+			patch: func() {
+				mockConversionDB.EXPECT().CreateQuote(gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewConversionUsecase(mockConversionDB, mockWalletDB, nil, mockRates, time.Minute)
+			got, err := uc.CreateQuote(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.CreateQuote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateQuote() error = %v, resultErr %v", err, tt.resultErr)
+			}
+			if got != nil && !got.ConvertedAmount.Equal(decimal.RequireFromString(tt.wantConverted)) {
+				t.Errorf("Usecase.CreateQuote() converted amount = %v, want %v", got.ConvertedAmount, tt.wantConverted)
+			}
+		})
+	}
+}
+
+func TestUsecaseConversion_CreateConversion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockConversionDB := mockDB.NewMockConversionRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockWalletUSD := &model.Wallet{
+		ID:        "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f",
+		OwnedBy:   mockCustomerXid,
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(100),
+		Currency:  "USD",
+	}
+	mockWalletIDR := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   mockCustomerXid,
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockWalletIDRDisable := *mockWalletIDR
+	mockWalletIDRDisable.Status = 2
+	mockQuote := &model.ConversionQuote{
+		ID:              "3b4f8a52-0d6e-4d1c-9a57-6f2b8c1e0a9d",
+		QuotedFor:       mockCustomerXid,
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(150000),
+		Rate:            decimal.NewFromInt(15000),
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(time.Minute),
+	}
+	mockQuoteExpired := *mockQuote
+	mockQuoteExpired.ExpiresAt = now.Add(-time.Second)
+	mockQuoteUsed := *mockQuote
+	mockQuoteUsed.Used = true
+	mockQuoteLarge := *mockQuote
+	mockQuoteLarge.Amount = decimal.NewFromInt(1000)
+	mockConversion := &model.Conversion{
+		ID:              "9c1d2e3f-4a5b-4c6d-8e7f-0a1b2c3d4e5f",
+		ConvertedBy:     mockCustomerXid,
+		QuoteID:         mockQuote.ID,
+		Status:          1,
+		ConvertedAt:     now,
+		FromCurrency:    "USD",
+		ToCurrency:      "IDR",
+		Amount:          decimal.NewFromInt(10),
+		ConvertedAmount: decimal.NewFromInt(150000),
+		Rate:            decimal.NewFromInt(15000),
+		ReferenceID:     "50535246-dcb2-4929-8cc9-004ea06f5241",
+	}
+	mockConversionNoQuote := *mockConversion
+	mockConversionNoQuote.QuoteID = ""
+	mockPayload := model.PayloadConversion{
+		ReferenceID: mockConversion.ReferenceID,
+		QuoteID:     mockQuote.ID,
+	}
+	mockPayloadNoQuote := model.PayloadConversion{
+		ReferenceID:  mockConversion.ReferenceID,
+		FromCurrency: "USD",
+		ToCurrency:   "IDR",
+		Amount:       decimal.NewFromInt(10),
+	}
+	expectWallets := func(target *model.Wallet) {
+		mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "USD").Return(mockWalletUSD, nil)
+		mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(target, nil)
+	}
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		payload     model.PayloadConversion
+	}
+	tests := []struct {
+		name         string
+		args         args
+		limits       limits.Defaults
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
+	}{
+		{
+			name:    "Success with quote",
+			args:    args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr: false,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockConversionDB.EXPECT().CreateConversion(gomock.Any(), gomock.Any(), mockWalletUSD, mockWalletIDR).DoAndReturn(func(ctx context.Context, conversion *model.Conversion, source, target *model.Wallet) (*model.Conversion, error) {
+					if conversion.QuoteID != mockQuote.ID || !conversion.ConvertedAmount.Equal(mockQuote.ConvertedAmount) {
+						t.Errorf("CreateConversion() conversion = %+v, want the quote %+v", conversion, mockQuote)
+					}
+					return conversion, nil
+				})
+			},
+		},
+		{
+			name:    "Success without quote",
+			args:    args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayloadNoQuote},
+			wantErr: false,
+			patch: func() {
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockConversionDB.EXPECT().CreateConversion(gomock.Any(), gomock.Any(), mockWalletUSD, mockWalletIDR).DoAndReturn(func(ctx context.Context, conversion *model.Conversion, source, target *model.Wallet) (*model.Conversion, error) {
+					if conversion.QuoteID != "" || !conversion.ConvertedAmount.Equal(decimal.NewFromInt(155000)) {
+						t.Errorf("CreateConversion() conversion = %+v, want priced at the current rate", conversion)
+					}
+					return conversion, nil
+				})
+			},
+		},
+		{
+			name:      "Failed quote not found",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrQuoteNotFound,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:    "Failed on GetQuote",
+			args:    args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr: true,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(nil, mockError)
+			},
+		},
+		{
+			name:      "Failed rate unavailable",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: model.PayloadConversion{ReferenceID: mockPayload.ReferenceID, FromCurrency: "EUR", ToCurrency: "IDR", Amount: decimal.NewFromInt(10)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrRateUnavailable,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed wallet empty",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "USD").Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed target wallet disabled",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletDisabled,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(&mockWalletIDRDisable)
+			},
+		},
+		{
+			name:         "Success replay on GetConversionByReferenceId",
+			args:         args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(&mockQuoteUsed, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockConversion, nil)
+			},
+		},
+		{
+			name:      "Failed on GetConversionByReferenceId with different payload",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayloadNoQuote},
+			wantErr:   true,
+			resultErr: commonerr.ErrReferenceIdUsed,
+			patch: func() {
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockConversion, nil)
+			},
+		},
+		{
+			name:      "Failed quote used",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrQuoteUsed,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(&mockQuoteUsed, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed quote expired",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrQuoteExpired,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(&mockQuoteExpired, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed balance not enough",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrBalanceNotEnough,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(&mockQuoteLarge, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed source max withdrawal limit",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			limits:    limits.Defaults{"USD": {MaxWithdrawal: decimal.NewFromInt(5)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed target max balance limit",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			limits:    limits.Defaults{"IDR": {MaxBalance: decimal.NewFromInt(100000)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed on CreateConversion target max balance meanwhile",
+			args:      args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockConversionDB.EXPECT().CreateConversion(gomock.Any(), gomock.Any(), mockWalletUSD, mockWalletIDR).
+					Return(nil, &limits.ExceededError{Limit: limits.MaxBalance, Remaining: decimal.Zero})
+			},
+		},
+		{
+			name:         "Success replay on CreateConversion reference id used",
+			args:         args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayloadNoQuote},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockConversionDB.EXPECT().CreateConversion(gomock.Any(), gomock.Any(), mockWalletUSD, mockWalletIDR).Return(nil, commonerr.ErrReferenceIdUsed)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockConversionNoQuote, nil)
+			},
+		},
+		{
+			name:    "Failed on CreateConversion cause databaseErr",
+			args:    args{ctx: mockCtx, customerXid: mockCustomerXid, payload: mockPayload},
+			wantErr: true,
+			patch: func() {
+				mockConversionDB.EXPECT().GetQuote(gomock.Any(), mockCustomerXid, mockQuote.ID).Return(mockQuote, nil)
+				expectWallets(mockWalletIDR)
+				mockConversionDB.EXPECT().GetConversionByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockConversionDB.EXPECT().CreateConversion(gomock.Any(), gomock.Any(), mockWalletUSD, mockWalletIDR).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewConversionUsecase(mockConversionDB, mockWalletDB, usecase.NewLimitChecker(mockLimitDB, tt.limits), mockRates, time.Minute)
+			got, err := uc.CreateConversion(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.CreateConversion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateConversion() error = %v, resultErr %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.CreateConversion() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
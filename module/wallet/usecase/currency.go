@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/currency"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/shopspring/decimal"
+)
+
+// lookupCurrency returns the currency of code, model.DefaultCurrency when code
+// is empty.
+func lookupCurrency(code string) (currency.Currency, error) {
+	if code == "" {
+		code = model.DefaultCurrency
+	}
+
+	c, ok := currency.Lookup(code)
+	if !ok {
+		return currency.Currency{}, commonerr.ErrCurrencyNotSupported
+	}
+
+	return c, nil
+}
+
+// lookupAmountCurrency is lookupCurrency that also refuses an amount with more
+// decimals than the currency has minor units.
+func lookupAmountCurrency(code string, amount decimal.Decimal) (currency.Currency, error) {
+	c, err := lookupCurrency(code)
+	if err != nil {
+		return c, err
+	}
+
+	if !c.ValidAmount(amount) {
+		return c, commonerr.ErrAmountPrecision
+	}
+
+	return c, nil
+}
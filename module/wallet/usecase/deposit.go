@@ -2,50 +2,74 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
 )
 
 type DepositUsecase struct {
 	repo       DepositRepository
 	walletRepo WalletRepository
+	limits     *LimitChecker
 }
 
 const (
 	SuccessStatus = 1
 )
 
-func NewDepositUsecase(repo DepositRepository, walletRepo WalletRepository) *DepositUsecase {
+func NewDepositUsecase(repo DepositRepository, walletRepo WalletRepository, limits *LimitChecker) *DepositUsecase {
 	return &DepositUsecase{
 		repo:       repo,
 		walletRepo: walletRepo,
+		limits:     limits,
 	}
 }
 
 func (h *DepositUsecase) CreateDeposit(ctx context.Context, customerXid string, payload model.PayloadDeposit) (*model.Deposit, error) {
-	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid)
+	if payload.Currency == "" {
+		payload.Currency = model.DefaultCurrency
+	}
+
+	deposit, err := h.createDeposit(ctx, customerXid, payload)
+	observeTransaction(metrics.TransactionDeposit, payload.Currency, payload.Amount, deposit != nil && deposit.Replayed, err)
+
+	return deposit, err
+}
+
+func (h *DepositUsecase) createDeposit(ctx context.Context, customerXid string, payload model.PayloadDeposit) (*model.Deposit, error) {
+	_, err := lookupAmountCurrency(payload.Currency, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, payload.Currency)
 	if err != nil {
 		return nil, err
 	}
 
 	if wallet == nil {
-		return nil, errors.New("Wallet not enable")
+		return nil, commonerr.ErrWalletNotFound
 	} else {
-		if wallet.Status == DisabledStatus {
-			return nil, errors.New("Wallet disabled")
+		if wallet.Status != EnabledStatus {
+			return nil, walletStatusError(wallet.Status)
 		}
 	}
-	wallet.Balance = wallet.Balance.Add(payload.Amount)
 
-	deposit, err := h.repo.GetDepositByReferenceId(ctx, payload.ReferenceID)
+	deposit, err := h.repo.GetDepositByReferenceId(ctx, customerXid, payload.ReferenceID)
 	if err != nil {
 		return nil, err
 	}
 
 	if deposit != nil {
-		return nil, errors.New("Reference id already used")
+		return replayDeposit(ctx, deposit, payload)
+	}
+
+	err = h.limits.CheckDeposit(ctx, wallet, payload.Amount)
+	if err != nil {
+		return nil, err
 	}
 
 	data := &model.Deposit{
@@ -54,13 +78,42 @@ func (h *DepositUsecase) CreateDeposit(ctx context.Context, customerXid string,
 		DepositedAt: time.Now(),
 		Amount:      payload.Amount,
 		ReferenceID: payload.ReferenceID,
+		Currency:    payload.Currency,
 	}
 
 	deposit, err = h.repo.CreateDeposit(ctx, data, wallet)
+	err = walletWriteError(ctx, err, wallet)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		deposit, err = h.repo.GetDepositByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if deposit == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayDeposit(ctx, deposit, payload)
+	}
 	if err != nil {
 		return nil, err
 	}
 	deposit.StatusMessage = "success"
+	logger.FromContext(ctx).Info("deposit created", "deposit_id", deposit.ID, "amount", deposit.Amount.String())
 
 	return deposit, err
 }
+
+// replayDeposit returns the deposit already made with the payload reference id
+// when the payload matches it. A refund is not a deposit the payload could
+// have made.
+func replayDeposit(ctx context.Context, deposit *model.Deposit, payload model.PayloadDeposit) (*model.Deposit, error) {
+	if !deposit.Amount.Equal(payload.Amount) || deposit.Currency != payload.Currency || deposit.RefundOf != "" {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	deposit.StatusMessage = transactionStatusMessage(deposit.Status)
+	deposit.Replayed = true
+
+	return deposit, nil
+}
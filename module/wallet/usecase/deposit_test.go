@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
 	"github.com/herwando/mini-wallet/module/wallet/usecase"
 	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
 	"github.com/shopspring/decimal"
@@ -17,6 +19,8 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockDepositDB := mockDB.NewMockDepositRepository(ctrl)
 	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	defer ctrl.Finish()
 	now := time.Now()
 	mockCtx := context.Background()
@@ -27,6 +31,7 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 		Status:    1,
 		EnabledAt: now,
 		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
 	}
 	mockWalletDisable := &model.Wallet{
 		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
@@ -42,8 +47,11 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 		DepositedAt: now,
 		Amount:      decimal.NewFromInt(1000),
 		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:    "IDR",
 	}
 	mockCustomerXid := mockDeposit.DepositedBy
+	mockDepositOther := *mockDeposit
+	mockDepositOther.Amount = decimal.NewFromInt(2000)
 	mockPayload := model.PayloadDeposit{
 		ReferenceID: mockDeposit.ReferenceID,
 		Amount:      mockDeposit.Amount,
@@ -55,10 +63,13 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 		payload     model.PayloadDeposit
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		patch   func()
+		name         string
+		args         args
+		limits       limits.Defaults
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
 	}{
 		{
 			name: "Success",
@@ -69,11 +80,31 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
 				mockDepositDB.EXPECT().CreateDeposit(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockDeposit, nil)
 			},
 		},
+		{
+			name: "Failed currency not supported",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadDeposit{ReferenceID: mockPayload.ReferenceID, Amount: mockPayload.Amount, Currency: "ABC"},
+			},
+			wantErr: true,
+			patch:   func() {},
+		},
+		{
+			name: "Failed amount precision",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadDeposit{ReferenceID: mockPayload.ReferenceID, Amount: decimal.RequireFromString("10.5"), Currency: "JPY"},
+			},
+			wantErr: true,
+			patch:   func() {},
+		},
 		{
 			name: "Failed on GetWalletByCustomerXid errpr",
 			args: args{
@@ -83,7 +114,7 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
 			},
 		},
 		{
@@ -95,7 +126,7 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
 			},
 		},
 		{
@@ -107,7 +138,7 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletDisable, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletDisable, nil)
 			},
 		},
 		{
@@ -119,12 +150,26 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, mockError)
 			},
 		},
 		{
-			name: "Failed on GetDepositByReferenceId",
+			name: "Success replay on GetDepositByReferenceId",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockDeposit, nil)
+			},
+		},
+		{
+			name: "Failed on GetDepositByReferenceId with different payload",
 			args: args{
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
@@ -132,8 +177,54 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockDepositOther, nil)
+			},
+		},
+		{
+			name: "Success replay on CreateDeposit reference id used",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockDepositDB.EXPECT().CreateDeposit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, commonerr.ErrReferenceIdUsed)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockDeposit, nil)
+			},
+		},
+		{
+			name: "Failed max deposit limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {MaxDeposit: decimal.NewFromInt(500)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Success replay over limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:       limits.Defaults{"IDR": {MaxDeposit: decimal.NewFromInt(500)}},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockDeposit, nil)
 			},
 		},
 		{
@@ -145,21 +236,69 @@ func TestUsecaseDeposit_CreateDeposit(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
 				mockDepositDB.EXPECT().CreateDeposit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockError)
 			},
 		},
+		{
+			name: "Failed on CreateDeposit wallet closed meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletClosed,
+			patch: func() {
+				// CloseWallet commits between the wallet read and the credit,
+				// so the credit's status guard matches no row.
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockDepositDB.EXPECT().CreateDeposit(gomock.Any(), gomock.Any(), &wallet).DoAndReturn(
+					func(ctx context.Context, deposit *model.Deposit, wallet *model.Wallet) (*model.Deposit, error) {
+						wallet.Status = usecase.ClosedStatus
+						return nil, commonerr.ErrWalletTransition
+					})
+			},
+		},
+		{
+			name: "Failed on CreateDeposit wallet frozen meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletFrozen,
+			patch: func() {
+				wallet := *mockWallet
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockDepositDB.EXPECT().CreateDeposit(gomock.Any(), gomock.Any(), &wallet).DoAndReturn(
+					func(ctx context.Context, deposit *model.Deposit, wallet *model.Wallet) (*model.Deposit, error) {
+						wallet.Status = usecase.FrozenStatus
+						return nil, commonerr.ErrWalletTransition
+					})
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
-			uc := usecase.NewDepositUsecase(mockDepositDB, mockWalletDB)
-			_, err := uc.CreateDeposit(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			uc := usecase.NewDepositUsecase(mockDepositDB, mockWalletDB, usecase.NewLimitChecker(mockLimitDB, tt.limits))
+			got, err := uc.CreateDeposit(tt.args.ctx, tt.args.customerXid, tt.args.payload)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.CreateDeposit() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateDeposit() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.CreateDeposit() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
 		})
 	}
 }
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type HealthUsecase struct {
+	repo             HealthRepository
+	migrationVersion uint64
+}
+
+// NewHealthUsecase takes the migration version this build expects the
+// database to be at, usually db.LatestMigrationVersion.
+func NewHealthUsecase(repo HealthRepository, migrationVersion uint64) *HealthUsecase {
+	return &HealthUsecase{
+		repo:             repo,
+		migrationVersion: migrationVersion,
+	}
+}
+
+// Ready reports whether the database is reachable and migrated far enough for
+// this build to serve requests.
+func (h *HealthUsecase) Ready(ctx context.Context) error {
+	err := h.repo.Ping(ctx)
+	if err != nil {
+		return errors.New("Database unreachable")
+	}
+
+	version, dirty, err := h.repo.GetMigrationVersion(ctx)
+	if err != nil {
+		return errors.New("Migration version unreadable")
+	}
+
+	if dirty {
+		return fmt.Errorf("Migration %d failed halfway", version)
+	}
+
+	if version < h.migrationVersion {
+		return fmt.Errorf("Migrations pending, database at %d, want %d", version, h.migrationVersion)
+	}
+
+	return nil
+}
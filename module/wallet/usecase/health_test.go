@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+)
+
+func TestUsecaseHealth_Ready(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockHealthDB := mockDB.NewMockHealthRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockVersion := uint64(20261018100400)
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		patch   func()
+	}{
+		{
+			name:    "Success",
+			wantErr: false,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockHealthDB.EXPECT().GetMigrationVersion(gomock.Any()).Return(mockVersion, false, nil)
+			},
+		},
+		{
+			name:    "Success database ahead of build",
+			wantErr: false,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockHealthDB.EXPECT().GetMigrationVersion(gomock.Any()).Return(mockVersion+100, false, nil)
+			},
+		},
+		{
+			name:    "Failed on Ping",
+			wantErr: true,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(mockError)
+			},
+		},
+		{
+			name:    "Failed on GetMigrationVersion",
+			wantErr: true,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockHealthDB.EXPECT().GetMigrationVersion(gomock.Any()).Return(uint64(0), false, mockError)
+			},
+		},
+		{
+			name:    "Failed migration dirty",
+			wantErr: true,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockHealthDB.EXPECT().GetMigrationVersion(gomock.Any()).Return(mockVersion, true, nil)
+			},
+		},
+		{
+			name:    "Failed migration pending",
+			wantErr: true,
+			patch: func() {
+				mockHealthDB.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockHealthDB.EXPECT().GetMigrationVersion(gomock.Any()).Return(mockVersion-100, false, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewHealthUsecase(mockHealthDB, mockVersion)
+			err := uc.Ready(mockCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.Ready() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
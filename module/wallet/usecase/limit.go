@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/shopspring/decimal"
+)
+
+// withdrawnStatuses are the statuses of the withdrawals and transfers counted
+// against the daily and monthly withdrawal limits; a pending withdrawal
+// already holds its amount.
+var withdrawnStatuses = []int{SuccessStatus, PendingStatus}
+
+// LimitChecker checks the transactions of a wallet against the default limits
+// of its currency, as overridden for the wallet. A check that passes sets the
+// wallet's MaxBalance, which the repository holds every credit to in the
+// same UPDATE that makes it, and its Limits, which the repository checks the
+// wallet's usage against again once it holds the wallet's lock: the usage
+// read here may not count the transactions made concurrently.
+type LimitChecker struct {
+	repo     LimitRepository
+	defaults limits.Defaults
+}
+
+func NewLimitChecker(repo LimitRepository, defaults limits.Defaults) *LimitChecker {
+	return &LimitChecker{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+// CheckDeposit returns commonerr.ErrLimitExceeded when depositing amount to
+// the wallet goes over one of its limits.
+func (h *LimitChecker) CheckDeposit(ctx context.Context, wallet *model.Wallet, amount decimal.Decimal) error {
+	l, err := h.walletLimits(ctx, wallet)
+	if err != nil {
+		return err
+	}
+
+	usage, err := h.usage(ctx, wallet, l, time.Now())
+	if err != nil {
+		return err
+	}
+
+	wallet.MaxBalance = l.MaxBalance
+	wallet.Limits = &l
+	wallet.WithdrawnStatuses = withdrawnStatuses
+	return limitExceeded(ctx, l.CheckDeposit(amount, wallet.Balance, usage))
+}
+
+// CheckCredit returns commonerr.ErrLimitExceeded when crediting amount to the
+// wallet, from a transfer, a conversion or a refund, takes it over its
+// balance cap.
+func (h *LimitChecker) CheckCredit(ctx context.Context, wallet *model.Wallet, amount decimal.Decimal) error {
+	l, err := h.walletLimits(ctx, wallet)
+	if err != nil {
+		return err
+	}
+
+	wallet.MaxBalance = l.MaxBalance
+	return limitExceeded(ctx, l.CheckCredit(amount, wallet.Balance))
+}
+
+// CheckWithdrawal returns commonerr.ErrLimitExceeded when sending amount out
+// of the wallet, by a withdrawal, a transfer or a conversion, goes over one of
+// its limits.
+func (h *LimitChecker) CheckWithdrawal(ctx context.Context, wallet *model.Wallet, amount decimal.Decimal) error {
+	l, err := h.walletLimits(ctx, wallet)
+	if err != nil {
+		return err
+	}
+
+	usage, err := h.usage(ctx, wallet, l, time.Now())
+	if err != nil {
+		return err
+	}
+
+	wallet.Limits = &l
+	wallet.WithdrawnStatuses = withdrawnStatuses
+	return limitExceeded(ctx, l.CheckWithdrawal(amount, usage))
+}
+
+func (h *LimitChecker) walletLimits(ctx context.Context, wallet *model.Wallet) (limits.Limits, error) {
+	override, err := h.repo.GetWalletLimits(ctx, wallet.ID)
+	if err != nil {
+		return limits.Limits{}, err
+	}
+
+	return h.defaults[wallet.Currency].Apply(override), nil
+}
+
+// usage reads only what the limits set in l need, over limits.Windows.
+func (h *LimitChecker) usage(ctx context.Context, wallet *model.Wallet, l limits.Limits, now time.Time) (limits.Usage, error) {
+	var usage limits.Usage
+	var err error
+
+	day, month, hour := limits.Windows(now)
+	if l.DailyWithdrawal.IsPositive() {
+		usage.DailyWithdrawn, err = h.repo.GetWithdrawnAmount(ctx, wallet.OwnedBy, wallet.Currency, withdrawnStatuses, day)
+		if err != nil {
+			return usage, err
+		}
+	}
+
+	if l.MonthlyWithdrawal.IsPositive() {
+		usage.MonthlyWithdrawn, err = h.repo.GetWithdrawnAmount(ctx, wallet.OwnedBy, wallet.Currency, withdrawnStatuses, month)
+		if err != nil {
+			return usage, err
+		}
+	}
+
+	if l.HourlyTransactions > 0 {
+		usage.HourlyTransactions, err = h.repo.CountTransactions(ctx, wallet.OwnedBy, wallet.Currency, hour)
+		if err != nil {
+			return usage, err
+		}
+	}
+
+	return usage, nil
+}
+
+// limitExceeded turns a limits.ExceededError, from a check or from a credit
+// the repository refused, into commonerr.ErrLimitExceeded telling the client
+// which limit was hit and what it still allows.
+func limitExceeded(ctx context.Context, err error) error {
+	var exceeded *limits.ExceededError
+	if !errors.As(err, &exceeded) {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("transaction limit exceeded", "limit", exceeded.Limit, "remaining", exceeded.Remaining.String())
+
+	return commonerr.ErrLimitExceeded.WithMeta(map[string]string{
+		"limit":     exceeded.Limit,
+		"remaining": exceeded.Remaining.String(),
+	})
+}
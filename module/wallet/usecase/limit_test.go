@@ -0,0 +1,230 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestUsecaseLimit_CheckDeposit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:       "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:  "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:   1,
+		Balance:  decimal.NewFromInt(10000),
+		Currency: "IDR",
+	}
+	defaults := limits.Defaults{"IDR": {MaxBalance: decimal.NewFromInt(20000), HourlyTransactions: 5}}
+
+	tests := []struct {
+		name          string
+		amount        decimal.Decimal
+		wantErr       bool
+		resultErr     error
+		wantLimit     string
+		wantRemaining string
+		patch         func()
+	}{
+		{
+			name:    "Success",
+			amount:  decimal.NewFromInt(5000),
+			wantErr: false,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().CountTransactions(gomock.Any(), mockWallet.OwnedBy, "IDR", gomock.Any()).Return(4, nil)
+			},
+		},
+		{
+			name:          "Failed max balance",
+			amount:        decimal.NewFromInt(12000),
+			wantErr:       true,
+			resultErr:     commonerr.ErrLimitExceeded,
+			wantLimit:     limits.MaxBalance,
+			wantRemaining: "10000",
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().CountTransactions(gomock.Any(), mockWallet.OwnedBy, "IDR", gomock.Any()).Return(0, nil)
+			},
+		},
+		{
+			name:          "Failed hourly transactions",
+			amount:        decimal.NewFromInt(1000),
+			wantErr:       true,
+			resultErr:     commonerr.ErrLimitExceeded,
+			wantLimit:     limits.HourlyTransactions,
+			wantRemaining: "0",
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().CountTransactions(gomock.Any(), mockWallet.OwnedBy, "IDR", gomock.Any()).Return(5, nil)
+			},
+		},
+		{
+			name:    "Success override lifts limits",
+			amount:  decimal.NewFromInt(50000),
+			wantErr: false,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(&limits.Override{
+					MaxBalance:         decimal.NullDecimal{Decimal: decimal.Zero, Valid: true},
+					HourlyTransactions: sql.NullInt64{Int64: 0, Valid: true},
+				}, nil)
+			},
+		},
+		{
+			name:    "Failed GetWalletLimits",
+			amount:  decimal.NewFromInt(1000),
+			wantErr: true,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed CountTransactions",
+			amount:  decimal.NewFromInt(1000),
+			wantErr: true,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().CountTransactions(gomock.Any(), mockWallet.OwnedBy, "IDR", gomock.Any()).Return(0, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			checker := usecase.NewLimitChecker(mockLimitDB, defaults)
+			err := checker.CheckDeposit(mockCtx, mockWallet, tt.amount)
+			assertLimitErr(t, "LimitChecker.CheckDeposit()", err, tt.wantErr, tt.resultErr, tt.wantLimit, tt.wantRemaining)
+		})
+	}
+}
+
+func TestUsecaseLimit_CheckWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:       "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:  "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:   1,
+		Balance:  decimal.NewFromInt(10000),
+		Currency: "IDR",
+	}
+	defaults := limits.Defaults{"IDR": {DailyWithdrawal: decimal.NewFromInt(5000), MonthlyWithdrawal: decimal.NewFromInt(20000)}}
+	statuses := []int{usecase.SuccessStatus, usecase.PendingStatus}
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		amount        decimal.Decimal
+		wantErr       bool
+		resultErr     error
+		wantLimit     string
+		wantRemaining string
+		patch         func()
+	}{
+		{
+			name:    "Success",
+			amount:  decimal.NewFromInt(1000),
+			wantErr: false,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, day).Return(decimal.NewFromInt(3000), nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, month).Return(decimal.NewFromInt(15000), nil)
+			},
+		},
+		{
+			name:          "Failed daily withdrawal",
+			amount:        decimal.NewFromInt(2500),
+			wantErr:       true,
+			resultErr:     commonerr.ErrLimitExceeded,
+			wantLimit:     limits.DailyWithdrawal,
+			wantRemaining: "2000",
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, day).Return(decimal.NewFromInt(3000), nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, month).Return(decimal.NewFromInt(3000), nil)
+			},
+		},
+		{
+			name:          "Failed monthly withdrawal",
+			amount:        decimal.NewFromInt(1000),
+			wantErr:       true,
+			resultErr:     commonerr.ErrLimitExceeded,
+			wantLimit:     limits.MonthlyWithdrawal,
+			wantRemaining: "500",
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, day).Return(decimal.NewFromInt(0), nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, month).Return(decimal.NewFromInt(19500), nil)
+			},
+		},
+		{
+			name:          "Failed max withdrawal override",
+			amount:        decimal.NewFromInt(1000),
+			wantErr:       true,
+			resultErr:     commonerr.ErrLimitExceeded,
+			wantLimit:     limits.MaxWithdrawal,
+			wantRemaining: "800",
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(&limits.Override{
+					MaxWithdrawal:     decimal.NullDecimal{Decimal: decimal.NewFromInt(800), Valid: true},
+					DailyWithdrawal:   decimal.NullDecimal{Decimal: decimal.Zero, Valid: true},
+					MonthlyWithdrawal: decimal.NullDecimal{Decimal: decimal.Zero, Valid: true},
+				}, nil)
+			},
+		},
+		{
+			name:    "Failed GetWithdrawnAmount",
+			amount:  decimal.NewFromInt(1000),
+			wantErr: true,
+			patch: func() {
+				mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), mockWallet.ID).Return(nil, nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", statuses, day).Return(decimal.Zero, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			checker := usecase.NewLimitChecker(mockLimitDB, defaults)
+			err := checker.CheckWithdrawal(mockCtx, mockWallet, tt.amount)
+			assertLimitErr(t, "LimitChecker.CheckWithdrawal()", err, tt.wantErr, tt.resultErr, tt.wantLimit, tt.wantRemaining)
+		})
+	}
+}
+
+func assertLimitErr(t *testing.T, call string, err error, wantErr bool, resultErr error, wantLimit, wantRemaining string) {
+	t.Helper()
+	if (err != nil) != wantErr {
+		t.Errorf("%s error = %v, wantErr %v", call, err, wantErr)
+		return
+	}
+	if resultErr != nil && !errors.Is(err, resultErr) {
+		t.Errorf("%s error = %v, want %v", call, err, resultErr)
+	}
+
+	var domainErr *commonerr.DomainError
+	if wantLimit != "" && errors.As(err, &domainErr) {
+		if domainErr.Meta["limit"] != wantLimit || domainErr.Meta["remaining"] != wantRemaining {
+			t.Errorf("%s meta = %v, want limit %s remaining %s", call, domainErr.Meta, wantLimit, wantRemaining)
+		}
+	}
+}
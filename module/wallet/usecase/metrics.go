@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/shopspring/decimal"
+)
+
+// observeTransaction counts the outcome of a create call. A replay did not
+// move any money, so it is neither a new transaction nor a failure.
+func observeTransaction(transactionType, currency string, amount decimal.Decimal, replayed bool, err error) {
+	switch {
+	case err == nil:
+		if !replayed {
+			metrics.ObserveTransaction(transactionType, currency, amount)
+		}
+	case err == commonerr.ErrBalanceNotEnough:
+		metrics.ObserveTransactionFailed(transactionType, metrics.ReasonBalanceNotEnough)
+	case err == commonerr.ErrReferenceIdUsed:
+		metrics.ObserveTransactionFailed(transactionType, metrics.ReasonReferenceIdUsed)
+	case errors.Is(err, commonerr.ErrLimitExceeded):
+		metrics.ObserveTransactionFailed(transactionType, metrics.ReasonLimitExceeded)
+	default:
+		metrics.ObserveTransactionFailed(transactionType, metrics.ReasonOther)
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+// reconcileBatchSize is how many wallets Reconcile reads at a time.
+const reconcileBatchSize = 500
+
+type ReconciliationUsecase struct {
+	repo ReconciliationRepository
+}
+
+func NewReconciliationUsecase(repo ReconciliationRepository) *ReconciliationUsecase {
+	return &ReconciliationUsecase{
+		repo: repo,
+	}
+}
+
+// Reconcile compares the balance of every wallet with the balance its
+// successful transactions add up to, and reports the wallets that drifted.
+// With record the discrepancies are also kept in balance_discrepancies.
+func (h *ReconciliationUsecase) Reconcile(ctx context.Context, record bool) (*model.ReconciliationReport, error) {
+	report := &model.ReconciliationReport{
+		ReconciledAt:  time.Now(),
+		Discrepancies: []*model.BalanceDiscrepancy{},
+	}
+
+	afterID := ""
+	for {
+		reconciliations, err := h.repo.GetWalletReconciliations(ctx, SuccessStatus, afterID, reconcileBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reconciliation := range reconciliations {
+			report.WalletsChecked++
+			drift := reconciliation.Balance.Sub(reconciliation.ExpectedBalance)
+			if drift.IsZero() {
+				continue
+			}
+
+			logger.FromContext(ctx).Warn("wallet balance drifted", "wallet_id", reconciliation.WalletID, "balance", reconciliation.Balance.String(), "expected_balance", reconciliation.ExpectedBalance.String())
+			report.Discrepancies = append(report.Discrepancies, &model.BalanceDiscrepancy{
+				WalletReconciliation: *reconciliation,
+				Drift:                drift,
+				ReconciledAt:         report.ReconciledAt,
+			})
+		}
+
+		if len(reconciliations) < reconcileBatchSize {
+			break
+		}
+		afterID = reconciliations[len(reconciliations)-1].WalletID
+	}
+
+	if record && len(report.Discrepancies) > 0 {
+		err := h.repo.CreateBalanceDiscrepancies(ctx, report.Discrepancies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsecaseReconciliation_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockReconciliationDB := mockDB.NewMockReconciliationRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockBalanced := &model.WalletReconciliation{
+		WalletID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		CustomerXid:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Currency:        "IDR",
+		Balance:         decimal.NewFromInt(10000),
+		ExpectedBalance: decimal.NewFromInt(10000),
+	}
+	mockDrifted := &model.WalletReconciliation{
+		WalletID:        "9b1c4d2e-7f3a-4e5b-8c6d-0a1b2c3d4e5f",
+		CustomerXid:     "5b1c1f0e-3a4d-4c2b-9e8f-7a6b5c4d3e2f",
+		Currency:        "USD",
+		Balance:         decimal.NewFromInt(150),
+		ExpectedBalance: decimal.NewFromInt(100),
+	}
+	// A full batch makes Reconcile read on from the last wallet of it.
+	mockFullBatch := make([]*model.WalletReconciliation, 500)
+	for i := range mockFullBatch {
+		reconciliation := *mockBalanced
+		reconciliation.WalletID = fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		mockFullBatch[i] = &reconciliation
+	}
+
+	type args struct {
+		ctx    context.Context
+		record bool
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantChecked int
+		wantDrift   []decimal.Decimal
+		wantErr     bool
+		patch       func()
+	}{
+		{
+			name: "Success without drift",
+			args: args{
+				ctx:    mockCtx,
+				record: true,
+			},
+			wantChecked: 1,
+			wantErr:     false,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return([]*model.WalletReconciliation{mockBalanced}, nil)
+			},
+		},
+		{
+			name: "Success with drift recorded",
+			args: args{
+				ctx:    mockCtx,
+				record: true,
+			},
+			wantChecked: 2,
+			wantDrift:   []decimal.Decimal{decimal.NewFromInt(50)},
+			wantErr:     false,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return([]*model.WalletReconciliation{mockBalanced, mockDrifted}, nil)
+				mockReconciliationDB.EXPECT().CreateBalanceDiscrepancies(gomock.Any(), gomock.Len(1)).Return(nil)
+			},
+		},
+		{
+			name: "Success with drift not recorded",
+			args: args{
+				ctx:    mockCtx,
+				record: false,
+			},
+			wantChecked: 1,
+			wantDrift:   []decimal.Decimal{decimal.NewFromInt(50)},
+			wantErr:     false,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return([]*model.WalletReconciliation{mockDrifted}, nil)
+			},
+		},
+		{
+			name: "Success over several batches",
+			args: args{
+				ctx:    mockCtx,
+				record: false,
+			},
+			wantChecked: 501,
+			wantDrift:   []decimal.Decimal{decimal.NewFromInt(50)},
+			wantErr:     false,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return(mockFullBatch, nil)
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, mockFullBatch[499].WalletID, 500).Return([]*model.WalletReconciliation{mockDrifted}, nil)
+			},
+		},
+		{
+			name: "Failed on GetWalletReconciliations",
+			args: args{
+				ctx:    mockCtx,
+				record: true,
+			},
+			wantErr: true,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed on CreateBalanceDiscrepancies",
+			args: args{
+				ctx:    mockCtx,
+				record: true,
+			},
+			wantErr: true,
+			patch: func() {
+				mockReconciliationDB.EXPECT().GetWalletReconciliations(gomock.Any(), usecase.SuccessStatus, "", 500).Return([]*model.WalletReconciliation{mockDrifted}, nil)
+				mockReconciliationDB.EXPECT().CreateBalanceDiscrepancies(gomock.Any(), gomock.Len(1)).Return(mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewReconciliationUsecase(mockReconciliationDB)
+			report, err := uc.Reconcile(tt.args.ctx, tt.args.record)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, tt.wantChecked, report.WalletsChecked)
+			assert.Len(t, report.Discrepancies, len(tt.wantDrift))
+			for i, drift := range tt.wantDrift {
+				assert.True(t, drift.Equal(report.Discrepancies[i].Drift), "drift = %s, want %s", report.Discrepancies[i].Drift, drift)
+				assert.Equal(t, mockDrifted.WalletID, report.Discrepancies[i].WalletID)
+				assert.Equal(t, report.ReconciledAt, report.Discrepancies[i].ReconciledAt)
+			}
+		})
+	}
+}
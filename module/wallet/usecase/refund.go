@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/shopspring/decimal"
+)
+
+type RefundUsecase struct {
+	repo           RefundRepository
+	depositRepo    DepositRepository
+	withdrawalRepo WithdrawalRepository
+	walletRepo     WalletRepository
+	limits         *LimitChecker
+}
+
+func NewRefundUsecase(repo RefundRepository, depositRepo DepositRepository, withdrawalRepo WithdrawalRepository, walletRepo WalletRepository, limits *LimitChecker) *RefundUsecase {
+	return &RefundUsecase{
+		repo:           repo,
+		depositRepo:    depositRepo,
+		withdrawalRepo: withdrawalRepo,
+		walletRepo:     walletRepo,
+		limits:         limits,
+	}
+}
+
+// RefundWithdrawal gives back part of a captured withdrawal of the customer,
+// or what is left of it when the payload has no amount, as a deposit linked
+// to it. Only operations staff may refund: the customer already has the money
+// the withdrawal paid out.
+func (h *RefundUsecase) RefundWithdrawal(ctx context.Context, customerXid, id string, payload model.PayloadRefund) (*model.Deposit, error) {
+	refund, err := h.refundWithdrawal(ctx, customerXid, id, payload)
+
+	currency, amount := "", payload.Amount
+	if refund != nil {
+		currency, amount = refund.Currency, refund.Amount
+	}
+	observeTransaction(metrics.TransactionRefund, currency, amount, refund != nil && refund.Replayed, err)
+
+	return refund, err
+}
+
+func (h *RefundUsecase) refundWithdrawal(ctx context.Context, customerXid, id string, payload model.PayloadRefund) (*model.Deposit, error) {
+	if !uuidPattern.MatchString(customerXid) || !uuidPattern.MatchString(id) {
+		return nil, commonerr.ErrWithdrawalNotFound
+	}
+
+	withdrawal, err := h.withdrawalRepo.GetWithdrawalById(ctx, customerXid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if withdrawal == nil {
+		return nil, commonerr.ErrWithdrawalNotFound
+	}
+
+	// Conversion legs and reversals are undone with the record they belong
+	// to, and only a captured withdrawal has taken any money.
+	if withdrawal.Status != SuccessStatus || withdrawal.ConversionID != "" || withdrawal.ReversalOf != "" {
+		return nil, commonerr.ErrWithdrawalNotRefundable
+	}
+
+	amount := payload.Amount
+	if amount.IsZero() {
+		amount = withdrawal.Amount.Sub(withdrawal.RefundedAmount)
+	}
+
+	_, err = lookupAmountCurrency(withdrawal.Currency, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, withdrawal.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, commonerr.ErrWalletNotFound
+	} else {
+		if wallet.Status != EnabledStatus {
+			return nil, walletStatusError(wallet.Status)
+		}
+	}
+
+	refund, err := h.depositRepo.GetDepositByReferenceId(ctx, customerXid, payload.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if refund != nil {
+		return replayRefund(ctx, refund, withdrawal, payload)
+	}
+
+	if !amount.IsPositive() || withdrawal.Amount.Sub(withdrawal.RefundedAmount).LessThan(amount) {
+		return nil, commonerr.ErrRefundExceedsAmount
+	}
+
+	err = h.limits.CheckCredit(ctx, wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &model.Deposit{
+		DepositedBy: customerXid,
+		Status:      SuccessStatus,
+		DepositedAt: time.Now(),
+		Amount:      amount,
+		ReferenceID: payload.ReferenceID,
+		Currency:    withdrawal.Currency,
+	}
+
+	refund, err = h.repo.RefundWithdrawal(ctx, withdrawal, data, wallet)
+	err = walletWriteError(ctx, err, wallet)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		refund, err = h.depositRepo.GetDepositByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if refund == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayRefund(ctx, refund, withdrawal, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	refund.StatusMessage = "success"
+	logger.FromContext(ctx).Info("withdrawal refunded", "deposit_id", refund.ID, "withdrawal_id", withdrawal.ID, "amount", refund.Amount.String())
+
+	return refund, nil
+}
+
+// replayRefund returns the refund already made with the payload reference id
+// when it refunds the same withdrawal, by the payload amount if one is given.
+func replayRefund(ctx context.Context, refund *model.Deposit, withdrawal *model.Withdrawal, payload model.PayloadRefund) (*model.Deposit, error) {
+	if refund.RefundOf != withdrawal.ID || (!payload.Amount.IsZero() && !refund.Amount.Equal(payload.Amount)) {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	refund.StatusMessage = transactionStatusMessage(refund.Status)
+	refund.Replayed = true
+
+	return refund, nil
+}
+
+// ReverseDeposit takes a deposit of the customer back off the wallet as a
+// withdrawal linked to it. A deposit can only be reversed once, and in full,
+// and only by operations staff.
+func (h *RefundUsecase) ReverseDeposit(ctx context.Context, customerXid, id string, payload model.PayloadReversal) (*model.Withdrawal, error) {
+	reversal, err := h.reverseDeposit(ctx, customerXid, id, payload)
+
+	var currency string
+	var amount decimal.Decimal
+	if reversal != nil {
+		currency, amount = reversal.Currency, reversal.Amount
+	}
+	observeTransaction(metrics.TransactionReversal, currency, amount, reversal != nil && reversal.Replayed, err)
+
+	return reversal, err
+}
+
+func (h *RefundUsecase) reverseDeposit(ctx context.Context, customerXid, id string, payload model.PayloadReversal) (*model.Withdrawal, error) {
+	if !uuidPattern.MatchString(customerXid) || !uuidPattern.MatchString(id) {
+		return nil, commonerr.ErrDepositNotFound
+	}
+
+	deposit, err := h.depositRepo.GetDepositById(ctx, customerXid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit == nil {
+		return nil, commonerr.ErrDepositNotFound
+	}
+
+	if deposit.Status != SuccessStatus || deposit.ConversionID != "" || deposit.RefundOf != "" {
+		return nil, commonerr.ErrDepositNotReversible
+	}
+
+	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, deposit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, commonerr.ErrWalletNotFound
+	} else {
+		if wallet.Status != EnabledStatus {
+			return nil, walletStatusError(wallet.Status)
+		}
+	}
+
+	reversal, err := h.withdrawalRepo.GetWithdrawalByReferenceId(ctx, customerXid, payload.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reversal != nil {
+		return replayReversal(ctx, reversal, deposit, payload)
+	}
+
+	if wallet.Available().LessThan(deposit.Amount) {
+		return nil, commonerr.ErrBalanceNotEnough
+	}
+
+	data := &model.Withdrawal{
+		WithdrawnBy: customerXid,
+		Status:      SuccessStatus,
+		WithdrawnAt: time.Now(),
+		Amount:      deposit.Amount,
+		ReferenceID: payload.ReferenceID,
+		Currency:    deposit.Currency,
+	}
+
+	reversal, err = h.repo.ReverseDeposit(ctx, deposit, data, wallet)
+	err = walletWriteError(ctx, err, wallet)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		reversal, err = h.withdrawalRepo.GetWithdrawalByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if reversal == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayReversal(ctx, reversal, deposit, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reversal.StatusMessage = "success"
+	logger.FromContext(ctx).Info("deposit reversed", "withdrawal_id", reversal.ID, "deposit_id", deposit.ID, "amount", reversal.Amount.String())
+
+	return reversal, nil
+}
+
+// replayReversal returns the reversal already made with the payload reference
+// id when it reverses the same deposit.
+func replayReversal(ctx context.Context, reversal *model.Withdrawal, deposit *model.Deposit, payload model.PayloadReversal) (*model.Withdrawal, error) {
+	if reversal.ReversalOf != deposit.ID {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	reversal.StatusMessage = transactionStatusMessage(reversal.Status)
+	reversal.Replayed = true
+
+	return reversal, nil
+}
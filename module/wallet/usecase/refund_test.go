@@ -0,0 +1,598 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestUsecaseRefund_RefundWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRefundDB := mockDB.NewMockRefundRepository(ctrl)
+	mockDepositDB := mockDB.NewMockDepositRepository(ctrl)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(0),
+		Currency:    "IDR",
+	}
+	mockWalletDisable := *mockWallet
+	mockWalletDisable.Status = 2
+	mockWithdrawal := &model.Withdrawal{
+		ID:             "5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11",
+		WithdrawnBy:    "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:         1,
+		WithdrawnAt:    now,
+		Amount:         decimal.NewFromInt(1000),
+		RefundedAmount: decimal.NewFromInt(400),
+		ReferenceID:    "0f8c6a9e-8a77-4b8e-9f53-7b2a4a3c4d10",
+		Currency:       "IDR",
+	}
+	mockCustomerXid := mockWithdrawal.WithdrawnBy
+	mockWithdrawalPending := *mockWithdrawal
+	mockWithdrawalPending.Status = usecase.PendingStatus
+	mockWithdrawalConversion := *mockWithdrawal
+	mockWithdrawalConversion.ConversionID = "7d1f3c55-2f6a-4f0e-8f0d-2b8b4c7d9e01"
+	mockRefund := &model.Deposit{
+		ID:          "2c9a8f1e-6b4d-4a3e-9c1f-0d7e5b6a4f22",
+		DepositedBy: mockCustomerXid,
+		Status:      1,
+		DepositedAt: now,
+		Amount:      decimal.NewFromInt(600),
+		ReferenceID: "9b7e2d4c-1a3f-4e5b-8c6d-7f0a1b2c3d44",
+		Currency:    "IDR",
+		RefundOf:    mockWithdrawal.ID,
+	}
+	mockRefundOther := *mockRefund
+	mockRefundOther.RefundOf = ""
+	mockPayload := model.PayloadRefund{
+		ReferenceID: mockRefund.ReferenceID,
+	}
+	mockPayloadPartial := mockPayload
+	mockPayloadPartial.Amount = decimal.NewFromInt(250)
+	mockPayloadExceeds := mockPayload
+	mockPayloadExceeds.Amount = decimal.NewFromInt(601)
+	mockPayloadPrecision := mockPayload
+	mockPayloadPrecision.Amount = decimal.RequireFromString("10.555")
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		id          string
+		payload     model.PayloadRefund
+	}
+	tests := []struct {
+		name         string
+		args         args
+		limits       limits.Defaults
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
+	}{
+		{
+			name: "Success refund remainder",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr: false,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().RefundWithdrawal(gomock.Any(), mockWithdrawal, gomock.Any(), mockWallet).DoAndReturn(func(_ context.Context, _ *model.Withdrawal, refund *model.Deposit, _ *model.Wallet) (*model.Deposit, error) {
+					if !refund.Amount.Equal(decimal.NewFromInt(600)) || refund.Currency != "IDR" {
+						t.Errorf("RefundWithdrawal() got amount %s %s, want 600 IDR", refund.Amount, refund.Currency)
+					}
+					return mockRefund, nil
+				})
+			},
+		},
+		{
+			name: "Success partial refund",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayloadPartial,
+			},
+			wantErr: false,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().RefundWithdrawal(gomock.Any(), mockWithdrawal, gomock.Any(), mockWallet).DoAndReturn(func(_ context.Context, _ *model.Withdrawal, refund *model.Deposit, _ *model.Wallet) (*model.Deposit, error) {
+					if !refund.Amount.Equal(decimal.NewFromInt(250)) {
+						t.Errorf("RefundWithdrawal() got amount %s, want 250", refund.Amount)
+					}
+					return mockRefund, nil
+				})
+			},
+		},
+		{
+			name: "Success replay",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockRefund, nil)
+			},
+		},
+		{
+			name: "Failed replay of other deposit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrReferenceIdUsed,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockRefundOther, nil)
+			},
+		},
+		{
+			name: "Failed id not uuid",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          "not-a-uuid",
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotFound,
+			patch:     func() {},
+		},
+		{
+			name: "Failed withdrawal not found",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotFound,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed withdrawal pending",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotRefundable,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(&mockWithdrawalPending, nil)
+			},
+		},
+		{
+			name: "Failed withdrawal of conversion",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotRefundable,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(&mockWithdrawalConversion, nil)
+			},
+		},
+		{
+			name: "Failed amount precision",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayloadPrecision,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrAmountPrecision,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+			},
+		},
+		{
+			name: "Failed wallet disabled",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletDisabled,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&mockWalletDisable, nil)
+			},
+		},
+		{
+			name: "Failed refund exceeds amount",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayloadExceeds,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrRefundExceedsAmount,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Success replay after reference id race",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().RefundWithdrawal(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, commonerr.ErrReferenceIdUsed)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockRefund, nil)
+			},
+		},
+		{
+			name: "Failed max balance limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {MaxBalance: decimal.NewFromInt(10500)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed on RefundWithdrawal max balance meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().RefundWithdrawal(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, &limits.ExceededError{Limit: limits.MaxBalance, Remaining: decimal.NewFromInt(100)})
+			},
+		},
+		{
+			name: "Failed invalid customer",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: "not-a-customer",
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotFound,
+			patch:     func() {},
+		},
+		{
+			name: "Failed refund withdrawal",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockWithdrawal.ID,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockDepositDB.EXPECT().GetDepositByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().RefundWithdrawal(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewRefundUsecase(mockRefundDB, mockDepositDB, mockWithdrawalDB, mockWalletDB, usecase.NewLimitChecker(mockLimitDB, tt.limits))
+			got, err := uc.RefundWithdrawal(tt.args.ctx, tt.args.customerXid, tt.args.id, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.RefundWithdrawal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.RefundWithdrawal() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.RefundWithdrawal() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestUsecaseRefund_ReverseDeposit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRefundDB := mockDB.NewMockRefundRepository(ctrl)
+	mockDepositDB := mockDB.NewMockDepositRepository(ctrl)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(0),
+	}
+	mockWalletHeld := *mockWallet
+	mockWalletHeld.HeldBalance = decimal.NewFromInt(9500)
+	mockDeposit := &model.Deposit{
+		ID:          "2c9a8f1e-6b4d-4a3e-9c1f-0d7e5b6a4f22",
+		DepositedBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		DepositedAt: now,
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "0f8c6a9e-8a77-4b8e-9f53-7b2a4a3c4d10",
+		Currency:    "IDR",
+	}
+	mockCustomerXid := mockDeposit.DepositedBy
+	mockDepositRefund := *mockDeposit
+	mockDepositRefund.RefundOf = "5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11"
+	mockReversal := &model.Withdrawal{
+		ID:          "5e0b1a27-3d0e-4b5c-9a51-1c4f2f0e8a11",
+		WithdrawnBy: mockCustomerXid,
+		Status:      1,
+		WithdrawnAt: now,
+		Amount:      mockDeposit.Amount,
+		ReferenceID: "9b7e2d4c-1a3f-4e5b-8c6d-7f0a1b2c3d44",
+		Currency:    "IDR",
+		ReversalOf:  mockDeposit.ID,
+	}
+	mockReversalOther := *mockReversal
+	mockReversalOther.ReversalOf = ""
+	mockPayload := model.PayloadReversal{
+		ReferenceID: mockReversal.ReferenceID,
+	}
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		id          string
+		payload     model.PayloadReversal
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr: false,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().ReverseDeposit(gomock.Any(), mockDeposit, gomock.Any(), mockWallet).DoAndReturn(func(_ context.Context, _ *model.Deposit, reversal *model.Withdrawal, _ *model.Wallet) (*model.Withdrawal, error) {
+					if !reversal.Amount.Equal(mockDeposit.Amount) || reversal.Currency != "IDR" {
+						t.Errorf("ReverseDeposit() got amount %s %s, want 1000 IDR", reversal.Amount, reversal.Currency)
+					}
+					return mockReversal, nil
+				})
+			},
+		},
+		{
+			name: "Success replay",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockReversal, nil)
+			},
+		},
+		{
+			name: "Failed replay of other withdrawal",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrReferenceIdUsed,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockReversalOther, nil)
+			},
+		},
+		{
+			name: "Failed id not uuid",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          "not-a-uuid",
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrDepositNotFound,
+			patch:     func() {},
+		},
+		{
+			name: "Failed deposit not found",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrDepositNotFound,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed invalid customer",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: "not-a-customer",
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrDepositNotFound,
+			patch:     func() {},
+		},
+		{
+			name: "Failed deposit is a refund",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrDepositNotReversible,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(&mockDepositRefund, nil)
+			},
+		},
+		{
+			name: "Failed available balance held",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrBalanceNotEnough,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&mockWalletHeld, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed deposit already reversed",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrDepositAlreadyReversed,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(mockDeposit, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockRefundDB.EXPECT().ReverseDeposit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, commonerr.ErrDepositAlreadyReversed)
+			},
+		},
+		{
+			name: "Failed reverse deposit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				id:          mockDeposit.ID,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockDepositDB.EXPECT().GetDepositById(gomock.Any(), mockCustomerXid, mockDeposit.ID).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewRefundUsecase(mockRefundDB, mockDepositDB, mockWithdrawalDB, mockWalletDB, nil)
+			got, err := uc.ReverseDeposit(tt.args.ctx, tt.args.customerXid, tt.args.id, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.ReverseDeposit() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.ReverseDeposit() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.ReverseDeposit() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
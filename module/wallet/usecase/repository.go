@@ -2,27 +2,102 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/shopspring/decimal"
 )
 
 type AccountRepository interface {
-	ExistAccountByCustomerXid(ctx context.Context, customerXid string) (bool, error)
+	GetAccountByCustomerXid(ctx context.Context, customerXid string) (*model.Account, error)
 	CreateAccount(ctx context.Context, account *model.Account) error
+	GetAnonymizableAccounts(ctx context.Context, status int, closedBefore time.Time, limit int) ([]string, error)
+	AnonymizeAccount(ctx context.Context, customerXid string, anonymizedAt time.Time) (bool, error)
 }
 
 type DepositRepository interface {
-	GetDepositByReferenceId(ctx context.Context, referenceId string) (*model.Deposit, error)
+	GetDepositByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Deposit, error)
+	GetDepositsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Deposit, error)
 	CreateDeposit(ctx context.Context, deposit *model.Deposit, wallet *model.Wallet) (*model.Deposit, error)
+	GetDepositById(ctx context.Context, customerXid, id string) (*model.Deposit, error)
 }
 
 type WalletRepository interface {
 	CreateWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error)
-	UpdateStatusWallet(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error)
-	GetWalletByCustomerXid(ctx context.Context, customerXid string) (*model.Wallet, error)
+	UpdateStatusWallet(ctx context.Context, wallet *model.Wallet, from int, reason, event string) (*model.Wallet, error)
+	GetWalletByCustomerXid(ctx context.Context, customerXid, currency string) (*model.Wallet, error)
+	GetWalletsByCustomerXid(ctx context.Context, customerXid string) ([]*model.Wallet, error)
+	GetWalletById(ctx context.Context, id string) (*model.Wallet, error)
+	GetWalletStatusHistory(ctx context.Context, walletID string) ([]*model.WalletStatusChange, error)
+	CloseWallet(ctx context.Context, wallet *model.Wallet, from int, payout *model.Withdrawal) (*model.Wallet, error)
 }
 
 type WithdrawalRepository interface {
-	GetWithdrawalByReferenceId(ctx context.Context, referenceId string) (*model.Withdrawal, error)
+	GetWithdrawalByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Withdrawal, error)
+	GetWithdrawalsByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Withdrawal, error)
 	CreateWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error)
+	GetWithdrawalById(ctx context.Context, customerXid, id string) (*model.Withdrawal, error)
+	GetExpiredWithdrawals(ctx context.Context, status int, now time.Time, limit int) ([]*model.Withdrawal, error)
+	CreatePendingWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error)
+	CaptureWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error)
+	ReleaseWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, wallet *model.Wallet, from int) (*model.Withdrawal, error)
+}
+
+type TransferRepository interface {
+	GetTransferByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Transfer, error)
+	GetTransfersByCustomerXid(ctx context.Context, customerXid string, filter model.TransactionFilter) ([]*model.Transfer, error)
+	CreateTransfer(ctx context.Context, transfer *model.Transfer, sender *model.Wallet, recipient *model.Wallet) (*model.Transfer, error)
+}
+
+type ConversionRepository interface {
+	CreateQuote(ctx context.Context, quote *model.ConversionQuote) (*model.ConversionQuote, error)
+	GetQuote(ctx context.Context, customerXid, quoteId string) (*model.ConversionQuote, error)
+	GetConversionByReferenceId(ctx context.Context, customerXid, referenceId string) (*model.Conversion, error)
+	CreateConversion(ctx context.Context, conversion *model.Conversion, source *model.Wallet, target *model.Wallet) (*model.Conversion, error)
+}
+
+type RefundRepository interface {
+	RefundWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, refund *model.Deposit, wallet *model.Wallet) (*model.Deposit, error)
+	ReverseDeposit(ctx context.Context, deposit *model.Deposit, reversal *model.Withdrawal, wallet *model.Wallet) (*model.Withdrawal, error)
+}
+
+type AdjustmentRepository interface {
+	CreateAdjustment(ctx context.Context, adjustment *model.Adjustment, wallet *model.Wallet) (*model.Adjustment, error)
+}
+
+type AuditRepository interface {
+	RecordAuditEvent(ctx context.Context, event *model.AuditEvent) error
+	GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]*model.AuditEvent, error)
+	GetAuditEventsAfter(ctx context.Context, chain string, chainSeq int64, limit int) ([]*model.AuditEvent, error)
+}
+
+type LimitRepository interface {
+	GetWalletLimits(ctx context.Context, walletId string) (*limits.Override, error)
+	GetWithdrawnAmount(ctx context.Context, customerXid, currency string, statuses []int, since time.Time) (decimal.Decimal, error)
+	CountTransactions(ctx context.Context, customerXid, currency string, since time.Time) (int, error)
+}
+
+type WebhookRepository interface {
+	CreateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error)
+	GetWebhookEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*model.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error)
+}
+
+type TokenRepository interface {
+	RevokeToken(ctx context.Context, jti, customerXid string, expiresAt time.Time) (bool, error)
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeRevokedTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+type ReconciliationRepository interface {
+	GetWalletReconciliations(ctx context.Context, status int, afterID string, limit int) ([]*model.WalletReconciliation, error)
+	CreateBalanceDiscrepancies(ctx context.Context, discrepancies []*model.BalanceDiscrepancy) error
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (uint64, bool, error)
 }
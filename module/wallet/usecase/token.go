@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+type TokenUsecase struct {
+	repo   TokenRepository
+	tokens TokenIssuer
+}
+
+func NewTokenUsecase(repo TokenRepository, tokens TokenIssuer) *TokenUsecase {
+	return &TokenUsecase{
+		repo:   repo,
+		tokens: tokens,
+	}
+}
+
+// Refresh revokes the token presented and issues a new one for the customer.
+// The new token is issued only by the request that revoked the old one, so
+// concurrent refreshes of the same token get a single new token.
+func (h *TokenUsecase) Refresh(ctx context.Context, claims *model.Claims) (string, error) {
+	revoked, err := h.repo.RevokeToken(ctx, claims.ID, claims.CustomerXid, claims.ExpiresAt.Time)
+	if err != nil {
+		return "", err
+	}
+	if !revoked {
+		return "", commonerr.ErrTokenRevoked
+	}
+
+	return h.tokens.Issue(claims.CustomerXid)
+}
+
+func (h *TokenUsecase) Logout(ctx context.Context, claims *model.Claims) error {
+	_, err := h.repo.RevokeToken(ctx, claims.ID, claims.CustomerXid, claims.ExpiresAt.Time)
+	return err
+}
+
+// PurgeExpiredRevocations deletes the revocations of the tokens that have
+// expired since; an expired token is rejected without them.
+func (h *TokenUsecase) PurgeExpiredRevocations(ctx context.Context) (int64, error) {
+	return h.repo.PurgeRevokedTokens(ctx, time.Now())
+}
@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/token"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsecaseToken_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTokenDB := mockDB.NewMockTokenRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockKey, _ := token.NewHMACKey(token.DefaultKeyID, []byte("secret"))
+	mockTokens, _ := token.NewService(token.Config{
+		Keys:        []token.Key{mockKey},
+		ActiveKeyID: token.DefaultKeyID,
+	})
+	mockClaims := &model.Claims{
+		CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "4f1c2a9b8e7d6c5b4a3f2e1d0c9b8a7f",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	type args struct {
+		ctx    context.Context
+		claims *model.Claims
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		err     error
+		patch   func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				ctx:    mockCtx,
+				claims: mockClaims,
+			},
+			wantErr: false,
+			patch: func() {
+				mockTokenDB.EXPECT().RevokeToken(gomock.Any(), mockClaims.ID, mockClaims.CustomerXid, mockClaims.ExpiresAt.Time).Return(true, nil)
+			},
+		},
+		{
+			name: "Failed on RevokeToken cause already revoked",
+			args: args{
+				ctx:    mockCtx,
+				claims: mockClaims,
+			},
+			wantErr: true,
+			err:     commonerr.ErrTokenRevoked,
+			patch: func() {
+				mockTokenDB.EXPECT().RevokeToken(gomock.Any(), mockClaims.ID, mockClaims.CustomerXid, mockClaims.ExpiresAt.Time).Return(false, nil)
+			},
+		},
+		{
+			name: "Failed on RevokeToken cause databaseErr",
+			args: args{
+				ctx:    mockCtx,
+				claims: mockClaims,
+			},
+			wantErr: true,
+			err:     mockError,
+			patch: func() {
+				mockTokenDB.EXPECT().RevokeToken(gomock.Any(), mockClaims.ID, mockClaims.CustomerXid, mockClaims.ExpiresAt.Time).Return(false, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewTokenUsecase(mockTokenDB, mockTokens)
+			tokenString, err := uc.Refresh(tt.args.ctx, tt.args.claims)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.Refresh() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, tokenString)
+				return
+			}
+
+			claims, err := mockTokens.Parse(tokenString)
+			assert.Nil(t, err)
+			assert.Equal(t, mockClaims.CustomerXid, claims.CustomerXid)
+			assert.NotEqual(t, mockClaims.ID, claims.ID)
+		})
+	}
+}
+
+func TestUsecaseToken_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTokenDB := mockDB.NewMockTokenRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockClaims := &model.Claims{
+		CustomerXid: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "4f1c2a9b8e7d6c5b4a3f2e1d0c9b8a7f",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	type args struct {
+		ctx    context.Context
+		claims *model.Claims
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		patch   func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				ctx:    mockCtx,
+				claims: mockClaims,
+			},
+			wantErr: false,
+			patch: func() {
+				mockTokenDB.EXPECT().RevokeToken(gomock.Any(), mockClaims.ID, mockClaims.CustomerXid, mockClaims.ExpiresAt.Time).Return(false, nil)
+			},
+		},
+		{
+			name: "Failed on RevokeToken cause databaseErr",
+			args: args{
+				ctx:    mockCtx,
+				claims: mockClaims,
+			},
+			wantErr: true,
+			patch: func() {
+				mockTokenDB.EXPECT().RevokeToken(gomock.Any(), mockClaims.ID, mockClaims.CustomerXid, mockClaims.ExpiresAt.Time).Return(false, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewTokenUsecase(mockTokenDB, nil)
+			err := uc.Logout(tt.args.ctx, tt.args.claims)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.Logout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func TestUsecaseToken_PurgeExpiredRevocations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTokenDB := mockDB.NewMockTokenRepository(ctrl)
+	defer ctrl.Finish()
+
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+		patch   func()
+	}{
+		{
+			name:    "Success",
+			want:    3,
+			wantErr: false,
+			patch: func() {
+				mockTokenDB.EXPECT().PurgeRevokedTokens(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+			},
+		},
+		{
+			name:    "Failed on PurgeRevokedTokens cause databaseErr",
+			want:    0,
+			wantErr: true,
+			patch: func() {
+				mockTokenDB.EXPECT().PurgeRevokedTokens(gomock.Any(), gomock.Any()).Return(int64(0), mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewTokenUsecase(mockTokenDB, nil)
+			purged, err := uc.PurgeExpiredRevocations(mockCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.PurgeExpiredRevocations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, purged)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+)
+
+type TransactionUsecase struct {
+	depositRepo    DepositRepository
+	withdrawalRepo WithdrawalRepository
+	transferRepo   TransferRepository
+	walletRepo     WalletRepository
+}
+
+const (
+	DefaultTransactionLimit = 10
+	MaxTransactionLimit     = 100
+)
+
+func NewTransactionUsecase(depositRepo DepositRepository, withdrawalRepo WithdrawalRepository, transferRepo TransferRepository, walletRepo WalletRepository) *TransactionUsecase {
+	return &TransactionUsecase{
+		depositRepo:    depositRepo,
+		withdrawalRepo: withdrawalRepo,
+		transferRepo:   transferRepo,
+		walletRepo:     walletRepo,
+	}
+}
+
+func (h *TransactionUsecase) GetTransactions(ctx context.Context, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error) {
+	wallets, err := h.walletRepo.GetWalletsByCustomerXid(ctx, customerXid)
+	if err != nil {
+		return nil, err
+	}
+
+	// The history is open while the customer has an enabled or frozen wallet
+	// in the currency filtered on, or in any currency when there is no filter.
+	var found, enabled bool
+	for _, wallet := range wallets {
+		if filter.Currency != "" && wallet.Currency != filter.Currency {
+			continue
+		}
+		found = true
+		if wallet.Status == EnabledStatus || wallet.Status == FrozenStatus {
+			enabled = true
+		}
+	}
+
+	if !found {
+		return nil, commonerr.ErrWalletNotFound
+	}
+	if !enabled {
+		return nil, commonerr.ErrWalletDisabled
+	}
+
+	return h.listTransactions(ctx, customerXid, filter)
+}
+
+// listTransactions returns a page of the transactions of the customer
+// matching filter, newest first, whatever the status of their wallets.
+func (h *TransactionUsecase) listTransactions(ctx context.Context, customerXid string, filter model.TransactionFilter) (*model.TransactionList, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultTransactionLimit
+	}
+	if filter.Limit > MaxTransactionLimit {
+		filter.Limit = MaxTransactionLimit
+	}
+
+	// Each source is asked for one row more than the page size, so after merging
+	// we know whether another page exists without a separate count query.
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	transactions := []*model.Transaction{}
+	if filter.Type == "" || filter.Type == model.TransactionTypeDeposit {
+		deposits, err := h.depositRepo.GetDepositsByCustomerXid(ctx, customerXid, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deposit := range deposits {
+			transactions = append(transactions, &model.Transaction{
+				ID:           deposit.ID,
+				Type:         model.TransactionTypeDeposit,
+				Status:       transactionStatusMessage(deposit.Status),
+				TransactedAt: deposit.DepositedAt,
+				Amount:       deposit.Amount,
+				ReferenceID:  deposit.ReferenceID,
+				Currency:     deposit.Currency,
+			})
+		}
+	}
+
+	if filter.Type == "" || filter.Type == model.TransactionTypeWithdrawal {
+		withdrawals, err := h.withdrawalRepo.GetWithdrawalsByCustomerXid(ctx, customerXid, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, withdrawal := range withdrawals {
+			transactions = append(transactions, &model.Transaction{
+				ID:           withdrawal.ID,
+				Type:         model.TransactionTypeWithdrawal,
+				Status:       transactionStatusMessage(withdrawal.Status),
+				TransactedAt: withdrawal.WithdrawnAt,
+				Amount:       withdrawal.Amount,
+				ReferenceID:  withdrawal.ReferenceID,
+				Currency:     withdrawal.Currency,
+			})
+		}
+	}
+
+	if filter.Type == "" || filter.Type == model.TransactionTypeTransferIn || filter.Type == model.TransactionTypeTransferOut {
+		transfers, err := h.transferRepo.GetTransfersByCustomerXid(ctx, customerXid, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, transfer := range transfers {
+			transactionType := model.TransactionTypeTransferIn
+			if transfer.TransferredBy == customerXid {
+				transactionType = model.TransactionTypeTransferOut
+			}
+
+			transactions = append(transactions, &model.Transaction{
+				ID:           transfer.ID,
+				Type:         transactionType,
+				Status:       transactionStatusMessage(transfer.Status),
+				TransactedAt: transfer.TransferredAt,
+				Amount:       transfer.Amount,
+				ReferenceID:  transfer.ReferenceID,
+				Currency:     transfer.Currency,
+			})
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].TransactedAt.Equal(transactions[j].TransactedAt) {
+			return transactions[i].ID > transactions[j].ID
+		}
+		return transactions[i].TransactedAt.After(transactions[j].TransactedAt)
+	})
+
+	result := &model.TransactionList{
+		Transactions: transactions,
+	}
+	if len(transactions) > limit {
+		result.Transactions = transactions[:limit]
+		last := result.Transactions[limit-1]
+		result.NextCursor = model.TransactionCursor{
+			TransactedAt: last.TransactedAt,
+			ID:           last.ID,
+		}.Encode()
+	}
+
+	return result, nil
+}
+
+func transactionStatusMessage(status int) string {
+	switch status {
+	case SuccessStatus:
+		return "success"
+	case PendingStatus:
+		return "pending"
+	case VoidedStatus:
+		return "voided"
+	case ExpiredStatus:
+		return "expired"
+	}
+	return "failed"
+}
@@ -0,0 +1,249 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsecaseTransaction_GetTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDepositDB := mockDB.NewMockDepositRepository(ctrl)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockTransferDB := mockDB.NewMockTransferRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockWalletDisable := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    2,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockDeposit := &model.Deposit{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		DepositedBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		DepositedAt: now.Add(-2 * time.Minute),
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+	}
+	mockWithdrawal := &model.Withdrawal{
+		ID:          "4b01c9bb-3acd-47dc-87db-d9ac483d20b2",
+		WithdrawnBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		WithdrawnAt: now.Add(-time.Minute),
+		Amount:      decimal.NewFromInt(500),
+		ReferenceID: "4b01c9bb-3acd-47dc-87db-d9ac483d20b2",
+	}
+	mockTransfer := &model.Transfer{
+		ID:            "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+		TransferredBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		TransferredTo: "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11",
+		Status:        1,
+		TransferredAt: now.Add(-3 * time.Minute),
+		Amount:        decimal.NewFromInt(200),
+		ReferenceID:   "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+	}
+	mockCustomerXid := mockWallet.OwnedBy
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		filter      model.TransactionFilter
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantErr    bool
+		wantIDs    []string
+		wantCursor bool
+		patch      func()
+	}{
+		{
+			name: "Success newest first",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: false,
+			wantIDs: []string{mockWithdrawal.ID, mockDeposit.ID, mockTransfer.ID},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: usecase.DefaultTransactionLimit + 1}).Return([]*model.Deposit{mockDeposit}, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalsByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: usecase.DefaultTransactionLimit + 1}).Return([]*model.Withdrawal{mockWithdrawal}, nil)
+				mockTransferDB.EXPECT().GetTransfersByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: usecase.DefaultTransactionLimit + 1}).Return([]*model.Transfer{mockTransfer}, nil)
+			},
+		},
+		{
+			name: "Success with next cursor",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				filter:      model.TransactionFilter{Limit: 1},
+			},
+			wantErr:    false,
+			wantIDs:    []string{mockWithdrawal.ID},
+			wantCursor: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: 2}).Return([]*model.Deposit{mockDeposit}, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalsByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: 2}).Return([]*model.Withdrawal{mockWithdrawal}, nil)
+				mockTransferDB.EXPECT().GetTransfersByCustomerXid(gomock.Any(), mockCustomerXid, model.TransactionFilter{Limit: 2}).Return([]*model.Transfer{mockTransfer}, nil)
+			},
+		},
+		{
+			name: "Success filter by type",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				filter:      model.TransactionFilter{Type: model.TransactionTypeDeposit},
+			},
+			wantErr: false,
+			wantIDs: []string{mockDeposit.ID},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Deposit{mockDeposit}, nil)
+			},
+		},
+		{
+			name: "Success filter by transfer direction",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				filter:      model.TransactionFilter{Type: model.TransactionTypeTransferOut},
+			},
+			wantErr: false,
+			wantIDs: []string{mockTransfer.ID},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockTransferDB.EXPECT().GetTransfersByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Transfer{mockTransfer}, nil)
+			},
+		},
+		{
+			name: "Failed on GetWalletsByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{}, nil)
+			},
+		},
+		{
+			name: "Failed wallet disabled",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWalletDisable}, nil)
+			},
+		},
+		{
+			name: "Failed currency wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				filter:      model.TransactionFilter{Currency: "USD"},
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+			},
+		},
+		{
+			name: "Failed on GetDepositsByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed on GetWithdrawalsByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Deposit{mockDeposit}, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed on GetTransfersByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet}, nil)
+				mockDepositDB.EXPECT().GetDepositsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Deposit{mockDeposit}, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalsByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return([]*model.Withdrawal{mockWithdrawal}, nil)
+				mockTransferDB.EXPECT().GetTransfersByCustomerXid(gomock.Any(), mockCustomerXid, gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewTransactionUsecase(mockDepositDB, mockWithdrawalDB, mockTransferDB, mockWalletDB)
+			result, err := uc.GetTransactions(tt.args.ctx, tt.args.customerXid, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.GetTransactions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ids := []string{}
+			for _, transaction := range result.Transactions {
+				ids = append(ids, transaction.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantCursor, result.NextCursor != "")
+		})
+	}
+}
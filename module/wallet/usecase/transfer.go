@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+)
+
+type TransferUsecase struct {
+	repo       TransferRepository
+	walletRepo WalletRepository
+	limits     *LimitChecker
+}
+
+func NewTransferUsecase(repo TransferRepository, walletRepo WalletRepository, limits *LimitChecker) *TransferUsecase {
+	return &TransferUsecase{
+		repo:       repo,
+		walletRepo: walletRepo,
+		limits:     limits,
+	}
+}
+
+func (h *TransferUsecase) CreateTransfer(ctx context.Context, customerXid string, payload model.PayloadTransfer) (*model.Transfer, error) {
+	if payload.Currency == "" {
+		payload.Currency = model.DefaultCurrency
+	}
+
+	transfer, err := h.createTransfer(ctx, customerXid, payload)
+	observeTransaction(metrics.TransactionTransfer, payload.Currency, payload.Amount, transfer != nil && transfer.Replayed, err)
+
+	return transfer, err
+}
+
+func (h *TransferUsecase) createTransfer(ctx context.Context, customerXid string, payload model.PayloadTransfer) (*model.Transfer, error) {
+	if payload.RecipientCustomerXid == customerXid {
+		return nil, commonerr.ErrTransferToOwnWallet
+	}
+
+	_, err := lookupAmountCurrency(payload.Currency, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, payload.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if sender == nil {
+		return nil, commonerr.ErrWalletNotFound
+	} else {
+		if sender.Status != EnabledStatus {
+			return nil, walletStatusError(sender.Status)
+		}
+
+	}
+
+	recipient, err := h.walletRepo.GetWalletByCustomerXid(ctx, payload.RecipientCustomerXid, payload.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipient == nil {
+		return nil, commonerr.ErrRecipientWalletNotFound
+	} else {
+		if recipient.Status != EnabledStatus {
+			return nil, commonerr.ErrRecipientWalletDisabled
+		}
+	}
+
+	transfer, err := h.repo.GetTransferByReferenceId(ctx, customerXid, payload.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer != nil {
+		return replayTransfer(ctx, transfer, payload)
+	}
+
+	if sender.Available().LessThan(payload.Amount) {
+		return nil, commonerr.ErrBalanceNotEnough
+	}
+
+	err = h.limits.CheckWithdrawal(ctx, sender, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.limits.CheckCredit(ctx, recipient, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &model.Transfer{
+		TransferredBy: customerXid,
+		TransferredTo: payload.RecipientCustomerXid,
+		Status:        SuccessStatus,
+		TransferredAt: time.Now(),
+		Amount:        payload.Amount,
+		ReferenceID:   payload.ReferenceID,
+		Currency:      payload.Currency,
+	}
+
+	transfer, err = h.repo.CreateTransfer(ctx, data, sender, recipient)
+	if err == commonerr.ErrWalletTransition && recipient.Status != EnabledStatus {
+		err = commonerr.ErrRecipientWalletDisabled
+	}
+	err = walletWriteError(ctx, err, sender)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		transfer, err = h.repo.GetTransferByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if transfer == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayTransfer(ctx, transfer, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	transfer.StatusMessage = "success"
+	logger.FromContext(ctx).Info("transfer created", "transfer_id", transfer.ID, "amount", transfer.Amount.String(), "recipient_customer_xid", transfer.TransferredTo)
+
+	return transfer, err
+}
+
+// replayTransfer returns the transfer already made with the payload reference
+// id when the payload matches it.
+func replayTransfer(ctx context.Context, transfer *model.Transfer, payload model.PayloadTransfer) (*model.Transfer, error) {
+	if transfer.TransferredTo != payload.RecipientCustomerXid || !transfer.Amount.Equal(payload.Amount) || transfer.Currency != payload.Currency {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	transfer.StatusMessage = transactionStatusMessage(transfer.Status)
+	transfer.Replayed = true
+
+	return transfer, nil
+}
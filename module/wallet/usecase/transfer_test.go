@@ -0,0 +1,425 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/shopspring/decimal"
+)
+
+func TestUsecaseTransfer_CreateTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTransferDB := mockDB.NewMockTransferRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockWalletDisable := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    2,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+	}
+	mockWalletZero := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(0),
+	}
+	mockRecipient := &model.Wallet{
+		ID:        "f3c2b1a0-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		OwnedBy:   "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(500),
+		Currency:  "IDR",
+	}
+	mockRecipientDisable := &model.Wallet{
+		ID:        "f3c2b1a0-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		OwnedBy:   "6d1f3c52-5a3c-4d6e-bf8a-2a7c7b3b8f11",
+		Status:    2,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(500),
+	}
+	mockTransfer := &model.Transfer{
+		ID:            "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+		TransferredBy: mockWallet.OwnedBy,
+		TransferredTo: mockRecipient.OwnedBy,
+		Status:        1,
+		TransferredAt: now,
+		Amount:        decimal.NewFromInt(1000),
+		ReferenceID:   "0e8b9f3a-8e23-4c36-9d1c-5c0f1f1b2d40",
+		Currency:      "IDR",
+	}
+	mockCustomerXid := mockTransfer.TransferredBy
+	mockTransferOther := *mockTransfer
+	mockTransferOther.Amount = decimal.NewFromInt(2000)
+	mockPayload := model.PayloadTransfer{
+		RecipientCustomerXid: mockTransfer.TransferredTo,
+		ReferenceID:          mockTransfer.ReferenceID,
+		Amount:               mockTransfer.Amount,
+	}
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		payload     model.PayloadTransfer
+	}
+	tests := []struct {
+		name         string
+		args         args
+		limits       limits.Defaults
+		wantErr      bool
+		wantReplayed bool
+		resultErr    error
+		patch        func()
+	}{
+		{
+			name: "Success",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: false,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), mockWallet, mockRecipient).Return(mockTransfer, nil)
+			},
+		},
+		{
+			name: "Failed transfer to own wallet",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload: model.PayloadTransfer{
+					RecipientCustomerXid: mockCustomerXid,
+					ReferenceID:          mockTransfer.ReferenceID,
+					Amount:               mockTransfer.Amount,
+				},
+			},
+			wantErr: true,
+			patch:   func() {},
+		},
+		{
+			name: "Failed on GetWalletByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed wallet disabled",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletDisable, nil)
+			},
+		},
+		{
+			name: "Failed wallet less than balance",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletZero, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed on recipient GetWalletByCustomerXid error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed recipient wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed recipient wallet disabled",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipientDisable, nil)
+			},
+		},
+		{
+			name: "Failed on GetTransferByReferenceId error",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Success replay on GetTransferByReferenceId",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockTransfer, nil)
+			},
+		},
+		{
+			name: "Failed on GetTransferByReferenceId with different payload",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockTransferOther, nil)
+			},
+		},
+		{
+			name: "Success replay on CreateTransfer reference id used",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), mockWallet, mockRecipient).Return(nil, commonerr.ErrReferenceIdUsed)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockTransfer, nil)
+			},
+		},
+		{
+			name: "Failed on CreateTransfer cause databaseErr",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), mockWallet, mockRecipient).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed max withdrawal limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {MaxWithdrawal: decimal.NewFromInt(500)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed hourly transactions limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {HourlyTransactions: 5}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockLimitDB.EXPECT().CountTransactions(gomock.Any(), mockCustomerXid, "IDR", gomock.Any()).Return(5, nil)
+			},
+		},
+		{
+			name: "Failed recipient max balance limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {MaxBalance: decimal.NewFromInt(1000)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(mockRecipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed on CreateTransfer recipient max balance meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {MaxBalance: decimal.NewFromInt(2000)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				sender, recipient := *mockWallet, *mockRecipient
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&sender, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(&recipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), &sender, &recipient).DoAndReturn(
+					func(ctx context.Context, transfer *model.Transfer, sender, recipient *model.Wallet) (*model.Transfer, error) {
+						// A concurrent credit took the recipient close to its cap.
+						if !recipient.MaxBalance.Equal(decimal.NewFromInt(2000)) {
+							t.Errorf("recipient.MaxBalance = %s, want 2000", recipient.MaxBalance)
+						}
+						return nil, &limits.ExceededError{Limit: limits.MaxBalance, Remaining: decimal.NewFromInt(200)}
+					})
+			},
+		},
+		{
+			name: "Failed on CreateTransfer sender frozen meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletFrozen,
+			patch: func() {
+				sender, recipient := *mockWallet, *mockRecipient
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&sender, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(&recipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), &sender, &recipient).DoAndReturn(
+					func(ctx context.Context, transfer *model.Transfer, sender, recipient *model.Wallet) (*model.Transfer, error) {
+						sender.Status = usecase.FrozenStatus
+						return nil, commonerr.ErrWalletTransition
+					})
+			},
+		},
+		{
+			name: "Failed on CreateTransfer recipient disabled meanwhile",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrRecipientWalletDisabled,
+			patch: func() {
+				sender, recipient := *mockWallet, *mockRecipient
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&sender, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockPayload.RecipientCustomerXid, "IDR").Return(&recipient, nil)
+				mockTransferDB.EXPECT().GetTransferByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockTransferDB.EXPECT().CreateTransfer(gomock.Any(), gomock.Any(), &sender, &recipient).DoAndReturn(
+					func(ctx context.Context, transfer *model.Transfer, sender, recipient *model.Wallet) (*model.Transfer, error) {
+						recipient.Status = usecase.DisabledStatus
+						return nil, commonerr.ErrWalletTransition
+					})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewTransferUsecase(mockTransferDB, mockWalletDB, usecase.NewLimitChecker(mockLimitDB, tt.limits))
+			got, err := uc.CreateTransfer(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.CreateTransfer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateTransfer() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.CreateTransfer() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
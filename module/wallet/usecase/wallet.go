@@ -2,41 +2,81 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 )
 
 type WalletUsecase struct {
 	repo WalletRepository
 }
 
+// A wallet is pending until it is first enabled. Only an enabled wallet moves
+// money; a frozen one is held by staff, and a closed one is gone for good.
 const (
-	EnabledStatus  = 1
-	DisabledStatus = 2
+	PendingWalletStatus = 0
+	EnabledStatus       = 1
+	DisabledStatus      = 2
+	FrozenStatus        = 3
+	ClosedStatus        = 4
 )
 
+// walletTransitions lists the statuses a wallet may move to from each status.
+// A status missing from it is final.
+var walletTransitions = map[int][]int{
+	PendingWalletStatus: {EnabledStatus, ClosedStatus},
+	EnabledStatus:       {DisabledStatus, FrozenStatus, ClosedStatus},
+	DisabledStatus:      {EnabledStatus, FrozenStatus, ClosedStatus},
+	FrozenStatus:        {EnabledStatus, DisabledStatus, ClosedStatus},
+}
+
+// walletStatusEvents names the event of a wallet moving to each status.
+var walletStatusEvents = map[int]string{
+	EnabledStatus:  webhook.EventWalletEnabled,
+	DisabledStatus: webhook.EventWalletDisabled,
+	FrozenStatus:   webhook.EventWalletFrozen,
+	ClosedStatus:   webhook.EventWalletClosed,
+}
+
+var walletStatusMessages = map[int]string{
+	PendingWalletStatus: "pending",
+	EnabledStatus:       "enabled",
+	DisabledStatus:      "disabled",
+	FrozenStatus:        "frozen",
+	ClosedStatus:        "closed",
+}
+
 func NewWalletUsecase(repo WalletRepository) *WalletUsecase {
 	return &WalletUsecase{
 		repo: repo,
 	}
 }
 
-func (h *WalletUsecase) Enabled(ctx context.Context, customerXid string) (*model.Wallet, error) {
-	wallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid)
+func (h *WalletUsecase) Enabled(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
+	c, err := lookupCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid, c.Code)
 	if err != nil {
 		return nil, err
 	}
 
 	if wallet != nil {
 		if wallet.Status == EnabledStatus {
-			return wallet, errors.New("Already enabled")
+			return wallet, commonerr.ErrWalletAlreadyEnabled
 		}
 
-		wallet.Status = EnabledStatus
+		// Only staff lift a freeze.
+		if wallet.Status == FrozenStatus {
+			return nil, commonerr.ErrWalletFrozen
+		}
 
-		wallet, err = h.repo.UpdateStatusWallet(ctx, wallet)
+		wallet, err = transitionWallet(ctx, h.repo, wallet, EnabledStatus, "")
 		if err != nil {
 			return nil, err
 		}
@@ -45,6 +85,7 @@ func (h *WalletUsecase) Enabled(ctx context.Context, customerXid string) (*model
 			OwnedBy:   customerXid,
 			Status:    EnabledStatus,
 			EnabledAt: time.Now(),
+			Currency:  c.Code,
 		}
 
 		wallet, err = h.repo.CreateWallet(ctx, payload)
@@ -53,52 +94,226 @@ func (h *WalletUsecase) Enabled(ctx context.Context, customerXid string) (*model
 		}
 	}
 
-	wallet.StatusMessage = "enabled"
+	wallet.StatusMessage = walletStatusMessage(wallet.Status)
 
 	return wallet, err
 }
 
-func (h *WalletUsecase) Disable(ctx context.Context, customerXid string) (*model.Wallet, error) {
-	wallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid)
+func (h *WalletUsecase) Disable(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
+	c, err := lookupCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid, c.Code)
 	if err != nil {
 		return nil, err
 	}
 
 	if wallet == nil {
-		return nil, errors.New("Wallet not enable")
+		return nil, commonerr.ErrWalletNotFound
 	} else {
 		if wallet.Status == DisabledStatus {
-			return nil, errors.New("Wallet already disabled")
+			return nil, commonerr.ErrWalletAlreadyDisabled
 		}
 	}
 
-	wallet.Status = DisabledStatus
+	// Only staff lift a freeze, even into the disabled status.
+	if wallet.Status == FrozenStatus {
+		return nil, commonerr.ErrWalletFrozen
+	}
+
+	return transitionWallet(ctx, h.repo, wallet, DisabledStatus, "")
+}
+
+// Close closes the wallet of the customer in currency for good. A wallet with
+// a balance is paid out in full to the destination of the payload first; one
+// with pending withdrawals must see them settled before. Transaction limits do
+// not apply to the payout, so that a customer can always leave.
+func (h *WalletUsecase) Close(ctx context.Context, customerXid string, payload model.PayloadClose) (*model.WalletClosure, error) {
+	c, err := lookupCurrency(payload.Currency)
+	if err != nil {
+		return nil, err
+	}
 
-	wallet, err = h.repo.UpdateStatusWallet(ctx, wallet)
+	wallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid, c.Code)
 	if err != nil {
 		return nil, err
 	}
 
-	wallet.StatusMessage = "disabled"
+	if wallet == nil {
+		return nil, commonerr.ErrWalletNotFound
+	}
 
-	return wallet, err
+	// Only staff lift a freeze, or close a frozen wallet.
+	if wallet.Status == FrozenStatus {
+		return nil, commonerr.ErrWalletFrozen
+	}
+
+	from := wallet.Status
+	if !canTransitionWallet(from, ClosedStatus) {
+		return nil, commonerr.ErrWalletClosed
+	}
+
+	if wallet.HeldBalance.IsPositive() {
+		return nil, commonerr.ErrWalletHoldsPending
+	}
+
+	now := time.Now()
+	var payout *model.Withdrawal
+	if wallet.Balance.IsPositive() {
+		if payload.Destination == "" {
+			return nil, commonerr.ErrWalletNotEmpty
+		}
+
+		payout = &model.Withdrawal{
+			WithdrawnBy: customerXid,
+			Status:      SuccessStatus,
+			WithdrawnAt: now,
+			Amount:      wallet.Balance,
+			ReferenceID: payload.ReferenceID,
+			Currency:    c.Code,
+			Destination: payload.Destination,
+		}
+	}
+
+	wallet.Status = ClosedStatus
+	wallet.ClosedAt = &now
+
+	wallet, err = h.repo.CloseWallet(ctx, wallet, from, payout)
+	if payout != nil {
+		observeTransaction(metrics.TransactionWithdrawal, c.Code, payout.Amount, false, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.StatusMessage = walletStatusMessage(wallet.Status)
+	if payout != nil {
+		payout.StatusMessage = transactionStatusMessage(payout.Status)
+	}
+
+	return &model.WalletClosure{
+		Wallet: wallet,
+		Payout: payout,
+	}, nil
 }
 
-func (h *WalletUsecase) GetWallet(ctx context.Context, customerXid string) (*model.Wallet, error) {
-	infoWallet, err := h.repo.GetWalletByCustomerXid(ctx, customerXid)
+// GetWallet returns the wallet of the customer in currency, along with the
+// balances of all the wallets of the customer.
+func (h *WalletUsecase) GetWallet(ctx context.Context, customerXid, currency string) (*model.Wallet, error) {
+	c, err := lookupCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := h.repo.GetWalletsByCustomerXid(ctx, customerXid)
 	if err != nil {
 		return nil, err
 	}
 
+	var infoWallet *model.Wallet
+	balances := make([]*model.WalletBalance, 0, len(wallets))
+	for _, wallet := range wallets {
+		if wallet.Currency == c.Code {
+			infoWallet = wallet
+		}
+		wallet.AvailableBalance = wallet.Available()
+		balances = append(balances, &model.WalletBalance{
+			Currency:         wallet.Currency,
+			Balance:          wallet.Balance,
+			HeldBalance:      wallet.HeldBalance,
+			AvailableBalance: wallet.AvailableBalance,
+			Status:           walletStatusMessage(wallet.Status),
+		})
+	}
+
 	if infoWallet == nil {
-		return nil, errors.New("Wallet not enable")
+		return nil, commonerr.ErrWalletNotFound
 	}
 
-	if infoWallet.Status == DisabledStatus {
-		return nil, errors.New("Disabled")
-	} else {
-		infoWallet.StatusMessage = "enabled"
+	// A frozen wallet can still be looked at, to learn that it is frozen.
+	if infoWallet.Status != EnabledStatus && infoWallet.Status != FrozenStatus {
+		return nil, walletStatusError(infoWallet.Status)
 	}
+	infoWallet.StatusMessage = walletStatusMessage(infoWallet.Status)
+	infoWallet.Balances = balances
 
 	return infoWallet, err
 }
+
+func walletStatusMessage(status int) string {
+	return walletStatusMessages[status]
+}
+
+func canTransitionWallet(from, to int) bool {
+	for _, status := range walletTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// walletStatusError is the error of using a wallet that is not enabled.
+func walletStatusError(status int) error {
+	switch status {
+	case FrozenStatus:
+		return commonerr.ErrWalletFrozen
+	case ClosedStatus:
+		return commonerr.ErrWalletClosed
+	}
+	return commonerr.ErrWalletDisabled
+}
+
+// walletWriteError turns the error of a write refused by the guards of the
+// repository into the error the client gets: the ErrWalletTransition of a
+// write that found one of wallets no longer in the status it was read in
+// becomes the error of the status the wallet moved to, and a credit that would
+// have gone over a balance cap becomes ErrLimitExceeded.
+func walletWriteError(ctx context.Context, err error, wallets ...*model.Wallet) error {
+	if err != commonerr.ErrWalletTransition {
+		return limitExceeded(ctx, err)
+	}
+
+	for _, wallet := range wallets {
+		if wallet.Status != EnabledStatus {
+			return walletStatusError(wallet.Status)
+		}
+	}
+
+	return err
+}
+
+// transitionWallet moves the wallet to the status to when walletTransitions
+// allows it, stamping when it was enabled, disabled or closed, and records the
+// move along with its reason.
+func transitionWallet(ctx context.Context, repo WalletRepository, wallet *model.Wallet, to int, reason string) (*model.Wallet, error) {
+	from := wallet.Status
+	if !canTransitionWallet(from, to) {
+		if from == ClosedStatus {
+			return nil, commonerr.ErrWalletClosed
+		}
+		return nil, commonerr.ErrWalletTransition
+	}
+
+	now := time.Now()
+	switch to {
+	case EnabledStatus:
+		wallet.EnabledAt = now
+	case DisabledStatus:
+		wallet.DisabledAt = &now
+	case ClosedStatus:
+		wallet.ClosedAt = &now
+	}
+	wallet.Status = to
+
+	wallet, err := repo.UpdateStatusWallet(ctx, wallet, from, reason, walletStatusEvents[to])
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.StatusMessage = walletStatusMessage(wallet.Status)
+
+	return wallet, nil
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
 	"github.com/herwando/mini-wallet/module/wallet/usecase"
 	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
 	"github.com/shopspring/decimal"
 )
 
@@ -26,6 +28,7 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 		Status:    1,
 		EnabledAt: now,
 		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
 	}
 	mockWalletDisable := &model.Wallet{
 		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
@@ -33,18 +36,33 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 		Status:    2,
 		EnabledAt: now,
 		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockWalletUSD := &model.Wallet{
+		ID:          "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.RequireFromString("12.5"),
+		HeldBalance: decimal.RequireFromString("2.5"),
+		Currency:    "USD",
 	}
 	mockCustomerXid := mockWallet.OwnedBy
 
 	type args struct {
 		ctx         context.Context
 		customerXid string
+		currency    string
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		patch   func()
+		name          string
+		args          args
+		wantErr       bool
+		resultErr     error
+		wantCurrency  string
+		wantBalances  int
+		wantAvailable decimal.Decimal
+		patch         func()
 	}{
 		{
 			name: "Success",
@@ -52,20 +70,49 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
 			},
-			wantErr: false,
+			wantErr:       false,
+			wantCurrency:  "IDR",
+			wantBalances:  2,
+			wantAvailable: decimal.NewFromInt(10000),
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet, mockWalletUSD}, nil)
 			},
 		},
 		{
-			name: "Failed on GetWalletByCustomerXid",
+			name: "Success currency",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				currency:    "USD",
+			},
+			wantErr:       false,
+			wantCurrency:  "USD",
+			wantBalances:  2,
+			wantAvailable: decimal.NewFromInt(10),
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet, mockWalletUSD}, nil)
+			},
+		},
+		{
+			name: "Failed currency not supported",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				currency:    "ABC",
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrCurrencyNotSupported,
+			patch:     func() {},
+		},
+		{
+			name: "Failed on GetWalletsByCustomerXid",
 			args: args{
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
 			},
 		},
 		{
@@ -74,9 +121,23 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
 			},
-			wantErr: true,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{}, nil)
+			},
+		},
+		{
+			name: "Failed currency wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				currency:    "EUR",
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWallet, mockWalletUSD}, nil)
 			},
 		},
 		{
@@ -85,9 +146,40 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
 			},
-			wantErr: true,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletDisabled,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{mockWalletDisable}, nil)
+			},
+		},
+		{
+			name: "Success wallet frozen",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr:       false,
+			wantCurrency:  "IDR",
+			wantBalances:  1,
+			wantAvailable: decimal.NewFromInt(10000),
+			patch: func() {
+				wallet := *mockWallet
+				wallet.Status = usecase.FrozenStatus
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{&wallet}, nil)
+			},
+		},
+		{
+			name: "Failed wallet closed",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletClosed,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletDisable, nil)
+				wallet := *mockWallet
+				wallet.Status = usecase.ClosedStatus
+				mockWalletDB.EXPECT().GetWalletsByCustomerXid(gomock.Any(), mockCustomerXid).Return([]*model.Wallet{&wallet}, nil)
 			},
 		},
 	}
@@ -95,11 +187,23 @@ func TestUsecaseWallet_GetWallet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
 			uc := usecase.NewWalletUsecase(mockWalletDB)
-			_, err := uc.GetWallet(tt.args.ctx, tt.args.customerXid)
+			wallet, err := uc.GetWallet(tt.args.ctx, tt.args.customerXid, tt.args.currency)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.GetWallet() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.GetWallet() error = %v, resultErr %v", err, tt.resultErr)
+			}
+			if err != nil {
+				return
+			}
+			if wallet.Currency != tt.wantCurrency || len(wallet.Balances) != tt.wantBalances {
+				t.Errorf("Usecase.GetWallet() = %v %d balances, want %v %d balances", wallet.Currency, len(wallet.Balances), tt.wantCurrency, tt.wantBalances)
+			}
+			if !wallet.AvailableBalance.Equal(tt.wantAvailable) {
+				t.Errorf("Usecase.GetWallet() available balance = %v, want %v", wallet.AvailableBalance, tt.wantAvailable)
+			}
 		})
 	}
 }
@@ -130,6 +234,7 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 	type args struct {
 		ctx         context.Context
 		customerXid string
+		currency    string
 	}
 	tests := []struct {
 		name    string
@@ -145,10 +250,38 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
 				mockWalletDB.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(mockWallet, nil)
 			},
 		},
+		{
+			name: "Success with create currency",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				currency:    "USD",
+			},
+			wantErr: false,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "USD").Return(nil, nil)
+				mockWalletDB.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, wallet *model.Wallet) (*model.Wallet, error) {
+					if wallet.Currency != "USD" {
+						t.Errorf("CreateWallet() currency = %v, want USD", wallet.Currency)
+					}
+					return wallet, nil
+				})
+			},
+		},
+		{
+			name: "Failed currency not supported",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				currency:    "ABC",
+			},
+			wantErr: true,
+			patch:   func() {},
+		},
 		{
 			name: "Failed on CreateWallet",
 			args: args{
@@ -157,7 +290,7 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
 				mockWalletDB.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(nil, mockError)
 			},
 		},
@@ -169,8 +302,53 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletDisable, nil)
-				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), gomock.Any()).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletDisable, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), gomock.Any(), usecase.DisabledStatus, "", webhook.EventWalletEnabled).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Success with re-enable",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: false,
+			patch: func() {
+				wallet := *mockWalletDisable
+				wallet.Status = usecase.DisabledStatus
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), gomock.Any(), usecase.DisabledStatus, "", webhook.EventWalletEnabled).DoAndReturn(func(ctx context.Context, wallet *model.Wallet, from int, reason, event string) (*model.Wallet, error) {
+					if !wallet.EnabledAt.After(now) {
+						t.Errorf("UpdateStatusWallet() enabled_at = %v, want after %v", wallet.EnabledAt, now)
+					}
+					return wallet, nil
+				})
+			},
+		},
+		{
+			name: "Failed wallet frozen",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				wallet := *mockWalletDisable
+				wallet.Status = usecase.FrozenStatus
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
+			},
+		},
+		{
+			name: "Failed wallet closed",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				wallet := *mockWalletDisable
+				wallet.Status = usecase.ClosedStatus
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
 			},
 		},
 		{
@@ -181,7 +359,7 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
 			},
 		},
 		{
@@ -192,7 +370,7 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
 			},
 		},
 	}
@@ -200,7 +378,7 @@ func TestUsecaseWallet_Enabled(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
 			uc := usecase.NewWalletUsecase(mockWalletDB)
-			_, err := uc.Enabled(tt.args.ctx, tt.args.customerXid)
+			_, err := uc.Enabled(tt.args.ctx, tt.args.customerXid, tt.args.currency)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.Enabled() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -235,6 +413,7 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 	type args struct {
 		ctx         context.Context
 		customerXid string
+		currency    string
 	}
 	tests := []struct {
 		name    string
@@ -250,8 +429,26 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), gomock.Any()).Return(mockWallet, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWalletDB.EXPECT().UpdateStatusWallet(gomock.Any(), gomock.Any(), usecase.EnabledStatus, "", webhook.EventWalletDisabled).DoAndReturn(func(ctx context.Context, wallet *model.Wallet, from int, reason, event string) (*model.Wallet, error) {
+					if wallet.DisabledAt == nil {
+						t.Errorf("UpdateStatusWallet() disabled_at = nil")
+					}
+					return wallet, nil
+				})
+			},
+		},
+		{
+			name: "Failed wallet frozen",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: true,
+			patch: func() {
+				wallet := *mockWalletDisable
+				wallet.Status = usecase.FrozenStatus
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&wallet, nil)
 			},
 		},
 		{
@@ -262,7 +459,7 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
 			},
 		},
 		{
@@ -273,7 +470,7 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
 			},
 		},
 		{
@@ -284,7 +481,7 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletDisable, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletDisable, nil)
 			},
 		},
 	}
@@ -292,7 +489,7 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
 			uc := usecase.NewWalletUsecase(mockWalletDB)
-			_, err := uc.Disable(tt.args.ctx, tt.args.customerXid)
+			_, err := uc.Disable(tt.args.ctx, tt.args.customerXid, tt.args.currency)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.Disable() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -300,3 +497,191 @@ func TestUsecaseWallet_Disable(t *testing.T) {
 		})
 	}
 }
+
+func TestUsecaseWallet_Close(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:   "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:    1,
+		EnabledAt: now,
+		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
+	}
+	mockCustomerXid := mockWallet.OwnedBy
+	mockPayload := model.PayloadClose{
+		Destination: "BCA 1234567890",
+		ReferenceID: "c4a3e9b2-5d1f-4e6a-9b7c-8d0e1f2a3b4c",
+	}
+	withBalance := func(status int, balance, held int64) *model.Wallet {
+		wallet := *mockWallet
+		wallet.Status = status
+		wallet.Balance = decimal.NewFromInt(balance)
+		wallet.HeldBalance = decimal.NewFromInt(held)
+		return &wallet
+	}
+
+	type args struct {
+		ctx         context.Context
+		customerXid string
+		payload     model.PayloadClose
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantErr    error
+		wantPayout bool
+		patch      func()
+	}{
+		{
+			name: "Success empty wallet",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.DisabledStatus, 0, 0), nil)
+				mockWalletDB.EXPECT().CloseWallet(gomock.Any(), gomock.Any(), usecase.DisabledStatus, nil).DoAndReturn(func(ctx context.Context, wallet *model.Wallet, from int, payout *model.Withdrawal) (*model.Wallet, error) {
+					if wallet.Status != usecase.ClosedStatus || wallet.ClosedAt == nil {
+						t.Errorf("CloseWallet() wallet = %+v, want closed", wallet)
+					}
+					return wallet, nil
+				})
+			},
+		},
+		{
+			name: "Success with payout",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantPayout: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.EnabledStatus, 10000, 0), nil)
+				mockWalletDB.EXPECT().CloseWallet(gomock.Any(), gomock.Any(), usecase.EnabledStatus, gomock.Any()).DoAndReturn(func(ctx context.Context, wallet *model.Wallet, from int, payout *model.Withdrawal) (*model.Wallet, error) {
+					if payout == nil || !payout.Amount.Equal(decimal.NewFromInt(10000)) || payout.Destination != mockPayload.Destination || payout.ReferenceID != mockPayload.ReferenceID {
+						t.Errorf("CloseWallet() payout = %+v, want the whole balance to %s", payout, mockPayload.Destination)
+					}
+					wallet.Balance = decimal.Zero
+					return wallet, nil
+				})
+			},
+		},
+		{
+			name: "Failed on GetWalletByCustomerXid",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: mockError,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
+			},
+		},
+		{
+			name: "Failed wallet empty",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed wallet frozen",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: commonerr.ErrWalletFrozen,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.FrozenStatus, 0, 0), nil)
+			},
+		},
+		{
+			name: "Failed wallet already closed",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: commonerr.ErrWalletClosed,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.ClosedStatus, 0, 0), nil)
+			},
+		},
+		{
+			name: "Failed holds pending",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: commonerr.ErrWalletHoldsPending,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.EnabledStatus, 10000, 500), nil)
+			},
+		},
+		{
+			name: "Failed balance without destination",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+			},
+			wantErr: commonerr.ErrWalletNotEmpty,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.EnabledStatus, 10000, 0), nil)
+			},
+		},
+		{
+			name: "Failed currency not supported",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     model.PayloadClose{Currency: "XXX"},
+			},
+			wantErr: commonerr.ErrCurrencyNotSupported,
+			patch:   func() {},
+		},
+		{
+			name: "Failed on CloseWallet",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr: mockError,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(withBalance(usecase.EnabledStatus, 10000, 0), nil)
+				mockWalletDB.EXPECT().CloseWallet(gomock.Any(), gomock.Any(), usecase.EnabledStatus, gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWalletUsecase(mockWalletDB)
+			got, err := uc.Close(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			if err != tt.wantErr {
+				t.Errorf("Usecase.Close() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Wallet.Status != usecase.ClosedStatus {
+				t.Errorf("Usecase.Close() status = %v, want %v", got.Wallet.Status, usecase.ClosedStatus)
+			}
+			if (got.Payout != nil) != tt.wantPayout {
+				t.Errorf("Usecase.Close() payout = %v, wantPayout %v", got.Payout, tt.wantPayout)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+const (
+	// dispatchBatchSize is how many deliveries DispatchWebhooks claims at a
+	// time.
+	dispatchBatchSize = 50
+
+	// dispatchLeaseMargin is added to the time sending a batch may take to
+	// give the lease on a claimed batch, covering the writes of its outcomes.
+	dispatchLeaseMargin = time.Minute
+)
+
+type WebhookSender interface {
+	Send(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+type WebhookUsecase struct {
+	repo        WebhookRepository
+	sender      WebhookSender
+	maxAttempts int
+	// lease is how long a claimed batch is not claimed again: long enough
+	// for each of its deliveries to take the whole timeout of the sender.
+	lease time.Duration
+}
+
+func NewWebhookUsecase(repo WebhookRepository, sender WebhookSender, timeout time.Duration, maxAttempts int) *WebhookUsecase {
+	if timeout <= 0 {
+		timeout = webhook.DefaultTimeout
+	}
+
+	if maxAttempts <= 0 {
+		maxAttempts = webhook.DefaultMaxAttempts
+	}
+
+	return &WebhookUsecase{
+		repo:        repo,
+		sender:      sender,
+		maxAttempts: maxAttempts,
+		lease:       dispatchBatchSize*timeout + dispatchLeaseMargin,
+	}
+}
+
+// CreateEndpoint registers an https endpoint for the events of the customer.
+// The secret is returned this once.
+func (h *WebhookUsecase) CreateEndpoint(ctx context.Context, customerXid string, payload model.PayloadWebhookEndpoint) (*model.WebhookEndpoint, error) {
+	u, err := url.Parse(payload.URL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, commonerr.ErrWebhookURLInvalid
+	}
+	// A name is checked when the delivery is sent, on the address it
+	// resolves to then.
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !webhook.IsPublicIP(ip) {
+		return nil, commonerr.ErrWebhookURLInvalid
+	}
+
+	secret := payload.Secret
+	if secret == "" {
+		secret, err = newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h.repo.CreateWebhookEndpoint(ctx, &model.WebhookEndpoint{
+		OwnedBy:   customerXid,
+		URL:       u.String(),
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (h *WebhookUsecase) GetEndpoints(ctx context.Context, customerXid string) ([]*model.WebhookEndpoint, error) {
+	return h.repo.GetWebhookEndpoints(ctx, customerXid)
+}
+
+// DeleteEndpoint deletes the endpoint, dropping the deliveries still pending
+// to it.
+func (h *WebhookUsecase) DeleteEndpoint(ctx context.Context, customerXid, id string) (*model.WebhookEndpoint, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, commonerr.ErrWebhookNotFound
+	}
+
+	endpoint, err := h.repo.DeleteWebhookEndpoint(ctx, customerXid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint == nil {
+		return nil, commonerr.ErrWebhookNotFound
+	}
+
+	return endpoint, nil
+}
+
+// DispatchWebhooks sends the deliveries that are due and returns how many
+// were delivered. A delivery that fails is retried after webhook.Backoff, and
+// is dead once it failed maxAttempts times. The outcome of a delivery that was
+// claimed again meanwhile is dropped; the new claim sends it again.
+func (h *WebhookUsecase) DispatchWebhooks(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		now := time.Now()
+		deliveries, err := h.repo.ClaimWebhookDeliveries(ctx, now, now.Add(h.lease), dispatchBatchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, delivery := range deliveries {
+			err = h.sender.Send(ctx, delivery)
+			if ctx.Err() != nil {
+				// Shutting down; the lease runs out and the delivery is
+				// retried without counting this attempt.
+				return delivered, ctx.Err()
+			}
+
+			now = time.Now()
+			if err == nil {
+				delivery.Status = webhook.DeliveryDelivered
+				delivery.LastError = ""
+				delivery.DeliveredAt = &now
+			} else {
+				delivery.Attempts++
+				delivery.LastError = err.Error()
+				delivery.NextAttemptAt = now.Add(webhook.Backoff(delivery.Attempts))
+				if delivery.Attempts >= h.maxAttempts {
+					delivery.Status = webhook.DeliveryDead
+					logger.FromContext(ctx).Warn("webhook delivery dead", "delivery_id", delivery.ID, "event_id", delivery.EventID, "error", err)
+				}
+			}
+
+			updated, err := h.repo.UpdateWebhookDelivery(ctx, delivery)
+			if err != nil {
+				return delivered, err
+			}
+			if !updated {
+				logger.FromContext(ctx).Warn("webhook delivery claimed again", "delivery_id", delivery.ID, "event_id", delivery.EventID)
+				continue
+			}
+			if delivery.Status == webhook.DeliveryDelivered {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < dispatchBatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
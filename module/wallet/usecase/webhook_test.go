@@ -0,0 +1,407 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/usecase"
+	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+)
+
+func TestUsecaseWebhook_CreateEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookDB := mockDB.NewMockWebhookRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockEndpoint := &model.WebhookEndpoint{
+		ID:      "3f1c2b4a-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+		OwnedBy: mockCustomerXid,
+		URL:     "https://merchant.example.com/hooks",
+		Secret:  "0123456789abcdef",
+	}
+
+	tests := []struct {
+		name       string
+		payload    model.PayloadWebhookEndpoint
+		wantErr    bool
+		resultErr  error
+		wantSecret string
+		patch      func()
+	}{
+		{
+			name:       "Success",
+			payload:    model.PayloadWebhookEndpoint{URL: "https://merchant.example.com/hooks", Secret: "0123456789abcdef"},
+			wantErr:    false,
+			wantSecret: "0123456789abcdef",
+			patch: func() {
+				mockWebhookDB.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+					endpoint.ID = mockEndpoint.ID
+					return endpoint, nil
+				})
+			},
+		},
+		{
+			name:    "Success generated secret",
+			payload: model.PayloadWebhookEndpoint{URL: "https://merchant.example.com/hooks"},
+			wantErr: false,
+			patch: func() {
+				mockWebhookDB.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+					if len(endpoint.Secret) != 64 {
+						t.Errorf("generated secret = %q, want 64 hex digits", endpoint.Secret)
+					}
+					return endpoint, nil
+				})
+			},
+		},
+		{
+			name:      "Failed scheme",
+			payload:   model.PayloadWebhookEndpoint{URL: "ftp://merchant.example.com/hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed http",
+			payload:   model.PayloadWebhookEndpoint{URL: "http://merchant.example.com/hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed loopback address",
+			payload:   model.PayloadWebhookEndpoint{URL: "https://127.0.0.1:8080/hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed private address",
+			payload:   model.PayloadWebhookEndpoint{URL: "https://10.0.0.5/hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed link-local address",
+			payload:   model.PayloadWebhookEndpoint{URL: "https://[fe80::1]/hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed no host",
+			payload:   model.PayloadWebhookEndpoint{URL: "https:///hooks"},
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookURLInvalid,
+			patch:     func() {},
+		},
+		{
+			name:    "Failed CreateWebhookEndpoint",
+			payload: model.PayloadWebhookEndpoint{URL: "https://merchant.example.com/hooks"},
+			wantErr: true,
+			patch: func() {
+				mockWebhookDB.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWebhookUsecase(mockWebhookDB, webhook.NewSender(time.Second), time.Second, 0)
+			got, err := uc.CreateEndpoint(mockCtx, mockCustomerXid, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.CreateEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateEndpoint() error = %v, want %v", err, tt.resultErr)
+			}
+			if tt.wantSecret != "" && got.Secret != tt.wantSecret {
+				t.Errorf("Usecase.CreateEndpoint() secret = %v, want %v", got.Secret, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestUsecaseWebhook_DeleteEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookDB := mockDB.NewMockWebhookRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockCustomerXid := "ea0212d3-abd6-406f-8c67-868e814a2436"
+	mockEndpoint := &model.WebhookEndpoint{
+		ID:      "3f1c2b4a-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+		OwnedBy: mockCustomerXid,
+		URL:     "https://merchant.example.com/hooks",
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		wantErr   bool
+		resultErr error
+		patch     func()
+	}{
+		{
+			name:    "Success",
+			id:      mockEndpoint.ID,
+			wantErr: false,
+			patch: func() {
+				mockWebhookDB.EXPECT().DeleteWebhookEndpoint(gomock.Any(), mockCustomerXid, mockEndpoint.ID).Return(mockEndpoint, nil)
+			},
+		},
+		{
+			name:      "Failed invalid id",
+			id:        "not-a-uuid",
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookNotFound,
+			patch:     func() {},
+		},
+		{
+			name:      "Failed not found",
+			id:        mockEndpoint.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWebhookNotFound,
+			patch: func() {
+				mockWebhookDB.EXPECT().DeleteWebhookEndpoint(gomock.Any(), mockCustomerXid, mockEndpoint.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:    "Failed DeleteWebhookEndpoint",
+			id:      mockEndpoint.ID,
+			wantErr: true,
+			patch: func() {
+				mockWebhookDB.EXPECT().DeleteWebhookEndpoint(gomock.Any(), mockCustomerXid, mockEndpoint.ID).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWebhookUsecase(mockWebhookDB, webhook.NewSender(time.Second), time.Second, 0)
+			_, err := uc.DeleteEndpoint(mockCtx, mockCustomerXid, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.DeleteEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.DeleteEndpoint() error = %v, want %v", err, tt.resultErr)
+			}
+		})
+	}
+}
+
+// sendFunc stands in for the webhook sender, which does not post to the
+// loopback address an httptest server listens on.
+type sendFunc func(ctx context.Context, delivery *model.WebhookDelivery) error
+
+func (f sendFunc) Send(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return f(ctx, delivery)
+}
+
+func TestUsecaseWebhook_DispatchWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookDB := mockDB.NewMockWebhookRepository(ctrl)
+	defer ctrl.Finish()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+
+	status := http.StatusOK
+	sender := sendFunc(func(ctx context.Context, delivery *model.WebhookDelivery) error {
+		if status < 200 || status > 299 {
+			return fmt.Errorf("webhook endpoint answered %d", status)
+		}
+		return nil
+	})
+
+	newDelivery := func(attempts int) *model.WebhookDelivery {
+		return &model.WebhookDelivery{
+			ID:        "0e1b3d4c-8f3a-4d9e-9f0e-5b1f3d2c7a10",
+			EventID:   "9b2f1e4a-2c3d-4e5f-8a9b-0c1d2e3f4a5b",
+			EventType: webhook.EventDepositSucceeded,
+			Payload:   json.RawMessage(`{}`),
+			URL:       "https://merchant.example.com/hooks",
+			Secret:    "secret",
+			Status:    webhook.DeliveryPending,
+			Attempts:  attempts,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantDelivered int
+		patch         func()
+	}{
+		{
+			name:          "Success delivered",
+			status:        http.StatusOK,
+			wantErr:       false,
+			wantDelivered: 1,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.WebhookDelivery{newDelivery(0)}, nil)
+				mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+					if delivery.Status != webhook.DeliveryDelivered || delivery.DeliveredAt == nil || delivery.Attempts != 0 {
+						t.Errorf("delivery = %+v, want delivered", delivery)
+					}
+					return true, nil
+				})
+			},
+		},
+		{
+			name:          "Success failed attempt retried",
+			status:        http.StatusInternalServerError,
+			wantErr:       false,
+			wantDelivered: 0,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.WebhookDelivery{newDelivery(1)}, nil)
+				mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+					if delivery.Status != webhook.DeliveryPending || delivery.Attempts != 2 || delivery.LastError == "" {
+						t.Errorf("delivery = %+v, want pending after 2 attempts", delivery)
+					}
+					if wait := time.Until(delivery.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+						t.Errorf("next attempt in %v, want about %v", wait, webhook.Backoff(2))
+					}
+					return true, nil
+				})
+			},
+		},
+		{
+			name:          "Success last attempt dead",
+			status:        http.StatusBadRequest,
+			wantErr:       false,
+			wantDelivered: 0,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.WebhookDelivery{newDelivery(2)}, nil)
+				mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+					if delivery.Status != webhook.DeliveryDead || delivery.Attempts != 3 {
+						t.Errorf("delivery = %+v, want dead after 3 attempts", delivery)
+					}
+					return true, nil
+				})
+			},
+		},
+		{
+			name:          "Success claimed again",
+			status:        http.StatusOK,
+			wantErr:       false,
+			wantDelivered: 0,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.WebhookDelivery{newDelivery(0)}, nil)
+				mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name:    "Failed ClaimWebhookDeliveries",
+			wantErr: true,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed UpdateWebhookDelivery",
+			status:  http.StatusOK,
+			wantErr: true,
+			patch: func() {
+				mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.WebhookDelivery{newDelivery(0)}, nil)
+				mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Return(false, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			status = tt.status
+			uc := usecase.NewWebhookUsecase(mockWebhookDB, sender, time.Second, 3)
+			delivered, err := uc.DispatchWebhooks(mockCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.DispatchWebhooks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if delivered != tt.wantDelivered {
+				t.Errorf("Usecase.DispatchWebhooks() delivered = %v, want %v", delivered, tt.wantDelivered)
+			}
+		})
+	}
+}
+
+func TestUsecaseWebhook_DispatchWebhooksSlowSender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhookDB := mockDB.NewMockWebhookRepository(ctrl)
+	defer ctrl.Finish()
+
+	// Every endpoint of a full batch takes the whole timeout to answer.
+	timeout := 20 * time.Millisecond
+	sender := sendFunc(func(ctx context.Context, delivery *model.WebhookDelivery) error {
+		time.Sleep(timeout)
+		return nil
+	})
+
+	batch := make([]*model.WebhookDelivery, 50)
+	for i := range batch {
+		batch[i] = &model.WebhookDelivery{
+			ID:        fmt.Sprintf("0e1b3d4c-8f3a-4d9e-9f0e-5b1f3d2c7a%02d", i),
+			EventID:   "9b2f1e4a-2c3d-4e5f-8a9b-0c1d2e3f4a5b",
+			EventType: webhook.EventDepositSucceeded,
+			Payload:   json.RawMessage(`{}`),
+			URL:       "https://merchant.example.com/hooks",
+			Secret:    "secret",
+			Status:    webhook.DeliveryPending,
+			ClaimID:   "5f2d8c1a-7e4b-4a9c-b3d6-1e0f2a4b6c8d",
+		}
+	}
+
+	var leaseUntil time.Time
+	gomock.InOrder(
+		mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), 50).DoAndReturn(func(ctx context.Context, now, until time.Time, limit int) ([]*model.WebhookDelivery, error) {
+			leaseUntil = until
+			return batch, nil
+		}),
+		mockWebhookDB.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(len(batch)).DoAndReturn(func(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+			if time.Now().After(leaseUntil) {
+				t.Errorf("delivery %s sent after its lease ran out at %v", delivery.ID, leaseUntil)
+			}
+			return true, nil
+		}),
+		mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), 50).Return([]*model.WebhookDelivery{}, nil),
+	)
+
+	uc := usecase.NewWebhookUsecase(mockWebhookDB, sender, timeout, 3)
+	start := time.Now()
+	delivered, err := uc.DispatchWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("Usecase.DispatchWebhooks() error = %v", err)
+	}
+	if delivered != len(batch) {
+		t.Errorf("Usecase.DispatchWebhooks() delivered = %v, want %v", delivered, len(batch))
+	}
+	if lease := leaseUntil.Sub(start); lease < time.Duration(len(batch))*timeout {
+		t.Errorf("lease = %v, want at least %v", lease, time.Duration(len(batch))*timeout)
+	}
+
+	// The same holds for the default timeout, which a fixed lease of a few
+	// minutes would not cover.
+	mockWebhookDB.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), 50).DoAndReturn(func(ctx context.Context, now, until time.Time, limit int) ([]*model.WebhookDelivery, error) {
+		if lease := until.Sub(now); lease < 50*webhook.DefaultTimeout {
+			t.Errorf("lease = %v, want at least %v", lease, 50*webhook.DefaultTimeout)
+		}
+		return []*model.WebhookDelivery{}, nil
+	})
+	_, err = usecase.NewWebhookUsecase(mockWebhookDB, sender, 0, 3).DispatchWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("Usecase.DispatchWebhooks() error = %v", err)
+	}
+}
@@ -2,50 +2,109 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"regexp"
 	"time"
 
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
+	"github.com/herwando/mini-wallet/lib/common/logger"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/metrics"
+	"github.com/shopspring/decimal"
 )
 
+// A pending withdrawal only holds its amount on the wallet. Capturing it moves
+// it to SuccessStatus; voiding it, or letting its hold lapse, releases the
+// amount.
+const (
+	PendingStatus = 3
+	VoidedStatus  = 4
+	ExpiredStatus = 5
+)
+
+// DefaultHoldTTL is how long a pending withdrawal holds its amount when no
+// TTL is configured.
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+// expireBatchSize is how many lapsed holds ExpireWithdrawals reads at a time.
+const expireBatchSize = 100
+
+// withdrawalTransitions lists the statuses a withdrawal may move to from each
+// status. A status missing from it is final.
+var withdrawalTransitions = map[int][]int{
+	PendingStatus: {SuccessStatus, VoidedStatus, ExpiredStatus},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type WithdrawalUsecase struct {
 	repo       WithdrawalRepository
 	walletRepo WalletRepository
+	limits     *LimitChecker
+	holdTTL    time.Duration
 }
 
-func NewWithdrawalUsecase(repo WithdrawalRepository, walletRepo WalletRepository) *WithdrawalUsecase {
+func NewWithdrawalUsecase(repo WithdrawalRepository, walletRepo WalletRepository, limits *LimitChecker, holdTTL time.Duration) *WithdrawalUsecase {
+	if holdTTL <= 0 {
+		holdTTL = DefaultHoldTTL
+	}
+
 	return &WithdrawalUsecase{
 		repo:       repo,
 		walletRepo: walletRepo,
+		limits:     limits,
+		holdTTL:    holdTTL,
 	}
 }
 
 func (h *WithdrawalUsecase) CreateWithdrawal(ctx context.Context, customerXid string, payload model.PayloadWithdrawal) (*model.Withdrawal, error) {
-	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid)
+	if payload.Currency == "" {
+		payload.Currency = model.DefaultCurrency
+	}
+
+	withdrawal, err := h.createWithdrawal(ctx, customerXid, payload)
+	// A hold moves no money yet, the withdrawal is counted once captured.
+	if err != nil || !payload.Pending {
+		observeTransaction(metrics.TransactionWithdrawal, payload.Currency, payload.Amount, withdrawal != nil && withdrawal.Replayed, err)
+	}
+
+	return withdrawal, err
+}
+
+func (h *WithdrawalUsecase) createWithdrawal(ctx context.Context, customerXid string, payload model.PayloadWithdrawal) (*model.Withdrawal, error) {
+	_, err := lookupAmountCurrency(payload.Currency, payload.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, customerXid, payload.Currency)
 	if err != nil {
 		return nil, err
 	}
 
 	if wallet == nil {
-		return nil, errors.New("Wallet not enable")
+		return nil, commonerr.ErrWalletNotFound
 	} else {
-		if wallet.Status == DisabledStatus {
-			return nil, errors.New("Wallet disabled")
-		}
-
-		if wallet.Balance.LessThan(payload.Amount) {
-			return nil, errors.New("Wallet balance not enough")
+		if wallet.Status != EnabledStatus {
+			return nil, walletStatusError(wallet.Status)
 		}
 	}
-	wallet.Balance = wallet.Balance.Sub(payload.Amount)
 
-	withdrawal, err := h.repo.GetWithdrawalByReferenceId(ctx, payload.ReferenceID)
+	withdrawal, err := h.repo.GetWithdrawalByReferenceId(ctx, customerXid, payload.ReferenceID)
 	if err != nil {
 		return nil, err
 	}
 
 	if withdrawal != nil {
-		return nil, errors.New("Reference id already used")
+		return replayWithdrawal(ctx, withdrawal, payload)
+	}
+
+	if wallet.Available().LessThan(payload.Amount) {
+		return nil, commonerr.ErrBalanceNotEnough
+	}
+
+	err = h.limits.CheckWithdrawal(ctx, wallet, payload.Amount)
+	if err != nil {
+		return nil, err
 	}
 
 	data := &model.Withdrawal{
@@ -54,13 +113,182 @@ func (h *WithdrawalUsecase) CreateWithdrawal(ctx context.Context, customerXid st
 		WithdrawnAt: time.Now(),
 		Amount:      payload.Amount,
 		ReferenceID: payload.ReferenceID,
+		Currency:    payload.Currency,
 	}
 
-	withdrawal, err = h.repo.CreateWithdrawal(ctx, data, wallet)
+	if payload.Pending {
+		expiresAt := data.WithdrawnAt.Add(h.holdTTL)
+		data.Status = PendingStatus
+		data.ExpiresAt = &expiresAt
+		withdrawal, err = h.repo.CreatePendingWithdrawal(ctx, data, wallet)
+	} else {
+		withdrawal, err = h.repo.CreateWithdrawal(ctx, data, wallet)
+	}
+	err = walletWriteError(ctx, err, wallet)
+	if err == commonerr.ErrReferenceIdUsed {
+		// A concurrent request with the same reference id got there first.
+		withdrawal, err = h.repo.GetWithdrawalByReferenceId(ctx, customerXid, payload.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if withdrawal == nil {
+			return nil, commonerr.ErrReferenceIdUsed
+		}
+		return replayWithdrawal(ctx, withdrawal, payload)
+	}
 	if err != nil {
 		return nil, err
 	}
-	withdrawal.StatusMessage = "success"
+	withdrawal.StatusMessage = transactionStatusMessage(withdrawal.Status)
+	logger.FromContext(ctx).Info("withdrawal created", "withdrawal_id", withdrawal.ID, "amount", withdrawal.Amount.String(), "status", withdrawal.StatusMessage)
 
 	return withdrawal, err
 }
+
+// replayWithdrawal returns the withdrawal already made with the payload
+// reference id when the payload matches it. A reversal is not a withdrawal
+// the payload could have made.
+func replayWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, payload model.PayloadWithdrawal) (*model.Withdrawal, error) {
+	pending := withdrawal.ExpiresAt != nil
+	if !withdrawal.Amount.Equal(payload.Amount) || withdrawal.Currency != payload.Currency || pending != payload.Pending || withdrawal.ReversalOf != "" {
+		logger.FromContext(ctx).Warn("reference id reused with a different payload", "reference_id", payload.ReferenceID)
+		return nil, commonerr.ErrReferenceIdUsed
+	}
+
+	withdrawal.StatusMessage = transactionStatusMessage(withdrawal.Status)
+	withdrawal.Replayed = true
+
+	return withdrawal, nil
+}
+
+// CaptureWithdrawal takes the amount held by a pending withdrawal off the
+// wallet balance. Capturing a captured withdrawal replays it.
+func (h *WithdrawalUsecase) CaptureWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	withdrawal, err := h.settleWithdrawal(ctx, customerXid, id, SuccessStatus)
+
+	var currency string
+	var amount decimal.Decimal
+	if withdrawal != nil {
+		currency, amount = withdrawal.Currency, withdrawal.Amount
+	}
+	observeTransaction(metrics.TransactionWithdrawal, currency, amount, withdrawal != nil && withdrawal.Replayed, err)
+
+	return withdrawal, err
+}
+
+// VoidWithdrawal releases the amount held by a pending withdrawal. Voiding a
+// voided withdrawal replays it.
+func (h *WithdrawalUsecase) VoidWithdrawal(ctx context.Context, customerXid, id string) (*model.Withdrawal, error) {
+	return h.settleWithdrawal(ctx, customerXid, id, VoidedStatus)
+}
+
+// ExpireWithdrawals releases the holds of the pending withdrawals whose
+// expires_at has passed, and returns how many it expired.
+func (h *WithdrawalUsecase) ExpireWithdrawals(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		now := time.Now()
+		withdrawals, err := h.repo.GetExpiredWithdrawals(ctx, PendingStatus, now, expireBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, withdrawal := range withdrawals {
+			_, err = h.transitionWithdrawal(ctx, withdrawal, ExpiredStatus, now)
+			if err == commonerr.ErrWithdrawalTransition {
+				// Captured or voided since it was read.
+				continue
+			}
+			if err != nil {
+				return expired, err
+			}
+			expired++
+		}
+
+		if len(withdrawals) < expireBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+func (h *WithdrawalUsecase) settleWithdrawal(ctx context.Context, customerXid, id string, to int) (*model.Withdrawal, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, commonerr.ErrWithdrawalNotFound
+	}
+
+	withdrawal, err := h.repo.GetWithdrawalById(ctx, customerXid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if withdrawal == nil {
+		return nil, commonerr.ErrWithdrawalNotFound
+	}
+
+	if withdrawal.Status == to {
+		withdrawal.StatusMessage = transactionStatusMessage(withdrawal.Status)
+		withdrawal.Replayed = true
+		return withdrawal, nil
+	}
+
+	now := time.Now()
+	if withdrawal.Status == PendingStatus && withdrawal.ExpiresAt != nil && !now.Before(*withdrawal.ExpiresAt) {
+		// The hold lapsed before the sweep got to it.
+		_, err = h.transitionWithdrawal(ctx, withdrawal, ExpiredStatus, now)
+		if err != nil && err != commonerr.ErrWithdrawalTransition {
+			return nil, err
+		}
+		return nil, commonerr.ErrWithdrawalExpired
+	}
+
+	return h.transitionWithdrawal(ctx, withdrawal, to, now)
+}
+
+// transitionWithdrawal moves the withdrawal to status to, if
+// withdrawalTransitions allows it, capturing or releasing its hold.
+func (h *WithdrawalUsecase) transitionWithdrawal(ctx context.Context, withdrawal *model.Withdrawal, to int, now time.Time) (*model.Withdrawal, error) {
+	from := withdrawal.Status
+	if !canTransitionWithdrawal(from, to) {
+		return nil, commonerr.ErrWithdrawalTransition
+	}
+
+	wallet, err := h.walletRepo.GetWalletByCustomerXid(ctx, withdrawal.WithdrawnBy, withdrawal.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, commonerr.ErrWalletNotFound
+	}
+
+	// A wallet that is not enabled can still have its holds released, but not
+	// spent.
+	if to == SuccessStatus && wallet.Status != EnabledStatus {
+		return nil, walletStatusError(wallet.Status)
+	}
+
+	withdrawal.Status = to
+	withdrawal.SettledAt = &now
+	if to == SuccessStatus {
+		withdrawal, err = h.repo.CaptureWithdrawal(ctx, withdrawal, wallet, from)
+		err = walletWriteError(ctx, err, wallet)
+	} else {
+		withdrawal, err = h.repo.ReleaseWithdrawal(ctx, withdrawal, wallet, from)
+	}
+	if err != nil {
+		return nil, err
+	}
+	withdrawal.StatusMessage = transactionStatusMessage(withdrawal.Status)
+	logger.FromContext(ctx).Info("withdrawal settled", "withdrawal_id", withdrawal.ID, "status", withdrawal.StatusMessage)
+
+	return withdrawal, nil
+}
+
+func canTransitionWithdrawal(from, to int) bool {
+	for _, status := range withdrawalTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
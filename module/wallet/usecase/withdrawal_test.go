@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/herwando/mini-wallet/lib/common/commonerr"
 	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/limits"
 	"github.com/herwando/mini-wallet/module/wallet/usecase"
 	mockDB "github.com/herwando/mini-wallet/module/wallet/usecase/_mocks"
 	"github.com/shopspring/decimal"
@@ -17,6 +19,8 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
 	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	mockLimitDB := mockDB.NewMockLimitRepository(ctrl)
+	mockLimitDB.EXPECT().GetWalletLimits(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	defer ctrl.Finish()
 	now := time.Now()
 	mockCtx := context.Background()
@@ -27,6 +31,7 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 		Status:    1,
 		EnabledAt: now,
 		Balance:   decimal.NewFromInt(10000),
+		Currency:  "IDR",
 	}
 	mockWalletDisable := &model.Wallet{
 		ID:        "81401b03-60e0-4f20-afc6-419b3773e7b3",
@@ -49,12 +54,23 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 		WithdrawnAt: now,
 		Amount:      decimal.NewFromInt(1000),
 		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:    "IDR",
 	}
 	mockCustomerXid := mockWithdrawal.WithdrawnBy
+	mockWithdrawalOther := *mockWithdrawal
+	mockWithdrawalOther.Amount = decimal.NewFromInt(2000)
 	mockPayload := model.PayloadWithdrawal{
 		ReferenceID: mockWithdrawal.ReferenceID,
 		Amount:      mockWithdrawal.Amount,
 	}
+	mockPayloadPending := mockPayload
+	mockPayloadPending.Pending = true
+	expiresAt := now.Add(usecase.DefaultHoldTTL)
+	mockWithdrawalPending := *mockWithdrawal
+	mockWithdrawalPending.Status = usecase.PendingStatus
+	mockWithdrawalPending.ExpiresAt = &expiresAt
+	mockWalletHeld := *mockWallet
+	mockWalletHeld.HeldBalance = decimal.NewFromInt(9500)
 
 	type args struct {
 		ctx         context.Context
@@ -62,10 +78,13 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 		payload     model.PayloadWithdrawal
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		patch   func()
+		name         string
+		args         args
+		limits       limits.Defaults
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
 	}{
 		{
 			name: "Success",
@@ -76,11 +95,58 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: false,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
 				mockWithdrawalDB.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockWithdrawal, nil)
 			},
 		},
+		{
+			name: "Success pending",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayloadPending,
+			},
+			wantErr: false,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockWithdrawalDB.EXPECT().CreatePendingWithdrawal(gomock.Any(), gomock.Any(), mockWallet).DoAndReturn(func(_ context.Context, withdrawal *model.Withdrawal, _ *model.Wallet) (*model.Withdrawal, error) {
+					if withdrawal.Status != usecase.PendingStatus || withdrawal.ExpiresAt == nil {
+						t.Errorf("CreatePendingWithdrawal() got status %d, expires at %v", withdrawal.Status, withdrawal.ExpiresAt)
+					}
+					return &mockWithdrawalPending, nil
+				})
+			},
+		},
+		{
+			name: "Failed available balance held",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrBalanceNotEnough,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&mockWalletHeld, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+			},
+		},
+		{
+			name: "Failed replay pending with payload not pending",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:   true,
+			resultErr: commonerr.ErrReferenceIdUsed,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockWithdrawalPending, nil)
+			},
+		},
 		{
 			name: "Failed on GetWalletByCustomerXid errpr",
 			args: args{
@@ -90,7 +156,7 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, mockError)
 			},
 		},
 		{
@@ -102,7 +168,7 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
 			},
 		},
 		{
@@ -114,7 +180,7 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletDisable, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletDisable, nil)
 			},
 		},
 		{
@@ -126,7 +192,7 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWalletZero, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWalletZero, nil)
 			},
 		},
 		{
@@ -138,12 +204,26 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, mockError)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, mockError)
+			},
+		},
+		{
+			name: "Success replay on GetWithdrawalByReferenceId",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockWithdrawal, nil)
 			},
 		},
 		{
-			name: "Failed on GetWithdrawalByReferenceId",
+			name: "Failed on GetWithdrawalByReferenceId with different payload",
 			args: args{
 				ctx:         mockCtx,
 				customerXid: mockCustomerXid,
@@ -151,8 +231,40 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(mockWithdrawal, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(&mockWithdrawalOther, nil)
+			},
+		},
+		{
+			name: "Success replay on CreateWithdrawal reference id used",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockWithdrawalDB.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, commonerr.ErrReferenceIdUsed)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(mockWithdrawal, nil)
+			},
+		},
+		{
+			name: "Failed daily withdrawal limit",
+			args: args{
+				ctx:         mockCtx,
+				customerXid: mockCustomerXid,
+				payload:     mockPayload,
+			},
+			limits:    limits.Defaults{"IDR": {DailyWithdrawal: decimal.NewFromInt(1500)}},
+			wantErr:   true,
+			resultErr: commonerr.ErrLimitExceeded,
+			patch: func() {
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
+				mockLimitDB.EXPECT().GetWithdrawnAmount(gomock.Any(), mockWallet.OwnedBy, "IDR", []int{usecase.SuccessStatus, usecase.PendingStatus}, gomock.Any()).Return(decimal.NewFromInt(1000), nil)
 			},
 		},
 		{
@@ -164,8 +276,8 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 			},
 			wantErr: true,
 			patch: func() {
-				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid).Return(mockWallet, nil)
-				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockPayload.ReferenceID).Return(nil, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().GetWithdrawalByReferenceId(gomock.Any(), mockCustomerXid, mockPayload.ReferenceID).Return(nil, nil)
 				mockWithdrawalDB.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockError)
 			},
 		},
@@ -173,12 +285,382 @@ func TestUsecaseWithdrawal_CreateWithdrawal(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.patch()
-			uc := usecase.NewWithdrawalUsecase(mockWithdrawalDB, mockWalletDB)
-			_, err := uc.CreateWithdrawal(tt.args.ctx, tt.args.customerXid, tt.args.payload)
+			uc := usecase.NewWithdrawalUsecase(mockWithdrawalDB, mockWalletDB, usecase.NewLimitChecker(mockLimitDB, tt.limits), 0)
+			got, err := uc.CreateWithdrawal(tt.args.ctx, tt.args.customerXid, tt.args.payload)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Usecase.CreateWithdrawal() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CreateWithdrawal() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.CreateWithdrawal() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestUsecaseWithdrawal_CaptureWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(1000),
+		Currency:    "IDR",
+	}
+	mockWalletDisable := *mockWallet
+	mockWalletDisable.Status = 2
+	expiresAt := now.Add(time.Hour)
+	expiredAt := now.Add(-time.Minute)
+	mockWithdrawal := model.Withdrawal{
+		ID:          "5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d",
+		WithdrawnBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      usecase.PendingStatus,
+		WithdrawnAt: now,
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:    "IDR",
+		ExpiresAt:   &expiresAt,
+	}
+	mockCustomerXid := mockWithdrawal.WithdrawnBy
+	pending := func() *model.Withdrawal {
+		withdrawal := mockWithdrawal
+		return &withdrawal
+	}
+	withStatus := func(status int) *model.Withdrawal {
+		withdrawal := pending()
+		withdrawal.Status = status
+		return withdrawal
+	}
+	expired := func() *model.Withdrawal {
+		withdrawal := pending()
+		withdrawal.ExpiresAt = &expiredAt
+		return withdrawal
+	}
+
+	tests := []struct {
+		name         string
+		id           string
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
+	}{
+		{
+			name:    "Success",
+			id:      mockWithdrawal.ID,
+			wantErr: false,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(pending(), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().CaptureWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).DoAndReturn(func(_ context.Context, withdrawal *model.Withdrawal, _ *model.Wallet, _ int) (*model.Withdrawal, error) {
+					if withdrawal.Status != usecase.SuccessStatus || withdrawal.SettledAt == nil {
+						t.Errorf("CaptureWithdrawal() got status %d, settled at %v", withdrawal.Status, withdrawal.SettledAt)
+					}
+					return withdrawal, nil
+				})
+			},
+		},
+		{
+			name:         "Success replay captured",
+			id:           mockWithdrawal.ID,
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(withStatus(usecase.SuccessStatus), nil)
+			},
+		},
+		{
+			name:      "Failed id not uuid",
+			id:        "not-a-uuid",
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotFound,
+			patch:     func() {},
+		},
+		{
+			name:    "Failed on GetWithdrawalById error",
+			id:      mockWithdrawal.ID,
+			wantErr: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(nil, mockError)
+			},
+		},
+		{
+			name:      "Failed withdrawal not found",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalNotFound,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed withdrawal voided",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalTransition,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(withStatus(usecase.VoidedStatus), nil)
+			},
+		},
+		{
+			name:      "Failed hold expired",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalExpired,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(expired(), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().ReleaseWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).DoAndReturn(func(_ context.Context, withdrawal *model.Withdrawal, _ *model.Wallet, _ int) (*model.Withdrawal, error) {
+					if withdrawal.Status != usecase.ExpiredStatus {
+						t.Errorf("ReleaseWithdrawal() got status %d, want %d", withdrawal.Status, usecase.ExpiredStatus)
+					}
+					return withdrawal, nil
+				})
+			},
+		},
+		{
+			name:      "Failed wallet empty",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletNotFound,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(pending(), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(nil, nil)
+			},
+		},
+		{
+			name:      "Failed wallet disabled",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWalletDisabled,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(pending(), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(&mockWalletDisable, nil)
+			},
+		},
+		{
+			name:      "Failed on CaptureWithdrawal lost race",
+			id:        mockWithdrawal.ID,
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalTransition,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(pending(), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().CaptureWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).Return(nil, commonerr.ErrWithdrawalTransition)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWithdrawalUsecase(mockWithdrawalDB, mockWalletDB, nil, 0)
+			got, err := uc.CaptureWithdrawal(mockCtx, mockCustomerXid, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.CaptureWithdrawal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.CaptureWithdrawal() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.CaptureWithdrawal() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestUsecaseWithdrawal_VoidWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockWallet := &model.Wallet{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      2,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(1000),
+		Currency:    "IDR",
+	}
+	expiresAt := now.Add(time.Hour)
+	mockWithdrawal := model.Withdrawal{
+		ID:          "5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d",
+		WithdrawnBy: "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      usecase.PendingStatus,
+		WithdrawnAt: now,
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		Currency:    "IDR",
+		ExpiresAt:   &expiresAt,
+	}
+	mockCustomerXid := mockWithdrawal.WithdrawnBy
+	withStatus := func(status int) *model.Withdrawal {
+		withdrawal := mockWithdrawal
+		withdrawal.Status = status
+		return &withdrawal
+	}
+
+	tests := []struct {
+		name         string
+		wantErr      bool
+		resultErr    error
+		wantReplayed bool
+		patch        func()
+	}{
+		{
+			name:    "Success on disabled wallet",
+			wantErr: false,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(withStatus(usecase.PendingStatus), nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockCustomerXid, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().ReleaseWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).DoAndReturn(func(_ context.Context, withdrawal *model.Withdrawal, _ *model.Wallet, _ int) (*model.Withdrawal, error) {
+					if withdrawal.Status != usecase.VoidedStatus {
+						t.Errorf("ReleaseWithdrawal() got status %d, want %d", withdrawal.Status, usecase.VoidedStatus)
+					}
+					return withdrawal, nil
+				})
+			},
+		},
+		{
+			name:         "Success replay voided",
+			wantErr:      false,
+			wantReplayed: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(withStatus(usecase.VoidedStatus), nil)
+			},
+		},
+		{
+			name:      "Failed withdrawal captured",
+			wantErr:   true,
+			resultErr: commonerr.ErrWithdrawalTransition,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetWithdrawalById(gomock.Any(), mockCustomerXid, mockWithdrawal.ID).Return(withStatus(usecase.SuccessStatus), nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWithdrawalUsecase(mockWithdrawalDB, mockWalletDB, nil, 0)
+			got, err := uc.VoidWithdrawal(mockCtx, mockCustomerXid, mockWithdrawal.ID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.VoidWithdrawal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.resultErr != nil && !errors.Is(err, tt.resultErr) {
+				t.Errorf("Usecase.VoidWithdrawal() error = %v, want %v", err, tt.resultErr)
+			}
+			if got != nil && got.Replayed != tt.wantReplayed {
+				t.Errorf("Usecase.VoidWithdrawal() replayed = %v, want %v", got.Replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestUsecaseWithdrawal_ExpireWithdrawals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWithdrawalDB := mockDB.NewMockWithdrawalRepository(ctrl)
+	mockWalletDB := mockDB.NewMockWalletRepository(ctrl)
+	defer ctrl.Finish()
+	now := time.Now()
+	mockCtx := context.Background()
+	mockError := errors.New("fake error")
+	mockWallet := &model.Wallet{
+		ID:          "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:     "ea0212d3-abd6-406f-8c67-868e814a2436",
+		Status:      1,
+		EnabledAt:   now,
+		Balance:     decimal.NewFromInt(10000),
+		HeldBalance: decimal.NewFromInt(2000),
+		Currency:    "IDR",
+	}
+	expiredAt := now.Add(-time.Minute)
+	expired := func(id string) *model.Withdrawal {
+		return &model.Withdrawal{
+			ID:          id,
+			WithdrawnBy: mockWallet.OwnedBy,
+			Status:      usecase.PendingStatus,
+			WithdrawnAt: now,
+			Amount:      decimal.NewFromInt(1000),
+			ReferenceID: id,
+			Currency:    "IDR",
+			ExpiresAt:   &expiredAt,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		wantErr     bool
+		wantExpired int
+		patch       func()
+	}{
+		{
+			name:        "Success",
+			wantErr:     false,
+			wantExpired: 1,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetExpiredWithdrawals(gomock.Any(), usecase.PendingStatus, gomock.Any(), gomock.Any()).
+					Return([]*model.Withdrawal{expired("5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d"), expired("6c1e7d2f-3b8a-4a4c-8d9e-2f3a4b5c6d7e")}, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockWallet.OwnedBy, "IDR").Return(mockWallet, nil).Times(2)
+				gomock.InOrder(
+					mockWithdrawalDB.EXPECT().ReleaseWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).Return(expired("5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d"), nil),
+					// Voided by its owner after the sweep read it.
+					mockWithdrawalDB.EXPECT().ReleaseWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).Return(nil, commonerr.ErrWithdrawalTransition),
+				)
+			},
+		},
+		{
+			name:        "Success nothing expired",
+			wantErr:     false,
+			wantExpired: 0,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetExpiredWithdrawals(gomock.Any(), usecase.PendingStatus, gomock.Any(), gomock.Any()).Return([]*model.Withdrawal{}, nil)
+			},
+		},
+		{
+			name:    "Failed on GetExpiredWithdrawals error",
+			wantErr: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetExpiredWithdrawals(gomock.Any(), usecase.PendingStatus, gomock.Any(), gomock.Any()).Return(nil, mockError)
+			},
+		},
+		{
+			name:    "Failed on ReleaseWithdrawal error",
+			wantErr: true,
+			patch: func() {
+				mockWithdrawalDB.EXPECT().GetExpiredWithdrawals(gomock.Any(), usecase.PendingStatus, gomock.Any(), gomock.Any()).
+					Return([]*model.Withdrawal{expired("5b0d6c1e-2a7f-4f3b-9c8d-1e2f3a4b5c6d")}, nil)
+				mockWalletDB.EXPECT().GetWalletByCustomerXid(gomock.Any(), mockWallet.OwnedBy, "IDR").Return(mockWallet, nil)
+				mockWithdrawalDB.EXPECT().ReleaseWithdrawal(gomock.Any(), gomock.Any(), mockWallet, usecase.PendingStatus).Return(nil, mockError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch()
+			uc := usecase.NewWithdrawalUsecase(mockWithdrawalDB, mockWalletDB, nil, 0)
+			got, err := uc.ExpireWithdrawals(mockCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Usecase.ExpireWithdrawals() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.wantExpired {
+				t.Errorf("Usecase.ExpireWithdrawals() = %v, want %v", got, tt.wantExpired)
+			}
 		})
 	}
 }
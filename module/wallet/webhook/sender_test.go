@@ -0,0 +1,181 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Send(t *testing.T) {
+	testCases := map[string]struct {
+		status  int
+		wantErr bool
+	}{
+		"success": {
+			status:  http.StatusOK,
+			wantErr: false,
+		},
+		"success no content": {
+			status:  http.StatusNoContent,
+			wantErr: false,
+		},
+		"failed server error": {
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+		"failed not modified": {
+			status:  http.StatusNotModified,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			delivery := &model.WebhookDelivery{
+				ID:             "0e1b3d4c-8f3a-4d9e-9f0e-5b1f3d2c7a10",
+				EventID:        "9b2f1e4a-2c3d-4e5f-8a9b-0c1d2e3f4a5b",
+				EventType:      webhook.EventDepositSucceeded,
+				Payload:        json.RawMessage(`{"id":"5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"}`),
+				EventCreatedAt: now,
+				Secret:         "secret",
+			}
+
+			var got *http.Request
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+			delivery.URL = server.URL
+
+			err := webhook.NewLoopbackSender(time.Second).Send(context.Background(), delivery)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, http.MethodPost, got.Method)
+			assert.Equal(t, delivery.EventID, got.Header.Get(webhook.EventIDHeader))
+			assert.Equal(t, delivery.EventType, got.Header.Get(webhook.EventTypeHeader))
+			assert.JSONEq(t, `{"id":"9b2f1e4a-2c3d-4e5f-8a9b-0c1d2e3f4a5b","type":"deposit.succeeded","created_at":"2026-10-18T10:00:00Z","data":{"id":"5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11"}}`, string(gotBody))
+
+			var timestamp int64
+			_, _ = fmt.Sscanf(got.Header.Get(webhook.SignatureHeader), "t=%d,", &timestamp)
+			assert.Equal(t, webhook.Sign(delivery.Secret, time.Unix(timestamp, 0), gotBody), got.Header.Get(webhook.SignatureHeader))
+		})
+	}
+}
+
+func TestSender_SendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	err := webhook.NewLoopbackSender(time.Second).Send(context.Background(), &model.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.NotNil(t, err)
+}
+
+func TestSender_SendNoRedirect(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	err := webhook.NewLoopbackSender(time.Second).Send(context.Background(), &model.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.NotNil(t, err)
+	assert.False(t, redirected)
+}
+
+func TestSender_SendAddressNotAllowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	testCases := map[string]string{
+		"loopback":    server.URL,
+		"private":     "http://10.0.0.5/hooks",
+		"link-local":  "http://169.254.169.254/latest/meta-data",
+		"unspecified": "http://0.0.0.0/hooks",
+		"ipv6":        "http://[::1]/hooks",
+	}
+
+	for name, url := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := webhook.NewSender(time.Second).Send(context.Background(), &model.WebhookDelivery{URL: url, Payload: json.RawMessage(`{}`)})
+			assert.True(t, errors.Is(err, webhook.ErrAddressNotAllowed), "error = %v", err)
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	testCases := map[string]bool{
+		"93.184.216.34":          true,
+		"8.8.8.8":                true,
+		"100.63.255.255":         true,
+		"100.128.0.0":            true,
+		"198.20.0.1":             true,
+		"2606:4700::1111":        true,
+		"::ffff:93.184.216.34":   true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"100.127.255.254":        false,
+		"0.0.0.0":                false,
+		"0.1.2.3":                false,
+		"192.0.0.8":              false,
+		"192.0.2.1":              false,
+		"192.88.99.1":            false,
+		"198.18.0.1":             false,
+		"198.19.255.255":         false,
+		"198.51.100.7":           false,
+		"203.0.113.7":            false,
+		"224.0.0.1":              false,
+		"240.0.0.1":              false,
+		"255.255.255.255":        false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:0.0.0.0":         false,
+		"::ffff:0.1.2.3":         false,
+		"::ffff:100.64.0.1":      false,
+		"::ffff:169.254.169.254": false,
+		"::":                     false,
+		"::1":                    false,
+		"::127.0.0.1":            false,
+		"64:ff9b::a00:1":         false,
+		"100::1":                 false,
+		"2001::1":                false,
+		"2001:db8::1":            false,
+		"2002:a00:1::1":          false,
+		"fd00::1":                false,
+		"fe80::1":                false,
+		"fec0::1":                false,
+		"ff02::1":                false,
+	}
+
+	for ip, want := range testCases {
+		t.Run(ip, func(t *testing.T) {
+			assert.Equal(t, want, webhook.IsPublicIP(net.ParseIP(ip)))
+		})
+	}
+
+	assert.False(t, webhook.IsPublicIP(nil))
+}
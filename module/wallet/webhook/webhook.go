@@ -0,0 +1,264 @@
+// Package webhook tells the endpoints a customer registered about what happens
+// to their wallets. Events are written to an outbox in the same transaction as
+// the change they describe, so an event is never sent for a change that rolled
+// back nor lost for one that committed, and are delivered later by a
+// dispatcher, retrying with exponential backoff until the delivery succeeds or
+// is given up on as dead.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	EventDepositSucceeded    = "deposit.succeeded"
+	EventWithdrawalSucceeded = "withdrawal.succeeded"
+	EventTransferCreated     = "transfer.created"
+	EventConversionCreated   = "conversion.created"
+	EventWalletEnabled       = "wallet.enabled"
+	EventWalletDisabled      = "wallet.disabled"
+	EventWalletFrozen        = "wallet.frozen"
+	EventWalletClosed        = "wallet.closed"
+)
+
+// Directions of a transfer, as told to each side of it.
+const (
+	TransferOut = "out"
+	TransferIn  = "in"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryDead is a delivery given up on after its last attempt.
+	DeliveryDead = "dead"
+)
+
+const (
+	EventIDHeader   = "Webhook-Id"
+	EventTypeHeader = "Webhook-Event"
+	// SignatureHeader carries t=<unix time>,v1=<signature>, the signature
+	// being the hex HMAC-SHA256, keyed by the endpoint secret, of the time, a
+	// dot and the request body.
+	SignatureHeader = "Webhook-Signature"
+)
+
+const (
+	DefaultMaxAttempts = 10
+
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+)
+
+type Event struct {
+	Type        string
+	CustomerXid string
+	CreatedAt   time.Time
+	Data        interface{}
+}
+
+// TransactionData is the data of the deposit and withdrawal events.
+type TransactionData struct {
+	ID           string          `json:"id"`
+	WalletID     string          `json:"wallet_id"`
+	ReferenceID  string          `json:"reference_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	TransactedAt time.Time       `json:"transacted_at"`
+}
+
+// TransferData is the data of the transfer events. The reference id is the
+// sender's, so it is only told to the sender.
+type TransferData struct {
+	ID           string          `json:"id"`
+	WalletID     string          `json:"wallet_id"`
+	Direction    string          `json:"direction"`
+	ReferenceID  string          `json:"reference_id,omitempty"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	TransactedAt time.Time       `json:"transacted_at"`
+}
+
+// ConversionData is the data of the conversion events.
+type ConversionData struct {
+	ID              string          `json:"id"`
+	ReferenceID     string          `json:"reference_id"`
+	FromWalletID    string          `json:"from_wallet_id"`
+	ToWalletID      string          `json:"to_wallet_id"`
+	FromCurrency    string          `json:"from_currency"`
+	ToCurrency      string          `json:"to_currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount"`
+	Rate            decimal.Decimal `json:"rate"`
+	TransactedAt    time.Time       `json:"transacted_at"`
+}
+
+// WalletData is the data of the wallet events.
+type WalletData struct {
+	ID       string          `json:"id"`
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+// Tx is the part of *sql.Tx events are written through, so an event is always
+// committed together with the change it describes.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func NewDepositEvent(deposit *model.Deposit, wallet *model.Wallet) *Event {
+	return &Event{
+		Type:        EventDepositSucceeded,
+		CustomerXid: deposit.DepositedBy,
+		CreatedAt:   deposit.DepositedAt,
+		Data: TransactionData{
+			ID:           deposit.ID,
+			WalletID:     wallet.ID,
+			ReferenceID:  deposit.ReferenceID,
+			Amount:       deposit.Amount,
+			Currency:     deposit.Currency,
+			TransactedAt: deposit.DepositedAt,
+		},
+	}
+}
+
+// NewWithdrawalEvent tells a withdrawal succeeded, when it is made or, for a
+// pending withdrawal, when it is captured.
+func NewWithdrawalEvent(withdrawal *model.Withdrawal, wallet *model.Wallet, succeededAt time.Time) *Event {
+	return &Event{
+		Type:        EventWithdrawalSucceeded,
+		CustomerXid: withdrawal.WithdrawnBy,
+		CreatedAt:   succeededAt,
+		Data: TransactionData{
+			ID:           withdrawal.ID,
+			WalletID:     wallet.ID,
+			ReferenceID:  withdrawal.ReferenceID,
+			Amount:       withdrawal.Amount,
+			Currency:     withdrawal.Currency,
+			TransactedAt: withdrawal.WithdrawnAt,
+		},
+	}
+}
+
+// NewTransferEvents tells both sides of the transfer about it: the sender of
+// the money going out of sender, and the recipient of the money coming in to
+// recipient.
+func NewTransferEvents(transfer *model.Transfer, sender, recipient *model.Wallet) []*Event {
+	return []*Event{
+		{
+			Type:        EventTransferCreated,
+			CustomerXid: transfer.TransferredBy,
+			CreatedAt:   transfer.TransferredAt,
+			Data: TransferData{
+				ID:           transfer.ID,
+				WalletID:     sender.ID,
+				Direction:    TransferOut,
+				ReferenceID:  transfer.ReferenceID,
+				Amount:       transfer.Amount,
+				Currency:     transfer.Currency,
+				TransactedAt: transfer.TransferredAt,
+			},
+		},
+		{
+			Type:        EventTransferCreated,
+			CustomerXid: transfer.TransferredTo,
+			CreatedAt:   transfer.TransferredAt,
+			Data: TransferData{
+				ID:           transfer.ID,
+				WalletID:     recipient.ID,
+				Direction:    TransferIn,
+				Amount:       transfer.Amount,
+				Currency:     transfer.Currency,
+				TransactedAt: transfer.TransferredAt,
+			},
+		},
+	}
+}
+
+func NewConversionEvent(conversion *model.Conversion, source, target *model.Wallet) *Event {
+	return &Event{
+		Type:        EventConversionCreated,
+		CustomerXid: conversion.ConvertedBy,
+		CreatedAt:   conversion.ConvertedAt,
+		Data: ConversionData{
+			ID:              conversion.ID,
+			ReferenceID:     conversion.ReferenceID,
+			FromWalletID:    source.ID,
+			ToWalletID:      target.ID,
+			FromCurrency:    conversion.FromCurrency,
+			ToCurrency:      conversion.ToCurrency,
+			Amount:          conversion.Amount,
+			ConvertedAmount: conversion.ConvertedAmount,
+			Rate:            conversion.Rate,
+			TransactedAt:    conversion.ConvertedAt,
+		},
+	}
+}
+
+func NewWalletEvent(eventType string, wallet *model.Wallet, createdAt time.Time) *Event {
+	return &Event{
+		Type:        eventType,
+		CustomerXid: wallet.OwnedBy,
+		CreatedAt:   createdAt,
+		Data: WalletData{
+			ID:       wallet.ID,
+			Currency: wallet.Currency,
+			Balance:  wallet.Balance,
+		},
+	}
+}
+
+// Enqueue writes the event to the outbox with a pending delivery to every
+// endpoint of its customer, due right away.
+func Enqueue(ctx context.Context, tx Tx, event *Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `WITH event AS (
+			INSERT INTO webhook_events (event_type, customer_xid, payload, created_at)
+			VALUES ($1, $2, $3, $4) RETURNING id, created_at
+		)
+		INSERT INTO webhook_deliveries (event_id, endpoint_id, status, next_attempt_at)
+		SELECT event.id, webhook_endpoints.id, $5, event.created_at
+		FROM event, webhook_endpoints WHERE webhook_endpoints.owned_by = $2`, event.Type, event.CustomerXid, payload, event.CreatedAt, DeliveryPending)
+
+	return err
+}
+
+// Sign returns the signature of body sent at timestamp, as set in
+// SignatureHeader.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(unix + "."))
+	_, _ = mac.Write(body)
+
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is how long to wait before retrying a delivery that failed attempts
+// times: 30s doubling with every attempt, up to 6h.
+func Backoff(attempts int) time.Duration {
+	backoff := backoffBase
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= backoffMax {
+			return backoffMax
+		}
+	}
+
+	return backoff
+}
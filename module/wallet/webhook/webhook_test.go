@@ -0,0 +1,161 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/herwando/mini-wallet/module/wallet/entity/model"
+	"github.com/herwando/mini-wallet/module/wallet/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	now         = time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	customerXid = "ea0212d3-abd6-406f-8c67-868e814a2436"
+	wallet      = &model.Wallet{
+		ID:       "81401b03-60e0-4f20-afc6-419b3773e7b3",
+		OwnedBy:  customerXid,
+		Balance:  decimal.NewFromInt(10000),
+		Currency: "IDR",
+	}
+	deposit = &model.Deposit{
+		ID:          "5a7c5c7e-3c2f-4e0c-9a53-0c1c0b0f7a11",
+		DepositedBy: customerXid,
+		DepositedAt: now,
+		Amount:      decimal.NewFromInt(1000),
+		ReferenceID: "50535246-dcb2-4929-8cc9-004ea06f5241",
+		Currency:    "IDR",
+	}
+)
+
+func TestWebhook_Enqueue(t *testing.T) {
+	testCases := map[string]struct {
+		event   *webhook.Event
+		wantErr bool
+	}{
+		"success deposit": {
+			event:   webhook.NewDepositEvent(deposit, wallet),
+			wantErr: false,
+		},
+		"success conversion": {
+			event: webhook.NewConversionEvent(&model.Conversion{
+				ID:              "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a",
+				ConvertedBy:     customerXid,
+				ConvertedAt:     now,
+				FromCurrency:    "IDR",
+				ToCurrency:      "USD",
+				Amount:          decimal.NewFromInt(16000),
+				ConvertedAmount: decimal.NewFromInt(1),
+				Rate:            decimal.RequireFromString("0.0000625"),
+				ReferenceID:     "50535246-dcb2-4929-8cc9-004ea06f5241",
+			}, wallet, wallet),
+			wantErr: false,
+		},
+		"success wallet": {
+			event:   webhook.NewWalletEvent(webhook.EventWalletDisabled, wallet, now),
+			wantErr: false,
+		},
+		"failed insert": {
+			event:   webhook.NewDepositEvent(deposit, wallet),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, sMock, _ := sqlmock.New()
+			sMock.ExpectBegin()
+			exec := sMock.ExpectExec(regexp.QuoteMeta("WITH event AS ( INSERT INTO webhook_events (event_type, customer_xid, payload, created_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at ) INSERT INTO webhook_deliveries (event_id, endpoint_id, status, next_attempt_at) SELECT event.id, webhook_endpoints.id, $5, event.created_at FROM event, webhook_endpoints WHERE webhook_endpoints.owned_by = $2")).
+				WithArgs(tc.event.Type, customerXid, sqlmock.AnyArg(), now, webhook.DeliveryPending)
+			if tc.wantErr {
+				exec.WillReturnError(errors.New("database error"))
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+
+			tx, _ := db.Begin()
+			err := webhook.Enqueue(context.Background(), tx, tc.event)
+
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, sMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhook_NewTransferEvents(t *testing.T) {
+	recipient := &model.Wallet{ID: "2b1f0c3d-4e5a-4b6c-8d7e-9f0a1b2c3d4e", OwnedBy: "6f1e2d3c-4b5a-4968-8776-5a4b3c2d1e0f", Currency: "IDR"}
+	transfer := &model.Transfer{
+		ID:            "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f",
+		TransferredBy: customerXid,
+		TransferredTo: recipient.OwnedBy,
+		TransferredAt: now,
+		Amount:        decimal.NewFromInt(500),
+		ReferenceID:   "50535246-dcb2-4929-8cc9-004ea06f5241",
+		Currency:      "IDR",
+	}
+
+	events := webhook.NewTransferEvents(transfer, wallet, recipient)
+
+	assert.Equal(t, []*webhook.Event{
+		{
+			Type:        webhook.EventTransferCreated,
+			CustomerXid: customerXid,
+			CreatedAt:   now,
+			Data: webhook.TransferData{
+				ID:           transfer.ID,
+				WalletID:     wallet.ID,
+				Direction:    webhook.TransferOut,
+				ReferenceID:  transfer.ReferenceID,
+				Amount:       transfer.Amount,
+				Currency:     "IDR",
+				TransactedAt: now,
+			},
+		},
+		{
+			Type:        webhook.EventTransferCreated,
+			CustomerXid: recipient.OwnedBy,
+			CreatedAt:   now,
+			Data: webhook.TransferData{
+				ID:           transfer.ID,
+				WalletID:     recipient.ID,
+				Direction:    webhook.TransferIn,
+				Amount:       transfer.Amount,
+				Currency:     "IDR",
+				TransactedAt: now,
+			},
+		},
+	}, events)
+}
+
+func TestWebhook_Sign(t *testing.T) {
+	// echo -n '1792317600.{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	got := webhook.Sign("secret", now, []byte(`{"id":"1"}`))
+	assert.Equal(t, "t=1792317600,v1=da62a990520dbbe53e9f3b69c4f062c1b7a3be394f54e44ad6956db866782969", got)
+}
+
+func TestWebhook_Backoff(t *testing.T) {
+	testCases := map[string]struct {
+		attempts int
+		want     time.Duration
+	}{
+		"first":  {attempts: 1, want: 30 * time.Second},
+		"second": {attempts: 2, want: time.Minute},
+		"fifth":  {attempts: 5, want: 8 * time.Minute},
+		"capped": {attempts: 20, want: 6 * time.Hour},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, webhook.Backoff(tc.attempts))
+		})
+	}
+}